@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/iteratr/internal/config"
+	"github.com/mark3labs/iteratr/internal/session"
+	"github.com/spf13/cobra"
+)
+
+var backupFlags struct {
+	dataDir string
+	output  string
+	check   bool
+	force   bool
+}
+
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Back up all sessions without stopping them",
+	Long: `Write a backup archive of the whole event log: every session, transcript
+and the knowledge base, as of one point in time. Running sessions keep
+running; with NATS storage the server takes a consistent snapshot of the
+stream, with file storage the event file is copied under its lock.
+
+Archives go to backup.dir (default: <data-dir>/backups), where only the
+newest backup.keep are kept. Set backup.every to also back up from the build
+loop every N iterations.
+
+Examples:
+  iteratr backup
+  iteratr backup -o before-upgrade.tar`,
+	Args: cobra.NoArgs,
+	RunE: runBackup,
+}
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore <file>",
+	Short: "Replace all sessions with a backup",
+	Long: `Replace the whole event log with a backup archive written by 'iteratr backup'.
+The archive's checksum is verified before anything is changed, and so is the
+restored log; if the restore fails, the current data is put back. Restoring
+is refused while a build runs any session, in the current data or in the
+backup. The current data is first saved as a backup of its own in backup.dir.
+
+Use --check to only verify an archive.
+
+Examples:
+  iteratr restore --check .iteratr/backups/iteratr-20250101-120000.000.tar
+  iteratr restore .iteratr/backups/iteratr-20250101-120000.000.tar`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRestore,
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{backupCmd, restoreCmd} {
+		cmd.Flags().StringVar(&backupFlags.dataDir, "data-dir", "", "Data directory (overrides config file, default: .iteratr)")
+	}
+	backupCmd.Flags().StringVarP(&backupFlags.output, "output", "o", "", "Output file (default: a new archive in backup.dir)")
+	restoreCmd.Flags().BoolVar(&backupFlags.check, "check", false, "Only verify the archive")
+	restoreCmd.Flags().BoolVarP(&backupFlags.force, "force", "f", false, "Restore without asking for confirmation")
+}
+
+func runBackup(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	dataDir := resolveDataDir(backupFlags.dataDir)
+
+	store, cleanup, err := setupWizardStore(dataDir)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	ctx := context.Background()
+	var path string
+	var manifest *session.BackupManifest
+	if backupFlags.output != "" {
+		path = backupFlags.output
+		manifest, err = backupToFile(ctx, store, path)
+	} else {
+		path, manifest, err = store.BackupToDir(ctx, cfg.Backup.DirFor(dataDir), cfg.Backup.Keep)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Backed up %d messages of %d sessions to %s\n", manifest.Messages, len(manifest.Sessions), path)
+	return nil
+}
+
+// backupToFile writes a backup archive to path, removing it again if the
+// backup fails.
+func backupToFile(ctx context.Context, store *session.Store, path string) (*session.BackupManifest, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create backup file: %w", err)
+	}
+	manifest, err := store.Backup(ctx, f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return nil, err
+	}
+	return manifest, nil
+}
+
+func runRestore(cmd *cobra.Command, args []string) error {
+	f, err := os.Open(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	manifest, err := session.VerifyBackup(f)
+	if err != nil {
+		return err
+	}
+	fmt.Print(describeBackup(manifest))
+	if backupFlags.check {
+		fmt.Println("Backup is intact.")
+		return nil
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	dataDir := resolveDataDir(backupFlags.dataDir)
+
+	if !backupFlags.force {
+		fmt.Printf("Replace all sessions in %s with this backup? [y/N]: ", dataDir)
+		var response string
+		_, _ = fmt.Scanln(&response)
+		if response != "y" && response != "Y" {
+			fmt.Println("Nothing restored.")
+			return nil
+		}
+	}
+
+	store, cleanup, err := setupWizardStore(dataDir)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	// Restore checks this too while holding the sessions' locks, but by then
+	// the current data has been saved for nothing
+	ctx := context.Background()
+	if err := checkNotRunning(ctx, store, manifest.Sessions); err != nil {
+		return err
+	}
+
+	// Kept whatever the backup directory's limit, so the archive being
+	// restored is never pruned by it
+	saved, _, err := store.BackupToDir(ctx, cfg.Backup.DirFor(dataDir), 0)
+	if err != nil {
+		return fmt.Errorf("failed to save current data before restoring: %w", err)
+	}
+	fmt.Printf("Saved current data to %s\n", saved)
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := store.Restore(ctx, f); err != nil {
+		return err
+	}
+	fmt.Printf("Restored %d messages of %d sessions\n", manifest.Messages, len(manifest.Sessions))
+	return nil
+}
+
+// checkNotRunning fails if a build runs any of the current sessions or of
+// the named ones.
+func checkNotRunning(ctx context.Context, store *session.Store, names []string) error {
+	infos, err := store.ListSessions(ctx)
+	if err != nil {
+		return err
+	}
+	for _, info := range infos {
+		names = append(names, info.Name)
+	}
+	for _, name := range names {
+		holder, err := store.ReadLease(ctx, name)
+		if errors.Is(err, session.ErrNoLease) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read lock of session '%s': %w", name, err)
+		}
+		if !holder.Stale(time.Now()) {
+			return fmt.Errorf("session '%s' is running (locked by %s), stop it before restoring", name, holder)
+		}
+	}
+	return nil
+}
+
+// describeBackup summarizes a backup's manifest.
+func describeBackup(manifest *session.BackupManifest) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Backup of %s storage taken %s\n", manifest.Kind, manifest.CreatedAt.Local().Format(time.RFC3339))
+	fmt.Fprintf(&b, "  Messages: %d (up to sequence %d)\n", manifest.Messages, manifest.LastSequence)
+	sessions := "none"
+	if len(manifest.Sessions) > 0 {
+		sessions = strings.Join(manifest.Sessions, ", ")
+	}
+	fmt.Fprintf(&b, "  Sessions: %s\n", sessions)
+	return b.String()
+}
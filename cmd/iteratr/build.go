@@ -14,6 +14,7 @@ import (
 	"github.com/mark3labs/iteratr/internal/session"
 	"github.com/mark3labs/iteratr/internal/tui/wizard"
 	natsserver "github.com/nats-io/nats-server/v2/server"
+	natsgo "github.com/nats-io/nats.go"
 	"github.com/spf13/cobra"
 )
 
@@ -28,6 +29,7 @@ var buildFlags struct {
 	model             string
 	reset             bool
 	autoCommit        bool
+	observe           bool
 }
 
 var buildCmd = &cobra.Command{
@@ -43,7 +45,11 @@ Configuration is loaded from multiple sources with the following precedence:
   CLI flags > Environment variables > Project config > Global config > Defaults
 
 Project config: ./iteratr.yml
-Global config: ~/.config/iteratr/iteratr.yml`,
+Global config: ~/.config/iteratr/iteratr.yml
+
+Only one build runs a session at a time. A second build of a running session
+is refused; with --observe it opens the TUI read-only instead and follows the
+session as the first build runs it.`,
 	RunE: runBuild,
 }
 
@@ -57,10 +63,14 @@ func init() {
 	buildCmd.Flags().StringVar(&buildFlags.dataDir, "data-dir", ".iteratr", "Data directory for NATS storage (overrides config file)")
 	buildCmd.Flags().StringVarP(&buildFlags.model, "model", "m", "", "Model to use (overrides config file, e.g., anthropic/claude-sonnet-4-5)")
 	buildCmd.Flags().BoolVar(&buildFlags.reset, "reset", false, "Reset session data before starting (clears all NATS events for this session)")
+	buildCmd.Flags().BoolVar(&buildFlags.observe, "observe", false, "Watch the session read-only in the TUI instead of running it")
+	buildCmd.MarkFlagsMutuallyExclusive("observe", "reset")
+	buildCmd.MarkFlagsMutuallyExclusive("observe", "headless")
 	buildCmd.Flags().BoolVar(&buildFlags.autoCommit, "auto-commit", true, "Auto-commit modified files after iteration (overrides config file)")
 }
 
-// setupWizardStore creates a temporary NATS connection and session store for the wizard.
+// setupWizardStore creates a temporary NATS connection and session store for the wizard
+// (or opens the event file when file storage is configured).
 // Returns the store and a cleanup function that must be called when done.
 func setupWizardStore(dataDir string) (*session.Store, func(), error) {
 	// Ensure data directory exists
@@ -69,8 +79,32 @@ func setupWizardStore(dataDir string) (*session.Store, func(), error) {
 		return nil, nil, fmt.Errorf("failed to create data directory: %w", err)
 	}
 
-	// Try to connect to existing NATS server first
-	nc := nats.TryConnectExisting(fullDataDir)
+	// File storage needs no NATS server
+	var retention nats.Retention
+	var connect nats.ConnectOptions
+	var layout nats.Layout
+	if cfg, err := config.Load(); err == nil {
+		if cfg.Storage == session.StorageFile {
+			return openFileStore(fullDataDir)
+		}
+		if retention, err = streamRetention(cfg); err != nil {
+			return nil, nil, err
+		}
+		if connect, layout, err = natsSettings(cfg); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Use the configured external server, or an existing embedded one
+	var nc *natsgo.Conn
+	if connect.URL != "" {
+		var err error
+		if nc, err = nats.Connect(connect); err != nil {
+			return nil, nil, err
+		}
+	} else {
+		nc = nats.TryConnectExisting(fullDataDir)
+	}
 	var ns interface{} // NATS server (if we started one)
 
 	if nc == nil {
@@ -102,7 +136,7 @@ func setupWizardStore(dataDir string) (*session.Store, func(), error) {
 
 	// Setup stream
 	ctx := context.Background()
-	stream, err := nats.SetupStream(ctx, js)
+	stream, err := nats.SetupLayoutStream(ctx, js, layout, retention)
 	if err != nil {
 		nc.Close()
 		if ns != nil {
@@ -112,7 +146,10 @@ func setupWizardStore(dataDir string) (*session.Store, func(), error) {
 	}
 
 	// Create session store
-	store := session.NewStore(js, stream)
+	store := session.NewStore(session.NewJetStreamLogWithLayout(js, stream, layout).WithConn(nc))
+	if err := store.EnableSnapshots(ctx); err != nil {
+		logger.Warn("State snapshots unavailable: %v", err)
+	}
 
 	// Return cleanup function
 	cleanup := func() {
@@ -126,6 +163,59 @@ func setupWizardStore(dataDir string) (*session.Store, func(), error) {
 	return store, cleanup, nil
 }
 
+// streamRetention converts the configured retention to JetStream stream limits.
+func streamRetention(cfg *config.Config) (nats.Retention, error) {
+	maxAge, err := cfg.Retention.StreamMaxAge()
+	if err != nil {
+		return nats.Retention{}, err
+	}
+	if cfg.Retention.MaxBytes < 0 {
+		return nats.Retention{}, fmt.Errorf("invalid retention.max_bytes: must be >= 0")
+	}
+	return nats.Retention{MaxAge: maxAge, MaxBytes: cfg.Retention.MaxBytes}, nil
+}
+
+// natsSettings converts the configured external NATS server and JetStream
+// names. An empty URL means the embedded server.
+func natsSettings(cfg *config.Config) (nats.ConnectOptions, nats.Layout, error) {
+	layout := nats.Layout{
+		Stream:         cfg.NATS.Stream,
+		SubjectPrefix:  cfg.NATS.SubjectPrefix,
+		SnapshotBucket: cfg.NATS.SnapshotBucket,
+	}
+	if err := layout.Validate(); err != nil {
+		return nats.ConnectOptions{}, nats.Layout{}, fmt.Errorf("invalid nats config: %w", err)
+	}
+	return nats.ConnectOptions{
+		URL:      cfg.NATS.URL,
+		Creds:    cfg.NATS.Creds,
+		NKeySeed: cfg.NATS.NKey,
+		Token:    cfg.NATS.Token,
+		TLSCA:    cfg.NATS.TLS.CA,
+		TLSCert:  cfg.NATS.TLS.Cert,
+		TLSKey:   cfg.NATS.TLS.Key,
+	}, layout, nil
+}
+
+// openFileStore creates a session store on the event file in dataDir, for the
+// file storage backend. The cleanup function closes the file.
+func openFileStore(dataDir string) (*session.Store, func(), error) {
+	log, err := session.OpenFileLog(dataDir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open file storage: %w", err)
+	}
+
+	store := session.NewStore(log)
+	if err := store.EnableSnapshots(context.Background()); err != nil {
+		logger.Warn("State snapshots unavailable: %v", err)
+	}
+
+	cleanup := func() {
+		_ = store.Close()
+	}
+	return store, cleanup, nil
+}
+
 func runBuild(cmd *cobra.Command, args []string) error {
 	// Load config via Viper
 	cfg, err := config.Load()
@@ -168,14 +258,18 @@ func runBuild(cmd *cobra.Command, args []string) error {
 	if buildFlags.model == "" {
 		return fmt.Errorf("model not configured\n\nSet model via:\n  - iteratr setup (creates config file)\n  - ITERATR_MODEL environment variable\n  - --model flag")
 	}
+	if buildFlags.observe && buildFlags.headless {
+		return fmt.Errorf("--observe needs the TUI, but headless is enabled in the config")
+	}
 
 	// Track temp template file for cleanup
 	var tempTemplatePath string
-	// Track if we're resuming an existing session (spec is optional in this case)
-	resumeMode := false
+	// Track if we're resuming an existing session (spec is optional in this case,
+	// as it is when only observing one)
+	resumeMode := buildFlags.observe
 
-	// Run wizard if no spec provided and not headless
-	if buildFlags.spec == "" && !buildFlags.headless {
+	// Run wizard if no spec provided and not headless, unless observing a named session
+	if buildFlags.spec == "" && !buildFlags.headless && !(buildFlags.observe && buildFlags.name != "") {
 		logger.Info("No spec file provided, launching wizard...")
 
 		// Set up NATS for wizard session selector
@@ -270,16 +364,8 @@ func runBuild(cmd *cobra.Command, args []string) error {
 	}
 
 	// Validate session name (alphanumeric, hyphens, underscores only)
-	if sessionName == "" {
-		return fmt.Errorf("session name cannot be empty")
-	}
-	if len(sessionName) > 64 {
-		return fmt.Errorf("session name too long (max 64 characters): %s", sessionName)
-	}
-	for _, r := range sessionName {
-		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') && r != '-' && r != '_' {
-			return fmt.Errorf("invalid session name: %s (use only alphanumeric, hyphens, underscores)", sessionName)
-		}
+	if err := session.ValidateName(sessionName); err != nil {
+		return err
 	}
 
 	// Validate iteration count
@@ -302,6 +388,26 @@ func runBuild(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Price tokens for the selected model if configured
+	var pricing *session.Pricing
+	if p, ok := cfg.PricingFor(buildFlags.model); ok {
+		pricing = &session.Pricing{
+			Input:      p.Input,
+			Output:     p.Output,
+			CacheRead:  p.CacheRead,
+			CacheWrite: p.CacheWrite,
+		}
+	}
+
+	retention, err := streamRetention(cfg)
+	if err != nil {
+		return err
+	}
+	connect, layout, err := natsSettings(cfg)
+	if err != nil {
+		return err
+	}
+
 	// Create orchestrator
 	orch, err := orchestrator.New(orchestrator.Config{
 		SessionName:       sessionName,
@@ -314,6 +420,17 @@ func runBuild(cmd *cobra.Command, args []string) error {
 		Model:             buildFlags.model,
 		Reset:             buildFlags.reset,
 		AutoCommit:        buildFlags.autoCommit,
+		Scheduler:         cfg.Scheduler,
+		Pricing:           pricing,
+		ResetTasks:        cfg.ResetTasks,
+		Storage:           cfg.Storage,
+		Retention:         retention,
+		NATS:              connect,
+		Layout:            layout,
+		Observe:           buildFlags.observe,
+		BackupEvery:       cfg.Backup.Every,
+		BackupDir:         cfg.Backup.DirFor(buildFlags.dataDir),
+		BackupKeep:        cfg.Backup.Keep,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to create orchestrator: %w", err)
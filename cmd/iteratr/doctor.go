@@ -1,12 +1,16 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os/exec"
 	"strings"
 
 	"charm.land/lipgloss/v2"
 	"charm.land/lipgloss/v2/table"
+	"github.com/mark3labs/iteratr/internal/config"
+	"github.com/mark3labs/iteratr/internal/nats"
+	"github.com/mark3labs/iteratr/internal/session"
 	"github.com/spf13/cobra"
 )
 
@@ -18,10 +22,30 @@ var doctorCmd = &cobra.Command{
 This command verifies that:
 - opencode is installed and in PATH
 - The data directory is writable
-- Other environment requirements are met`,
+- The external NATS server, if configured, is reachable with JetStream
+- Other environment requirements are met
+
+With --events, it also replays every event in the data directory and reports
+events that cannot be applied (malformed JSON, bad metadata, unknown actions,
+or a schema version newer than this build), along with how many events were
+written by older versions and are upgraded when read.
+
+Examples:
+  iteratr doctor
+  iteratr doctor --events --data-dir .iteratr`,
 	RunE: runDoctor,
 }
 
+var doctorFlags struct {
+	events  bool
+	dataDir string
+}
+
+func init() {
+	doctorCmd.Flags().BoolVar(&doctorFlags.events, "events", false, "Validate the events in the data directory")
+	doctorCmd.Flags().StringVar(&doctorFlags.dataDir, "data-dir", "", "Data directory (overrides config file, default: .iteratr)")
+}
+
 // Theme colors (catppuccin mocha)
 var (
 	colorPrimary = lipgloss.Color("#cba6f7") // Mauve
@@ -41,7 +65,9 @@ type checkResult struct {
 
 func runDoctor(cmd *cobra.Command, args []string) error {
 	var results []checkResult
+	var problems []string
 	allOk := true
+	eventsOk := true
 
 	// Check for opencode
 	if _, err := exec.LookPath("opencode"); err != nil {
@@ -69,6 +95,46 @@ func runDoctor(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Check the external NATS server, if one is configured
+	if cfg, err := config.Load(); err == nil && cfg.NATS.URL != "" {
+		result := checkResult{name: "nats", status: "OK", details: cfg.NATS.URL}
+		if err := checkNATS(cfg); err != nil {
+			result.status = "FAIL"
+			result.details = err.Error()
+			allOk = false
+		}
+		results = append(results, result)
+	}
+
+	if doctorFlags.events {
+		checks, err := checkDataDir(resolveDataDir(doctorFlags.dataDir))
+		if err != nil {
+			return err
+		}
+		for _, check := range checks {
+			result := checkResult{
+				name:    "events: " + check.Name,
+				status:  "OK",
+				details: fmt.Sprintf("%d events", check.Events),
+			}
+			if check.Outdated > 0 {
+				result.details += fmt.Sprintf(" (%d upgraded from older versions)", check.Outdated)
+			}
+			if len(check.Problems) > 0 {
+				result.status = "FAIL"
+				result.details = fmt.Sprintf("%d of %d events corrupt", len(check.Problems), check.Events)
+				eventsOk = false
+			}
+			results = append(results, result)
+			for _, problem := range check.Problems {
+				problems = append(problems, fmt.Sprintf("%s seq %d: %v", check.Name, problem.Sequence, problem.Err))
+			}
+		}
+		if len(checks) == 0 {
+			results = append(results, checkResult{name: "events", status: "OK", details: "No events"})
+		}
+	}
+
 	// Build rows with status icons
 	rows := make([][]string, len(results))
 	for i, r := range results {
@@ -88,7 +154,7 @@ func runDoctor(cmd *cobra.Command, args []string) error {
 	t := table.New().
 		Border(lipgloss.RoundedBorder()).
 		BorderStyle(lipgloss.NewStyle().Foreground(colorBorder)).
-		Headers("Dependency", "Status", "Details").
+		Headers("Check", "Status", "Details").
 		Rows(rows...).
 		StyleFunc(func(row, col int) lipgloss.Style {
 			if row == table.HeaderRow {
@@ -124,16 +190,58 @@ func runDoctor(cmd *cobra.Command, args []string) error {
 
 	fmt.Println(t)
 
+	if len(problems) > 0 {
+		fmt.Println()
+		for _, problem := range problems {
+			fmt.Println(lipgloss.NewStyle().Foreground(colorMuted).Render("  " + problem))
+		}
+	}
+
 	// Summary
 	fmt.Println()
 	successStyle := lipgloss.NewStyle().Foreground(colorSuccess)
 	errorStyle := lipgloss.NewStyle().Foreground(colorError)
 
-	if allOk {
+	switch {
+	case allOk && eventsOk:
 		fmt.Println(successStyle.Render("✓ All checks passed!"))
 		return nil
-	} else {
+	case !allOk:
 		fmt.Println(errorStyle.Render("⊗ Some checks failed. Please install missing dependencies."))
-		return fmt.Errorf("doctor check failed")
+	default:
+		fmt.Println(errorStyle.Render("⊗ Corrupt events found. They are skipped when sessions are loaded."))
+	}
+	return fmt.Errorf("doctor check failed")
+}
+
+// checkDataDir validates the events of every session in dataDir.
+func checkDataDir(dataDir string) ([]session.EventCheck, error) {
+	store, cleanup, err := setupWizardStore(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+	return store.CheckEvents(context.Background())
+}
+
+// checkNATS connects to the configured external NATS server and verifies that
+// JetStream is enabled for the account.
+func checkNATS(cfg *config.Config) error {
+	connect, _, err := natsSettings(cfg)
+	if err != nil {
+		return err
+	}
+	nc, err := nats.Connect(connect)
+	if err != nil {
+		return err
+	}
+	defer nc.Close()
+	js, err := nats.CreateJetStream(nc)
+	if err != nil {
+		return fmt.Errorf("failed to create JetStream: %w", err)
+	}
+	if _, err := js.AccountInfo(context.Background()); err != nil {
+		return fmt.Errorf("JetStream unavailable: %w", err)
 	}
+	return nil
 }
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mark3labs/iteratr/internal/config"
+	"github.com/mark3labs/iteratr/internal/logger"
+	"github.com/mark3labs/iteratr/internal/session"
+	"github.com/spf13/cobra"
+)
+
+var gcFlags struct {
+	dataDir    string
+	archiveDir string
+	olderThan  string
+	dryRun     bool
+	compact    bool
+}
+
+var gcCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Archive and purge old completed sessions",
+	Long: `Archive completed sessions that have been inactive for longer than
+retention.archive_after and remove them from the event log. Nothing is
+archived by age unless retention.archive_after, a per-session override or
+--older-than is set.
+Each session is written to a gzip-compressed archive in the archive directory
+that 'iteratr session import' can restore, and its transcripts to a
+<session>-<timestamp>.transcripts.jsonl.gz archive next to it.
+
+Sessions set to "never" under retention.sessions are always kept, and
+sessions that are not complete are never archived. With --compact, the
+sessions that are kept are compacted as well.
+
+Examples:
+  iteratr gc --dry-run
+  iteratr gc --older-than 30d --compact`,
+	Args: cobra.NoArgs,
+	RunE: runGC,
+}
+
+func init() {
+	gcCmd.Flags().StringVar(&gcFlags.dataDir, "data-dir", "", "Data directory (overrides config file, default: .iteratr)")
+	gcCmd.Flags().StringVar(&gcFlags.archiveDir, "archive-dir", "", "Where to write archives (default: <data-dir>/archive)")
+	gcCmd.Flags().StringVar(&gcFlags.olderThan, "older-than", "", "Archive completed sessions inactive for longer than this (overrides retention.archive_after)")
+	gcCmd.Flags().BoolVar(&gcFlags.dryRun, "dry-run", false, "Only list the sessions that would be archived")
+	gcCmd.Flags().BoolVar(&gcFlags.compact, "compact", false, "Also compact the sessions that are kept")
+}
+
+func runGC(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	retention := cfg.Retention
+	if gcFlags.olderThan != "" {
+		if _, err := config.ParseDuration(gcFlags.olderThan); err != nil {
+			return fmt.Errorf("invalid --older-than: %w", err)
+		}
+		retention.ArchiveAfter = gcFlags.olderThan
+	}
+
+	dataDir := resolveDataDir(gcFlags.dataDir)
+	archiveDir := gcFlags.archiveDir
+	if archiveDir == "" {
+		archiveDir = filepath.Join(dataDir, "archive")
+	}
+
+	store, cleanup, err := setupWizardStore(dataDir)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	ctx := context.Background()
+	infos, err := store.ListSessions(ctx)
+	if err != nil {
+		return err
+	}
+	expired, kept, err := gcCandidates(infos, retention, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if len(expired) == 0 {
+		fmt.Println("No sessions to archive")
+		if retention.ArchiveAfter == "" && len(retention.Sessions) == 0 {
+			fmt.Println("Archiving by age is off: set retention.archive_after or pass --older-than")
+		}
+	}
+	for _, info := range expired {
+		if gcFlags.dryRun {
+			fmt.Printf("Would archive '%s' (last active %s)\n", info.Name, info.LastActivity.Format(time.RFC3339))
+			continue
+		}
+		path, err := archiveSession(ctx, store, info.Name, archiveDir)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Archived '%s' to %s\n", info.Name, path)
+	}
+
+	if gcFlags.compact {
+		for _, name := range kept {
+			if gcFlags.dryRun {
+				fmt.Printf("Would compact '%s'\n", name)
+				continue
+			}
+			before, after, err := compactSession(ctx, store, name)
+			if err != nil {
+				// Keep going: one session failing to compact doesn't affect the others
+				logger.Warn("Failed to compact session '%s': %v", name, err)
+				fmt.Fprintf(os.Stderr, "Failed to compact '%s': %v\n", name, err)
+				continue
+			}
+			fmt.Printf("Compacted '%s' from %d to %d events\n", name, before, after)
+		}
+	}
+	return nil
+}
+
+// gcCandidates splits sessions into those due for archiving (complete and
+// inactive for longer than their archive_after) and the names of the rest.
+func gcCandidates(infos []session.SessionInfo, retention config.Retention, now time.Time) (expired []session.SessionInfo, kept []string, err error) {
+	for _, info := range infos {
+		after, keep, err := retention.ArchiveAfterFor(info.Name)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !keep && info.Complete && now.Sub(info.LastActivity) >= after {
+			expired = append(expired, info)
+			continue
+		}
+		kept = append(kept, info.Name)
+	}
+	return expired, kept, nil
+}
+
+// compactSession compacts a session while holding its lease, so a running
+// session is left alone.
+func compactSession(ctx context.Context, store *session.Store, name string) (int, int, error) {
+	lease, err := store.AcquireLease(ctx, name)
+	if err != nil {
+		return 0, 0, fmt.Errorf("can't compact a running session: %w", err)
+	}
+	defer func() { _ = lease.Release(ctx) }()
+	return store.CompactSession(ctx, name)
+}
+
+// archiveSession exports a session to gzip-compressed archives in dir, its
+// events to <session>-<timestamp>.jsonl.gz and its transcripts, if any, to
+// <session>-<timestamp>.transcripts.jsonl.gz. The session is removed from the
+// event log only once the archives are safely written and hold all of its
+// messages. The session's lease is held throughout, so nothing is appended
+// after the export. Returns the events archive's path.
+func archiveSession(ctx context.Context, store *session.Store, name, dir string) (string, error) {
+	lease, err := store.AcquireLease(ctx, name)
+	if err != nil {
+		return "", fmt.Errorf("can't archive a running session '%s': %w", name, err)
+	}
+	defer func() { _ = lease.Release(ctx) }()
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create archive directory: %w", err)
+	}
+	base := filepath.Join(dir, fmt.Sprintf("%s-%s", name, time.Now().Format("20060102-150405")))
+	path, transcriptPath := base+".jsonl.gz", base+".transcripts.jsonl.gz"
+
+	events, err := writeGzip(path, func(w io.Writer) (int, error) {
+		return store.ExportSession(ctx, name, w)
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to archive session '%s': %w", name, err)
+	}
+	entries, err := writeGzip(transcriptPath, func(w io.Writer) (int, error) {
+		return store.ExportTranscripts(ctx, name, w)
+	})
+	if err == nil && entries == 0 {
+		err = os.Remove(transcriptPath)
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return "", fmt.Errorf("failed to archive transcripts of session '%s': %w", name, err)
+	}
+
+	// Unreadable events are left out of the export, and would be lost
+	total, err := store.MessageCount(ctx, name)
+	if err == nil && total != uint64(events+entries) {
+		err = fmt.Errorf("archived %d of its %d messages", events+entries, total)
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		_ = os.Remove(transcriptPath)
+		return "", fmt.Errorf("session '%s' not archived: %w", name, err)
+	}
+
+	if err := store.ResetSession(ctx, name); err != nil {
+		return "", fmt.Errorf("archived session '%s' to %s but failed to purge it: %w", name, path, err)
+	}
+	return path, nil
+}
+
+// writeGzip creates a gzip-compressed file at path with the contents written
+// by write, removing it again if writing fails. Returns write's count.
+func writeGzip(path string, write func(w io.Writer) (int, error)) (int, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create archive: %w", err)
+	}
+
+	gz := gzip.NewWriter(f)
+	count, err := write(gz)
+	if err == nil {
+		err = gz.Close()
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return 0, err
+	}
+	return count, nil
+}
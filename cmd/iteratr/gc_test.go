@@ -0,0 +1,149 @@
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/iteratr/internal/config"
+	"github.com/mark3labs/iteratr/internal/nats"
+	"github.com/mark3labs/iteratr/internal/session"
+)
+
+func TestGCCandidates(t *testing.T) {
+	now := time.Now()
+	infos := []session.SessionInfo{
+		{Name: "old", Complete: true, LastActivity: now.Add(-10 * 24 * time.Hour)},
+		{Name: "recent", Complete: true, LastActivity: now.Add(-time.Hour)},
+		{Name: "running", Complete: false, LastActivity: now.Add(-30 * 24 * time.Hour)},
+		{Name: "pinned", Complete: true, LastActivity: now.Add(-30 * 24 * time.Hour)},
+		{Name: "scratch", Complete: true, LastActivity: now.Add(-2 * time.Hour)},
+	}
+	retention := config.Retention{
+		ArchiveAfter: "7d",
+		Sessions:     map[string]string{"pinned": "never", "scratch": "1h"},
+	}
+
+	expired, kept, err := gcCandidates(infos, retention, now)
+	if err != nil {
+		t.Fatalf("gcCandidates failed: %v", err)
+	}
+	var names []string
+	for _, info := range expired {
+		names = append(names, info.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"old", "scratch"}) {
+		t.Errorf("expected old and scratch to expire, got %v", names)
+	}
+	if !reflect.DeepEqual(kept, []string{"recent", "running", "pinned"}) {
+		t.Errorf("unexpected kept sessions: %v", kept)
+	}
+
+	// Nothing is archived by age unless configured
+	if expired, _, err := gcCandidates(infos, config.Retention{}, now); err != nil || len(expired) != 0 {
+		t.Errorf("expected nothing to expire without archive_after, got %v (%v)", expired, err)
+	}
+
+	if _, _, err := gcCandidates(infos, config.Retention{ArchiveAfter: "soon"}, now); err == nil {
+		t.Error("expected error for invalid archive_after")
+	}
+}
+
+func TestArchiveSession(t *testing.T) {
+	ctx := context.Background()
+	store := session.NewStore(session.NewMemoryLog())
+	_, _ = store.TaskAdd(ctx, "done", session.TaskAddParams{Content: "Ship it"})
+	_ = store.AppendTranscript(ctx, "done", session.TranscriptEntry{Iteration: 1, Kind: session.TranscriptKindText, Content: "Shipped."})
+
+	path, err := archiveSession(ctx, store, "done", t.TempDir())
+	if err != nil {
+		t.Fatalf("archiveSession failed: %v", err)
+	}
+	if exists, _ := store.SessionExists(ctx, "done"); exists {
+		t.Error("expected archived session to be purged")
+	}
+
+	// The transcripts are archived next to the events
+	tf, err := os.Open(strings.TrimSuffix(path, ".jsonl.gz") + ".transcripts.jsonl.gz")
+	if err != nil {
+		t.Fatalf("failed to open transcript archive: %v", err)
+	}
+	defer func() { _ = tf.Close() }()
+	tgz, err := gzip.NewReader(tf)
+	if err != nil {
+		t.Fatalf("transcript archive is not gzip-compressed: %v", err)
+	}
+	var entry session.TranscriptEntry
+	if err := json.NewDecoder(tgz).Decode(&entry); err != nil {
+		t.Fatalf("failed to read transcript entry: %v", err)
+	}
+	if entry.Iteration != 1 || entry.Content != "Shipped." {
+		t.Errorf("unexpected transcript entry: %+v", entry)
+	}
+
+	// The archive restores the session
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open archive: %v", err)
+	}
+	defer func() { _ = f.Close() }()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("archive is not gzip-compressed: %v", err)
+	}
+	if _, count, err := store.ImportSession(ctx, gz, ""); err != nil || count != 1 {
+		t.Fatalf("expected to import 1 event, got %d (%v)", count, err)
+	}
+	state, _ := store.LoadState(ctx, "done")
+	if state.Tasks["TAS-1"] == nil {
+		t.Error("expected task restored from archive")
+	}
+}
+
+func TestArchiveSession_Running(t *testing.T) {
+	ctx := context.Background()
+	store := session.NewStore(session.NewMemoryLog())
+	_, _ = store.TaskAdd(ctx, "busy", session.TaskAddParams{Content: "Ship it"})
+
+	lease, err := store.AcquireLease(ctx, "busy")
+	if err != nil {
+		t.Fatalf("AcquireLease failed: %v", err)
+	}
+	defer func() { _ = lease.Release(ctx) }()
+
+	dir := t.TempDir()
+	if _, err := archiveSession(ctx, store, "busy", dir); !errors.Is(err, session.ErrSessionLocked) {
+		t.Fatalf("expected ErrSessionLocked, got %v", err)
+	}
+	if exists, _ := store.SessionExists(ctx, "busy"); !exists {
+		t.Error("expected running session to be kept")
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("expected no archive written, got %d files", len(entries))
+	}
+}
+
+func TestArchiveSession_Incomplete(t *testing.T) {
+	ctx := context.Background()
+	log := session.NewMemoryLog()
+	store := session.NewStore(log)
+	_, _ = store.TaskAdd(ctx, "done", session.TaskAddParams{Content: "Ship it"})
+	_, _ = log.Append(ctx, nats.SubjectForEvent("done", nats.EventTypeTask), []byte("not json"))
+
+	dir := t.TempDir()
+	if _, err := archiveSession(ctx, store, "done", dir); err == nil {
+		t.Fatal("expected error archiving a session with an unreadable event")
+	}
+	if exists, _ := store.SessionExists(ctx, "done"); !exists {
+		t.Error("expected session to be kept")
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("expected no archive left behind, got %d files", len(entries))
+	}
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/iteratr/internal/session"
+	"github.com/spf13/cobra"
+)
+
+var historyFlags struct {
+	dataDir   string
+	seq       uint64
+	at        string
+	iteration int
+	state     bool
+}
+
+var historyCmd = &cobra.Command{
+	Use:   "history <session>",
+	Short: "Show a session's event log and past states",
+	Long: `Print a session's event log with stream sequence numbers.
+
+Use --seq, --time or --iteration to stop the log at a point in history, and
+--state to render the session state reconstructed at that point (or at the
+end of the log if no point is given).
+
+Examples:
+  iteratr history my-session
+  iteratr history my-session --iteration 12 --state
+  iteratr history my-session --seq 41 --state   # right before event 42`,
+	Args: cobra.ExactArgs(1),
+	RunE: runHistory,
+}
+
+func init() {
+	historyCmd.Flags().StringVar(&historyFlags.dataDir, "data-dir", "", "Data directory (overrides config file, default: .iteratr)")
+	historyCmd.Flags().Uint64Var(&historyFlags.seq, "seq", 0, "Stop after the event with this sequence number")
+	historyCmd.Flags().StringVar(&historyFlags.at, "time", "", "Stop after the last event at or before this time (RFC3339)")
+	historyCmd.Flags().IntVar(&historyFlags.iteration, "iteration", 0, "Stop at the end of this iteration")
+	historyCmd.Flags().BoolVar(&historyFlags.state, "state", false, "Render the reconstructed state instead of the event log")
+}
+
+func runHistory(cmd *cobra.Command, args []string) error {
+	sessionName := args[0]
+
+	// Parse the point in history, if any
+	var point session.StatePoint
+	hasPoint := false
+	if historyFlags.seq > 0 {
+		point.Sequence = historyFlags.seq
+		hasPoint = true
+	}
+	if historyFlags.at != "" {
+		t, err := time.Parse(time.RFC3339, historyFlags.at)
+		if err != nil {
+			return fmt.Errorf("invalid --time (expected RFC3339, e.g. 2026-01-02T15:04:05Z): %w", err)
+		}
+		point.Time = t
+		hasPoint = true
+	}
+	if historyFlags.iteration > 0 {
+		point.Iteration = historyFlags.iteration
+		hasPoint = true
+	}
+
+	if hasPoint {
+		if err := point.Validate(); err != nil {
+			return fmt.Errorf("use only one of --seq, --time and --iteration")
+		}
+	}
+
+	store, cleanup, err := setupWizardStore(resolveDataDir(historyFlags.dataDir))
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	ctx := context.Background()
+
+	if historyFlags.state {
+		var state *session.State
+		var lastSeq uint64
+		if hasPoint {
+			state, lastSeq, err = store.LoadStateAt(ctx, sessionName, point)
+		} else {
+			state, err = store.LoadState(ctx, sessionName)
+		}
+		if err != nil {
+			return err
+		}
+		label := "latest"
+		if hasPoint {
+			label = fmt.Sprintf("%s (last event seq %d)", point, lastSeq)
+		}
+		fmt.Print(renderHistoryState(state, label))
+		return nil
+	}
+
+	events, err := store.History(ctx, sessionName)
+	if err != nil {
+		return err
+	}
+	if len(events) == 0 {
+		return fmt.Errorf("session %q has no events", sessionName)
+	}
+
+	// Cut the log at the requested point
+	if hasPoint {
+		end := 0
+		for end < len(events) && point.Includes(events[end].Sequence, events[end].Event) {
+			end++
+		}
+		events = events[:end]
+	}
+
+	for _, e := range events {
+		fmt.Println(formatHistoryEvent(e))
+	}
+	return nil
+}
+
+// formatHistoryEvent formats one event log line: sequence, time, type/action and data.
+func formatHistoryEvent(e session.HistoryEvent) string {
+	data := strings.ReplaceAll(e.Data, "\n", " ")
+	if len(data) > 80 {
+		data = data[:77] + "..."
+	}
+	return fmt.Sprintf("%6d  %s  %-22s %s",
+		e.Sequence, e.Timestamp.Local().Format("2006-01-02 15:04:05"), e.Type+"/"+e.Action, data)
+}
+
+// renderHistoryState renders a reconstructed session state for the history command.
+func renderHistoryState(state *session.State, label string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("State of %s at %s\n", state.Session, label))
+
+	// Tasks grouped by status, subtasks nested under their parent
+	byStatus := make(map[string][]*session.Task)
+	for _, task := range state.Tasks {
+		byStatus[task.Status] = append(byStatus[task.Status], task)
+	}
+	sb.WriteString(fmt.Sprintf("\nTasks (%d):\n", len(state.Tasks)))
+	for _, status := range []string{"remaining", "in_progress", "completed", "blocked", "cancelled"} {
+		tasks := byStatus[status]
+		if len(tasks) == 0 {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("  %s:\n", strings.ReplaceAll(status, "_", " ")))
+		for _, node := range session.TaskTree(tasks) {
+			t := node.Task
+			sb.WriteString(fmt.Sprintf("    %s[%s] P%d %s\n", strings.Repeat("  ", node.Depth), t.ID, t.Priority, t.Content))
+		}
+	}
+
+	sb.WriteString(fmt.Sprintf("\nNotes (%d):\n", len(state.Notes)))
+	for _, note := range state.Notes {
+		sb.WriteString(fmt.Sprintf("  [%s] (#%d) %s\n", note.Type, note.Iteration, note.Content))
+	}
+
+	sb.WriteString(fmt.Sprintf("\nIterations (%d):\n", len(state.Iterations)))
+	for _, iter := range state.Iterations {
+		status := "running"
+		if !iter.EndedAt.IsZero() {
+			status = iter.Outcome
+		}
+		line := fmt.Sprintf("  #%d %s", iter.Number, status)
+		if iter.Duration > 0 {
+			line += fmt.Sprintf(" in %s", iter.Duration.Round(time.Second))
+		}
+		if iter.Model != "" {
+			line += fmt.Sprintf(" (%s)", iter.Model)
+		}
+		if iter.Summary != "" {
+			line += ": " + iter.Summary
+		}
+		sb.WriteString(line + "\n")
+		if iter.Error != "" {
+			sb.WriteString(fmt.Sprintf("      error: %s\n", iter.Error))
+		}
+		if len(iter.InterruptedTasks) > 0 {
+			sb.WriteString(fmt.Sprintf("      interrupted: %s\n", strings.Join(iter.InterruptedTasks, ", ")))
+		}
+		for _, f := range iter.Files {
+			marker := ""
+			if f.IsNew {
+				marker = " (new)"
+			}
+			sb.WriteString(fmt.Sprintf("      %s%s +%d -%d\n", f.Path, marker, f.Additions, f.Deletions))
+		}
+	}
+
+	if state.Complete {
+		sb.WriteString("\nSession complete\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/mark3labs/iteratr/internal/session"
+	"github.com/spf13/cobra"
+)
+
+var knowledgeFlags struct {
+	dataDir   string
+	all       bool
+	entryType string
+	content   string
+	reason    string
+}
+
+var knowledgeCmd = &cobra.Command{
+	Use:   "knowledge",
+	Short: "Curate the project knowledge base",
+	Long: `The knowledge base keeps learnings, tips and decisions across sessions.
+Active entries are injected into every prompt through the {{knowledge}}
+template variable (the most relevant ones to the spec if there are many).
+
+Examples:
+  iteratr knowledge promote my-session NOT-4
+  iteratr knowledge add "Integration tests need docker running" --type tip
+  iteratr knowledge retire KNO-2 --reason "replaced by KNO-7"`,
+}
+
+var knowledgeListCmd = &cobra.Command{
+	Use:     "ls",
+	Aliases: []string{"list"},
+	Short:   "List knowledge entries",
+	Args:    cobra.NoArgs,
+	RunE:    runKnowledgeList,
+}
+
+var knowledgeAddCmd = &cobra.Command{
+	Use:   "add <content>",
+	Short: "Add a knowledge entry",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runKnowledgeAdd,
+}
+
+var knowledgePromoteCmd = &cobra.Command{
+	Use:   "promote <session> <note-id>",
+	Short: "Copy a learning, tip or decision note into the knowledge base",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runKnowledgePromote,
+}
+
+var knowledgeEditCmd = &cobra.Command{
+	Use:   "edit <id>",
+	Short: "Change a knowledge entry's content or type",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runKnowledgeEdit,
+}
+
+var knowledgeRetireCmd = &cobra.Command{
+	Use:   "retire <id>",
+	Short: "Stop injecting an outdated entry into prompts",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runKnowledgeRetire,
+}
+
+var knowledgeRestoreCmd = &cobra.Command{
+	Use:   "restore <id>",
+	Short: "Make a retired entry active again",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runKnowledgeRestore,
+}
+
+func init() {
+	knowledgeCmd.PersistentFlags().StringVar(&knowledgeFlags.dataDir, "data-dir", "", "Data directory (overrides config file, default: .iteratr)")
+	knowledgeListCmd.Flags().BoolVarP(&knowledgeFlags.all, "all", "a", false, "Include retired entries")
+	knowledgeAddCmd.Flags().StringVar(&knowledgeFlags.entryType, "type", "learning", "Entry type (learning, tip, decision)")
+	knowledgeEditCmd.Flags().StringVar(&knowledgeFlags.content, "content", "", "New content")
+	knowledgeEditCmd.Flags().StringVar(&knowledgeFlags.entryType, "type", "", "New type (learning, tip, decision)")
+	knowledgeRetireCmd.Flags().StringVar(&knowledgeFlags.reason, "reason", "", "Why the entry is retired")
+
+	knowledgeCmd.AddCommand(knowledgeListCmd)
+	knowledgeCmd.AddCommand(knowledgeAddCmd)
+	knowledgeCmd.AddCommand(knowledgePromoteCmd)
+	knowledgeCmd.AddCommand(knowledgeEditCmd)
+	knowledgeCmd.AddCommand(knowledgeRetireCmd)
+	knowledgeCmd.AddCommand(knowledgeRestoreCmd)
+}
+
+func runKnowledgeList(cmd *cobra.Command, args []string) error {
+	store, cleanup, err := setupWizardStore(resolveDataDir(knowledgeFlags.dataDir))
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	kb, err := store.LoadKnowledge(context.Background())
+	if err != nil {
+		return err
+	}
+	entries := kb.Entries
+	if !knowledgeFlags.all {
+		entries = kb.Active()
+	}
+	if len(entries) == 0 {
+		fmt.Println("No knowledge entries")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tTYPE\tSOURCE\tCONTENT")
+	for _, entry := range entries {
+		source := entry.Source
+		if source == "" {
+			source = "-"
+		}
+		content := strings.ReplaceAll(entry.Content, "\n", " ")
+		if entry.Retired {
+			content = "(retired) " + content
+			if entry.RetireReason != "" {
+				content += " [" + entry.RetireReason + "]"
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", entry.ID, entry.Type, source, content)
+	}
+	return tw.Flush()
+}
+
+func runKnowledgeAdd(cmd *cobra.Command, args []string) error {
+	store, cleanup, err := setupWizardStore(resolveDataDir(knowledgeFlags.dataDir))
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	entry, err := store.KnowledgeAdd(context.Background(), session.KnowledgeAddParams{
+		Content: args[0],
+		Type:    knowledgeFlags.entryType,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Added %s\n", entry.ID)
+	return nil
+}
+
+func runKnowledgePromote(cmd *cobra.Command, args []string) error {
+	store, cleanup, err := setupWizardStore(resolveDataDir(knowledgeFlags.dataDir))
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	ctx := context.Background()
+	if err := requireSession(ctx, store, args[0]); err != nil {
+		return err
+	}
+	entry, err := store.KnowledgePromote(ctx, args[0], args[1])
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Promoted %s to %s\n", args[1], entry.ID)
+	return nil
+}
+
+func runKnowledgeEdit(cmd *cobra.Command, args []string) error {
+	store, cleanup, err := setupWizardStore(resolveDataDir(knowledgeFlags.dataDir))
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	err = store.KnowledgeEdit(context.Background(), session.KnowledgeEditParams{
+		ID:      args[0],
+		Content: knowledgeFlags.content,
+		Type:    knowledgeFlags.entryType,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Updated %s\n", args[0])
+	return nil
+}
+
+func runKnowledgeRetire(cmd *cobra.Command, args []string) error {
+	store, cleanup, err := setupWizardStore(resolveDataDir(knowledgeFlags.dataDir))
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	if err := store.KnowledgeRetire(context.Background(), args[0], knowledgeFlags.reason); err != nil {
+		return err
+	}
+	fmt.Printf("Retired %s\n", args[0])
+	return nil
+}
+
+func runKnowledgeRestore(cmd *cobra.Command, args []string) error {
+	store, cleanup, err := setupWizardStore(resolveDataDir(knowledgeFlags.dataDir))
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	if err := store.KnowledgeRestore(context.Background(), args[0]); err != nil {
+		return err
+	}
+	fmt.Printf("Restored %s\n", args[0])
+	return nil
+}
@@ -59,4 +59,12 @@ Getting Started:
 	rootCmd.AddCommand(doctorCmd)
 	rootCmd.AddCommand(setupCmd)
 	rootCmd.AddCommand(configCmd)
+	rootCmd.AddCommand(historyCmd)
+	rootCmd.AddCommand(sessionCmd)
+	rootCmd.AddCommand(transcriptCmd)
+	rootCmd.AddCommand(searchCmd)
+	rootCmd.AddCommand(knowledgeCmd)
+	rootCmd.AddCommand(gcCmd)
+	rootCmd.AddCommand(backupCmd)
+	rootCmd.AddCommand(restoreCmd)
 }
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/mark3labs/iteratr/internal/session"
+	"github.com/spf13/cobra"
+)
+
+var searchFlags struct {
+	dataDir  string
+	noteType string
+	status   string
+	session  string
+	limit    int
+}
+
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search tasks, notes and iteration summaries across sessions",
+	Long: `Find tasks, notes and iteration summaries containing every word of the
+query (case-insensitive), across all stored sessions. Results are listed most
+recent first.
+
+--type only returns notes of that type and --status only tasks with that
+status; give both to get matching notes and tasks together.
+
+Examples:
+  iteratr search flaky nats test
+  iteratr search "nats" --type learning
+  iteratr search migration --status blocked --session my-session`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runSearch,
+}
+
+func init() {
+	searchCmd.Flags().StringVar(&searchFlags.dataDir, "data-dir", "", "Data directory (overrides config file, default: .iteratr)")
+	searchCmd.Flags().StringVar(&searchFlags.noteType, "type", "", "Only notes of this type (learning, stuck, tip, decision)")
+	searchCmd.Flags().StringVar(&searchFlags.status, "status", "", "Only tasks with this status (remaining, in_progress, completed, blocked, cancelled)")
+	searchCmd.Flags().StringVar(&searchFlags.session, "session", "", "Only search this session")
+	searchCmd.Flags().IntVarP(&searchFlags.limit, "limit", "n", 50, "Maximum number of results (0 for all)")
+}
+
+func runSearch(cmd *cobra.Command, args []string) error {
+	store, cleanup, err := setupWizardStore(resolveDataDir(searchFlags.dataDir))
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	results, err := store.Search(context.Background(), session.SearchParams{
+		Query:    strings.Join(args, " "),
+		NoteType: searchFlags.noteType,
+		Status:   searchFlags.status,
+		Session:  searchFlags.session,
+		Limit:    searchFlags.limit,
+	})
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		fmt.Println("No matches found")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SESSION\tID\tITER\tKIND\tCONTENT")
+	for _, r := range results {
+		fmt.Fprintln(tw, formatSearchResult(r))
+	}
+	return tw.Flush()
+}
+
+// formatSearchResult formats one tab-separated result row.
+func formatSearchResult(r session.SearchResult) string {
+	id := r.ID
+	if id == "" {
+		id = "-"
+	}
+	kind := r.Kind
+	switch {
+	case r.Status != "":
+		kind += " (" + r.Status + ")"
+	case r.NoteType != "":
+		kind += " (" + r.NoteType + ")"
+	}
+	content := strings.ReplaceAll(r.Content, "\n", " ")
+	if len(content) > 100 {
+		content = content[:97] + "..."
+	}
+	return fmt.Sprintf("%s\t%s\t#%d\t%s\t%s", r.Session, id, r.Iteration, kind, content)
+}
@@ -0,0 +1,483 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mark3labs/iteratr/internal/config"
+	"github.com/mark3labs/iteratr/internal/session"
+	"github.com/spf13/cobra"
+)
+
+var sessionFlags struct {
+	dataDir   string
+	output    string
+	name      string
+	force     bool
+	iteration int
+	sort      string
+	limit     int
+	offset    int
+}
+
+var sessionCmd = &cobra.Command{
+	Use:   "session",
+	Short: "Manage stored sessions",
+}
+
+var sessionListCmd = &cobra.Command{
+	Use:     "ls",
+	Aliases: []string{"list"},
+	Short:   "List sessions, most recently active first",
+	Long: `List sessions with their status, task progress and last activity. Use
+--sort to order by name or creation time instead, and --limit and --offset to
+page through large data directories.
+
+Examples:
+  iteratr session ls --sort name
+  iteratr session ls --limit 20 --offset 20`,
+	Args: cobra.NoArgs,
+	RunE: runSessionList,
+}
+
+var sessionShowCmd = &cobra.Command{
+	Use:   "show <session>",
+	Short: "Show a session's tasks, notes and iterations",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runSessionShow,
+}
+
+var sessionRemoveCmd = &cobra.Command{
+	Use:     "rm <session>",
+	Aliases: []string{"delete"},
+	Short:   "Delete a session and all its events",
+	Args:    cobra.ExactArgs(1),
+	RunE:    runSessionRemove,
+}
+
+var sessionMoveCmd = &cobra.Command{
+	Use:     "mv <session> <new-name>",
+	Aliases: []string{"rename"},
+	Short:   "Rename a session",
+	Long: `Rename a session by moving its events to the new name's subjects.
+Events are copied first and the old session is removed only after the copy
+succeeded. Do not rename a session while it is running.`,
+	Args: cobra.ExactArgs(2),
+	RunE: runSessionMove,
+}
+
+var sessionForkCmd = &cobra.Command{
+	Use:   "fork <session> <new-session>",
+	Short: "Copy a session, optionally up to an iteration, into a new session",
+	Long: `Copy a session's events into a new session, leaving the original untouched.
+With --iteration, only events up to the end of that iteration are copied, so
+you can retry from a known-good point with a different model or template.
+
+Examples:
+  iteratr session fork my-session my-session-retry --iteration 4
+  iteratr build --name my-session-retry --model anthropic/claude-sonnet-4-5`,
+	Args: cobra.ExactArgs(2),
+	RunE: runSessionFork,
+}
+
+var sessionExportCmd = &cobra.Command{
+	Use:   "export <session>",
+	Short: "Export a session's events as a JSONL archive",
+	Long: `Write every event of a session, in sequence order, as one JSON object per
+line. The archive is gzip-compressed when the output file ends in .gz, and
+written to stdout when no output file is given.
+
+Examples:
+  iteratr session export my-session -o my-session.jsonl
+  iteratr session export my-session -o my-session.jsonl.gz`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSessionExport,
+}
+
+var sessionImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import a session from a JSONL archive",
+	Long: `Republish the events of an archive written by 'iteratr session export'.
+The session keeps its original name unless --name is given, and must not
+already exist. Gzip-compressed archives are detected automatically; use "-"
+to read from stdin.
+
+Examples:
+  iteratr session import my-session.jsonl.gz
+  iteratr session import my-session.jsonl --name my-session-copy`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSessionImport,
+}
+
+var sessionUnlockCmd = &cobra.Command{
+	Use:   "unlock <session>",
+	Short: "Release a session's lock left behind by a build that is gone",
+	Long: `A running build holds a lock on its session so that no second build runs
+it at the same time. The lock expires on its own shortly after its build
+stops renewing it, and is taken over at once if the build ran on this machine
+and its process is gone. Use unlock for a build that can't release it, e.g. on
+a machine that is unreachable.
+
+A lock that is still being renewed is only removed with --force; its build
+then stops at its next renewal.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSessionUnlock,
+}
+
+var sessionCompactCmd = &cobra.Command{
+	Use:   "compact <session>",
+	Short: "Rewrite a session's history into the fewest events with the same state",
+	Long: `Replace a session's task, note, iteration and control events with the
+smallest set of events that rebuilds the same state, e.g. one event per task
+instead of every status change. Transcripts are kept as they are. The new
+events are checked to rebuild the exact state before anything is written.
+A session can't be compacted while a build is running it.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSessionCompact,
+}
+
+func init() {
+	sessionCmd.PersistentFlags().StringVar(&sessionFlags.dataDir, "data-dir", "", "Data directory (overrides config file, default: .iteratr)")
+	sessionExportCmd.Flags().StringVarP(&sessionFlags.output, "output", "o", "", "Output file (.jsonl or .jsonl.gz, default: stdout)")
+	sessionImportCmd.Flags().StringVar(&sessionFlags.name, "name", "", "Import under this session name instead of the archived one")
+	sessionRemoveCmd.Flags().BoolVarP(&sessionFlags.force, "force", "f", false, "Delete without asking for confirmation")
+	sessionUnlockCmd.Flags().BoolVarP(&sessionFlags.force, "force", "f", false, "Remove the lock even if its build still renews it")
+	sessionForkCmd.Flags().IntVar(&sessionFlags.iteration, "iteration", 0, "Copy events up to the end of this iteration (default: all)")
+	sessionListCmd.Flags().StringVar(&sessionFlags.sort, "sort", session.SortByActivity, "Sort by activity, name or created")
+	sessionListCmd.Flags().IntVar(&sessionFlags.limit, "limit", 0, "Show at most this many sessions (default: all)")
+	sessionListCmd.Flags().IntVar(&sessionFlags.offset, "offset", 0, "Skip this many sessions")
+
+	sessionCmd.AddCommand(sessionListCmd)
+	sessionCmd.AddCommand(sessionShowCmd)
+	sessionCmd.AddCommand(sessionRemoveCmd)
+	sessionCmd.AddCommand(sessionMoveCmd)
+	sessionCmd.AddCommand(sessionForkCmd)
+	sessionCmd.AddCommand(sessionExportCmd)
+	sessionCmd.AddCommand(sessionImportCmd)
+	sessionCmd.AddCommand(sessionCompactCmd)
+	sessionCmd.AddCommand(sessionUnlockCmd)
+}
+
+// resolveDataDir applies the data directory precedence: CLI flag > config > default.
+func resolveDataDir(flag string) string {
+	if flag != "" {
+		return flag
+	}
+	if cfg, err := config.Load(); err == nil && cfg.DataDir != "" {
+		return cfg.DataDir
+	}
+	return ".iteratr"
+}
+
+func runSessionList(cmd *cobra.Command, args []string) error {
+	store, cleanup, err := setupWizardStore(resolveDataDir(sessionFlags.dataDir))
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	if sessionFlags.limit < 0 || sessionFlags.offset < 0 {
+		return fmt.Errorf("--limit and --offset must not be negative")
+	}
+	infos, total, err := store.ListSessionsPage(context.Background(), session.SessionListParams{
+		Sort:   sessionFlags.sort,
+		Offset: sessionFlags.offset,
+		Limit:  sessionFlags.limit,
+	})
+	if err != nil {
+		return err
+	}
+	if len(infos) == 0 {
+		if total > 0 {
+			fmt.Printf("No sessions past offset %d (%d sessions)\n", sessionFlags.offset, total)
+			return nil
+		}
+		fmt.Println("No sessions found")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tSTATUS\tTASKS\tLAST ACTIVITY")
+	for _, info := range infos {
+		status := "active"
+		if info.Complete {
+			status = "complete"
+		}
+		lastActivity := "-"
+		if !info.LastActivity.IsZero() {
+			lastActivity = info.LastActivity.Local().Format("2006-01-02 15:04")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d/%d\t%s\n", info.Name, status, info.TasksCompleted, info.TasksTotal, lastActivity)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if len(infos) < total {
+		fmt.Printf("\nShowing %d-%d of %d sessions\n", sessionFlags.offset+1, sessionFlags.offset+len(infos), total)
+	}
+	return nil
+}
+
+func runSessionShow(cmd *cobra.Command, args []string) error {
+	sessionName := args[0]
+
+	store, cleanup, err := setupWizardStore(resolveDataDir(sessionFlags.dataDir))
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	ctx := context.Background()
+	if err := requireSession(ctx, store, sessionName); err != nil {
+		return err
+	}
+	state, err := store.LoadState(ctx, sessionName)
+	if err != nil {
+		return err
+	}
+	fmt.Print(renderHistoryState(state, "latest"))
+	return nil
+}
+
+func runSessionRemove(cmd *cobra.Command, args []string) error {
+	sessionName := args[0]
+
+	store, cleanup, err := setupWizardStore(resolveDataDir(sessionFlags.dataDir))
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	ctx := context.Background()
+	if err := requireSession(ctx, store, sessionName); err != nil {
+		return err
+	}
+
+	lease, err := store.AcquireLease(ctx, sessionName)
+	if err != nil {
+		return fmt.Errorf("can't delete a running session: %w", err)
+	}
+	defer func() { _ = lease.Release(ctx) }()
+
+	if !sessionFlags.force {
+		fmt.Printf("Delete session '%s' and all its events? [y/N]: ", sessionName)
+		var response string
+		_, _ = fmt.Scanln(&response)
+		if response != "y" && response != "Y" {
+			fmt.Println("Session not deleted.")
+			return nil
+		}
+	}
+
+	if err := store.ResetSession(ctx, sessionName); err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	fmt.Printf("Deleted session '%s'\n", sessionName)
+	return nil
+}
+
+func runSessionMove(cmd *cobra.Command, args []string) error {
+	store, cleanup, err := setupWizardStore(resolveDataDir(sessionFlags.dataDir))
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	// Neither session may be run while its events are moved
+	ctx := context.Background()
+	for _, name := range args {
+		lease, err := store.AcquireLease(ctx, name)
+		if err != nil {
+			return fmt.Errorf("can't rename a running session: %w", err)
+		}
+		defer func() { _ = lease.Release(ctx) }()
+	}
+
+	if err := store.RenameSession(ctx, args[0], args[1]); err != nil {
+		return err
+	}
+	fmt.Printf("Renamed session '%s' to '%s'\n", args[0], args[1])
+	return nil
+}
+
+func runSessionFork(cmd *cobra.Command, args []string) error {
+	if sessionFlags.iteration < 0 {
+		return fmt.Errorf("--iteration must be positive")
+	}
+
+	store, cleanup, err := setupWizardStore(resolveDataDir(sessionFlags.dataDir))
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	point := session.StatePoint{Iteration: sessionFlags.iteration}
+	count, err := store.ForkSession(context.Background(), args[0], args[1], point)
+	if err != nil {
+		return err
+	}
+	if sessionFlags.iteration > 0 {
+		fmt.Printf("Forked session '%s' at iteration %d into '%s' (%d events)\n", args[0], sessionFlags.iteration, args[1], count)
+	} else {
+		fmt.Printf("Forked session '%s' into '%s' (%d events)\n", args[0], args[1], count)
+	}
+	return nil
+}
+
+// requireSession returns an error if the session has no events.
+func requireSession(ctx context.Context, store *session.Store, name string) error {
+	exists, err := store.SessionExists(ctx, name)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("session %q not found", name)
+	}
+	return nil
+}
+
+func runSessionExport(cmd *cobra.Command, args []string) error {
+	sessionName := args[0]
+
+	store, cleanup, err := setupWizardStore(resolveDataDir(sessionFlags.dataDir))
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	ctx := context.Background()
+	if sessionFlags.output == "" || sessionFlags.output == "-" {
+		_, err := store.ExportSession(ctx, sessionName, os.Stdout)
+		return err
+	}
+
+	count, err := exportToFile(ctx, store, sessionName, sessionFlags.output)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Exported %d events from session '%s' to %s\n", count, sessionName, sessionFlags.output)
+	return nil
+}
+
+// exportToFile exports a session to path, gzip-compressed if it ends in .gz,
+// removing the file again if the export fails.
+func exportToFile(ctx context.Context, store *session.Store, name, path string) (int, error) {
+	export := func(w io.Writer) (int, error) {
+		return store.ExportSession(ctx, name, w)
+	}
+	if strings.HasSuffix(path, ".gz") {
+		return writeGzip(path, export)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create output file: %w", err)
+	}
+	count, err := export(f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return 0, err
+	}
+	return count, nil
+}
+
+func runSessionCompact(cmd *cobra.Command, args []string) error {
+	store, cleanup, err := setupWizardStore(resolveDataDir(sessionFlags.dataDir))
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	ctx := context.Background()
+	if err := requireSession(ctx, store, args[0]); err != nil {
+		return err
+	}
+	lease, err := store.AcquireLease(ctx, args[0])
+	if err != nil {
+		return fmt.Errorf("can't compact a running session: %w", err)
+	}
+	defer func() { _ = lease.Release(ctx) }()
+
+	before, after, err := store.CompactSession(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Compacted session '%s' from %d to %d events\n", args[0], before, after)
+	return nil
+}
+
+func runSessionUnlock(cmd *cobra.Command, args []string) error {
+	store, cleanup, err := setupWizardStore(resolveDataDir(sessionFlags.dataDir))
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	ctx := context.Background()
+	sessionName := args[0]
+	holder, err := store.ReadLease(ctx, sessionName)
+	if errors.Is(err, session.ErrNoLease) {
+		fmt.Printf("Session '%s' is not locked\n", sessionName)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !holder.Stale(time.Now()) && !sessionFlags.force {
+		return fmt.Errorf("session '%s' is locked by %s, which is still running (use --force to unlock anyway)", sessionName, holder)
+	}
+
+	if err := store.BreakLease(ctx, sessionName); err != nil {
+		return err
+	}
+	fmt.Printf("Unlocked session '%s' (was locked by %s)\n", sessionName, holder)
+	return nil
+}
+
+func runSessionImport(cmd *cobra.Command, args []string) error {
+	var r io.Reader = os.Stdin
+	if args[0] != "-" {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to open archive: %w", err)
+		}
+		defer func() { _ = f.Close() }()
+		r = f
+	}
+
+	// Detect gzip by its magic bytes rather than the file name
+	br := bufio.NewReader(r)
+	r = br
+	if magic, _ := br.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return fmt.Errorf("failed to read gzip archive: %w", err)
+		}
+		defer func() { _ = gz.Close() }()
+		r = gz
+	}
+
+	store, cleanup, err := setupWizardStore(resolveDataDir(sessionFlags.dataDir))
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	name, count, err := store.ImportSession(context.Background(), r, sessionFlags.name)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Imported %d events into session '%s'\n", count, name)
+	return nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/iteratr/internal/session"
+)
+
+func TestExportToFile(t *testing.T) {
+	ctx := context.Background()
+	store := session.NewStore(session.NewMemoryLog())
+	_, _ = store.TaskAdd(ctx, "done", session.TaskAddParams{Content: "Ship it"})
+	dir := t.TempDir()
+
+	for _, name := range []string{"done.jsonl", "done.jsonl.gz"} {
+		path := filepath.Join(dir, name)
+		if count, err := exportToFile(ctx, store, "done", path); err != nil || count != 1 {
+			t.Fatalf("exportToFile(%s) = %d, %v, want 1 event", name, count, err)
+		}
+	}
+
+	// The gzip stream is complete
+	f, err := os.Open(filepath.Join(dir, "done.jsonl.gz"))
+	if err != nil {
+		t.Fatalf("failed to open export: %v", err)
+	}
+	defer func() { _ = f.Close() }()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("export is not gzip-compressed: %v", err)
+	}
+	if _, count, err := store.ImportSession(ctx, gz, "copy"); err != nil || count != 1 {
+		t.Fatalf("expected to import 1 event, got %d (%v)", count, err)
+	}
+
+	// A failed export leaves no file behind
+	for _, name := range []string{"missing.jsonl", "missing.jsonl.gz"} {
+		path := filepath.Join(dir, name)
+		if _, err := exportToFile(ctx, store, "missing", path); err == nil {
+			t.Errorf("expected exporting a missing session to %s to fail", name)
+		}
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be removed, got %v", name, err)
+		}
+	}
+}
@@ -8,8 +8,10 @@ import (
 	"time"
 
 	"github.com/mark3labs/iteratr/internal/config"
+	"github.com/mark3labs/iteratr/internal/logger"
 	"github.com/mark3labs/iteratr/internal/nats"
 	"github.com/mark3labs/iteratr/internal/session"
+	natsgo "github.com/nats-io/nats.go"
 	"github.com/nats-io/nats.go/jetstream"
 	"github.com/spf13/cobra"
 )
@@ -36,6 +38,10 @@ func init() {
 	toolCmd.AddCommand(taskStatusCmd)
 	toolCmd.AddCommand(taskPriorityCmd)
 	toolCmd.AddCommand(taskDependsCmd)
+	toolCmd.AddCommand(taskUndependCmd)
+	toolCmd.AddCommand(taskEditCmd)
+	toolCmd.AddCommand(taskRemoveCmd)
+	toolCmd.AddCommand(taskLabelCmd)
 	toolCmd.AddCommand(taskListCmd)
 	toolCmd.AddCommand(taskNextCmd)
 	toolCmd.AddCommand(noteAddCmd)
@@ -48,31 +54,60 @@ func init() {
 	toolCmd.PersistentFlags().StringVar(&toolFlags.dataDir, "data-dir", "", "Data directory (overrides config file, default: .iteratr)")
 }
 
-// connectToSession connects to a running iteratr session's server
+// connectToSession connects to a running iteratr session's server, or opens
+// the event file when file storage is configured
 func connectToSession() (*session.Store, func(), error) {
+	// Try loading config (ignore errors, fall back to defaults)
+	cfg, err := config.Load()
+	if err != nil {
+		cfg = &config.Config{}
+	}
+
 	// Determine data directory with precedence: CLI flag > config > default
 	dataDir := toolFlags.dataDir
 	if dataDir == "" {
-		// Try loading from config (ignore errors, fall back to default)
-		if cfg, err := config.Load(); err == nil {
-			dataDir = cfg.DataDir
-		}
+		dataDir = cfg.DataDir
 	}
 	if dataDir == "" {
 		dataDir = ".iteratr"
 	}
 
-	// Read port from port file
+	// File storage is shared through the event file rather than a server
 	serverDataDir := dataDir + "/data"
-	port, err := nats.ReadPort(serverDataDir)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to connect to session (is iteratr build running?): %w", err)
+	if cfg.Storage == session.StorageFile {
+		store, cleanup, err := openFileStore(serverDataDir)
+		if err != nil {
+			return nil, nil, err
+		}
+		policy, err := session.SchedulingPolicyByName(cfg.Scheduler)
+		if err != nil {
+			cleanup()
+			return nil, nil, err
+		}
+		store.SetSchedulingPolicy(policy)
+		return store, cleanup, nil
 	}
 
-	// Connect to NATS
-	nc, err := nats.ConnectToPort(port)
+	connect, layout, err := natsSettings(cfg)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to connect to NATS: %w", err)
+		return nil, nil, err
+	}
+
+	// Connect to the configured external server, or to the running build's
+	// embedded server through its port file
+	var nc *natsgo.Conn
+	if connect.URL != "" {
+		if nc, err = nats.Connect(connect); err != nil {
+			return nil, nil, err
+		}
+	} else {
+		port, err := nats.ReadPort(serverDataDir)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to connect to session (is iteratr build running?): %w", err)
+		}
+		if nc, err = nats.ConnectToPort(port); err != nil {
+			return nil, nil, fmt.Errorf("failed to connect to NATS: %w", err)
+		}
 	}
 
 	// Create JetStream context
@@ -86,14 +121,27 @@ func connectToSession() (*session.Store, func(), error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	stream, err := js.Stream(ctx, nats.StreamName)
+	stream, err := js.Stream(ctx, layout.WithDefaults().Stream)
 	if err != nil {
 		nc.Close()
 		return nil, nil, fmt.Errorf("failed to get stream: %w", err)
 	}
 
 	// Create store
-	store := session.NewStore(js, stream)
+	store := session.NewStore(session.NewJetStreamLogWithLayout(js, stream, layout))
+
+	// Pick task-next order from config
+	policy, err := session.SchedulingPolicyByName(cfg.Scheduler)
+	if err != nil {
+		nc.Close()
+		return nil, nil, err
+	}
+	store.SetSchedulingPolicy(policy)
+
+	// Use state snapshots if available (falls back to full replay on failure)
+	if err := store.EnableSnapshots(ctx); err != nil {
+		logger.Warn("State snapshots unavailable: %v", err)
+	}
 
 	// Return cleanup function
 	cleanup := func() {
@@ -114,6 +162,10 @@ var taskAddCmd = &cobra.Command{
 
 		content, _ := cmd.Flags().GetString("content")
 		status, _ := cmd.Flags().GetString("status")
+		parent, _ := cmd.Flags().GetString("parent")
+		acceptance, _ := cmd.Flags().GetString("acceptance")
+		verify, _ := cmd.Flags().GetString("verify")
+		labels, _ := cmd.Flags().GetStringSlice("label")
 
 		if content == "" {
 			return fmt.Errorf("content is required")
@@ -127,19 +179,27 @@ var taskAddCmd = &cobra.Command{
 
 		ctx := context.Background()
 		task, err := store.TaskAdd(ctx, toolFlags.name, session.TaskAddParams{
-			Content: content,
-			Status:  status,
+			Content:    content,
+			Status:     status,
+			ParentID:   parent,
+			Acceptance: acceptance,
+			Verify:     verify,
+			Labels:     labels,
 		})
 		if err != nil {
 			return err
 		}
 
 		// Output JSON for parsing
-		output, _ := json.Marshal(map[string]string{
+		fields := map[string]string{
 			"id":      task.ID,
 			"status":  task.Status,
 			"content": task.Content,
-		})
+		}
+		if task.ParentID != "" {
+			fields["parent_id"] = task.ParentID
+		}
+		output, _ := json.Marshal(fields)
 		fmt.Println(string(output))
 		return nil
 	},
@@ -148,6 +208,10 @@ var taskAddCmd = &cobra.Command{
 func init() {
 	taskAddCmd.Flags().String("content", "", "Task content (required)")
 	taskAddCmd.Flags().String("status", "remaining", "Initial status")
+	taskAddCmd.Flags().String("parent", "", "Parent task ID (makes this a subtask)")
+	taskAddCmd.Flags().String("acceptance", "", "Acceptance criteria")
+	taskAddCmd.Flags().String("verify", "", "Shell command that must pass before the task can be completed")
+	taskAddCmd.Flags().StringSlice("label", nil, "Task label (repeatable or comma-separated)")
 }
 
 // task-batch-add command
@@ -166,8 +230,12 @@ var taskBatchAddCmd = &cobra.Command{
 
 		// Parse JSON array of task objects
 		var taskInputs []struct {
-			Content string `json:"content"`
-			Status  string `json:"status,omitempty"`
+			Content    string   `json:"content"`
+			Status     string   `json:"status,omitempty"`
+			Parent     string   `json:"parent,omitempty"`
+			Acceptance string   `json:"acceptance,omitempty"`
+			Verify     string   `json:"verify,omitempty"`
+			Labels     []string `json:"labels,omitempty"`
 		}
 		if err := json.Unmarshal([]byte(tasksJSON), &taskInputs); err != nil {
 			return fmt.Errorf("invalid tasks JSON: %w", err)
@@ -187,8 +255,12 @@ var taskBatchAddCmd = &cobra.Command{
 		params := make([]session.TaskAddParams, len(taskInputs))
 		for i, input := range taskInputs {
 			params[i] = session.TaskAddParams{
-				Content: input.Content,
-				Status:  input.Status,
+				Content:    input.Content,
+				Status:     input.Status,
+				ParentID:   input.Parent,
+				Acceptance: input.Acceptance,
+				Verify:     input.Verify,
+				Labels:     input.Labels,
 			}
 		}
 
@@ -201,11 +273,15 @@ var taskBatchAddCmd = &cobra.Command{
 		// Output JSON array for parsing
 		var output []map[string]string
 		for _, t := range tasks {
-			output = append(output, map[string]string{
+			fields := map[string]string{
 				"id":      t.ID,
 				"status":  t.Status,
 				"content": t.Content,
-			})
+			}
+			if t.ParentID != "" {
+				fields["parent_id"] = t.ParentID
+			}
+			output = append(output, fields)
 		}
 		result, _ := json.Marshal(output)
 		fmt.Println(string(result))
@@ -214,7 +290,7 @@ var taskBatchAddCmd = &cobra.Command{
 }
 
 func init() {
-	taskBatchAddCmd.Flags().String("tasks", "", `JSON array of tasks, e.g. [{"content":"Task 1"},{"content":"Task 2","status":"in_progress"}]`)
+	taskBatchAddCmd.Flags().String("tasks", "", `JSON array of tasks, e.g. [{"content":"Task 1"},{"content":"Task 2","status":"in_progress","parent":"TAS-1","labels":["backend"]}]`)
 }
 
 // task-status command
@@ -346,6 +422,178 @@ func init() {
 	taskDependsCmd.Flags().String("depends-on", "", "Task ID this task depends on (required)")
 }
 
+// task-undepend command
+var taskUndependCmd = &cobra.Command{
+	Use:   "task-undepend",
+	Short: "Remove task dependency",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if toolFlags.name == "" {
+			return fmt.Errorf("session name is required (--name)")
+		}
+
+		id, _ := cmd.Flags().GetString("id")
+		dependsOn, _ := cmd.Flags().GetString("depends-on")
+
+		if id == "" {
+			return fmt.Errorf("task ID is required")
+		}
+		if dependsOn == "" {
+			return fmt.Errorf("depends-on is required")
+		}
+
+		store, cleanup, err := connectToSession()
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+
+		ctx := context.Background()
+		err = store.TaskUndepend(ctx, toolFlags.name, session.TaskDependsParams{
+			ID:        id,
+			DependsOn: dependsOn,
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Println("OK")
+		return nil
+	},
+}
+
+func init() {
+	taskUndependCmd.Flags().String("id", "", "Task ID (required)")
+	taskUndependCmd.Flags().String("depends-on", "", "Task ID to remove from dependencies (required)")
+}
+
+// task-edit command
+var taskEditCmd = &cobra.Command{
+	Use:   "task-edit",
+	Short: "Edit task content",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if toolFlags.name == "" {
+			return fmt.Errorf("session name is required (--name)")
+		}
+
+		id, _ := cmd.Flags().GetString("id")
+		content, _ := cmd.Flags().GetString("content")
+
+		if id == "" {
+			return fmt.Errorf("task ID is required")
+		}
+		if content == "" {
+			return fmt.Errorf("content is required")
+		}
+
+		store, cleanup, err := connectToSession()
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+
+		ctx := context.Background()
+		err = store.TaskEdit(ctx, toolFlags.name, session.TaskEditParams{
+			ID:      id,
+			Content: content,
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Println("OK")
+		return nil
+	},
+}
+
+func init() {
+	taskEditCmd.Flags().String("id", "", "Task ID (required)")
+	taskEditCmd.Flags().String("content", "", "New task content (required)")
+}
+
+// task-remove command
+var taskRemoveCmd = &cobra.Command{
+	Use:   "task-remove",
+	Short: "Remove a task",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if toolFlags.name == "" {
+			return fmt.Errorf("session name is required (--name)")
+		}
+
+		id, _ := cmd.Flags().GetString("id")
+		if id == "" {
+			return fmt.Errorf("task ID is required")
+		}
+
+		store, cleanup, err := connectToSession()
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+
+		ctx := context.Background()
+		err = store.TaskRemove(ctx, toolFlags.name, session.TaskRemoveParams{
+			ID: id,
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Println("OK")
+		return nil
+	},
+}
+
+func init() {
+	taskRemoveCmd.Flags().String("id", "", "Task ID (required)")
+}
+
+// task-label command
+var taskLabelCmd = &cobra.Command{
+	Use:   "task-label",
+	Short: "Add or remove task labels",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if toolFlags.name == "" {
+			return fmt.Errorf("session name is required (--name)")
+		}
+
+		id, _ := cmd.Flags().GetString("id")
+		add, _ := cmd.Flags().GetStringSlice("add")
+		remove, _ := cmd.Flags().GetStringSlice("remove")
+
+		if id == "" {
+			return fmt.Errorf("task ID is required")
+		}
+		if len(add) == 0 && len(remove) == 0 {
+			return fmt.Errorf("at least one of --add or --remove is required")
+		}
+
+		store, cleanup, err := connectToSession()
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+
+		ctx := context.Background()
+		err = store.TaskLabel(ctx, toolFlags.name, session.TaskLabelParams{
+			ID:     id,
+			Add:    add,
+			Remove: remove,
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Println("OK")
+		return nil
+	},
+}
+
+func init() {
+	taskLabelCmd.Flags().String("id", "", "Task ID (required)")
+	taskLabelCmd.Flags().StringSlice("add", nil, "Labels to add (repeatable or comma-separated)")
+	taskLabelCmd.Flags().StringSlice("remove", nil, "Labels to remove (repeatable or comma-separated)")
+}
+
 // task-list command
 var taskListCmd = &cobra.Command{
 	Use:   "task-list",
@@ -361,8 +609,10 @@ var taskListCmd = &cobra.Command{
 		}
 		defer cleanup()
 
+		labels, _ := cmd.Flags().GetStringSlice("label")
+
 		ctx := context.Background()
-		result, err := store.TaskList(ctx, toolFlags.name)
+		result, err := store.TaskList(ctx, toolFlags.name, session.TaskListParams{Labels: labels})
 		if err != nil {
 			return err
 		}
@@ -379,8 +629,16 @@ var taskListCmd = &cobra.Command{
 				statusLabel = strings.ToUpper(statusLabel[:1]) + statusLabel[1:]
 			}
 			lines = append(lines, fmt.Sprintf("%s:", statusLabel))
-			for _, t := range tasks {
-				lines = append(lines, fmt.Sprintf("  [%s] %s", t.ID, t.Content))
+			for _, node := range session.TaskTree(tasks) {
+				t := node.Task
+				line := fmt.Sprintf("  %s[%s] %s", strings.Repeat("  ", node.Depth), t.ID, t.Content)
+				if t.ParentID != "" && node.Depth == 0 {
+					line += fmt.Sprintf(" (subtask of %s)", t.ParentID)
+				}
+				if len(t.Labels) > 0 {
+					line += fmt.Sprintf(" [labels: %s]", strings.Join(t.Labels, ", "))
+				}
+				lines = append(lines, line)
 			}
 		}
 
@@ -398,6 +656,10 @@ var taskListCmd = &cobra.Command{
 	},
 }
 
+func init() {
+	taskListCmd.Flags().StringSlice("label", nil, "Only list tasks with this label (repeatable; tasks must carry all)")
+}
+
 // note-add command
 var noteAddCmd = &cobra.Command{
 	Use:   "note-add",
@@ -498,8 +760,10 @@ var taskNextCmd = &cobra.Command{
 		}
 		defer cleanup()
 
+		labels, _ := cmd.Flags().GetStringSlice("label")
+
 		ctx := context.Background()
-		task, err := store.TaskNext(ctx, toolFlags.name)
+		task, err := store.TaskNext(ctx, toolFlags.name, session.TaskNextParams{Labels: labels})
 		if err != nil {
 			return err
 		}
@@ -510,17 +774,34 @@ var taskNextCmd = &cobra.Command{
 		}
 
 		// Output JSON for parsing
-		output, _ := json.Marshal(map[string]any{
+		fields := map[string]any{
 			"id":       task.ID,
 			"content":  task.Content,
 			"priority": task.Priority,
 			"status":   task.Status,
-		})
+		}
+		if task.ParentID != "" {
+			fields["parent_id"] = task.ParentID
+		}
+		if task.Acceptance != "" {
+			fields["acceptance"] = task.Acceptance
+		}
+		if task.Verify != "" {
+			fields["verify"] = task.Verify
+		}
+		if len(task.Labels) > 0 {
+			fields["labels"] = task.Labels
+		}
+		output, _ := json.Marshal(fields)
 		fmt.Println(string(output))
 		return nil
 	},
 }
 
+func init() {
+	taskNextCmd.Flags().StringSlice("label", nil, "Only consider tasks with this label (repeatable; tasks must carry all)")
+}
+
 // iteration-summary command
 var iterationSummaryCmd = &cobra.Command{
 	Use:   "iteration-summary",
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/iteratr/internal/session"
+	"github.com/spf13/cobra"
+)
+
+var transcriptFlags struct {
+	dataDir   string
+	iteration int
+	prompts   bool
+	json      bool
+}
+
+var transcriptCmd = &cobra.Command{
+	Use:   "transcript <session>",
+	Short: "Replay the agent conversation of a past iteration",
+	Long: `Print the recorded agent conversation of an iteration: prompts, responses,
+thinking, tool calls and how each turn finished. Defaults to the latest
+iteration.
+
+Examples:
+  iteratr transcript my-session
+  iteratr transcript my-session --iteration 3 --prompts
+  iteratr transcript my-session --iteration 3 --json | jq .`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTranscript,
+}
+
+func init() {
+	transcriptCmd.Flags().StringVar(&transcriptFlags.dataDir, "data-dir", "", "Data directory (overrides config file, default: .iteratr)")
+	transcriptCmd.Flags().IntVarP(&transcriptFlags.iteration, "iteration", "i", 0, "Iteration to replay (default: latest)")
+	transcriptCmd.Flags().BoolVar(&transcriptFlags.prompts, "prompts", false, "Print prompts in full instead of their first line")
+	transcriptCmd.Flags().BoolVar(&transcriptFlags.json, "json", false, "Print raw transcript entries as JSON lines")
+}
+
+func runTranscript(cmd *cobra.Command, args []string) error {
+	sessionName := args[0]
+
+	store, cleanup, err := setupWizardStore(resolveDataDir(transcriptFlags.dataDir))
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	ctx := context.Background()
+	iteration := transcriptFlags.iteration
+	if iteration <= 0 {
+		state, err := store.LoadState(ctx, sessionName)
+		if err != nil {
+			return err
+		}
+		if len(state.Iterations) == 0 {
+			return fmt.Errorf("session %q has no iterations", sessionName)
+		}
+		iteration = state.Iterations[len(state.Iterations)-1].Number
+	}
+
+	entries, err := store.Transcript(ctx, sessionName, iteration)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return fmt.Errorf("no transcript recorded for iteration %d of session %q", iteration, sessionName)
+	}
+
+	if transcriptFlags.json {
+		enc := json.NewEncoder(os.Stdout)
+		for _, entry := range entries {
+			if err := enc.Encode(entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	fmt.Printf("=== Session: %s, iteration #%d ===\n", sessionName, iteration)
+	fmt.Print(renderTranscript(entries, transcriptFlags.prompts))
+	return nil
+}
+
+// renderTranscript formats transcript entries like headless output. Tool calls
+// are shown once, where they started, with their final state.
+func renderTranscript(entries []session.TranscriptEntry, fullPrompts bool) string {
+	// Final state of each tool call
+	final := make(map[string]*session.TranscriptTool)
+	for _, entry := range entries {
+		if entry.Tool != nil {
+			final[entry.Tool.ID] = entry.Tool
+		}
+	}
+
+	var sb strings.Builder
+	shown := make(map[string]bool)
+	for _, entry := range entries {
+		switch entry.Kind {
+		case session.TranscriptKindPrompt:
+			sb.WriteString("\n> " + formatPrompt(entry.Content, fullPrompts) + "\n\n")
+		case session.TranscriptKindText:
+			sb.WriteString(entry.Content)
+		case session.TranscriptKindThinking:
+			sb.WriteString("\033[2m" + entry.Content + "\033[0m")
+		case session.TranscriptKindToolCall:
+			if entry.Tool == nil || shown[entry.Tool.ID] {
+				continue
+			}
+			shown[entry.Tool.ID] = true
+			sb.WriteString(formatTranscriptTool(final[entry.Tool.ID]))
+		case session.TranscriptKindFinish:
+			if entry.Finish == nil {
+				continue
+			}
+			f := entry.Finish
+			sb.WriteString(fmt.Sprintf("\n--- Agent finished: %s", f.Reason))
+			if f.Error != "" {
+				sb.WriteString(fmt.Sprintf(" (error: %s)", f.Error))
+			}
+			sb.WriteString(fmt.Sprintf(" | Duration: %s", f.Duration.Round(time.Millisecond)))
+			if f.Model != "" {
+				sb.WriteString(fmt.Sprintf(" | Model: %s", f.Model))
+			}
+			sb.WriteString(" ---\n")
+		}
+	}
+	return sb.String()
+}
+
+// formatPrompt returns a prompt in full, or its first line and a line count.
+func formatPrompt(content string, full bool) string {
+	content = strings.TrimSpace(content)
+	if full {
+		return strings.ReplaceAll(content, "\n", "\n> ")
+	}
+	lines := strings.Split(content, "\n")
+	if len(lines) == 1 {
+		return lines[0]
+	}
+	return fmt.Sprintf("%s (+%d lines)", lines[0], len(lines)-1)
+}
+
+// formatTranscriptTool formats a tool call's final state on one or two lines.
+func formatTranscriptTool(tool *session.TranscriptTool) string {
+	status := tool.Status
+	switch tool.Status {
+	case "completed":
+		status = "✓"
+	case "error", "canceled":
+		status = "✗ " + tool.Status
+	}
+
+	line := fmt.Sprintf("\n[tool: %s] %s", tool.Title, status)
+	if cmd, ok := tool.Input["command"].(string); ok {
+		line += " command: " + cmd
+	}
+	if tool.Output != "" {
+		line += fmt.Sprintf(" (output: %d bytes)", len(tool.Output))
+	}
+	line += "\n"
+	if tool.Diff != nil {
+		line += fmt.Sprintf("  edited %s (+%d -%d)\n", tool.Diff.File, tool.Diff.Additions, tool.Diff.Deletions)
+	}
+	return line
+}
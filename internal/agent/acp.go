@@ -614,8 +614,9 @@ func (c *acpConn) setModel(ctx context.Context, sessionID, modelID string) error
 // prompt sends a prompt to the session and streams notifications via callbacks.
 // Accepts multiple text blocks which are sent as separate content blocks in the same request.
 // Optional tools map enables/disables specific tools (tool name -> enabled).
-// Returns the stop reason when the prompt completes or an error occurs.
-func (c *acpConn) prompt(ctx context.Context, sessionID string, texts []string, tools map[string]bool, onText func(string), onToolCall func(ToolCallEvent), onThinking func(string), onFileChange func(FileChange)) (string, error) {
+// Returns the stop reason and reported usage when the prompt completes, or an error.
+// Usage.Cost is the cumulative ACP session cost from the last usage_update, if any.
+func (c *acpConn) prompt(ctx context.Context, sessionID string, texts []string, tools map[string]bool, onText func(string), onToolCall func(ToolCallEvent), onThinking func(string), onFileChange func(FileChange)) (string, Usage, error) {
 	// Build content blocks from texts
 	blocks := make([]contentBlock, 0, len(texts))
 	for _, text := range texts {
@@ -635,23 +636,24 @@ func (c *acpConn) prompt(ctx context.Context, sessionID string, texts []string,
 
 	reqID, err := c.sendRequest("session/prompt", params)
 	if err != nil {
-		return "", fmt.Errorf("failed to send session/prompt request: %w", err)
+		return "", Usage{}, fmt.Errorf("failed to send session/prompt request: %w", err)
 	}
 
 	// Track whether agent produced any output (to detect silent failures)
 	hadOutput := false
+	var usage Usage
 
 	// Read messages in loop until response with matching request ID arrives
 	for {
 		select {
 		case <-ctx.Done():
-			return "", ctx.Err()
+			return "", Usage{}, ctx.Err()
 		default:
 		}
 
 		resp, err := c.readMessage()
 		if err != nil {
-			return "", fmt.Errorf("failed to read prompt response: %w", err)
+			return "", Usage{}, fmt.Errorf("failed to read prompt response: %w", err)
 		}
 
 		// For notifications (id==nil, method=="session/update"): parse update params
@@ -753,6 +755,17 @@ func (c *acpConn) prompt(ctx context.Context, sessionID string, texts []string,
 					onToolCall(event)
 				}
 
+			case "usage_update":
+				// usage_update: cumulative cost of the ACP session so far
+				var uu usageUpdate
+				if err := json.Unmarshal(updateParams.Update, &uu); err != nil {
+					logger.Warn("Failed to parse usage_update: %v", err)
+					continue
+				}
+				if uu.Cost != nil {
+					usage.Cost = uu.Cost.Amount
+				}
+
 			case "available_commands_update":
 				// available_commands_update: skip
 				continue
@@ -811,7 +824,7 @@ func (c *acpConn) prompt(ctx context.Context, sessionID string, texts []string,
 
 		// Handle error response
 		if resp.Error != nil {
-			return "", fmt.Errorf("session/prompt failed: %s (code %d)", resp.Error.Message, resp.Error.Code)
+			return "", Usage{}, fmt.Errorf("session/prompt failed: %s (code %d)", resp.Error.Message, resp.Error.Code)
 		}
 
 		// Parse result to extract stop reason
@@ -819,7 +832,14 @@ func (c *acpConn) prompt(ctx context.Context, sessionID string, texts []string,
 		if err := json.Unmarshal(resp.Result, &result); err != nil {
 			logger.Warn("Failed to parse prompt result: %v", err)
 			// Default to "end_turn" if parsing fails
-			return "end_turn", nil
+			return "end_turn", usage, nil
+		}
+		if result.Usage != nil {
+			usage.InputTokens = result.Usage.InputTokens
+			usage.OutputTokens = result.Usage.OutputTokens
+			usage.ReasoningTokens = result.Usage.ThoughtTokens
+			usage.CacheReadTokens = result.Usage.CachedReadTokens
+			usage.CacheWriteTokens = result.Usage.CachedWriteTokens
 		}
 
 		// Return stop reason (e.g., "end_turn", "max_tokens", "cancelled", "refusal", "max_turn_requests")
@@ -832,11 +852,11 @@ func (c *acpConn) prompt(ctx context.Context, sessionID string, texts []string,
 		// This typically indicates credential errors or other API failures that don't return proper JSON-RPC errors
 		if stopReason == "end_turn" && !hadOutput {
 			logger.Warn("Agent returned end_turn without producing any output - possible credential or API error")
-			return "", fmt.Errorf("agent returned no output - this may indicate a credential error (e.g., API key restricted to specific use cases) or model availability issue")
+			return "", Usage{}, fmt.Errorf("agent returned no output - this may indicate a credential error (e.g., API key restricted to specific use cases) or model availability issue")
 		}
 
 		logger.Debug("ACP prompt completed with stop reason: %s", stopReason)
-		return stopReason, nil
+		return stopReason, usage, nil
 	}
 }
 
@@ -1045,7 +1065,18 @@ type contentBlock struct {
 }
 
 type promptResult struct {
-	StopReason string `json:"stopReason"`
+	StopReason string       `json:"stopReason"`
+	Usage      *promptUsage `json:"usage,omitempty"`
+}
+
+// Token usage of a prompt turn
+type promptUsage struct {
+	TotalTokens       int `json:"totalTokens"`
+	InputTokens       int `json:"inputTokens"`
+	OutputTokens      int `json:"outputTokens"`
+	ThoughtTokens     int `json:"thoughtTokens"`
+	CachedReadTokens  int `json:"cachedReadTokens"`
+	CachedWriteTokens int `json:"cachedWriteTokens"`
 }
 
 // SessionUpdate notification params
@@ -1059,6 +1090,19 @@ type sessionUpdate struct {
 	SessionUpdate string `json:"sessionUpdate"` // "agent_message_chunk", "tool_call", "tool_call_update", "available_commands_update"
 }
 
+// usage_update
+type usageUpdate struct {
+	SessionUpdate string     `json:"sessionUpdate"` // "usage_update"
+	Used          int        `json:"used"`          // Context tokens in use
+	Size          int        `json:"size"`          // Context window size
+	Cost          *usageCost `json:"cost,omitempty"`
+}
+
+type usageCost struct {
+	Amount   float64 `json:"amount"`
+	Currency string  `json:"currency"`
+}
+
 // agent_message_chunk
 type agentMessageChunk struct {
 	SessionUpdate string      `json:"sessionUpdate"` // "agent_message_chunk"
@@ -1,6 +1,9 @@
 package agent
 
 import (
+	"context"
+	"io"
+	"strings"
 	"testing"
 )
 
@@ -600,3 +603,28 @@ func TestBuildMcpServerSlice(t *testing.T) {
 		})
 	}
 }
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+func TestPromptUsage(t *testing.T) {
+	stdout := strings.Join([]string{
+		`{"jsonrpc":"2.0","method":"session/update","params":{"sessionId":"s1","update":{"sessionUpdate":"agent_message_chunk","content":{"type":"text","text":"hi"}}}}`,
+		`{"jsonrpc":"2.0","method":"session/update","params":{"sessionId":"s1","update":{"sessionUpdate":"usage_update","used":1200,"size":200000,"cost":{"amount":0.42,"currency":"USD"}}}}`,
+		`{"jsonrpc":"2.0","id":1,"result":{"stopReason":"end_turn","usage":{"totalTokens":1700,"inputTokens":1000,"outputTokens":500,"thoughtTokens":200,"cachedReadTokens":300}}}`,
+	}, "\n") + "\n"
+	conn := newACPConn(nopWriteCloser{io.Discard}, strings.NewReader(stdout))
+
+	stopReason, usage, err := conn.prompt(context.Background(), "s1", []string{"hello"}, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("prompt failed: %v", err)
+	}
+	if stopReason != "end_turn" {
+		t.Errorf("expected end_turn, got %s", stopReason)
+	}
+	want := Usage{InputTokens: 1000, OutputTokens: 500, ReasoningTokens: 200, CacheReadTokens: 300, Cost: 0.42}
+	if usage != want {
+		t.Errorf("expected usage %+v, got %+v", want, usage)
+	}
+}
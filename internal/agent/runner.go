@@ -38,11 +38,14 @@ type Runner struct {
 	onThinking    func(string)
 	onFinish      func(FinishEvent)
 	onFileChange  func(FileChange)
+	onPrompt      func([]string)
 
 	// ACP subprocess (reused) and current session (created fresh per iteration)
 	conn      *acpConn
 	sessionID string // Current session ID (replaced each iteration for fresh context)
-	cmd       *exec.Cmd
+	// Cumulative cost of the current ACP session as last reported by the agent
+	sessionCost float64
+	cmd         *exec.Cmd
 }
 
 // RunnerConfig holds configuration for creating a new Runner.
@@ -58,6 +61,7 @@ type RunnerConfig struct {
 	OnThinking    func(string)        // Callback for thinking/reasoning output
 	OnFinish      func(FinishEvent)   // Callback for iteration finish events
 	OnFileChange  func(FileChange)    // Callback for file modifications
+	OnPrompt      func([]string)      // Callback for content blocks sent to the agent
 }
 
 // NewRunner creates a new Runner instance.
@@ -74,6 +78,7 @@ func NewRunner(cfg RunnerConfig) *Runner {
 		onThinking:    cfg.OnThinking,
 		onFinish:      cfg.OnFinish,
 		onFileChange:  cfg.OnFileChange,
+		onPrompt:      cfg.OnPrompt,
 	}
 }
 
@@ -157,6 +162,7 @@ func (r *Runner) RunIteration(ctx context.Context, prompt string, hookOutput str
 		return fmt.Errorf("ACP new session failed: %w", err)
 	}
 	r.sessionID = sessID
+	r.sessionCost = 0
 
 	// Set model for the new session
 	if r.model != "" {
@@ -175,6 +181,9 @@ func (r *Runner) RunIteration(ctx context.Context, prompt string, hookOutput str
 		logger.Debug("Including hook output: %d bytes", len(hookOutput))
 	}
 	texts = append(texts, prompt)
+	if r.onPrompt != nil {
+		r.onPrompt(texts)
+	}
 
 	// Send prompt and stream notifications to callbacks
 	// Wire onText, onToolCall, onThinking, and onFileChange callbacks through to prompt()
@@ -184,8 +193,9 @@ func (r *Runner) RunIteration(ctx context.Context, prompt string, hookOutput str
 		"todowrite": false,
 	}
 	startTime := time.Now()
-	stopReason, err := r.conn.prompt(ctx, r.sessionID, texts, disabledTools, r.onText, r.onToolCall, r.onThinking, r.onFileChange)
+	stopReason, usage, err := r.conn.prompt(ctx, r.sessionID, texts, disabledTools, r.onText, r.onToolCall, r.onThinking, r.onFileChange)
 	duration := time.Since(startTime)
+	usage = r.turnUsage(usage)
 
 	if err != nil {
 		// Prompt failed - determine if it was cancelled or error
@@ -200,6 +210,7 @@ func (r *Runner) RunIteration(ctx context.Context, prompt string, hookOutput str
 				Duration:   duration,
 				Model:      r.model,
 				Provider:   extractProvider(r.model),
+				Usage:      usage,
 			})
 		}
 		return fmt.Errorf("ACP prompt failed: %w", err)
@@ -212,6 +223,7 @@ func (r *Runner) RunIteration(ctx context.Context, prompt string, hookOutput str
 			Duration:   duration,
 			Model:      r.model,
 			Provider:   extractProvider(r.model),
+			Usage:      usage,
 		})
 	}
 
@@ -236,12 +248,16 @@ func (r *Runner) SendMessages(ctx context.Context, texts []string) error {
 	}
 
 	logger.Debug("Sending %d user message(s) to ACP session", len(texts))
+	if r.onPrompt != nil {
+		r.onPrompt(texts)
+	}
 
 	// Send prompt with all messages as separate content blocks
 	// No tool restrictions for interactive user messages
 	startTime := time.Now()
-	stopReason, err := r.conn.prompt(ctx, r.sessionID, texts, nil, r.onText, r.onToolCall, r.onThinking, r.onFileChange)
+	stopReason, usage, err := r.conn.prompt(ctx, r.sessionID, texts, nil, r.onText, r.onToolCall, r.onThinking, r.onFileChange)
 	duration := time.Since(startTime)
+	usage = r.turnUsage(usage)
 
 	if err != nil {
 		// Prompt failed - determine if it was cancelled or error
@@ -256,6 +272,7 @@ func (r *Runner) SendMessages(ctx context.Context, texts []string) error {
 				Duration:   duration,
 				Model:      r.model,
 				Provider:   extractProvider(r.model),
+				Usage:      usage,
 			})
 		}
 		return fmt.Errorf("ACP user message failed: %w", err)
@@ -268,6 +285,7 @@ func (r *Runner) SendMessages(ctx context.Context, texts []string) error {
 			Duration:   duration,
 			Model:      r.model,
 			Provider:   extractProvider(r.model),
+			Usage:      usage,
 		})
 	}
 
@@ -275,6 +293,20 @@ func (r *Runner) SendMessages(ctx context.Context, texts []string) error {
 	return nil
 }
 
+// turnUsage converts the cumulative session cost reported by the agent into
+// the cost of the prompt that just finished.
+func (r *Runner) turnUsage(usage Usage) Usage {
+	if usage.Cost > 0 {
+		cumulative := usage.Cost
+		if cumulative >= r.sessionCost {
+			// Otherwise the agent restarted its count; the total is this turn's cost
+			usage.Cost = cumulative - r.sessionCost
+		}
+		r.sessionCost = cumulative
+	}
+	return usage
+}
+
 // Stop terminates the ACP subprocess and cleans up resources.
 // Should be called when done with the runner (e.g., on orchestrator exit).
 func (r *Runner) Stop() {
@@ -87,3 +87,18 @@ func TestExtractProvider(t *testing.T) {
 		})
 	}
 }
+
+func TestRunnerTurnUsage(t *testing.T) {
+	r := &Runner{}
+
+	// The agent reports cumulative session cost; each turn gets the difference
+	if got := r.turnUsage(Usage{OutputTokens: 10, Cost: 0.25}); got.Cost != 0.25 || got.OutputTokens != 10 {
+		t.Errorf("first turn: got %+v", got)
+	}
+	if got := r.turnUsage(Usage{Cost: 0.75}); got.Cost != 0.5 {
+		t.Errorf("second turn: expected cost 0.5, got %v", got.Cost)
+	}
+	if got := r.turnUsage(Usage{OutputTokens: 5}); got.Cost != 0 {
+		t.Errorf("turn without cost report: expected 0, got %v", got.Cost)
+	}
+}
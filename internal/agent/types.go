@@ -40,4 +40,16 @@ type FinishEvent struct {
 	Duration   time.Duration // Time taken for the iteration
 	Model      string        // Model used (e.g., "anthropic/claude-sonnet-4-5")
 	Provider   string        // Provider extracted from model (e.g., "Anthropic")
+	Usage      Usage         // Tokens and cost of this prompt (zero if the agent did not report usage)
+}
+
+// Usage reports token usage and cost for one prompt turn, as reported by the
+// agent in the prompt result and usage session updates.
+type Usage struct {
+	InputTokens      int     // Prompt tokens (excluding cache reads)
+	OutputTokens     int     // Response tokens
+	ReasoningTokens  int     // Thinking tokens
+	CacheReadTokens  int     // Prompt tokens served from cache
+	CacheWriteTokens int     // Prompt tokens written to cache
+	Cost             float64 // Cost in USD reported by the agent (0 if not reported)
 }
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 	"gopkg.in/yaml.v3"
@@ -13,15 +15,144 @@ import (
 
 // Config holds all configuration values for iteratr.
 type Config struct {
-	Model      string `mapstructure:"model" yaml:"model"`
-	AutoCommit bool   `mapstructure:"auto_commit" yaml:"auto_commit"`
-	DataDir    string `mapstructure:"data_dir" yaml:"data_dir"`
-	LogLevel   string `mapstructure:"log_level" yaml:"log_level"`
-	LogFile    string `mapstructure:"log_file" yaml:"log_file"`
-	Iterations int    `mapstructure:"iterations" yaml:"iterations"`
-	Headless   bool   `mapstructure:"headless" yaml:"headless"`
-	Template   string `mapstructure:"template" yaml:"template"`
-	SpecDir    string `mapstructure:"spec_dir" yaml:"spec_dir"`
+	Model      string         `mapstructure:"model" yaml:"model"`
+	AutoCommit bool           `mapstructure:"auto_commit" yaml:"auto_commit"`
+	DataDir    string         `mapstructure:"data_dir" yaml:"data_dir"`
+	LogLevel   string         `mapstructure:"log_level" yaml:"log_level"`
+	LogFile    string         `mapstructure:"log_file" yaml:"log_file"`
+	Iterations int            `mapstructure:"iterations" yaml:"iterations"`
+	Headless   bool           `mapstructure:"headless" yaml:"headless"`
+	Template   string         `mapstructure:"template" yaml:"template"`
+	SpecDir    string         `mapstructure:"spec_dir" yaml:"spec_dir"`
+	Scheduler  string         `mapstructure:"scheduler" yaml:"scheduler"`
+	ResetTasks bool           `mapstructure:"reset_tasks" yaml:"reset_tasks"`
+	Storage    string         `mapstructure:"storage" yaml:"storage"`
+	Retention  Retention      `mapstructure:"retention" yaml:"retention"`
+	NATS       NATS           `mapstructure:"nats" yaml:"nats,omitempty"`
+	Backup     Backup         `mapstructure:"backup" yaml:"backup"`
+	Pricing    []ModelPricing `mapstructure:"pricing" yaml:"pricing,omitempty"`
+}
+
+// NATS configures an external NATS server to keep sessions on instead of the
+// embedded one, e.g. a JetStream server shared by a team. Leave URL empty to
+// use the embedded server in the data directory.
+type NATS struct {
+	URL   string  `mapstructure:"url" yaml:"url,omitempty"`     // Server URL(s), comma-separated
+	Creds string  `mapstructure:"creds" yaml:"creds,omitempty"` // User credentials file
+	NKey  string  `mapstructure:"nkey" yaml:"nkey,omitempty"`   // Nkey seed file
+	Token string  `mapstructure:"token" yaml:"token,omitempty"` // Authentication token
+	TLS   NATSTLS `mapstructure:"tls" yaml:"tls,omitempty"`
+
+	// Stream, SubjectPrefix and SnapshotBucket name the JetStream resources,
+	// so several projects can share one server. Empty uses the defaults.
+	Stream         string `mapstructure:"stream" yaml:"stream,omitempty"`
+	SubjectPrefix  string `mapstructure:"subject_prefix" yaml:"subject_prefix,omitempty"`
+	SnapshotBucket string `mapstructure:"snapshot_bucket" yaml:"snapshot_bucket,omitempty"`
+}
+
+// Retention controls how long event history is kept. Durations accept Go
+// duration syntax plus a "d" suffix for days (e.g. "90d"); "0" means no limit.
+type Retention struct {
+	// MaxAge and MaxBytes limit the JetStream stream as a whole. They drop the
+	// oldest events of any session, so a session can lose part of its history.
+	MaxAge   string `mapstructure:"max_age" yaml:"max_age"`
+	MaxBytes int64  `mapstructure:"max_bytes" yaml:"max_bytes"`
+
+	// ArchiveAfter is how long a completed session must be inactive before
+	// `iteratr gc` archives and purges it ("0" = at the next gc). Empty, the
+	// default, archives nothing by age.
+	ArchiveAfter string `mapstructure:"archive_after" yaml:"archive_after"`
+
+	// Sessions overrides ArchiveAfter per session; "never" keeps a session.
+	Sessions map[string]string `mapstructure:"sessions" yaml:"sessions,omitempty"`
+}
+
+// Backup configures the backups written by `iteratr backup` and, every Every
+// iterations, by the build loop.
+type Backup struct {
+	Every int    `mapstructure:"every" yaml:"every"`       // Iterations between scheduled backups (0 = none)
+	Dir   string `mapstructure:"dir" yaml:"dir,omitempty"` // Where archives go (empty = backups in the data directory)
+	Keep  int    `mapstructure:"keep" yaml:"keep"`         // Archives kept in Dir, oldest deleted first (0 = all)
+}
+
+// DirFor returns the backup directory for a data directory.
+func (b Backup) DirFor(dataDir string) string {
+	if b.Dir != "" {
+		return b.Dir
+	}
+	return filepath.Join(dataDir, "backups")
+}
+
+// NATSTLS holds the TLS files for connecting to an external NATS server.
+type NATSTLS struct {
+	CA   string `mapstructure:"ca" yaml:"ca,omitempty"`     // CA certificate file to verify the server
+	Cert string `mapstructure:"cert" yaml:"cert,omitempty"` // Client certificate file, for mutual TLS
+	Key  string `mapstructure:"key" yaml:"key,omitempty"`   // Client key file
+}
+
+// RetentionNever keeps a session from ever being archived by gc.
+const RetentionNever = "never"
+
+// StreamMaxAge returns the parsed MaxAge (0 = no limit).
+func (r Retention) StreamMaxAge() (time.Duration, error) {
+	d, err := ParseDuration(r.MaxAge)
+	if err != nil {
+		return 0, fmt.Errorf("invalid retention.max_age: %w", err)
+	}
+	return d, nil
+}
+
+// ArchiveAfterFor returns how long a completed session must be inactive
+// before gc archives it. keep is true if the session must never be archived:
+// it is set to "never", or neither it nor ArchiveAfter is set.
+func (r Retention) ArchiveAfterFor(session string) (after time.Duration, keep bool, err error) {
+	value := r.ArchiveAfter
+	// Viper lowercases map keys, so match session names case-insensitively
+	for name, override := range r.Sessions {
+		if strings.EqualFold(name, session) {
+			value = override
+			break
+		}
+	}
+	if strings.TrimSpace(value) == "" || strings.EqualFold(value, RetentionNever) {
+		return 0, true, nil
+	}
+	after, err = ParseDuration(value)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid archive_after for session %s: %w", session, err)
+	}
+	return after, false, nil
+}
+
+// ParseDuration parses a Go duration or a number of days ("30d"). An empty
+// string or "0" is 0.
+func ParseDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "0" {
+		return 0, nil
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.ParseFloat(days, 64)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		return time.Duration(n * float64(24*time.Hour)), nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	return d, nil
+}
+
+// ModelPricing is the price of a model in USD per million tokens, used to
+// compute iteration and session costs.
+type ModelPricing struct {
+	Model      string  `mapstructure:"model" yaml:"model"`
+	Input      float64 `mapstructure:"input" yaml:"input"`
+	Output     float64 `mapstructure:"output" yaml:"output"`
+	CacheRead  float64 `mapstructure:"cache_read" yaml:"cache_read,omitempty"`
+	CacheWrite float64 `mapstructure:"cache_write" yaml:"cache_write,omitempty"`
 }
 
 // Load loads configuration with full precedence:
@@ -40,6 +171,14 @@ func Load() (*Config, error) {
 	v.SetDefault("headless", false)
 	v.SetDefault("template", "")
 	v.SetDefault("spec_dir", "./specs")
+	v.SetDefault("scheduler", "priority")
+	v.SetDefault("reset_tasks", true)
+	v.SetDefault("storage", "nats")
+	v.SetDefault("retention.max_age", "0")
+	v.SetDefault("retention.max_bytes", 0)
+	v.SetDefault("backup.every", 0)
+	v.SetDefault("backup.dir", "")
+	v.SetDefault("backup.keep", 5)
 
 	// Setup ENV binding with ITERATR_ prefix
 	v.SetEnvPrefix("ITERATR")
@@ -75,6 +214,35 @@ func Load() (*Config, error) {
 	if err := v.BindEnv("spec_dir", "ITERATR_SPEC_DIR"); err != nil {
 		return nil, fmt.Errorf("binding spec_dir env: %w", err)
 	}
+	if err := v.BindEnv("scheduler", "ITERATR_SCHEDULER"); err != nil {
+		return nil, fmt.Errorf("binding scheduler env: %w", err)
+	}
+	if err := v.BindEnv("reset_tasks", "ITERATR_RESET_TASKS"); err != nil {
+		return nil, fmt.Errorf("binding reset_tasks env: %w", err)
+	}
+	if err := v.BindEnv("storage", "ITERATR_STORAGE"); err != nil {
+		return nil, fmt.Errorf("binding storage env: %w", err)
+	}
+	if err := v.BindEnv("retention.max_age", "ITERATR_RETENTION_MAX_AGE"); err != nil {
+		return nil, fmt.Errorf("binding retention.max_age env: %w", err)
+	}
+	if err := v.BindEnv("retention.max_bytes", "ITERATR_RETENTION_MAX_BYTES"); err != nil {
+		return nil, fmt.Errorf("binding retention.max_bytes env: %w", err)
+	}
+	if err := v.BindEnv("retention.archive_after", "ITERATR_RETENTION_ARCHIVE_AFTER"); err != nil {
+		return nil, fmt.Errorf("binding retention.archive_after env: %w", err)
+	}
+	for _, key := range []string{"every", "dir", "keep"} {
+		if err := v.BindEnv("backup."+key, "ITERATR_BACKUP_"+strings.ToUpper(key)); err != nil {
+			return nil, fmt.Errorf("binding backup.%s env: %w", key, err)
+		}
+	}
+	for _, key := range []string{"url", "creds", "nkey", "token", "tls.ca", "tls.cert", "tls.key", "stream", "subject_prefix", "snapshot_bucket"} {
+		env := "ITERATR_NATS_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+		if err := v.BindEnv("nats."+key, env); err != nil {
+			return nil, fmt.Errorf("binding nats.%s env: %w", key, err)
+		}
+	}
 
 	// Load global config first (if exists)
 	globalPath := GlobalPath()
@@ -111,6 +279,23 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// PricingFor returns the configured pricing for a model. Models match
+// case-insensitively, and an entry without a provider prefix (e.g.
+// "claude-sonnet-4-5") matches any provider's model of that name.
+func (c *Config) PricingFor(model string) (ModelPricing, bool) {
+	model = strings.ToLower(model)
+	name := model
+	if idx := strings.Index(model, "/"); idx >= 0 {
+		name = model[idx+1:]
+	}
+	for _, p := range c.Pricing {
+		if m := strings.ToLower(p.Model); m == model || m == name {
+			return p, true
+		}
+	}
+	return ModelPricing{}, false
+}
+
 // Exists returns true if any config file exists (global or project).
 func Exists() bool {
 	return fileExists(GlobalPath()) || fileExists(ProjectPath())
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestGlobalPath(t *testing.T) {
@@ -328,6 +329,47 @@ func TestLoad_NoConfig(t *testing.T) {
 	if cfg.LogLevel != "info" {
 		t.Errorf("Load() default LogLevel = %v, want info", cfg.LogLevel)
 	}
+	if cfg.Scheduler != "priority" {
+		t.Errorf("Load() default Scheduler = %v, want priority", cfg.Scheduler)
+	}
+	if cfg.ResetTasks != true {
+		t.Errorf("Load() default ResetTasks = %v, want true", cfg.ResetTasks)
+	}
+	if cfg.Storage != "nats" {
+		t.Errorf("Load() default Storage = %v, want nats", cfg.Storage)
+	}
+	if cfg.Retention.MaxAge != "0" || cfg.Retention.ArchiveAfter != "" {
+		t.Errorf("Load() default Retention = %+v, want max_age 0 and no archive_after", cfg.Retention)
+	}
+	if cfg.NATS != (NATS{}) {
+		t.Errorf("Load() default NATS = %+v, want embedded server", cfg.NATS)
+	}
+	if cfg.Backup != (Backup{Keep: 5}) || cfg.Backup.DirFor(".iteratr") != filepath.Join(".iteratr", "backups") {
+		t.Errorf("Load() default Backup = %+v, want no scheduled backups keeping 5", cfg.Backup)
+	}
+
+	// External NATS settings from the environment
+	t.Setenv("ITERATR_NATS_URL", "tls://nats.example.com:4222")
+	t.Setenv("ITERATR_NATS_TLS_CA", "/etc/nats/ca.pem")
+	t.Setenv("ITERATR_NATS_SUBJECT_PREFIX", "team.iteratr")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.NATS.URL != "tls://nats.example.com:4222" || cfg.NATS.TLS.CA != "/etc/nats/ca.pem" || cfg.NATS.SubjectPrefix != "team.iteratr" {
+		t.Errorf("Load() NATS from env = %+v", cfg.NATS)
+	}
+
+	// Scheduled backups from the environment
+	t.Setenv("ITERATR_BACKUP_EVERY", "10")
+	t.Setenv("ITERATR_BACKUP_DIR", "/var/backups/iteratr")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Backup.Every != 10 || cfg.Backup.DirFor(".iteratr") != "/var/backups/iteratr" {
+		t.Errorf("Load() Backup from env = %+v", cfg.Backup)
+	}
 }
 
 func TestLoad_WithGlobalConfig(t *testing.T) {
@@ -373,6 +415,9 @@ func TestLoad_WithGlobalConfig(t *testing.T) {
 		Iterations: 3,
 		Headless:   false,
 		Template:   "",
+		Pricing: []ModelPricing{
+			{Model: "global/model", Input: 3, Output: 15, CacheRead: 0.3},
+		},
 	}
 	if err := WriteGlobal(globalCfg); err != nil {
 		t.Fatalf("WriteGlobal() error = %v", err)
@@ -396,6 +441,83 @@ func TestLoad_WithGlobalConfig(t *testing.T) {
 	if cfg.LogLevel != globalCfg.LogLevel {
 		t.Errorf("Load() LogLevel = %v, want %v", cfg.LogLevel, globalCfg.LogLevel)
 	}
+	if len(cfg.Pricing) != 1 || cfg.Pricing[0] != globalCfg.Pricing[0] {
+		t.Errorf("Load() Pricing = %+v, want %+v", cfg.Pricing, globalCfg.Pricing)
+	}
+}
+
+func TestPricingFor(t *testing.T) {
+	cfg := &Config{Pricing: []ModelPricing{
+		{Model: "anthropic/claude-sonnet-4-5", Input: 3, Output: 15},
+		{Model: "gpt-5", Input: 1.25, Output: 10},
+	}}
+
+	tests := []struct {
+		model     string
+		wantFound bool
+		wantInput float64
+	}{
+		{"anthropic/claude-sonnet-4-5", true, 3},
+		{"Anthropic/Claude-Sonnet-4-5", true, 3},
+		{"openai/gpt-5", true, 1.25},
+		{"gpt-5", true, 1.25},
+		{"openrouter/claude-sonnet-4-5", false, 0},
+		{"unknown/model", false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			p, found := cfg.PricingFor(tt.model)
+			if found != tt.wantFound || p.Input != tt.wantInput {
+				t.Errorf("PricingFor(%q) = %+v, %v; want input %v, %v", tt.model, p, found, tt.wantInput, tt.wantFound)
+			}
+		})
+	}
+}
+
+func TestRetention(t *testing.T) {
+	r := Retention{
+		MaxAge:       "90d",
+		ArchiveAfter: "48h",
+		Sessions:     map[string]string{"pinned": "never", "scratch": "1h"},
+	}
+
+	if d, err := r.StreamMaxAge(); err != nil || d != 90*24*time.Hour {
+		t.Errorf("StreamMaxAge() = %v, %v, want 2160h", d, err)
+	}
+
+	tests := []struct {
+		session string
+		after   time.Duration
+		keep    bool
+	}{
+		{"other", 48 * time.Hour, false},
+		{"Pinned", 0, true},
+		{"scratch", time.Hour, false},
+	}
+	for _, tt := range tests {
+		after, keep, err := r.ArchiveAfterFor(tt.session)
+		if err != nil || after != tt.after || keep != tt.keep {
+			t.Errorf("ArchiveAfterFor(%q) = %v, %v, %v, want %v, %v", tt.session, after, keep, err, tt.after, tt.keep)
+		}
+	}
+
+	// Without archive_after only sessions given their own are archived
+	r.ArchiveAfter = ""
+	if _, keep, err := r.ArchiveAfterFor("other"); err != nil || !keep {
+		t.Errorf("ArchiveAfterFor(\"other\") without archive_after = %v, %v, want keep", keep, err)
+	}
+	if after, keep, err := r.ArchiveAfterFor("scratch"); err != nil || keep || after != time.Hour {
+		t.Errorf("ArchiveAfterFor(\"scratch\") without archive_after = %v, %v, %v, want 1h", after, keep, err)
+	}
+
+	for _, bad := range []string{"soon", "-1h", "xd"} {
+		if _, err := ParseDuration(bad); err == nil {
+			t.Errorf("ParseDuration(%q) expected error", bad)
+		}
+	}
+	if d, err := ParseDuration("0"); err != nil || d != 0 {
+		t.Errorf("ParseDuration(\"0\") = %v, %v, want 0", d, err)
+	}
 }
 
 func TestValidate(t *testing.T) {
@@ -47,18 +47,39 @@ type Variables struct {
 	Iteration   string
 	TaskID      string
 	TaskContent string
+	TaskLabels  []string
 	Error       string
 }
 
-// Execute runs a hook command and returns its output.
-// Template variables in the command ({{session}}, {{iteration}}) are expanded before execution.
-// On error, returns an error message as output and nil error (graceful degradation).
-// Only returns error for context cancellation.
-func Execute(ctx context.Context, hook *HookConfig, workDir string, vars Variables) (string, error) {
-	if hook == nil || hook.Command == "" {
-		return "", nil
+// Result holds the outcome of running a hook command.
+type Result struct {
+	Command  string // Command after variable expansion
+	Stdout   string
+	Stderr   string
+	Err      error // Non-nil if the command exited non-zero or could not be started
+	TimedOut bool
+	Timeout  int // Effective timeout in seconds
+}
+
+// Failed reports whether the command timed out or exited unsuccessfully.
+func (r *Result) Failed() bool {
+	return r.TimedOut || r.Err != nil
+}
+
+// Output returns stdout followed by stderr (if any), so callers have full context.
+func (r *Result) Output() string {
+	output := r.Stdout
+	if r.Stderr != "" {
+		output += "\n[stderr]\n" + r.Stderr
 	}
+	return output
+}
 
+// Run runs a hook command and reports its exit status along with its output.
+// Template variables in the command are expanded before execution.
+// Command failures and timeouts are reported in the Result, not as errors.
+// Only returns error for context cancellation.
+func Run(ctx context.Context, hook *HookConfig, workDir string, vars Variables) (*Result, error) {
 	// Expand template variables in command
 	command := expandVariables(hook.Command, vars)
 	logger.Debug("Executing hook command: %s", command)
@@ -76,6 +97,8 @@ func Execute(ctx context.Context, hook *HookConfig, workDir string, vars Variabl
 	// Execute command via shell
 	cmd := exec.CommandContext(execCtx, "sh", "-c", command)
 	cmd.Dir = workDir
+	// Don't wait on output pipes held open by orphaned children after a timeout
+	cmd.WaitDelay = time.Second
 
 	// Capture stdout and stderr separately
 	var stdout, stderr bytes.Buffer
@@ -87,32 +110,55 @@ func Execute(ctx context.Context, hook *HookConfig, workDir string, vars Variabl
 
 	// Check for context cancellation (propagate this)
 	if ctx.Err() != nil {
-		return "", ctx.Err()
+		return nil, ctx.Err()
+	}
+
+	return &Result{
+		Command:  command,
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+		Err:      err,
+		TimedOut: execCtx.Err() == context.DeadlineExceeded,
+		Timeout:  timeout,
+	}, nil
+}
+
+// Execute runs a hook command and returns its output.
+// Template variables in the command ({{session}}, {{iteration}}) are expanded before execution.
+// On error, returns an error message as output and nil error (graceful degradation).
+// Only returns error for context cancellation.
+func Execute(ctx context.Context, hook *HookConfig, workDir string, vars Variables) (string, error) {
+	if hook == nil || hook.Command == "" {
+		return "", nil
+	}
+
+	if !matchesLabels(hook.Labels, vars.TaskLabels) {
+		logger.Debug("Skipping hook %q: task labels %v do not match %v", hook.Command, vars.TaskLabels, hook.Labels)
+		return "", nil
+	}
+
+	result, err := Run(ctx, hook, workDir, vars)
+	if err != nil {
+		return "", err
 	}
 
 	// Handle timeout
-	if execCtx.Err() == context.DeadlineExceeded {
-		logger.Warn("Hook command timed out after %ds: %s", timeout, command)
-		return fmt.Sprintf("[Hook timed out after %ds]\nPartial output:\n%s", timeout, stdout.String()), nil
+	if result.TimedOut {
+		logger.Warn("Hook command timed out after %ds: %s", result.Timeout, result.Command)
+		return fmt.Sprintf("[Hook timed out after %ds]\nPartial output:\n%s", result.Timeout, result.Stdout), nil
 	}
 
 	// Handle command failure (graceful degradation - include error in output)
-	if err != nil {
-		logger.Warn("Hook command failed: %v", err)
-		output := stdout.String()
-		if stderr.Len() > 0 {
-			output += "\n[stderr]\n" + stderr.String()
-		}
-		return fmt.Sprintf("[Hook command failed: %v]\n%s", err, output), nil
+	if result.Err != nil {
+		logger.Warn("Hook command failed: %v", result.Err)
+		return fmt.Sprintf("[Hook command failed: %v]\n%s", result.Err, result.Output()), nil
 	}
 
 	// Success - return stdout (include stderr if present)
-	output := stdout.String()
-	if stderr.Len() > 0 {
-		logger.Debug("Hook stderr: %s", stderr.String())
-		// Include stderr in output so agent has full context
-		output += "\n[stderr]\n" + stderr.String()
+	if result.Stderr != "" {
+		logger.Debug("Hook stderr: %s", result.Stderr)
 	}
+	output := result.Output()
 
 	logger.Debug("Hook executed successfully, output length: %d bytes", len(output))
 	return output, nil
@@ -173,6 +219,7 @@ func expandVariables(command string, vars Variables) string {
 		"{{iteration}}":    vars.Iteration,
 		"{{task_id}}":      vars.TaskID,
 		"{{task_content}}": vars.TaskContent,
+		"{{task_labels}}":  strings.Join(vars.TaskLabels, ","),
 		"{{error}}":        vars.Error,
 	}
 
@@ -182,3 +229,19 @@ func expandVariables(command string, vars Variables) string {
 	}
 	return result
 }
+
+// matchesLabels reports whether a hook restricted to the given labels should run
+// for a task with taskLabels. A hook without labels runs for every task.
+func matchesLabels(hookLabels, taskLabels []string) bool {
+	if len(hookLabels) == 0 {
+		return true
+	}
+	for _, want := range hookLabels {
+		for _, label := range taskLabels {
+			if strings.EqualFold(label, want) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -94,6 +94,72 @@ func TestExecuteAllPiped_ContextCancellation(t *testing.T) {
 	}
 }
 
+func TestExecuteAll_LabelFilter(t *testing.T) {
+	ctx := context.Background()
+	workDir := t.TempDir()
+	hooks := []*HookConfig{
+		{Command: "echo always", Timeout: 5},
+		{Command: "echo backend", Timeout: 5, Labels: []string{"backend"}},
+		{Command: "echo frontend", Timeout: 5, Labels: []string{"frontend", "ui"}},
+	}
+
+	output, err := ExecuteAll(ctx, hooks, workDir, Variables{TaskLabels: []string{"bug", "backend"}})
+	if err != nil {
+		t.Fatalf("ExecuteAll() unexpected error: %v", err)
+	}
+	expected := "always\n\nbackend\n"
+	if output != expected {
+		t.Errorf("ExecuteAll() = %q, expected %q", output, expected)
+	}
+}
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+	workDir := t.TempDir()
+	vars := Variables{Session: "test", Iteration: "1", TaskID: "TAS-1"}
+
+	tests := []struct {
+		name       string
+		hook       *HookConfig
+		wantFailed bool
+		wantOutput string
+	}{
+		{
+			name:       "success",
+			hook:       &HookConfig{Command: "echo ok {{task_id}}", Timeout: 5},
+			wantFailed: false,
+			wantOutput: "ok TAS-1\n",
+		},
+		{
+			name:       "non-zero exit",
+			hook:       &HookConfig{Command: "echo out; echo err >&2; exit 3", Timeout: 5},
+			wantFailed: true,
+			wantOutput: "out\n\n[stderr]\nerr\n",
+		},
+		{
+			name:       "timeout",
+			hook:       &HookConfig{Command: "sleep 5", Timeout: 1},
+			wantFailed: true,
+			wantOutput: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Run(ctx, tt.hook, workDir, vars)
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if result.Failed() != tt.wantFailed {
+				t.Errorf("Run() failed = %v, expected %v (err=%v)", result.Failed(), tt.wantFailed, result.Err)
+			}
+			if result.Output() != tt.wantOutput {
+				t.Errorf("Run() output = %q, expected %q", result.Output(), tt.wantOutput)
+			}
+		})
+	}
+}
+
 func TestConfigParsing(t *testing.T) {
 	yamlContent := `
 version: 1
@@ -308,6 +374,12 @@ func TestExpandVariables(t *testing.T) {
 			vars:     Variables{},
 			expected: "echo     ",
 		},
+		{
+			name:     "task labels",
+			command:  "./check.sh {{task_labels}}",
+			vars:     Variables{TaskLabels: []string{"backend", "bug"}},
+			expected: "./check.sh backend,bug",
+		},
 		{
 			name:     "multiple same variable",
 			command:  "{{session}}-{{session}}-{{session}}",
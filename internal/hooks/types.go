@@ -18,9 +18,10 @@ type HooksConfig struct {
 
 // HookConfig defines a single hook's configuration.
 type HookConfig struct {
-	Command    string `yaml:"command"`
-	Timeout    int    `yaml:"timeout"`     // seconds, default 30
-	PipeOutput bool   `yaml:"pipe_output"` // default false
+	Command    string   `yaml:"command"`
+	Timeout    int      `yaml:"timeout"`     // seconds, default 30
+	PipeOutput bool     `yaml:"pipe_output"` // default false
+	Labels     []string `yaml:"labels"`      // only run for tasks with one of these labels; empty runs for all
 }
 
 // DefaultTimeout is the default timeout for hook execution in seconds.
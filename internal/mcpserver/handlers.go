@@ -4,8 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 
+	"github.com/mark3labs/iteratr/internal/hooks"
+	"github.com/mark3labs/iteratr/internal/logger"
 	"github.com/mark3labs/iteratr/internal/session"
 	"github.com/mark3labs/mcp-go/mcp"
 )
@@ -61,10 +64,34 @@ func (s *Server) handleTaskAdd(ctx context.Context, request mcp.CallToolRequest)
 			priority = int(priorityVal)
 		}
 
+		// Extract optional parent task ID
+		parent := ""
+		if parentVal, ok := taskMap["parent"].(string); ok {
+			parent = parentVal
+		}
+
+		// Extract optional acceptance criteria and verify command
+		acceptance, _ := taskMap["acceptance"].(string)
+		verify, _ := taskMap["verify"].(string)
+
+		// Extract optional labels
+		var labels []string
+		if labelsVal, ok := taskMap["labels"].([]any); ok {
+			for _, l := range labelsVal {
+				if label, ok := l.(string); ok {
+					labels = append(labels, label)
+				}
+			}
+		}
+
 		taskParams = append(taskParams, session.TaskAddParams{
-			Content:  content,
-			Status:   status,
-			Priority: priority,
+			Content:    content,
+			Status:     status,
+			Priority:   priority,
+			ParentID:   parent,
+			Acceptance: acceptance,
+			Verify:     verify,
+			Labels:     labels,
 			// Iteration will be set by store based on current iteration
 		})
 	}
@@ -79,6 +106,9 @@ func (s *Server) handleTaskAdd(ctx context.Context, request mcp.CallToolRequest)
 	result := fmt.Sprintf("Added %d task(s):", len(tasks))
 	for _, task := range tasks {
 		result += fmt.Sprintf("\n  %s: %s", task.ID, task.Content)
+		if task.ParentID != "" {
+			result += fmt.Sprintf(" (subtask of %s)", task.ParentID)
+		}
 	}
 
 	return mcp.NewToolResultText(result), nil
@@ -110,9 +140,47 @@ func (s *Server) handleTaskUpdate(ctx context.Context, request mcp.CallToolReque
 		currentIteration = state.Iterations[len(state.Iterations)-1].Number
 	}
 
+	// Remove is exclusive: deleting the task makes any other update meaningless
+	if remove, ok := args["remove"].(bool); ok && remove {
+		for _, key := range []string{"content", "status", "priority", "depends_on", "remove_depends_on", "add_labels", "remove_labels"} {
+			if _, present := args[key]; present {
+				return mcp.NewToolResultText("error: 'remove' cannot be combined with other updates"), nil
+			}
+		}
+
+		err := s.store.TaskRemove(ctx, s.sessName, session.TaskRemoveParams{
+			ID:        id,
+			Iteration: currentIteration,
+		})
+		if err != nil {
+			return mcp.NewToolResultText(fmt.Sprintf("error: failed to remove task: %v", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Removed task %s", id)), nil
+	}
+
+	// Completing a task with a verify command requires the command to pass first
+	if status, _ := args["status"].(string); status == "completed" {
+		if failure := s.verifyTask(ctx, state, id, currentIteration); failure != "" {
+			return mcp.NewToolResultText(failure), nil
+		}
+	}
+
 	// Track what we updated for the success message
 	updated := []string{}
 
+	// Update content if provided
+	if content, ok := args["content"].(string); ok && content != "" {
+		err := s.store.TaskEdit(ctx, s.sessName, session.TaskEditParams{
+			ID:        id,
+			Content:   content,
+			Iteration: currentIteration,
+		})
+		if err != nil {
+			return mcp.NewToolResultText(fmt.Sprintf("error: failed to update content: %v", err)), nil
+		}
+		updated = append(updated, "content")
+	}
+
 	// Update status if provided
 	if status, ok := args["status"].(string); ok && status != "" {
 		err := s.store.TaskStatus(ctx, s.sessName, session.TaskStatusParams{
@@ -162,9 +230,51 @@ func (s *Server) handleTaskUpdate(ctx context.Context, request mcp.CallToolReque
 		updated = append(updated, fmt.Sprintf("depends_on=%s", dependsOn))
 	}
 
+	// Remove dependency if provided
+	if removeDependsOn, ok := args["remove_depends_on"].(string); ok && removeDependsOn != "" {
+		err := s.store.TaskUndepend(ctx, s.sessName, session.TaskDependsParams{
+			ID:        id,
+			DependsOn: removeDependsOn,
+			Iteration: currentIteration,
+		})
+		if err != nil {
+			return mcp.NewToolResultText(fmt.Sprintf("error: failed to remove dependency: %v", err)), nil
+		}
+		updated = append(updated, fmt.Sprintf("removed depends_on=%s", removeDependsOn))
+	}
+
+	// Update labels if provided (comma-separated)
+	addLabelsRaw, _ := args["add_labels"].(string)
+	removeLabelsRaw, _ := args["remove_labels"].(string)
+	if addLabelsRaw != "" || removeLabelsRaw != "" {
+		addLabels, err := session.ParseLabels(addLabelsRaw)
+		if err != nil {
+			return mcp.NewToolResultText(fmt.Sprintf("error: %v", err)), nil
+		}
+		removeLabels, err := session.ParseLabels(removeLabelsRaw)
+		if err != nil {
+			return mcp.NewToolResultText(fmt.Sprintf("error: %v", err)), nil
+		}
+		err = s.store.TaskLabel(ctx, s.sessName, session.TaskLabelParams{
+			ID:        id,
+			Add:       addLabels,
+			Remove:    removeLabels,
+			Iteration: currentIteration,
+		})
+		if err != nil {
+			return mcp.NewToolResultText(fmt.Sprintf("error: failed to update labels: %v", err)), nil
+		}
+		if len(addLabels) > 0 {
+			updated = append(updated, fmt.Sprintf("added labels=%s", strings.Join(addLabels, ",")))
+		}
+		if len(removeLabels) > 0 {
+			updated = append(updated, fmt.Sprintf("removed labels=%s", strings.Join(removeLabels, ",")))
+		}
+	}
+
 	// Check if anything was actually updated
 	if len(updated) == 0 {
-		return mcp.NewToolResultText("error: no valid update parameters provided (status, priority, or depends_on required)"), nil
+		return mcp.NewToolResultText("error: no valid update parameters provided (content, status, priority, depends_on, remove_depends_on, add_labels, remove_labels, or remove required)"), nil
 	}
 
 	// Return success message
@@ -173,13 +283,93 @@ func (s *Server) handleTaskUpdate(ctx context.Context, request mcp.CallToolReque
 		result += fmt.Sprintf(", %s", updated[i])
 	}
 
+	// Closing the last open subtask surfaces the parent for completion
+	if status, _ := args["status"].(string); status == "completed" || status == "cancelled" {
+		if state, err := s.store.LoadState(ctx, s.sessName); err == nil {
+			if taskID, err := state.ResolveTaskID(id); err == nil {
+				if parent := state.SurfacedParent(taskID); parent != nil {
+					result += fmt.Sprintf("\nAll subtasks of %s are closed; parent task is ready: %s", parent.ID, parent.Content)
+				}
+			}
+		}
+	}
+
 	return mcp.NewToolResultText(result), nil
 }
 
+// verifyTimeout is the timeout in seconds for a task's verify command.
+const verifyTimeout = 300
+
+// maxVerifyOutput caps how much verify output is recorded and returned to the agent.
+const maxVerifyOutput = 4000
+
+// verifyTask runs the task's verify command (if any) in the work dir.
+// Returns an empty string if the task may be completed, or an error message for
+// the agent if verification failed. Failures are also recorded as a "stuck" note.
+func (s *Server) verifyTask(ctx context.Context, state *session.State, id string, iteration int) string {
+	taskID, err := state.ResolveTaskID(id)
+	if err != nil {
+		// Let the status update report the resolution error
+		return ""
+	}
+	task := state.Tasks[taskID]
+	if task.Verify == "" || len(state.OpenChildren(taskID)) > 0 {
+		return ""
+	}
+
+	logger.Debug("Verifying task %s: %s", taskID, task.Verify)
+	result, err := hooks.Run(ctx, &hooks.HookConfig{Command: task.Verify, Timeout: verifyTimeout}, s.workDir, hooks.Variables{
+		Session:     s.sessName,
+		Iteration:   strconv.Itoa(iteration),
+		TaskID:      task.ID,
+		TaskContent: task.Content,
+	})
+	if err != nil {
+		return fmt.Sprintf("error: verification of %s cancelled: %v", taskID, err)
+	}
+	if !result.Failed() {
+		return ""
+	}
+
+	reason := fmt.Sprintf("%v", result.Err)
+	if result.TimedOut {
+		reason = fmt.Sprintf("timed out after %ds", result.Timeout)
+	}
+	output := strings.TrimSpace(result.Output())
+	if len(output) > maxVerifyOutput {
+		output = "...[truncated]\n" + output[len(output)-maxVerifyOutput:]
+	}
+
+	// Record the failure so it survives into the next iteration's prompt
+	_, err = s.store.NoteAdd(ctx, s.sessName, session.NoteAddParams{
+		Content:   fmt.Sprintf("Verification failed for %s (%s): %s\n%s", taskID, task.Verify, reason, output),
+		Type:      "stuck",
+		Iteration: iteration,
+	})
+	if err != nil {
+		logger.Warn("Failed to record verification failure note: %v", err)
+	}
+
+	return fmt.Sprintf("error: verification failed for %s, task not completed\nCommand: %s\nResult: %s\nOutput:\n%s",
+		taskID, task.Verify, reason, output)
+}
+
 // handleTaskList returns all tasks grouped by status.
 func (s *Server) handleTaskList(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract optional label filter (comma-separated, tasks must carry all)
+	labelFilter := ""
+	if args := request.GetArguments(); args != nil {
+		if l, ok := args["label"].(string); ok {
+			labelFilter = l
+		}
+	}
+	labels, err := session.ParseLabels(labelFilter)
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("error: %v", err)), nil
+	}
+
 	// Call TaskList
-	result, err := s.store.TaskList(ctx, s.sessName)
+	result, err := s.store.TaskList(ctx, s.sessName, session.TaskListParams{Labels: labels})
 	if err != nil {
 		return mcp.NewToolResultText(fmt.Sprintf("error: %v", err)), nil
 	}
@@ -196,8 +386,8 @@ func (s *Server) handleTaskList(ctx context.Context, request mcp.CallToolRequest
 			statusLabel = strings.ToUpper(statusLabel[:1]) + statusLabel[1:]
 		}
 		lines = append(lines, fmt.Sprintf("%s:", statusLabel))
-		for _, t := range tasks {
-			lines = append(lines, fmt.Sprintf("  [%s] %s", t.ID, t.Content))
+		for _, node := range session.TaskTree(tasks) {
+			lines = append(lines, formatTaskLine(node))
 		}
 	}
 
@@ -208,16 +398,45 @@ func (s *Server) handleTaskList(ctx context.Context, request mcp.CallToolRequest
 	formatTasks("cancelled", result.Cancelled)
 
 	if len(lines) == 0 {
+		if len(labels) > 0 {
+			return mcp.NewToolResultText(fmt.Sprintf("No tasks with label(s) %s", strings.Join(labels, ", "))), nil
+		}
 		return mcp.NewToolResultText("No tasks"), nil
 	}
 
 	return mcp.NewToolResultText(strings.Join(lines, "\n")), nil
 }
 
+// formatTaskLine formats a task for list output, indenting subtasks under their
+// parent and noting the parent of subtasks listed in a different status group.
+func formatTaskLine(node session.TaskTreeNode) string {
+	t := node.Task
+	line := fmt.Sprintf("  %s[%s] %s", strings.Repeat("  ", node.Depth), t.ID, t.Content)
+	if t.ParentID != "" && node.Depth == 0 {
+		line += fmt.Sprintf(" (subtask of %s)", t.ParentID)
+	}
+	if len(t.Labels) > 0 {
+		line += fmt.Sprintf(" [labels: %s]", strings.Join(t.Labels, ", "))
+	}
+	return line
+}
+
 // handleTaskNext returns the next highest priority unblocked task.
 func (s *Server) handleTaskNext(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract optional label filter (comma-separated, tasks must carry all)
+	labelFilter := ""
+	if args := request.GetArguments(); args != nil {
+		if l, ok := args["label"].(string); ok {
+			labelFilter = l
+		}
+	}
+	labels, err := session.ParseLabels(labelFilter)
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("error: %v", err)), nil
+	}
+
 	// Call TaskNext
-	task, err := s.store.TaskNext(ctx, s.sessName)
+	task, err := s.store.TaskNext(ctx, s.sessName, session.TaskNextParams{Labels: labels})
 	if err != nil {
 		return mcp.NewToolResultText(fmt.Sprintf("error: %v", err)), nil
 	}
@@ -227,12 +446,25 @@ func (s *Server) handleTaskNext(ctx context.Context, request mcp.CallToolRequest
 	}
 
 	// Output JSON for parsing (matching CLI format)
-	output, err := json.Marshal(map[string]any{
+	fields := map[string]any{
 		"id":       task.ID,
 		"content":  task.Content,
 		"priority": task.Priority,
 		"status":   task.Status,
-	})
+	}
+	if task.ParentID != "" {
+		fields["parent_id"] = task.ParentID
+	}
+	if task.Acceptance != "" {
+		fields["acceptance"] = task.Acceptance
+	}
+	if task.Verify != "" {
+		fields["verify"] = task.Verify
+	}
+	if len(task.Labels) > 0 {
+		fields["labels"] = task.Labels
+	}
+	output, err := json.Marshal(fields)
 	if err != nil {
 		return mcp.NewToolResultText(fmt.Sprintf("error: failed to marshal task: %v", err)), nil
 	}
@@ -240,6 +472,72 @@ func (s *Server) handleTaskNext(ctx context.Context, request mcp.CallToolRequest
 	return mcp.NewToolResultText(string(output)), nil
 }
 
+// handleTaskGraph describes task dependencies: the order open tasks can be done
+// in, the critical path, dependency cycles, and blocked-by chains.
+func (s *Server) handleTaskGraph(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	state, err := s.store.LoadState(ctx, s.sessName)
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("error: failed to load state: %v", err)), nil
+	}
+
+	// Single task: only its blocked-by chains
+	if args := request.GetArguments(); args != nil {
+		if id, ok := args["id"].(string); ok && id != "" {
+			taskID, err := state.ResolveTaskID(id)
+			if err != nil {
+				return mcp.NewToolResultText(fmt.Sprintf("error: %v", err)), nil
+			}
+			chains := state.BlockedBy(taskID)
+			if len(chains) == 0 {
+				return mcp.NewToolResultText(fmt.Sprintf("%s is not blocked by any dependency", taskID)), nil
+			}
+			lines := []string{fmt.Sprintf("%s is blocked by:", taskID)}
+			for _, chain := range chains {
+				lines = append(lines, "  "+session.FormatChain(chain))
+			}
+			return mcp.NewToolResultText(strings.Join(lines, "\n")), nil
+		}
+	}
+
+	order := state.TopologicalOrder()
+	if len(order) == 0 {
+		return mcp.NewToolResultText("No open tasks"), nil
+	}
+
+	lines := []string{"Order (dependencies first):"}
+	for i, task := range order {
+		lines = append(lines, fmt.Sprintf("  %d. [%s] %s (P%d, %s)", i+1, task.ID, task.Content, task.Priority, task.Status))
+	}
+
+	if path := state.CriticalPath(); len(path) > 1 {
+		ids := make([]string, len(path))
+		for i, task := range path {
+			ids[i] = task.ID
+		}
+		lines = append(lines, fmt.Sprintf("Critical path (%d tasks): %s", len(path), session.FormatChain(ids)))
+	}
+
+	var blocked []string
+	for _, task := range order {
+		for _, chain := range state.BlockedBy(task.ID) {
+			blocked = append(blocked, "  "+session.FormatChain(chain))
+		}
+	}
+	if len(blocked) > 0 {
+		lines = append(lines, "Blocked by:")
+		lines = append(lines, blocked...)
+	}
+
+	if cycles := state.DependencyCycles(); len(cycles) > 0 {
+		lines = append(lines, "Cycles (no task in a cycle can become ready; use task-update remove_depends_on to break):")
+		for _, cycle := range cycles {
+			lines = append(lines, "  "+session.FormatChain(cycle))
+		}
+	}
+
+	return mcp.NewToolResultText(strings.Join(lines, "\n")), nil
+}
+
 // handleNoteAdd adds one or more notes to the session.
 func (s *Server) handleNoteAdd(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	// Extract arguments
@@ -357,6 +655,28 @@ func (s *Server) handleNoteList(ctx context.Context, request mcp.CallToolRequest
 	return mcp.NewToolResultText(strings.Join(lines, "\n")), nil
 }
 
+// handleKnowledgePromote copies a note of the session into the project knowledge base.
+func (s *Server) handleKnowledgePromote(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract arguments
+	args := request.GetArguments()
+	if args == nil {
+		return mcp.NewToolResultText("error: no arguments provided"), nil
+	}
+
+	// Extract required note ID
+	id, ok := args["id"].(string)
+	if !ok || id == "" {
+		return mcp.NewToolResultText("error: missing or empty 'id' parameter"), nil
+	}
+
+	entry, err := s.store.KnowledgePromote(ctx, s.sessName, id)
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("error: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Promoted %s to knowledge base as %s", id, entry.ID)), nil
+}
+
 // handleIterationSummary records a summary for the current iteration.
 func (s *Server) handleIterationSummary(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	// Extract arguments
@@ -417,3 +737,57 @@ func (s *Server) handleSessionComplete(ctx context.Context, request mcp.CallTool
 
 	return mcp.NewToolResultText("Session marked complete"), nil
 }
+
+// defaultSearchLimit caps search results when the agent gives no limit.
+const defaultSearchLimit = 20
+
+// handleSearch searches tasks, notes and iteration summaries across all sessions.
+func (s *Server) handleSearch(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract arguments
+	args := request.GetArguments()
+	if args == nil {
+		return mcp.NewToolResultText("error: no arguments provided"), nil
+	}
+
+	// Extract required query parameter
+	query, ok := args["query"].(string)
+	if !ok || strings.TrimSpace(query) == "" {
+		return mcp.NewToolResultText("error: missing or empty 'query' parameter"), nil
+	}
+
+	// Extract optional filters
+	params := session.SearchParams{Query: query, Limit: defaultSearchLimit}
+	params.NoteType, _ = args["type"].(string)
+	params.Status, _ = args["status"].(string)
+	params.Session, _ = args["session"].(string)
+	if limit, ok := args["limit"].(float64); ok && limit > 0 {
+		params.Limit = int(limit)
+	}
+
+	results, err := s.store.Search(ctx, params)
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("error: %v", err)), nil
+	}
+	if len(results) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("No matches for '%s'", query)), nil
+	}
+
+	// Format: session ID (#iteration) kind: content
+	var lines []string
+	for _, r := range results {
+		line := r.Session
+		if r.ID != "" {
+			line += " " + r.ID
+		}
+		line += fmt.Sprintf(" (#%d) %s", r.Iteration, r.Kind)
+		switch {
+		case r.Status != "":
+			line += " [" + r.Status + "]"
+		case r.NoteType != "":
+			line += " [" + r.NoteType + "]"
+		}
+		lines = append(lines, line+": "+r.Content)
+	}
+
+	return mcp.NewToolResultText(strings.Join(lines, "\n")), nil
+}
@@ -40,11 +40,11 @@ func setupTestServer(t *testing.T) (*Server, func()) {
 	}
 
 	// Create store
-	store := session.NewStore(js, stream)
+	store := session.NewStore(session.NewJetStreamLog(js, stream))
 
 	// Create server
 	sessionName := "test-session"
-	srv := New(store, sessionName)
+	srv := New(store, sessionName, t.TempDir())
 
 	cleanup := func() {
 		nc.Close()
@@ -111,6 +111,216 @@ func TestHandleTaskAdd_Success(t *testing.T) {
 	}
 }
 
+func TestHandleTaskAdd_WithParent(t *testing.T) {
+	srv, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	// Add parent task
+	_, err := srv.store.TaskAdd(ctx, srv.sessName, session.TaskAddParams{Content: "Epic"})
+	if err != nil {
+		t.Fatalf("failed to add parent: %v", err)
+	}
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "task-add",
+			Arguments: map[string]any{
+				"tasks": []any{
+					map[string]any{
+						"content": "Step one",
+						"parent":  "TAS-1",
+					},
+				},
+			},
+		},
+	}
+
+	result, err := srv.handleTaskAdd(ctx, request)
+	if err != nil {
+		t.Fatalf("handleTaskAdd returned error: %v", err)
+	}
+
+	text := extractText(result)
+	if !strings.Contains(text, "TAS-2: Step one (subtask of TAS-1)") {
+		t.Errorf("expected subtask in result, got: %s", text)
+	}
+
+	// Task list nests the subtask under its parent
+	listResult, err := srv.handleTaskList(ctx, mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatalf("handleTaskList returned error: %v", err)
+	}
+	listText := extractText(listResult)
+	if !strings.Contains(listText, "  [TAS-1] Epic\n    [TAS-2] Step one") {
+		t.Errorf("expected nested task list, got: %s", listText)
+	}
+
+	// Completing the last subtask reports the surfaced parent
+	updateReq := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "task-update",
+			Arguments: map[string]any{
+				"id":     "TAS-2",
+				"status": "completed",
+			},
+		},
+	}
+	updateResult, err := srv.handleTaskUpdate(ctx, updateReq)
+	if err != nil {
+		t.Fatalf("handleTaskUpdate returned error: %v", err)
+	}
+	if text := extractText(updateResult); !strings.Contains(text, "parent task is ready") {
+		t.Errorf("expected surfaced parent message, got: %s", text)
+	}
+}
+
+func TestHandleTask_Labels(t *testing.T) {
+	srv, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	addReq := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "task-add",
+			Arguments: map[string]any{
+				"tasks": []any{
+					map[string]any{"content": "API endpoint", "priority": float64(2), "labels": []any{"Backend"}},
+					map[string]any{"content": "Login button", "priority": float64(1), "labels": []any{"frontend"}},
+				},
+			},
+		},
+	}
+	if _, err := srv.handleTaskAdd(ctx, addReq); err != nil {
+		t.Fatalf("handleTaskAdd returned error: %v", err)
+	}
+
+	// List filtered by label (labels are normalized to lowercase)
+	listReq := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name:      "task-list",
+			Arguments: map[string]any{"label": "backend"},
+		},
+	}
+	listResult, err := srv.handleTaskList(ctx, listReq)
+	if err != nil {
+		t.Fatalf("handleTaskList returned error: %v", err)
+	}
+	listText := extractText(listResult)
+	if !strings.Contains(listText, "[TAS-1] API endpoint [labels: backend]") {
+		t.Errorf("expected labelled task in list, got: %s", listText)
+	}
+	if strings.Contains(listText, "Login button") {
+		t.Errorf("expected frontend task to be filtered out, got: %s", listText)
+	}
+
+	// Next task respects the filter even though TAS-2 has higher priority
+	nextReq := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name:      "task-next",
+			Arguments: map[string]any{"label": "backend"},
+		},
+	}
+	nextResult, err := srv.handleTaskNext(ctx, nextReq)
+	if err != nil {
+		t.Fatalf("handleTaskNext returned error: %v", err)
+	}
+	if text := extractText(nextResult); !strings.Contains(text, `"id":"TAS-1"`) {
+		t.Errorf("expected TAS-1 as next backend task, got: %s", text)
+	}
+
+	// Relabel via task-update
+	updateReq := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "task-update",
+			Arguments: map[string]any{
+				"id":            "TAS-2",
+				"add_labels":    "backend,urgent",
+				"remove_labels": "frontend",
+			},
+		},
+	}
+	updateResult, err := srv.handleTaskUpdate(ctx, updateReq)
+	if err != nil {
+		t.Fatalf("handleTaskUpdate returned error: %v", err)
+	}
+	if text := extractText(updateResult); !strings.Contains(text, "added labels=backend,urgent") {
+		t.Errorf("expected label update in result, got: %s", text)
+	}
+
+	state, err := srv.store.LoadState(ctx, srv.sessName)
+	if err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+	if got := strings.Join(state.Tasks["TAS-2"].Labels, ","); got != "backend,urgent" {
+		t.Errorf("expected labels backend,urgent, got %q", got)
+	}
+}
+
+func TestHandleTaskGraph(t *testing.T) {
+	srv, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	for _, content := range []string{"Schema", "API", "UI"} {
+		if _, err := srv.store.TaskAdd(ctx, srv.sessName, session.TaskAddParams{Content: content}); err != nil {
+			t.Fatalf("failed to add task: %v", err)
+		}
+	}
+	// UI -> API -> Schema
+	_ = srv.store.TaskDepends(ctx, srv.sessName, session.TaskDependsParams{ID: "TAS-2", DependsOn: "TAS-1"})
+	_ = srv.store.TaskDepends(ctx, srv.sessName, session.TaskDependsParams{ID: "TAS-3", DependsOn: "TAS-2"})
+
+	result, err := srv.handleTaskGraph(ctx, mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatalf("handleTaskGraph returned error: %v", err)
+	}
+	text := extractText(result)
+	for _, want := range []string{
+		"1. [TAS-1] Schema",
+		"3. [TAS-3] UI",
+		"Critical path (3 tasks): TAS-1 -> TAS-2 -> TAS-3",
+		"  TAS-3 -> TAS-2 -> TAS-1",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in graph, got: %s", want, text)
+		}
+	}
+
+	// Single task view
+	req := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name:      "task-graph",
+			Arguments: map[string]any{"id": "TAS-1"},
+		},
+	}
+	result, err = srv.handleTaskGraph(ctx, req)
+	if err != nil {
+		t.Fatalf("handleTaskGraph returned error: %v", err)
+	}
+	if text := extractText(result); text != "TAS-1 is not blocked by any dependency" {
+		t.Errorf("unexpected single task result: %s", text)
+	}
+
+	// Closing the loop is rejected through task-update
+	updateReq := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name:      "task-update",
+			Arguments: map[string]any{"id": "TAS-1", "depends_on": "TAS-3"},
+		},
+	}
+	result, err = srv.handleTaskUpdate(ctx, updateReq)
+	if err != nil {
+		t.Fatalf("handleTaskUpdate returned error: %v", err)
+	}
+	if text := extractText(result); !strings.Contains(text, "dependency would create a cycle: TAS-1 -> TAS-3 -> TAS-2 -> TAS-1") {
+		t.Errorf("expected cycle error, got: %s", text)
+	}
+}
+
 func TestHandleTaskAdd_MissingTasksParam(t *testing.T) {
 	srv, cleanup := setupTestServer(t)
 	defer cleanup()
@@ -413,6 +623,92 @@ func TestHandleTaskUpdate_MultipleFields(t *testing.T) {
 	}
 }
 
+func TestHandleTaskUpdate_VerifyPasses(t *testing.T) {
+	srv, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	_, err := srv.store.TaskAdd(ctx, srv.sessName, session.TaskAddParams{
+		Content:    "Verified task",
+		Acceptance: "marker file exists",
+		Verify:     "touch verified-{{task_id}} && test -f verified-TAS-1",
+	})
+	if err != nil {
+		t.Fatalf("failed to add task: %v", err)
+	}
+
+	updateReq := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "task-update",
+			Arguments: map[string]any{
+				"id":     "TAS-1",
+				"status": "completed",
+			},
+		},
+	}
+
+	result, err := srv.handleTaskUpdate(ctx, updateReq)
+	if err != nil {
+		t.Fatalf("handleTaskUpdate returned error: %v", err)
+	}
+
+	text := extractText(result)
+	if !strings.Contains(text, "status=completed") {
+		t.Errorf("expected completion, got: %s", text)
+	}
+}
+
+func TestHandleTaskUpdate_VerifyFails(t *testing.T) {
+	srv, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	_, err := srv.store.TaskAdd(ctx, srv.sessName, session.TaskAddParams{
+		Content: "Failing task",
+		Verify:  "echo 'FAIL: TestSomething'; exit 1",
+	})
+	if err != nil {
+		t.Fatalf("failed to add task: %v", err)
+	}
+
+	updateReq := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "task-update",
+			Arguments: map[string]any{
+				"id":     "TAS-1",
+				"status": "completed",
+			},
+		},
+	}
+
+	result, err := srv.handleTaskUpdate(ctx, updateReq)
+	if err != nil {
+		t.Fatalf("handleTaskUpdate returned error: %v", err)
+	}
+
+	text := extractText(result)
+	if !strings.Contains(text, "error: verification failed for TAS-1") {
+		t.Errorf("expected verification failure, got: %s", text)
+	}
+	if !strings.Contains(text, "FAIL: TestSomething") {
+		t.Errorf("expected command output in result, got: %s", text)
+	}
+
+	// Transition rejected and failure recorded as a note
+	state, err := srv.store.LoadState(ctx, srv.sessName)
+	if err != nil {
+		t.Fatalf("failed to load state: %v", err)
+	}
+	if state.Tasks["TAS-1"].Status != "remaining" {
+		t.Errorf("expected status to stay remaining, got: %s", state.Tasks["TAS-1"].Status)
+	}
+	if len(state.Notes) != 1 || state.Notes[0].Type != "stuck" || !strings.Contains(state.Notes[0].Content, "FAIL: TestSomething") {
+		t.Errorf("expected stuck note with verify output, got: %+v", state.Notes)
+	}
+}
+
 func TestHandleTaskUpdate_MissingID(t *testing.T) {
 	srv, cleanup := setupTestServer(t)
 	defer cleanup()
@@ -507,6 +803,191 @@ func TestHandleTaskUpdate_InvalidTaskID(t *testing.T) {
 	}
 }
 
+func TestHandleTaskUpdate_Content(t *testing.T) {
+	srv, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	// Add a task
+	addReq := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "task-add",
+			Arguments: map[string]any{
+				"tasks": []any{
+					map[string]any{
+						"content": "Original content",
+					},
+				},
+			},
+		},
+	}
+	_, err := srv.handleTaskAdd(ctx, addReq)
+	if err != nil {
+		t.Fatalf("failed to add task: %v", err)
+	}
+
+	// Update content
+	updateReq := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "task-update",
+			Arguments: map[string]any{
+				"id":      "TAS-1",
+				"content": "Edited content",
+			},
+		},
+	}
+
+	result, err := srv.handleTaskUpdate(ctx, updateReq)
+	if err != nil {
+		t.Fatalf("handleTaskUpdate returned error: %v", err)
+	}
+
+	text := extractText(result)
+	if !strings.Contains(text, "Updated task TAS-1: content") {
+		t.Errorf("expected content update message, got: %s", text)
+	}
+
+	// Verify content changed
+	state, err := srv.store.LoadState(ctx, srv.sessName)
+	if err != nil {
+		t.Fatalf("failed to load state: %v", err)
+	}
+	if state.Tasks["TAS-1"].Content != "Edited content" {
+		t.Errorf("expected edited content, got: %s", state.Tasks["TAS-1"].Content)
+	}
+}
+
+func TestHandleTaskUpdate_RemoveDependency(t *testing.T) {
+	srv, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	// Add two tasks with a dependency
+	addReq := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "task-add",
+			Arguments: map[string]any{
+				"tasks": []any{
+					map[string]any{
+						"content": "Dependency task",
+					},
+					map[string]any{
+						"content": "Dependent task",
+					},
+				},
+			},
+		},
+	}
+	_, err := srv.handleTaskAdd(ctx, addReq)
+	if err != nil {
+		t.Fatalf("failed to add tasks: %v", err)
+	}
+	err = srv.store.TaskDepends(ctx, srv.sessName, session.TaskDependsParams{ID: "TAS-2", DependsOn: "TAS-1"})
+	if err != nil {
+		t.Fatalf("failed to add dependency: %v", err)
+	}
+
+	// Remove the dependency
+	updateReq := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "task-update",
+			Arguments: map[string]any{
+				"id":                "TAS-2",
+				"remove_depends_on": "TAS-1",
+			},
+		},
+	}
+
+	result, err := srv.handleTaskUpdate(ctx, updateReq)
+	if err != nil {
+		t.Fatalf("handleTaskUpdate returned error: %v", err)
+	}
+
+	text := extractText(result)
+	if !strings.Contains(text, "removed depends_on=TAS-1") {
+		t.Errorf("expected dependency removal in message, got: %s", text)
+	}
+
+	state, err := srv.store.LoadState(ctx, srv.sessName)
+	if err != nil {
+		t.Fatalf("failed to load state: %v", err)
+	}
+	if len(state.Tasks["TAS-2"].DependsOn) != 0 {
+		t.Errorf("expected no dependencies, got: %v", state.Tasks["TAS-2"].DependsOn)
+	}
+}
+
+func TestHandleTaskUpdate_Remove(t *testing.T) {
+	srv, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	// Add a task
+	addReq := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "task-add",
+			Arguments: map[string]any{
+				"tasks": []any{
+					map[string]any{
+						"content": "Task to remove",
+					},
+				},
+			},
+		},
+	}
+	_, err := srv.handleTaskAdd(ctx, addReq)
+	if err != nil {
+		t.Fatalf("failed to add task: %v", err)
+	}
+
+	// Remove combined with another update is rejected
+	updateReq := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "task-update",
+			Arguments: map[string]any{
+				"id":     "TAS-1",
+				"remove": true,
+				"status": "completed",
+			},
+		},
+	}
+
+	result, err := srv.handleTaskUpdate(ctx, updateReq)
+	if err != nil {
+		t.Fatalf("handleTaskUpdate returned error: %v", err)
+	}
+	if text := extractText(result); !strings.Contains(text, "error:") {
+		t.Errorf("expected error for remove combined with status, got: %s", text)
+	}
+
+	// Remove alone succeeds
+	updateReq.Params.Arguments = map[string]any{
+		"id":     "TAS-1",
+		"remove": true,
+	}
+
+	result, err = srv.handleTaskUpdate(ctx, updateReq)
+	if err != nil {
+		t.Fatalf("handleTaskUpdate returned error: %v", err)
+	}
+
+	text := extractText(result)
+	if !strings.Contains(text, "Removed task TAS-1") {
+		t.Errorf("expected removal message, got: %s", text)
+	}
+
+	state, err := srv.store.LoadState(ctx, srv.sessName)
+	if err != nil {
+		t.Fatalf("failed to load state: %v", err)
+	}
+	if _, exists := state.Tasks["TAS-1"]; exists {
+		t.Error("expected task to be removed")
+	}
+}
+
 func TestHandleTaskList_Empty(t *testing.T) {
 	srv, cleanup := setupTestServer(t)
 	defer cleanup()
@@ -1853,3 +2334,95 @@ func TestHandleNoteList_NoArguments(t *testing.T) {
 		t.Errorf("expected note in result, got: %s", text)
 	}
 }
+
+func TestHandleSearch_AcrossSessions(t *testing.T) {
+	srv, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	// A learning from an earlier session
+	_, err := srv.store.NoteAdd(ctx, "old-session", session.NoteAddParams{
+		Content: "Flaky NATS test: wait for the stream before publishing",
+		Type:    "learning",
+	})
+	if err != nil {
+		t.Fatalf("failed to add note: %v", err)
+	}
+	_, _ = srv.store.TaskAdd(ctx, srv.sessName, session.TaskAddParams{Content: "Fix flaky NATS test"})
+
+	req := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name:      "search",
+			Arguments: map[string]any{"query": "flaky nats"},
+		},
+	}
+	result, err := srv.handleSearch(ctx, req)
+	if err != nil {
+		t.Fatalf("handleSearch returned error: %v", err)
+	}
+	text := extractText(result)
+	if !strings.Contains(text, "old-session NOT-1 (#0) note [learning]: Flaky NATS test") {
+		t.Errorf("expected note from old session, got: %s", text)
+	}
+	if !strings.Contains(text, "test-session TAS-1 (#0) task [remaining]: Fix flaky NATS test") {
+		t.Errorf("expected task from current session, got: %s", text)
+	}
+
+	// Type filter drops tasks
+	req.Params.Arguments = map[string]any{"query": "flaky nats", "type": "learning"}
+	result, _ = srv.handleSearch(ctx, req)
+	if text := extractText(result); strings.Contains(text, "TAS-1") {
+		t.Errorf("expected only notes with type filter, got: %s", text)
+	}
+
+	// Missing query
+	req.Params.Arguments = map[string]any{}
+	result, _ = srv.handleSearch(ctx, req)
+	if text := extractText(result); !strings.Contains(text, "error") {
+		t.Errorf("expected error for missing query, got: %s", text)
+	}
+}
+
+func TestHandleKnowledgePromote(t *testing.T) {
+	srv, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	_, _ = srv.store.NoteAdd(ctx, srv.sessName, session.NoteAddParams{Content: "Use the embedded NATS in tests", Type: "tip"})
+	_, _ = srv.store.NoteAdd(ctx, srv.sessName, session.NoteAddParams{Content: "Can't reach the API", Type: "stuck"})
+
+	promote := func(id string) string {
+		result, err := srv.handleKnowledgePromote(ctx, mcp.CallToolRequest{
+			Params: mcp.CallToolParams{
+				Name:      "knowledge-promote",
+				Arguments: map[string]any{"id": id},
+			},
+		})
+		if err != nil {
+			t.Fatalf("handleKnowledgePromote returned error: %v", err)
+		}
+		return extractText(result)
+	}
+
+	if text := promote("NOT-1"); text != "Promoted NOT-1 to knowledge base as KNO-1" {
+		t.Errorf("unexpected result: %s", text)
+	}
+	if text := promote("NOT-1"); !strings.Contains(text, "already in the knowledge base as KNO-1") {
+		t.Errorf("expected duplicate promotion to fail, got: %s", text)
+	}
+	if text := promote("NOT-2"); !strings.Contains(text, "stuck notes cannot be promoted") {
+		t.Errorf("expected stuck note to be rejected, got: %s", text)
+	}
+	if text := promote("NOT-9"); !strings.Contains(text, "not found") {
+		t.Errorf("expected unknown note to fail, got: %s", text)
+	}
+
+	kb, err := srv.store.LoadKnowledge(ctx)
+	if err != nil {
+		t.Fatalf("LoadKnowledge failed: %v", err)
+	}
+	if len(kb.Entries) != 1 || kb.Entries[0].Source != "test-session/NOT-1" {
+		t.Errorf("unexpected knowledge base: %+v", kb.Entries)
+	}
+}
@@ -51,11 +51,11 @@ func TestServerIntegration(t *testing.T) {
 	}
 
 	// Create store
-	store := session.NewStore(js, stream)
+	store := session.NewStore(session.NewJetStreamLog(js, stream))
 
 	// Create server
 	sessionName := "integration-test-session"
-	srv := New(store, sessionName)
+	srv := New(store, sessionName, t.TempDir())
 
 	// Start server
 	port, err := srv.Start(ctx)
@@ -200,10 +200,10 @@ func TestServerStartStop(t *testing.T) {
 	}
 
 	// Create store
-	store := session.NewStore(js, stream)
+	store := session.NewStore(session.NewJetStreamLog(js, stream))
 
 	// Create server
-	srv := New(store, "test-session")
+	srv := New(store, "test-session", t.TempDir())
 
 	// Start server
 	port, err := srv.Start(ctx)
@@ -283,10 +283,10 @@ func TestServerDoubleStart(t *testing.T) {
 	}
 
 	// Create store
-	store := session.NewStore(js, stream)
+	store := session.NewStore(session.NewJetStreamLog(js, stream))
 
 	// Create server
-	srv := New(store, "test-session")
+	srv := New(store, "test-session", t.TempDir())
 
 	// Start server
 	_, err = srv.Start(ctx)
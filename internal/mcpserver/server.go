@@ -17,6 +17,7 @@ import (
 type Server struct {
 	store      *session.Store
 	sessName   string
+	workDir    string // Directory task verify commands run in
 	mcpServer  *server.MCPServer
 	httpServer *server.StreamableHTTPServer
 	port       int
@@ -24,11 +25,13 @@ type Server struct {
 }
 
 // New creates a new MCP server instance for the given session.
+// workDir is the directory task verify commands run in (empty means the current directory).
 // The server is not started until Start() is called.
-func New(store *session.Store, sessionName string) *Server {
+func New(store *session.Store, sessionName, workDir string) *Server {
 	return &Server{
 		store:    store,
 		sessName: sessionName,
+		workDir:  workDir,
 	}
 }
 
@@ -38,11 +38,11 @@ func TestNew(t *testing.T) {
 	}
 
 	// Create store
-	store := session.NewStore(js, stream)
+	store := session.NewStore(session.NewJetStreamLog(js, stream))
 
 	// Create server
 	sessionName := "test-session"
-	srv := New(store, sessionName)
+	srv := New(store, sessionName, t.TempDir())
 
 	if srv == nil {
 		t.Fatal("expected non-nil server")
@@ -94,10 +94,10 @@ func TestURL_BeforeStart(t *testing.T) {
 	}
 
 	// Create store
-	store := session.NewStore(js, stream)
+	store := session.NewStore(session.NewJetStreamLog(js, stream))
 
 	// Create server
-	srv := New(store, "test-session")
+	srv := New(store, "test-session", t.TempDir())
 
 	// URL before start should return port 0
 	url := srv.URL()
@@ -137,10 +137,10 @@ func TestStop_NotStarted(t *testing.T) {
 	}
 
 	// Create store
-	store := session.NewStore(js, stream)
+	store := session.NewStore(session.NewJetStreamLog(js, stream))
 
 	// Create server
-	srv := New(store, "test-session")
+	srv := New(store, "test-session", t.TempDir())
 
 	// Stop should be safe even if never started
 	if err := srv.Stop(); err != nil {
@@ -178,10 +178,10 @@ func TestStartPort_RandomAssignment(t *testing.T) {
 	}
 
 	// Create store
-	store := session.NewStore(js, stream)
+	store := session.NewStore(session.NewJetStreamLog(js, stream))
 
 	// Start multiple servers and ensure they get different ports
-	srv1 := New(store, "test-session-1")
+	srv1 := New(store, "test-session-1", t.TempDir())
 	port1, err := srv1.Start(ctx)
 	if err != nil {
 		t.Fatalf("failed to start server 1: %v", err)
@@ -192,7 +192,7 @@ func TestStartPort_RandomAssignment(t *testing.T) {
 		}
 	}()
 
-	srv2 := New(store, "test-session-2")
+	srv2 := New(store, "test-session-2", t.TempDir())
 	port2, err := srv2.Start(ctx)
 	if err != nil {
 		t.Fatalf("failed to start server 2: %v", err)
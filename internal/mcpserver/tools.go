@@ -27,6 +27,23 @@ func (s *Server) registerTools() error {
 							"type":        "integer",
 							"description": "Priority level (0=critical, 1=high, 2=medium, 3=low, 4=backlog)",
 						},
+						"parent": map[string]any{
+							"type":        "string",
+							"description": "ID of an existing task to add this task as a subtask of",
+						},
+						"acceptance": map[string]any{
+							"type":        "string",
+							"description": "Acceptance criteria that define when the task is done",
+						},
+						"verify": map[string]any{
+							"type":        "string",
+							"description": "Shell command that must exit 0 before the task can be marked completed (e.g. go test ./...)",
+						},
+						"labels": map[string]any{
+							"type":        "array",
+							"items":       map[string]any{"type": "string"},
+							"description": "Labels for grouping and filtering tasks (e.g. backend, bug)",
+						},
 					},
 					"required": []string{"content"},
 				})),
@@ -37,11 +54,16 @@ func (s *Server) registerTools() error {
 	// task-update: id required, other fields optional
 	s.mcpServer.AddTool(
 		mcp.NewTool("task-update",
-			mcp.WithDescription("Update task status, priority, or dependencies"),
+			mcp.WithDescription("Update task content, status, priority, dependencies, or labels, or remove the task"),
 			mcp.WithString("id", mcp.Required(), mcp.Description("Task ID or prefix")),
-			mcp.WithString("status", mcp.Description("New status (remaining, in_progress, completed, blocked, cancelled)")),
+			mcp.WithString("content", mcp.Description("New task description")),
+			mcp.WithString("status", mcp.Description("New status (remaining, in_progress, completed, blocked, cancelled). Completing runs the task's verify command, if any")),
 			mcp.WithNumber("priority", mcp.Description("New priority (0-4)")),
 			mcp.WithString("depends_on", mcp.Description("Task ID this task depends on")),
+			mcp.WithString("remove_depends_on", mcp.Description("Task ID to remove from this task's dependencies")),
+			mcp.WithString("add_labels", mcp.Description("Comma-separated labels to add to the task")),
+			mcp.WithString("remove_labels", mcp.Description("Comma-separated labels to remove from the task")),
+			mcp.WithBoolean("remove", mcp.Description("Delete the task (cannot be combined with other updates)")),
 		),
 		s.handleTaskUpdate,
 	)
@@ -49,7 +71,8 @@ func (s *Server) registerTools() error {
 	// task-list: list all tasks grouped by status
 	s.mcpServer.AddTool(
 		mcp.NewTool("task-list",
-			mcp.WithDescription("List all tasks grouped by status"),
+			mcp.WithDescription("List all tasks grouped by status, optionally filtered by label"),
+			mcp.WithString("label", mcp.Description("Comma-separated labels; only tasks carrying all of them are listed")),
 		),
 		s.handleTaskList,
 	)
@@ -57,11 +80,21 @@ func (s *Server) registerTools() error {
 	// task-next: get next highest priority unblocked task
 	s.mcpServer.AddTool(
 		mcp.NewTool("task-next",
-			mcp.WithDescription("Get the next highest priority unblocked task"),
+			mcp.WithDescription("Get the next highest priority unblocked task (subtasks before their parent), optionally filtered by label"),
+			mcp.WithString("label", mcp.Description("Comma-separated labels; only tasks carrying all of them are considered")),
 		),
 		s.handleTaskNext,
 	)
 
+	// task-graph: dependency ordering, critical path and blockers
+	s.mcpServer.AddTool(
+		mcp.NewTool("task-graph",
+			mcp.WithDescription("Show the dependency graph of open tasks: topological order, critical path, dependency cycles, and what blocks each task"),
+			mcp.WithString("id", mcp.Description("Task ID or prefix to show blocked-by chains for (default: all blocked tasks)")),
+		),
+		s.handleTaskGraph,
+	)
+
 	// note-add: array of note objects
 	s.mcpServer.AddTool(
 		mcp.NewTool("note-add",
@@ -94,6 +127,15 @@ func (s *Server) registerTools() error {
 		s.handleNoteList,
 	)
 
+	// knowledge-promote: copy a note into the project knowledge base
+	s.mcpServer.AddTool(
+		mcp.NewTool("knowledge-promote",
+			mcp.WithDescription("Promote a learning, tip or decision note into the project knowledge base, so that future sessions see it in their prompt"),
+			mcp.WithString("id", mcp.Required(), mcp.Description("Note ID (e.g. NOT-3)")),
+		),
+		s.handleKnowledgePromote,
+	)
+
 	// iteration-summary: record summary for current iteration
 	s.mcpServer.AddTool(
 		mcp.NewTool("iteration-summary",
@@ -111,5 +153,18 @@ func (s *Server) registerTools() error {
 		s.handleSessionComplete,
 	)
 
+	// search: full-text search across all sessions
+	s.mcpServer.AddTool(
+		mcp.NewTool("search",
+			mcp.WithDescription("Search task content, note content and iteration summaries across all sessions (including past ones), most recent first. Use it to check whether a problem was solved or a lesson learned before"),
+			mcp.WithString("query", mcp.Required(), mcp.Description("Words that must all appear (case-insensitive)")),
+			mcp.WithString("type", mcp.Description("Only notes of this type (learning, stuck, tip, decision)")),
+			mcp.WithString("status", mcp.Description("Only tasks with this status (remaining, in_progress, completed, blocked, cancelled)")),
+			mcp.WithString("session", mcp.Description("Only search this session")),
+			mcp.WithNumber("limit", mcp.Description("Maximum number of results (default 20)")),
+		),
+		s.handleSearch,
+	)
+
 	return nil
 }
@@ -0,0 +1,139 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+)
+
+// snapshotChunkSize is the size of the chunks a stream snapshot is sent and
+// restored in, well below the default max payload.
+const snapshotChunkSize = 64 * 1024
+
+// StreamSnapshot describes a stream snapshot: the stream's config and state
+// when it was taken. RestoreStream needs both to recreate the stream.
+type StreamSnapshot struct {
+	Config server.StreamConfig `json:"config"`
+	State  server.StreamState  `json:"state"`
+}
+
+// SnapshotStream writes a snapshot of a stream to w while the stream stays in
+// use. The snapshot is taken by the server at one point in time, so it is
+// consistent however many writers are appending. Consumers are not included,
+// and the server checks the messages' checksums while reading them.
+func SnapshotStream(ctx context.Context, nc *nats.Conn, stream string, w io.Writer) (*StreamSnapshot, error) {
+	inbox := nc.NewInbox()
+	sub, err := nc.SubscribeSync(inbox)
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to snapshot: %w", err)
+	}
+	defer func() { _ = sub.Unsubscribe() }()
+
+	req, err := json.Marshal(server.JSApiStreamSnapshotRequest{
+		DeliverSubject: inbox,
+		NoConsumers:    true,
+		ChunkSize:      snapshotChunkSize,
+		CheckMsgs:      true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	msg, err := nc.RequestWithContext(ctx, fmt.Sprintf(server.JSApiStreamSnapshotT, stream), req)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot request failed: %w", err)
+	}
+	var resp server.JSApiStreamSnapshotResponse
+	if err := json.Unmarshal(msg.Data, &resp); err != nil {
+		return nil, fmt.Errorf("invalid snapshot response: %w", err)
+	}
+	if resp.Error != nil {
+		return nil, fmt.Errorf("snapshot of stream %s failed: %s", stream, resp.Error.Description)
+	}
+	if resp.Config == nil || resp.State == nil {
+		return nil, fmt.Errorf("snapshot response of stream %s is incomplete", stream)
+	}
+
+	// Chunks that expect an ack carry a reply subject; an empty message ends
+	// the snapshot
+	for {
+		chunk, err := sub.NextMsgWithContext(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to receive snapshot: %w", err)
+		}
+		if len(chunk.Data) == 0 {
+			break
+		}
+		if _, err := w.Write(chunk.Data); err != nil {
+			return nil, fmt.Errorf("failed to write snapshot: %w", err)
+		}
+		if chunk.Reply != "" {
+			if err := nc.Publish(chunk.Reply, nil); err != nil {
+				return nil, fmt.Errorf("failed to ack snapshot chunk: %w", err)
+			}
+		}
+	}
+	return &StreamSnapshot{Config: *resp.Config, State: *resp.State}, nil
+}
+
+// RestoreStream recreates a stream from a snapshot written by SnapshotStream.
+// The stream must not exist. The server only creates the stream once all of r
+// has been received and its contents verified.
+func RestoreStream(ctx context.Context, nc *nats.Conn, snapshot *StreamSnapshot, r io.Reader) error {
+	stream := snapshot.Config.Name
+	req, err := json.Marshal(server.JSApiStreamRestoreRequest{Config: snapshot.Config, State: snapshot.State})
+	if err != nil {
+		return err
+	}
+	msg, err := nc.RequestWithContext(ctx, fmt.Sprintf(server.JSApiStreamRestoreT, stream), req)
+	if err != nil {
+		return fmt.Errorf("restore request failed: %w", err)
+	}
+	var resp server.JSApiStreamRestoreResponse
+	if err := json.Unmarshal(msg.Data, &resp); err != nil {
+		return fmt.Errorf("invalid restore response: %w", err)
+	}
+	if resp.Error != nil {
+		return fmt.Errorf("restore of stream %s failed: %s", stream, resp.Error.Description)
+	}
+
+	// Each chunk is acked with an empty reply; failures are replied as -ERR
+	buf := make([]byte, snapshotChunkSize)
+	for {
+		n, readErr := io.ReadFull(r, buf)
+		if n > 0 {
+			ack, err := nc.RequestWithContext(ctx, resp.DeliverSubject, buf[:n])
+			if err != nil {
+				return fmt.Errorf("failed to send snapshot chunk: %w", err)
+			}
+			if strings.HasPrefix(string(ack.Data), "-ERR") {
+				return fmt.Errorf("restore of stream %s failed: %s", stream, strings.TrimPrefix(string(ack.Data), "-ERR "))
+			}
+		}
+		if errors.Is(readErr, io.EOF) || errors.Is(readErr, io.ErrUnexpectedEOF) {
+			break
+		}
+		if readErr != nil {
+			return fmt.Errorf("failed to read snapshot: %w", readErr)
+		}
+	}
+
+	// An empty chunk ends the upload; the reply comes once the stream is restored
+	msg, err = nc.RequestWithContext(ctx, resp.DeliverSubject, nil)
+	if err != nil {
+		return fmt.Errorf("failed to finish restore: %w", err)
+	}
+	var created server.JSApiStreamCreateResponse
+	if err := json.Unmarshal(msg.Data, &created); err != nil {
+		return fmt.Errorf("invalid restore result: %w", err)
+	}
+	if created.Error != nil {
+		return fmt.Errorf("restore of stream %s failed: %s", stream, created.Error.Description)
+	}
+	return nil
+}
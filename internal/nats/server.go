@@ -132,6 +132,78 @@ func ConnectToPort(port int) (*nats.Conn, error) {
 	return conn, nil
 }
 
+// ConnectOptions configures a connection to an external NATS server. Only URL
+// is required; the credential options are alternatives, use at most one of
+// Creds, NKeySeed and Token.
+type ConnectOptions struct {
+	URL      string // Server URL(s), comma-separated (e.g. "tls://nats.example.com:4222")
+	Creds    string // Path to a user credentials (JWT + seed) file
+	NKeySeed string // Path to an nkey seed file
+	Token    string // Authentication token
+	TLSCA    string // Path to the CA certificate(s) to verify the server with
+	TLSCert  string // Path to a client certificate, for mutual TLS
+	TLSKey   string // Path to the client certificate's key
+}
+
+// Connect connects to the external NATS server described by opts.
+func Connect(opts ConnectOptions) (*nats.Conn, error) {
+	natsOpts, err := opts.natsOptions()
+	if err != nil {
+		return nil, err
+	}
+	logger.Debug("Connecting to NATS at %s", opts.URL)
+	conn, err := nats.Connect(opts.URL, natsOpts...)
+	if err != nil {
+		logger.Error("Failed to connect to NATS: %v", err)
+		return nil, fmt.Errorf("failed to connect to %s: %w", opts.URL, err)
+	}
+	logger.Debug("Connected to NATS successfully")
+	return conn, nil
+}
+
+// natsOptions converts opts to nats.go connection options.
+func (opts ConnectOptions) natsOptions() ([]nats.Option, error) {
+	if opts.URL == "" {
+		return nil, errors.New("NATS URL is required")
+	}
+	auth := 0
+	for _, set := range []bool{opts.Creds != "", opts.NKeySeed != "", opts.Token != ""} {
+		if set {
+			auth++
+		}
+	}
+	if auth > 1 {
+		return nil, errors.New("use only one of NATS creds, nkey and token")
+	}
+	if (opts.TLSCert == "") != (opts.TLSKey == "") {
+		return nil, errors.New("NATS TLS client certificate and key must be set together")
+	}
+
+	natsOpts := []nats.Option{
+		nats.Name("iteratr"),
+		nats.Timeout(5 * time.Second),
+	}
+	switch {
+	case opts.Creds != "":
+		natsOpts = append(natsOpts, nats.UserCredentials(opts.Creds))
+	case opts.NKeySeed != "":
+		opt, err := nats.NkeyOptionFromSeed(opts.NKeySeed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load nkey seed: %w", err)
+		}
+		natsOpts = append(natsOpts, opt)
+	case opts.Token != "":
+		natsOpts = append(natsOpts, nats.Token(opts.Token))
+	}
+	if opts.TLSCA != "" {
+		natsOpts = append(natsOpts, nats.RootCAs(opts.TLSCA))
+	}
+	if opts.TLSCert != "" {
+		natsOpts = append(natsOpts, nats.ClientCert(opts.TLSCert, opts.TLSKey))
+	}
+	return natsOpts, nil
+}
+
 // ConnectInProcess creates an in-process connection to the embedded NATS server.
 // This connection does not use network ports and communicates directly with the server.
 func ConnectInProcess(ns *server.Server) (*nats.Conn, error) {
@@ -0,0 +1,190 @@
+package nats
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nkeys"
+)
+
+// startServer launches a local nats-server with JetStream, configured by
+// configure, and returns its client URL.
+func startServer(t *testing.T, configure func(opts *server.Options)) string {
+	t.Helper()
+	port, err := findAvailablePort()
+	if err != nil {
+		t.Fatalf("findAvailablePort failed: %v", err)
+	}
+	opts := &server.Options{
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+		Host:      "127.0.0.1",
+		Port:      port,
+	}
+	configure(opts)
+	ns, err := server.NewServer(opts)
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+	go ns.Start()
+	if !ns.ReadyForConnections(4 * time.Second) {
+		t.Fatal("nats server not ready")
+	}
+	t.Cleanup(ns.Shutdown)
+	return fmt.Sprintf("nats://127.0.0.1:%d", port)
+}
+
+func TestConnect(t *testing.T) {
+	t.Run("token", func(t *testing.T) {
+		url := startServer(t, func(opts *server.Options) { opts.Authorization = "s3cret" })
+
+		if _, err := Connect(ConnectOptions{URL: url}); err == nil {
+			t.Error("expected connection without token to fail")
+		}
+		if _, err := Connect(ConnectOptions{URL: url, Token: "wrong"}); err == nil {
+			t.Error("expected connection with wrong token to fail")
+		}
+		nc, err := Connect(ConnectOptions{URL: url, Token: "s3cret"})
+		if err != nil {
+			t.Fatalf("Connect failed: %v", err)
+		}
+		nc.Close()
+	})
+
+	t.Run("nkey", func(t *testing.T) {
+		user, err := nkeys.CreateUser()
+		if err != nil {
+			t.Fatalf("CreateUser failed: %v", err)
+		}
+		pub, _ := user.PublicKey()
+		seed, _ := user.Seed()
+		seedFile := filepath.Join(t.TempDir(), "user.nk")
+		if err := os.WriteFile(seedFile, seed, 0600); err != nil {
+			t.Fatalf("failed to write seed: %v", err)
+		}
+		url := startServer(t, func(opts *server.Options) { opts.Nkeys = []*server.NkeyUser{{Nkey: pub}} })
+
+		nc, err := Connect(ConnectOptions{URL: url, NKeySeed: seedFile})
+		if err != nil {
+			t.Fatalf("Connect failed: %v", err)
+		}
+		nc.Close()
+		if _, err := Connect(ConnectOptions{URL: url, NKeySeed: filepath.Join(t.TempDir(), "missing.nk")}); err == nil {
+			t.Error("expected missing seed file to fail")
+		}
+	})
+
+	t.Run("tls", func(t *testing.T) {
+		dir := t.TempDir()
+		cert := writeTestCert(t, dir)
+		url := startServer(t, func(opts *server.Options) {
+			opts.TLS = true
+			opts.TLSTimeout = 2
+			opts.TLSConfig = &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+		})
+		url = strings.Replace(url, "nats://", "tls://", 1)
+
+		if _, err := Connect(ConnectOptions{URL: url}); err == nil {
+			t.Error("expected untrusted server certificate to fail")
+		}
+		nc, err := Connect(ConnectOptions{URL: url, TLSCA: filepath.Join(dir, "ca.pem")})
+		if err != nil {
+			t.Fatalf("Connect failed: %v", err)
+		}
+		nc.Close()
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		for name, opts := range map[string]ConnectOptions{
+			"no url":       {Token: "t"},
+			"two auths":    {URL: "nats://localhost:4222", Token: "t", Creds: "user.creds"},
+			"cert no key":  {URL: "nats://localhost:4222", TLSCert: "cert.pem"},
+			"key no cert":  {URL: "nats://localhost:4222", TLSKey: "key.pem"},
+			"missing seed": {URL: "nats://localhost:4222", NKeySeed: "/nonexistent/user.nk"},
+		} {
+			if _, err := opts.natsOptions(); err == nil {
+				t.Errorf("%s: expected error", name)
+			}
+		}
+	})
+}
+
+func TestSetupLayoutStream(t *testing.T) {
+	ctx := context.Background()
+	url := startServer(t, func(opts *server.Options) {})
+	nc, err := Connect(ConnectOptions{URL: url})
+	if err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer nc.Close()
+	js, err := CreateJetStream(nc)
+	if err != nil {
+		t.Fatalf("CreateJetStream failed: %v", err)
+	}
+
+	layout := Layout{Stream: "team_events", SubjectPrefix: "team.iteratr"}
+	stream, err := SetupLayoutStream(ctx, js, layout, Retention{})
+	if err != nil {
+		t.Fatalf("SetupLayoutStream failed: %v", err)
+	}
+	info, err := stream.Info(ctx)
+	if err != nil {
+		t.Fatalf("Info failed: %v", err)
+	}
+	if info.Config.Name != "team_events" || len(info.Config.Subjects) != 1 || info.Config.Subjects[0] != "team.iteratr.>" {
+		t.Errorf("unexpected stream config: %s %v", info.Config.Name, info.Config.Subjects)
+	}
+	if bucket := layout.WithDefaults().SnapshotBucket; bucket != "team_events_snapshots" {
+		t.Errorf("expected bucket derived from stream, got %s", bucket)
+	}
+
+	// Setting up again reuses the stream
+	if _, err := SetupLayoutStream(ctx, js, layout, Retention{}); err != nil {
+		t.Errorf("second SetupLayoutStream failed: %v", err)
+	}
+}
+
+// writeTestCert writes a self-signed certificate for 127.0.0.1 to dir/ca.pem
+// and returns it for the server.
+func writeTestCert(t *testing.T, dir string) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "iteratr test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate failed: %v", err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	if err := os.WriteFile(filepath.Join(dir, "ca.pem"), certPEM, 0600); err != nil {
+		t.Fatalf("failed to write certificate: %v", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
@@ -3,6 +3,7 @@ package nats
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/mark3labs/iteratr/internal/logger"
@@ -14,6 +15,15 @@ const (
 	// StreamName is the name of the JetStream stream for iteratr events
 	StreamName = "iteratr_events"
 
+	// SnapshotBucket is the name of the KV bucket holding per-session state snapshots
+	SnapshotBucket = "iteratr_snapshots"
+
+	// LeaseBucket is the name of the KV bucket holding session ownership leases
+	LeaseBucket = "iteratr_leases"
+
+	// SubjectPrefix is the first token of all iteratr subjects
+	SubjectPrefix = "iteratr"
+
 	// Event types
 	EventTypeTask      = "task"
 	EventTypeNote      = "note"
@@ -21,37 +31,219 @@ const (
 	EventTypeControl   = "control"
 )
 
+// EventTypes lists all known event types in the order they are documented.
+var EventTypes = []string{EventTypeTask, EventTypeNote, EventTypeIteration, EventTypeControl}
+
+// IsEventType reports whether t is one of the known event types.
+func IsEventType(t string) bool {
+	for _, known := range EventTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// SubjectKnowledge is the subject of the project knowledge base. It has no
+// session token, so it never matches a session's subjects.
+const SubjectKnowledge = "iteratr.knowledge"
+
 // SubjectForSession returns the wildcard subject pattern for all events in a session.
 // Example: "iteratr.mysession.>"
 func SubjectForSession(session string) string {
 	return fmt.Sprintf("iteratr.%s.>", session)
 }
 
+// SubjectForSessionEvents returns the subject pattern for a session's state
+// events (task, note, iteration, control) without nested subjects such as
+// transcripts. Example: "iteratr.mysession.*"
+func SubjectForSessionEvents(session string) string {
+	return fmt.Sprintf("iteratr.%s.*", session)
+}
+
+// SubjectForTranscript returns the subject holding the agent transcript of one
+// iteration of a session. Example: "iteratr.mysession.transcript.3"
+func SubjectForTranscript(session string, iteration int) string {
+	return fmt.Sprintf("iteratr.%s.transcript.%d", session, iteration)
+}
+
+// SubjectForTranscripts returns the wildcard subject pattern for all transcripts
+// of a session. Example: "iteratr.mysession.transcript.>"
+func SubjectForTranscripts(session string) string {
+	return fmt.Sprintf("iteratr.%s.transcript.>", session)
+}
+
 // SubjectForEvent returns the specific subject for an event type in a session.
 // Example: "iteratr.mysession.task"
 func SubjectForEvent(session, eventType string) string {
 	return fmt.Sprintf("iteratr.%s.%s", session, eventType)
 }
 
-// SetupStream creates or updates the JetStream stream for iteratr events.
-// The stream captures all events for all sessions with 30-day retention.
+// Retention limits how much history the stream keeps. Zero values mean no
+// limit. The limits apply to individual events regardless of session, so
+// reaching them drops the oldest events of whatever session they belong to.
+type Retention struct {
+	MaxAge   time.Duration // Discard events older than this
+	MaxBytes int64         // Discard the oldest events beyond this stream size
+}
+
+// Layout names the JetStream resources holding an iteratr data set. Projects
+// sharing one NATS server each need their own stream, subject prefix and
+// buckets; empty fields use the defaults.
+type Layout struct {
+	Stream         string // Event stream name (default iteratr_events)
+	SubjectPrefix  string // Subject prefix replacing "iteratr" in the stream (default iteratr)
+	SnapshotBucket string // Snapshot KV bucket name (default iteratr_snapshots, or {stream}_snapshots for another stream)
+	LeaseBucket    string // Lease KV bucket name (default iteratr_leases, or {stream}_leases for another stream)
+}
+
+// WithDefaults returns the layout with empty fields set to the defaults.
+func (l Layout) WithDefaults() Layout {
+	if l.Stream == "" {
+		l.Stream = StreamName
+	}
+	if l.SubjectPrefix == "" {
+		l.SubjectPrefix = SubjectPrefix
+	}
+	if l.SnapshotBucket == "" {
+		l.SnapshotBucket = SnapshotBucket
+		if l.Stream != StreamName {
+			// Keep snapshots apart along with the events
+			l.SnapshotBucket = l.Stream + "_snapshots"
+		}
+	}
+	if l.LeaseBucket == "" {
+		l.LeaseBucket = LeaseBucket
+		if l.Stream != StreamName {
+			l.LeaseBucket = l.Stream + "_leases"
+		}
+	}
+	return l
+}
+
+// Validate checks that the layout's names are usable: stream and bucket names
+// without dots, wildcards or whitespace, and a prefix of one or more such
+// subject tokens separated by dots.
+func (l Layout) Validate() error {
+	l = l.WithDefaults()
+	if !validName(l.Stream) {
+		return fmt.Errorf("invalid stream name: %q", l.Stream)
+	}
+	if !validName(l.SnapshotBucket) {
+		return fmt.Errorf("invalid snapshot bucket name: %q", l.SnapshotBucket)
+	}
+	if !validName(l.LeaseBucket) {
+		return fmt.Errorf("invalid lease bucket name: %q", l.LeaseBucket)
+	}
+	for _, token := range strings.Split(l.SubjectPrefix, ".") {
+		if !validName(token) {
+			return fmt.Errorf("invalid subject prefix: %q", l.SubjectPrefix)
+		}
+	}
+	return nil
+}
+
+// validName reports whether name is a valid stream, bucket or subject token name.
+func validName(name string) bool {
+	return name != "" && !strings.ContainsAny(name, ".*> \t\r\n/\\")
+}
+
+// SetupStream creates or updates the JetStream stream for iteratr events,
+// keeping all events forever.
 // Subject pattern: iteratr.> matches all sessions and event types.
 func SetupStream(ctx context.Context, js jetstream.JetStream) (jetstream.Stream, error) {
-	logger.Debug("Setting up JetStream stream: %s", StreamName)
+	return SetupStreamWithRetention(ctx, js, Retention{})
+}
+
+// SetupStreamWithRetention creates or updates the JetStream stream for iteratr
+// events with the given retention limits. Updating an existing stream applies
+// the new limits to it.
+func SetupStreamWithRetention(ctx context.Context, js jetstream.JetStream, retention Retention) (jetstream.Stream, error) {
+	return SetupLayoutStream(ctx, js, Layout{}, retention)
+}
+
+// SetupLayoutStream creates or updates the event stream of a layout, capturing
+// all subjects under its prefix. If the stream can't be created or updated,
+// e.g. because the account may not manage streams on a shared server, an
+// existing stream of that name is used as it is.
+func SetupLayoutStream(ctx context.Context, js jetstream.JetStream, layout Layout, retention Retention) (jetstream.Stream, error) {
+	layout = layout.WithDefaults()
+	logger.Debug("Setting up JetStream stream: %s on %s.> (max age %s, max bytes %d)",
+		layout.Stream, layout.SubjectPrefix, retention.MaxAge, retention.MaxBytes)
+	maxBytes := retention.MaxBytes
+	if maxBytes == 0 {
+		maxBytes = -1 // Unlimited
+	}
 	stream, err := js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
-		Name:     StreamName,
-		Subjects: []string{"iteratr.>"}, // Match all iteratr events
+		Name:     layout.Stream,
+		Subjects: []string{layout.SubjectPrefix + ".>"}, // Match all iteratr events
 		Storage:  jetstream.FileStorage,
-		MaxAge:   30 * 24 * time.Hour, // 30 day retention
+		MaxAge:   retention.MaxAge,
+		MaxBytes: maxBytes,
 	})
 	if err != nil {
-		logger.Error("Failed to create/update stream: %v", err)
-		return nil, err
+		existing, lookupErr := js.Stream(ctx, layout.Stream)
+		if lookupErr != nil {
+			logger.Error("Failed to create/update stream: %v", err)
+			return nil, err
+		}
+		logger.Warn("Using existing stream %s as is, failed to update it: %v", layout.Stream, err)
+		return existing, nil
 	}
-	logger.Debug("JetStream stream ready: %s", StreamName)
+	logger.Debug("JetStream stream ready: %s", layout.Stream)
 	return stream, nil
 }
 
+// SetupSnapshotBucket creates or updates the KV bucket used for session state snapshots.
+// Keys are session names; values are serialized snapshots. Only the latest
+// snapshot per session is kept (history of 1).
+func SetupSnapshotBucket(ctx context.Context, js jetstream.JetStream) (jetstream.KeyValue, error) {
+	return SetupNamedSnapshotBucket(ctx, js, SnapshotBucket)
+}
+
+// SetupNamedSnapshotBucket is SetupSnapshotBucket for a bucket of another
+// name. Like SetupLayoutStream, it falls back to an existing bucket that
+// can't be updated.
+func SetupNamedSnapshotBucket(ctx context.Context, js jetstream.JetStream, bucket string) (jetstream.KeyValue, error) {
+	return setupBucket(ctx, js, jetstream.KeyValueConfig{
+		Bucket:      bucket,
+		Description: "iteratr session state snapshots",
+		History:     1,
+		Storage:     jetstream.FileStorage,
+	})
+}
+
+// SetupLeaseBucket creates or updates the KV bucket holding session ownership
+// leases. Keys are session names; values are the serialized lease holders,
+// written with compare-and-set on the key's revision.
+func SetupLeaseBucket(ctx context.Context, js jetstream.JetStream, bucket string) (jetstream.KeyValue, error) {
+	return setupBucket(ctx, js, jetstream.KeyValueConfig{
+		Bucket:      bucket,
+		Description: "iteratr session ownership leases",
+		History:     1,
+		Storage:     jetstream.FileStorage,
+	})
+}
+
+// setupBucket creates or updates a KV bucket, falling back to an existing
+// bucket that can't be updated.
+func setupBucket(ctx context.Context, js jetstream.JetStream, cfg jetstream.KeyValueConfig) (jetstream.KeyValue, error) {
+	bucket := cfg.Bucket
+	logger.Debug("Setting up KV bucket: %s", bucket)
+	kv, err := js.CreateOrUpdateKeyValue(ctx, cfg)
+	if err != nil {
+		existing, lookupErr := js.KeyValue(ctx, bucket)
+		if lookupErr != nil {
+			logger.Error("Failed to create/update KV bucket: %v", err)
+			return nil, err
+		}
+		logger.Warn("Using existing KV bucket %s as is, failed to update it: %v", bucket, err)
+		return existing, nil
+	}
+	logger.Debug("KV bucket ready: %s", bucket)
+	return kv, nil
+}
+
 // CreateConsumer creates a durable consumer for reading event history.
 // The consumer starts from the beginning and requires explicit acknowledgment.
 func CreateConsumer(ctx context.Context, stream jetstream.Stream, name string) (jetstream.Consumer, error) {
@@ -73,10 +265,16 @@ func PurgeSession(ctx context.Context, stream jetstream.Stream, session string)
 // ListSessions returns a list of unique session names by querying stream subjects.
 // It extracts session names from subjects matching the pattern: iteratr.{session}.{eventtype}
 func ListSessions(ctx context.Context, stream jetstream.Stream) ([]string, error) {
+	return ListSessionsWithPrefix(ctx, stream, SubjectPrefix)
+}
+
+// ListSessionsWithPrefix is ListSessions for a stream whose subjects start
+// with prefix instead of "iteratr".
+func ListSessionsWithPrefix(ctx context.Context, stream jetstream.Stream, prefix string) ([]string, error) {
 	logger.Debug("Listing all sessions from stream subjects")
 
 	// Get stream info with all subjects
-	info, err := stream.Info(ctx, jetstream.WithSubjectFilter("iteratr.>"))
+	info, err := stream.Info(ctx, jetstream.WithSubjectFilter(prefix+".>"))
 	if err != nil {
 		logger.Error("Failed to get stream info: %v", err)
 		return nil, err
@@ -87,17 +285,12 @@ func ListSessions(ctx context.Context, stream jetstream.Stream) ([]string, error
 	for subject := range info.State.Subjects {
 		// Parse subject: iteratr.{session}.{eventtype}
 		// Example: iteratr.my-session.task
-		var session string
-		if _, err := fmt.Sscanf(subject, "iteratr.%s", &session); err == nil {
-			// Remove the event type suffix (everything after first dot in session part)
-			for i, ch := range session {
-				if ch == '.' {
-					session = session[:i]
-					break
-				}
-			}
-			if session != "" {
-				sessionMap[session] = true
+		if session, ok := strings.CutPrefix(subject, prefix+"."); ok {
+			// Remove the event type suffix (everything after first dot in session part).
+			// Subjects without one (e.g. iteratr.knowledge) are not session subjects.
+			i := strings.IndexByte(session, '.')
+			if i > 0 {
+				sessionMap[session[:i]] = true
 			}
 		}
 	}
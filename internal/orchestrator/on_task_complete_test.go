@@ -62,7 +62,7 @@ hooks:
 		t.Fatal(err)
 	}
 
-	store := session.NewStore(js, stream)
+	store := session.NewStore(session.NewJetStreamLog(js, stream))
 
 	// Verify hooks file exists
 	if _, err := os.Stat(hooksPath); err != nil {
@@ -234,7 +234,7 @@ hooks:
 		t.Fatal(err)
 	}
 
-	store := session.NewStore(js, stream)
+	store := session.NewStore(session.NewJetStreamLog(js, stream))
 	hooksConf, err := hooks.LoadConfig(tmpDir)
 	if err != nil {
 		t.Fatal(err)
@@ -392,7 +392,7 @@ hooks:
 		t.Fatal(err)
 	}
 
-	store := session.NewStore(js, stream)
+	store := session.NewStore(session.NewJetStreamLog(js, stream))
 	hooksConf, err := hooks.LoadConfig(tmpDir)
 	if err != nil {
 		t.Fatal(err)
@@ -2,11 +2,11 @@ package orchestrator
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -30,43 +30,64 @@ import (
 
 // Config holds configuration for the orchestrator.
 type Config struct {
-	SessionName       string // Name of the session
-	SpecPath          string // Path to spec file
-	TemplatePath      string // Path to custom template (optional)
-	ExtraInstructions string // Extra instructions (optional)
-	Iterations        int    // Max iterations (0 = infinite)
-	DataDir           string // Data directory for persistent storage
-	WorkDir           string // Working directory for agent
-	Headless          bool   // Run without TUI
-	Model             string // Model to use (e.g., anthropic/claude-sonnet-4-5)
-	Reset             bool   // Reset session data before starting
-	AutoCommit        bool   // Auto-commit modified files after iteration
+	SessionName       string              // Name of the session
+	SpecPath          string              // Path to spec file
+	TemplatePath      string              // Path to custom template (optional)
+	ExtraInstructions string              // Extra instructions (optional)
+	Iterations        int                 // Max iterations (0 = infinite)
+	DataDir           string              // Data directory for persistent storage
+	WorkDir           string              // Working directory for agent
+	Headless          bool                // Run without TUI
+	Model             string              // Model to use (e.g., anthropic/claude-sonnet-4-5)
+	Reset             bool                // Reset session data before starting
+	AutoCommit        bool                // Auto-commit modified files after iteration
+	Scheduler         string              // Task scheduling policy for task-next (empty = priority)
+	Pricing           *session.Pricing    // Model pricing for cost accounting (nil = cost reported by the agent)
+	ResetTasks        bool                // Reset tasks left in_progress by an interrupted iteration to remaining
+	Storage           string              // Event storage backend: nats (default) or file
+	Retention         nats.Retention      // JetStream stream limits (zero = keep all events)
+	NATS              nats.ConnectOptions // External NATS server (empty URL = embedded server)
+	Layout            nats.Layout         // JetStream stream, subject prefix and snapshot bucket names
+	Observe           bool                // Watch the session read-only in the TUI instead of running it
+	BackupEvery       int                 // Back up the event log after every N iterations (0 = never)
+	BackupDir         string              // Directory scheduled backups are written to
+	BackupKeep        int                 // Scheduled backups kept in BackupDir (0 = all)
 }
 
+// errLeaseLost is returned by Run when another process took over the session.
+var errLeaseLost = errors.New("lost ownership of the session to another process")
+
 // Orchestrator manages the iteration loop with embedded NATS, agent runner, and TUI.
 type Orchestrator struct {
 	cfg               Config
-	ns                *natsserver.Server // Embedded NATS server (nil if node mode)
-	natsPort          int                // NATS server port
-	nc                *natsgo.Conn       // NATS connection
-	store             *session.Store     // Session store
-	mcpServer         *mcpserver.Server  // MCP tools server
-	runner            *agent.Runner      // Agent runner for opencode subprocess
-	tuiApp            *tui.App           // TUI application (nil if headless)
-	tuiProgram        *tea.Program       // Bubbletea program
-	tuiDone           chan struct{}      // TUI completion signal
-	sendChan          chan string        // Channel for user input messages from TUI to orchestrator
-	ctx               context.Context    // Context for cancellation
-	cancel            context.CancelFunc // Cancel function
-	stopped           bool               // Track if Stop() was already called
-	isPrimary         bool               // True if this instance owns the NATS server
-	hooksConfig       *hooks.Config      // Hooks configuration (nil if no hooks file)
-	fileTracker       *agent.FileTracker // Tracks files modified during iteration
-	autoCommit        bool               // Auto-commit modified files after iteration
-	pendingHookOutput string             // Buffer for hook output to be sent in next iteration
-	pendingMu         sync.Mutex         // Protects pendingHookOutput (needed for NATS callback)
-	paused            atomic.Bool        // Pause state (atomic for thread-safe access)
-	resumeChan        chan struct{}      // Signals resume from pause
+	ns                *natsserver.Server          // Embedded NATS server (nil if node mode)
+	natsPort          int                         // NATS server port
+	nc                *natsgo.Conn                // NATS connection
+	store             *session.Store              // Session store
+	mcpServer         *mcpserver.Server           // MCP tools server
+	runner            *agent.Runner               // Agent runner for opencode subprocess
+	transcript        *session.TranscriptRecorder // Persists the agent conversation per iteration
+	iteration         int                         // Iteration that agent turns are attributed to
+	lastFinish        agent.FinishEvent           // Last agent turn of the current iteration
+	sessionUsage      session.Usage               // Tokens and cost of all iterations so far
+	tuiApp            *tui.App                    // TUI application (nil if headless)
+	tuiProgram        *tea.Program                // Bubbletea program
+	tuiDone           chan struct{}               // TUI completion signal
+	sendChan          chan string                 // Channel for user input messages from TUI to orchestrator
+	ctx               context.Context             // Context for cancellation
+	cancel            context.CancelFunc          // Cancel function
+	stopped           bool                        // Track if Stop() was already called
+	isPrimary         bool                        // True if this instance owns the NATS server
+	hooksConfig       *hooks.Config               // Hooks configuration (nil if no hooks file)
+	fileTracker       *agent.FileTracker          // Tracks files modified during iteration
+	autoCommit        bool                        // Auto-commit modified files after iteration
+	pendingHookOutput string                      // Buffer for hook output to be sent in next iteration
+	pendingMu         sync.Mutex                  // Protects pendingHookOutput (needed for NATS callback)
+	paused            atomic.Bool                 // Pause state (atomic for thread-safe access)
+	resumeChan        chan struct{}               // Signals resume from pause
+	lease             *session.Lease              // Ownership lease of the session (nil when observing)
+	leaseLost         atomic.Bool                 // Set when another process took the lease over
+	owner             string                      // Process running the observed session (observer mode)
 }
 
 // New creates a new Orchestrator with the given configuration.
@@ -100,31 +121,61 @@ func New(cfg Config) (*Orchestrator, error) {
 
 // Start initializes all components and starts the orchestrator.
 func (o *Orchestrator) Start() error {
+	if err := o.start(); err != nil {
+		// Let the next build have the session right away
+		o.releaseLease()
+		return err
+	}
+	return nil
+}
+
+// start is Start without the cleanup on failure.
+func (o *Orchestrator) start() error {
 	logger.Info("Starting orchestrator for session '%s'", o.cfg.SessionName)
 
-	// 1. Connect to existing NATS server or start a new one
-	logger.Debug("Ensuring NATS connection")
-	if err := o.ensureNATS(); err != nil {
-		logger.Error("Failed to ensure NATS: %v", err)
-		return fmt.Errorf("failed to ensure NATS: %w", err)
-	}
-	if o.isPrimary {
-		logger.Debug("Running as primary (owns NATS server)")
-	} else {
-		logger.Debug("Running as node (connected to existing server)")
+	switch o.cfg.Storage {
+	case session.StorageFile:
+		// 1-3. Open the event file instead of NATS
+		logger.Debug("Opening file storage")
+		if err := o.setupFileStore(); err != nil {
+			logger.Error("Failed to open file storage: %v", err)
+			return fmt.Errorf("failed to open file storage: %w", err)
+		}
+	case "", session.StorageNATS:
+		// 1. Connect to existing NATS server or start a new one
+		logger.Debug("Ensuring NATS connection")
+		if err := o.ensureNATS(); err != nil {
+			logger.Error("Failed to ensure NATS: %v", err)
+			return fmt.Errorf("failed to ensure NATS: %w", err)
+		}
+		if o.isPrimary {
+			logger.Debug("Running as primary (owns NATS server)")
+		} else {
+			logger.Debug("Running as node (connected to existing server)")
+		}
+
+		// 3. Setup JetStream stream
+		logger.Debug("Setting up JetStream")
+		if err := o.setupJetStream(); err != nil {
+			logger.Error("Failed to setup JetStream: %v", err)
+			return fmt.Errorf("failed to setup JetStream: %w", err)
+		}
+		logger.Debug("JetStream setup complete")
+	default:
+		return fmt.Errorf("unknown storage %q (use %s or %s)", o.cfg.Storage, session.StorageNATS, session.StorageFile)
 	}
 
-	// 3. Setup JetStream stream
-	logger.Debug("Setting up JetStream")
-	if err := o.setupJetStream(); err != nil {
-		logger.Error("Failed to setup JetStream: %v", err)
-		return fmt.Errorf("failed to setup JetStream: %w", err)
+	// 3.4. Take ownership of the session, or only watch it
+	if o.cfg.Observe {
+		return o.startObserver()
+	}
+	if err := o.acquireLease(); err != nil {
+		return err
 	}
-	logger.Debug("JetStream setup complete")
 
 	// 3.5. Start MCP tools server
 	logger.Debug("Starting MCP tools server")
-	o.mcpServer = mcpserver.New(o.store, o.cfg.SessionName)
+	o.mcpServer = mcpserver.New(o.store, o.cfg.SessionName, o.cfg.WorkDir)
 	port, err := o.mcpServer.Start(o.ctx)
 	if err != nil {
 		logger.Error("Failed to start MCP server: %v", err)
@@ -204,8 +255,22 @@ func (o *Orchestrator) Start() error {
 	return nil
 }
 
-// Run executes the main iteration loop.
+// Run executes the main iteration loop. In observer mode it only waits for
+// the TUI to quit.
 func (o *Orchestrator) Run() error {
+	if o.cfg.Observe {
+		<-o.ctx.Done()
+		return nil
+	}
+	err := o.runLoop()
+	if err == nil && o.leaseLost.Load() {
+		return errLeaseLost
+	}
+	return err
+}
+
+// runLoop is Run for the owner of the session.
+func (o *Orchestrator) runLoop() error {
 	logger.Info("Starting iteration loop for session '%s'", o.cfg.SessionName)
 
 	// Load current session state to determine starting iteration
@@ -216,6 +281,16 @@ func (o *Orchestrator) Run() error {
 		return fmt.Errorf("failed to load session state: %w", err)
 	}
 
+	// Close out iterations a previous run left open when it was killed
+	if len(state.DanglingIterations()) > 0 {
+		if err := o.abandonDanglingIterations(state); err != nil {
+			return err
+		}
+		if state, err = o.store.LoadState(o.ctx, o.cfg.SessionName); err != nil {
+			return fmt.Errorf("failed to load session state: %w", err)
+		}
+	}
+
 	// Determine starting iteration number
 	startIteration := len(state.Iterations) + 1
 	logger.Debug("Starting from iteration %d (found %d previous iterations)", startIteration, len(state.Iterations))
@@ -251,11 +326,18 @@ func (o *Orchestrator) Run() error {
 		fmt.Printf("Tasks: %d remaining, %d completed\n\n", remainingCount, completedCount)
 	}
 
+	// Record the agent conversation so past iterations can be replayed
+	o.transcript = o.store.NewTranscriptRecorder(o.ctx, o.cfg.SessionName)
+	o.transcript.SetIteration(startIteration)
+	defer o.transcript.Flush()
+	o.iteration = startIteration
+	o.sessionUsage = state.TotalUsage()
+
 	// Setup runner with callbacks based on headless mode
 	logger.Debug("Setting up agent runner with callbacks")
 	if o.tuiProgram != nil {
 		// TUI mode - send output to TUI
-		o.runner = agent.NewRunner(agent.RunnerConfig{
+		o.runner = agent.NewRunner(o.recordFinish(o.recordTranscript(agent.RunnerConfig{
 			Model:        o.cfg.Model,
 			WorkDir:      o.cfg.WorkDir,
 			SessionName:  o.cfg.SessionName,
@@ -308,10 +390,10 @@ func (o *Orchestrator) Run() error {
 					Deletions: change.Deletions,
 				})
 			},
-		})
+		})))
 	} else {
 		// Headless mode - print to stdout
-		o.runner = agent.NewRunner(agent.RunnerConfig{
+		o.runner = agent.NewRunner(o.recordFinish(o.recordTranscript(agent.RunnerConfig{
 			Model:        o.cfg.Model,
 			WorkDir:      o.cfg.WorkDir,
 			SessionName:  o.cfg.SessionName,
@@ -352,13 +434,16 @@ func (o *Orchestrator) Run() error {
 				if event.Model != "" {
 					fmt.Printf(" | Model: %s", event.Model)
 				}
+				if event.Usage != (agent.Usage{}) {
+					fmt.Printf(" | Usage: %s | Session: %s", toSessionUsage(event.Usage), o.sessionUsage)
+				}
 				fmt.Println(" ---")
 			},
 			OnFileChange: func(change agent.FileChange) {
 				// Record change in tracker
 				o.fileTracker.RecordChange(change.AbsPath, change.IsNew, change.Additions, change.Deletions)
 			},
-		})
+		})))
 	}
 
 	// Start the persistent ACP session
@@ -371,93 +456,25 @@ func (o *Orchestrator) Run() error {
 	// Ensure runner is stopped on exit
 	defer o.runner.Stop()
 
-	// Subscribe to task completion events for on_task_complete hooks
-	var taskCompleteSub *natsgo.Subscription
+	// Watch task completion events for on_task_complete hooks
 	if o.hooksConfig != nil && len(o.hooksConfig.Hooks.OnTaskComplete) > 0 {
-		logger.Debug("Subscribing to task completion events for on_task_complete hooks")
-		subject := fmt.Sprintf("iteratr.%s.task", o.cfg.SessionName)
-		sub, err := o.nc.Subscribe(subject, func(msg *natsgo.Msg) {
-			// Parse event to check if it's a status=completed action
-			var event struct {
-				Action string          `json:"action"`
-				Meta   json.RawMessage `json:"meta"`
-			}
-			if err := json.Unmarshal(msg.Data, &event); err != nil {
-				logger.Warn("Failed to parse task event for on_task_complete: %v", err)
-				return
-			}
-
-			// Only process status=completed events
-			if event.Action != "status" {
-				return
-			}
-
-			var meta struct {
-				TaskID string `json:"task_id"`
-				Status string `json:"status"`
-			}
-			if err := json.Unmarshal(event.Meta, &meta); err != nil {
-				logger.Warn("Failed to parse task event metadata: %v", err)
-				return
-			}
-
-			if meta.Status != "completed" {
-				return
-			}
-
-			// Load current state to get task content
-			state, err := o.store.LoadState(o.ctx, o.cfg.SessionName)
-			if err != nil {
-				logger.Warn("Failed to load state for on_task_complete: %v", err)
-				return
-			}
-
-			task, exists := state.Tasks[meta.TaskID]
-			if !exists {
-				logger.Warn("Task %s not found in state for on_task_complete", meta.TaskID)
-				return
-			}
-
-			// Execute on_task_complete hooks
-			logger.Info("Task %s completed, executing on_task_complete hooks", meta.TaskID)
-			hookVars := hooks.Variables{
-				Session:     o.cfg.SessionName,
-				TaskID:      meta.TaskID,
-				TaskContent: task.Content,
-			}
-			output, err := hooks.ExecuteAllPiped(o.ctx, o.hooksConfig.Hooks.OnTaskComplete, o.cfg.WorkDir, hookVars)
-			if err != nil {
-				// Context cancelled or error - just log
-				if o.ctx.Err() != nil {
-					logger.Debug("Context cancelled during on_task_complete hook execution")
-				} else {
-					logger.Error("on_task_complete hook execution failed: %v", err)
-				}
-				return
-			}
-
-			if output != "" {
-				// Append piped output to pending buffer (FIFO order)
-				logger.Debug("on_task_complete hook output: %d bytes (appending to pending buffer)", len(output))
-				o.appendPendingOutput(output)
-			}
-		})
+		logger.Debug("Watching task completion events for on_task_complete hooks")
+		watchCtx, stopWatch := context.WithCancel(o.ctx)
+		// Stop watching on exit
+		defer stopWatch()
+		events, err := o.store.Watch(watchCtx, o.cfg.SessionName)
 		if err != nil {
-			logger.Warn("Failed to subscribe to task completion events: %v", err)
+			logger.Warn("Failed to watch task completion events: %v", err)
 			// Don't fail - hooks are optional
 		} else {
-			taskCompleteSub = sub
-			logger.Debug("Subscribed to task completion events")
+			go func() {
+				for event := range events {
+					o.handleTaskEvent(event)
+				}
+			}()
+			logger.Debug("Watching task completion events")
 		}
 	}
-	// Unsubscribe on exit
-	if taskCompleteSub != nil {
-		defer func() {
-			if err := taskCompleteSub.Unsubscribe(); err != nil {
-				logger.Debug("Failed to unsubscribe from task complete: %v", err)
-			}
-		}()
-	}
 
 	// Execute session_start hooks if configured (before iteration loop)
 	if o.hooksConfig != nil && len(o.hooksConfig.Hooks.SessionStart) > 0 {
@@ -517,6 +534,12 @@ func (o *Orchestrator) Run() error {
 			o.tuiProgram.Send(tui.IterationStartMsg{Number: currentIteration})
 		}
 
+		// Attribute the agent conversation and its usage to this iteration
+		o.transcript.SetIteration(currentIteration)
+		o.iteration = currentIteration
+		o.lastFinish = agent.FinishEvent{}
+		iterationStarted := time.Now()
+
 		// Drain pending hook output from previous iterations (session_start, post_iteration, on_task_complete)
 		pendingOutput := o.drainPendingOutput()
 		if len(pendingOutput) > 0 {
@@ -585,11 +608,13 @@ func (o *Orchestrator) Run() error {
 			// Check if context was cancelled (TUI quit, signal, etc.) - exit gracefully
 			if o.ctx.Err() != nil {
 				logger.Info("Context cancelled during iteration, stopping gracefully")
+				o.completeIteration(currentIteration, o.iterationResult(session.IterationCancelled, iterationStarted, nil))
 				return nil
 			}
 
 			// Log the error (don't write to stderr - corrupts terminal during TUI shutdown)
 			logger.Error("Iteration #%d failed: %v", currentIteration, err)
+			o.completeIteration(currentIteration, o.iterationResult(session.IterationFailed, iterationStarted, err))
 
 			// Check if it's a panic error - these are critical
 			var panicErr *ierr.PanicError
@@ -651,13 +676,22 @@ func (o *Orchestrator) Run() error {
 		logger.Info("Iteration #%d agent execution completed", currentIteration)
 
 		// Log iteration complete
-		if err := o.store.IterationComplete(o.ctx, o.cfg.SessionName, currentIteration); err != nil {
+		result := o.iterationResult(session.IterationSucceeded, iterationStarted, nil)
+		if err := o.store.IterationComplete(o.ctx, o.cfg.SessionName, currentIteration, result); err != nil {
 			logger.Error("Failed to log iteration complete: %v", err)
 			return fmt.Errorf("failed to log iteration complete: %w", err)
 		}
 
 		logger.Info("=== Iteration #%d completed successfully ===", currentIteration)
 
+		// Snapshot state so subsequent loads only replay newer events
+		if err := o.store.SaveSnapshot(o.ctx, o.cfg.SessionName); err != nil {
+			logger.Warn("Failed to write state snapshot: %v", err)
+			// Don't fail the iteration - snapshots are an optimization
+		}
+
+		o.scheduledBackup(currentIteration)
+
 		// Execute post-iteration hooks if configured
 		if o.hooksConfig != nil && len(o.hooksConfig.Hooks.PostIteration) > 0 {
 			logger.Debug("Executing %d post-iteration hook(s)", len(o.hooksConfig.Hooks.PostIteration))
@@ -726,12 +760,9 @@ func (o *Orchestrator) Run() error {
 					if err := o.runner.SendMessages(o.ctx, []string{userMsg}); err != nil {
 						logger.Error("Failed to send user message: %v", err)
 					}
-					// Reload state and refresh TUI (task list may have changed)
+					// Reload state (the TUI follows changes through its event watch)
 					state, err = o.store.LoadState(o.ctx, o.cfg.SessionName)
 					if err == nil {
-						if o.tuiProgram != nil {
-							o.tuiProgram.Send(tui.StateUpdateMsg{State: state})
-						}
 						// Check if session was restarted (agent added tasks and marked incomplete)
 						if !state.Complete {
 							logger.Info("Session restarted, resuming iterations")
@@ -865,6 +896,256 @@ send:
 	return nil
 }
 
+// handleTaskEvent runs the on_task_complete hooks when a task is completed.
+// Their output is queued for the next iteration.
+func (o *Orchestrator) handleTaskEvent(event session.Event) {
+	// Only process task status=completed events
+	if event.Type != nats.EventTypeTask || event.Action != "status" {
+		return
+	}
+
+	var meta session.TaskStatusMeta
+	if err := session.DecodeMeta(event, &meta); err != nil {
+		logger.Warn("Failed to parse task event metadata: %v", err)
+		return
+	}
+
+	if meta.Status != "completed" {
+		return
+	}
+
+	// Load current state to get task content
+	state, err := o.store.LoadState(o.ctx, o.cfg.SessionName)
+	if err != nil {
+		logger.Warn("Failed to load state for on_task_complete: %v", err)
+		return
+	}
+
+	task, exists := state.Tasks[meta.TaskID]
+	if !exists {
+		logger.Warn("Task %s not found in state for on_task_complete", meta.TaskID)
+		return
+	}
+
+	// Execute on_task_complete hooks
+	logger.Info("Task %s completed, executing on_task_complete hooks", meta.TaskID)
+	hookVars := hooks.Variables{
+		Session:     o.cfg.SessionName,
+		TaskID:      meta.TaskID,
+		TaskContent: task.Content,
+		TaskLabels:  task.Labels,
+	}
+	output, err := hooks.ExecuteAllPiped(o.ctx, o.hooksConfig.Hooks.OnTaskComplete, o.cfg.WorkDir, hookVars)
+	if err != nil {
+		// Context cancelled or error - just log
+		if o.ctx.Err() != nil {
+			logger.Debug("Context cancelled during on_task_complete hook execution")
+		} else {
+			logger.Error("on_task_complete hook execution failed: %v", err)
+		}
+		return
+	}
+
+	if output != "" {
+		// Append piped output to pending buffer (FIFO order)
+		logger.Debug("on_task_complete hook output: %d bytes (appending to pending buffer)", len(output))
+		o.appendPendingOutput(output)
+	}
+}
+
+// recordFinish wraps the OnFinish callback in cfg so that the usage of every
+// agent turn is priced, added to the session total and persisted on the current
+// iteration before the wrapped callback sees it. With configured pricing the
+// cost is computed from tokens; otherwise the cost reported by the agent is kept.
+// The event is also kept for the iteration result.
+func (o *Orchestrator) recordFinish(cfg agent.RunnerConfig) agent.RunnerConfig {
+	onFinish := cfg.OnFinish
+	cfg.OnFinish = func(event agent.FinishEvent) {
+		o.lastFinish = event
+
+		usage := toSessionUsage(event.Usage)
+		if o.cfg.Pricing != nil {
+			usage.Cost = o.cfg.Pricing.Cost(usage)
+			event.Usage.Cost = usage.Cost
+		}
+		if !usage.IsZero() {
+			o.sessionUsage = o.sessionUsage.Add(usage)
+			if err := o.store.IterationUsage(o.ctx, o.cfg.SessionName, o.iteration, usage); err != nil {
+				logger.Warn("Failed to record iteration usage: %v", err)
+			}
+		}
+		if onFinish != nil {
+			onFinish(event)
+		}
+	}
+	return cfg
+}
+
+// abandonDanglingIterations marks iterations that started but never ended as
+// abandoned, recording the tasks that were in progress. With ResetTasks those
+// tasks go back to remaining so they can be picked up again. The next prompt's
+// iteration history tells the agent what was interrupted.
+func (o *Orchestrator) abandonDanglingIterations(state *session.State) error {
+	var inProgress []string
+	for id, task := range state.Tasks {
+		if task.Status == "in_progress" {
+			inProgress = append(inProgress, id)
+		}
+	}
+	sort.Strings(inProgress)
+
+	dangling := state.DanglingIterations()
+	for i, iter := range dangling {
+		// Tasks still in progress were interrupted by the most recent iteration
+		var interrupted []string
+		if i == len(dangling)-1 {
+			interrupted = inProgress
+		}
+		logger.Warn("Iteration #%d was interrupted, marking it abandoned", iter.Number)
+		if err := o.store.IterationAbandon(o.ctx, o.cfg.SessionName, iter.Number, interrupted); err != nil {
+			return fmt.Errorf("failed to mark iteration #%d abandoned: %w", iter.Number, err)
+		}
+		if o.cfg.Headless {
+			fmt.Printf("Iteration #%d was interrupted and marked abandoned\n", iter.Number)
+		}
+	}
+
+	if !o.cfg.ResetTasks || len(inProgress) == 0 {
+		return nil
+	}
+	number := dangling[len(dangling)-1].Number
+	for _, id := range inProgress {
+		err := o.store.TaskStatus(o.ctx, o.cfg.SessionName, session.TaskStatusParams{
+			ID:        id,
+			Status:    "remaining",
+			Iteration: number,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to reset interrupted task %s: %w", id, err)
+		}
+	}
+	logger.Info("Reset %d interrupted task(s) to remaining: %s", len(inProgress), strings.Join(inProgress, ", "))
+	if o.cfg.Headless {
+		fmt.Printf("Reset interrupted task(s) to remaining: %s\n", strings.Join(inProgress, ", "))
+	}
+	return nil
+}
+
+// iterationResult describes the current iteration from its last agent turn and
+// the files it modified.
+func (o *Orchestrator) iterationResult(outcome string, started time.Time, err error) session.IterationResult {
+	result := session.IterationResult{
+		Outcome:    outcome,
+		StopReason: o.lastFinish.StopReason,
+		Error:      o.lastFinish.Error,
+		Duration:   time.Since(started),
+		Model:      o.lastFinish.Model,
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	if result.Model == "" {
+		result.Model = o.cfg.Model
+	}
+	for _, change := range o.fileTracker.Changes() {
+		result.Files = append(result.Files, session.FileChange{
+			Path:      change.Path,
+			IsNew:     change.IsNew,
+			Additions: change.Additions,
+			Deletions: change.Deletions,
+		})
+	}
+	return result
+}
+
+// completeIteration records the end of an iteration that did not succeed.
+// Failures are only logged since the iteration is already ending; a fresh
+// context is used when the run context was cancelled.
+func (o *Orchestrator) completeIteration(number int, result session.IterationResult) {
+	ctx := o.ctx
+	if ctx.Err() != nil {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+	}
+	if err := o.store.IterationComplete(ctx, o.cfg.SessionName, number, result); err != nil {
+		logger.Warn("Failed to log iteration #%d %s: %v", number, result.Outcome, err)
+	}
+}
+
+// toSessionUsage converts usage reported by the agent runner.
+func toSessionUsage(u agent.Usage) session.Usage {
+	return session.Usage{
+		InputTokens:      u.InputTokens,
+		OutputTokens:     u.OutputTokens,
+		ReasoningTokens:  u.ReasoningTokens,
+		CacheReadTokens:  u.CacheReadTokens,
+		CacheWriteTokens: u.CacheWriteTokens,
+		Cost:             u.Cost,
+	}
+}
+
+// recordTranscript wraps the runner callbacks in cfg so that everything sent to
+// and received from the agent is also written to the session transcript.
+func (o *Orchestrator) recordTranscript(cfg agent.RunnerConfig) agent.RunnerConfig {
+	rec := o.transcript
+	if rec == nil {
+		return cfg
+	}
+
+	onText, onThinking, onToolCall, onFinish := cfg.OnText, cfg.OnThinking, cfg.OnToolCall, cfg.OnFinish
+	cfg.OnPrompt = rec.Prompt
+	cfg.OnText = func(content string) {
+		rec.Text(content)
+		if onText != nil {
+			onText(content)
+		}
+	}
+	cfg.OnThinking = func(content string) {
+		rec.Thinking(content)
+		if onThinking != nil {
+			onThinking(content)
+		}
+	}
+	cfg.OnToolCall = func(event agent.ToolCallEvent) {
+		tool := session.TranscriptTool{
+			ID:        event.ToolCallID,
+			Title:     event.Title,
+			Status:    event.Status,
+			Kind:      event.Kind,
+			Input:     event.RawInput,
+			Output:    event.Output,
+			SessionID: event.SessionID,
+		}
+		if event.FileDiff != nil {
+			tool.Diff = &session.TranscriptDiff{
+				File:      event.FileDiff.File,
+				Before:    event.FileDiff.Before,
+				After:     event.FileDiff.After,
+				Additions: event.FileDiff.Additions,
+				Deletions: event.FileDiff.Deletions,
+			}
+		}
+		rec.ToolCall(tool)
+		if onToolCall != nil {
+			onToolCall(event)
+		}
+	}
+	cfg.OnFinish = func(event agent.FinishEvent) {
+		rec.Finish(session.TranscriptFinish{
+			Reason:   event.StopReason,
+			Error:    event.Error,
+			Model:    event.Model,
+			Provider: event.Provider,
+			Duration: event.Duration,
+		})
+		if onFinish != nil {
+			onFinish(event)
+		}
+	}
+	return cfg
+}
+
 // runAutoCommit executes auto-commit after iteration completes.
 // Checks if in git repo, builds commit prompt with file list and context,
 // and reuses existing Runner to send commit request to current ACP session.
@@ -1014,6 +1295,16 @@ func (o *Orchestrator) Stop() error {
 		o.mcpServer = nil
 	}
 
+	// Release the session (before closing the log it is stored in)
+	o.releaseLease()
+
+	// Close the event log (the event file in file storage mode)
+	if o.store != nil {
+		if err := o.store.Close(); err != nil {
+			multiErr.Append(fmt.Errorf("event log close failed: %w", err))
+		}
+	}
+
 	// Close NATS connection (and server if primary)
 	if o.isPrimary {
 		// Primary mode: shut down the server we own
@@ -1043,9 +1334,10 @@ func (o *Orchestrator) Stop() error {
 }
 
 // ensureNATS connects to an existing NATS server or starts a new one.
-// If another iteratr instance is already running with a NATS server,
-// this instance runs in "node mode" and connects to the existing server.
-// Otherwise, it starts a new embedded server and runs in "primary mode".
+// If an external server is configured, or another iteratr instance is already
+// running with a NATS server, this instance runs in "node mode" and connects
+// to that server. Otherwise, it starts a new embedded server and runs in
+// "primary mode".
 func (o *Orchestrator) ensureNATS() error {
 	// Ensure data directory exists
 	dataDir := filepath.Join(o.cfg.DataDir, "data")
@@ -1053,6 +1345,18 @@ func (o *Orchestrator) ensureNATS() error {
 		return fmt.Errorf("failed to create data directory: %w", err)
 	}
 
+	// An external server is shared, never owned
+	if o.cfg.NATS.URL != "" {
+		nc, err := nats.Connect(o.cfg.NATS)
+		if err != nil {
+			return err
+		}
+		logger.Info("Connected to external NATS server (node mode)")
+		o.nc = nc
+		o.isPrimary = false
+		return nil
+	}
+
 	// Try to connect to existing server first
 	if nc := nats.TryConnectExisting(dataDir); nc != nil {
 		logger.Info("Connected to existing NATS server (node mode)")
@@ -1082,6 +1386,20 @@ func (o *Orchestrator) ensureNATS() error {
 	return nil
 }
 
+// scheduledBackup backs up the event log if iteration is due for one.
+// Failures are only logged; the next scheduled backup tries again.
+func (o *Orchestrator) scheduledBackup(iteration int) {
+	if o.cfg.BackupEvery <= 0 || iteration%o.cfg.BackupEvery != 0 {
+		return
+	}
+	path, manifest, err := o.store.BackupToDir(o.ctx, o.cfg.BackupDir, o.cfg.BackupKeep)
+	if err != nil {
+		logger.Warn("Scheduled backup after iteration #%d failed: %v", iteration, err)
+		return
+	}
+	logger.Info("Backed up %d messages to %s after iteration #%d", manifest.Messages, path, iteration)
+}
+
 // setupJetStream creates the JetStream stream and initializes the session store.
 func (o *Orchestrator) setupJetStream() error {
 	// Create JetStream context using modern API
@@ -1091,20 +1409,99 @@ func (o *Orchestrator) setupJetStream() error {
 	}
 
 	// Setup stream
-	stream, err := nats.SetupStream(o.ctx, js)
+	stream, err := nats.SetupLayoutStream(o.ctx, js, o.cfg.Layout, o.cfg.Retention)
 	if err != nil {
 		return fmt.Errorf("failed to setup stream: %w", err)
 	}
 
 	// Create session store
-	o.store = session.NewStore(js, stream)
+	return o.initStore(session.NewJetStreamLogWithLayout(js, stream, o.cfg.Layout).WithConn(o.nc))
+}
+
+// setupFileStore opens the event file in the data directory and initializes
+// the session store. No NATS server is started.
+func (o *Orchestrator) setupFileStore() error {
+	log, err := session.OpenFileLog(filepath.Join(o.cfg.DataDir, "data"))
+	if err != nil {
+		return err
+	}
+	return o.initStore(log)
+}
+
+// initStore creates the session store on the given event log.
+func (o *Orchestrator) initStore(log session.EventLog) error {
+	o.store = session.NewStore(log)
+
+	// Select the task scheduling policy used by task-next
+	policy, err := session.SchedulingPolicyByName(o.cfg.Scheduler)
+	if err != nil {
+		return err
+	}
+	o.store.SetSchedulingPolicy(policy)
+
+	// Enable state snapshots (idempotent - they may already exist)
+	if err := o.store.EnableSnapshots(o.ctx); err != nil {
+		return err
+	}
 	return nil
 }
 
+// acquireLease takes the ownership lease of the session, so that no other
+// build loop runs it at the same time. If another process takes the lease
+// over (after this one stalled past the lease TTL), the loop is stopped.
+func (o *Orchestrator) acquireLease() error {
+	lease, err := o.store.AcquireLease(o.ctx, o.cfg.SessionName)
+	if errors.Is(err, session.ErrSessionLocked) {
+		return fmt.Errorf("%w (run with --observe to watch it read-only, or 'iteratr session unlock %s' if that process is gone)", err, o.cfg.SessionName)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock session: %w", err)
+	}
+	o.lease = lease
+
+	go func() {
+		select {
+		case <-lease.Lost():
+			o.leaseLost.Store(true)
+			o.cancel()
+		case <-o.ctx.Done():
+		}
+	}()
+	return nil
+}
+
+// releaseLease releases the session's lease, if held.
+func (o *Orchestrator) releaseLease() {
+	if o.lease == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := o.lease.Release(ctx); err != nil {
+		logger.Warn("Failed to release session lease: %v", err)
+	}
+	o.lease = nil
+}
+
+// startObserver starts the TUI on the session without taking its lease. No
+// MCP server, hooks or iterations run, and the TUI makes no changes; it
+// follows the session as its owner writes to it.
+func (o *Orchestrator) startObserver() error {
+	o.owner = "no build running"
+	if holder, err := o.store.ReadLease(o.ctx, o.cfg.SessionName); err == nil && !holder.Stale(time.Now()) {
+		o.owner = "by " + holder.String()
+	}
+	logger.Info("Observing session '%s' (%s)", o.cfg.SessionName, o.owner)
+	return o.startTUI()
+}
+
 // startTUI initializes and starts the Bubbletea TUI.
 func (o *Orchestrator) startTUI() error {
 	// Create TUI app
 	o.tuiApp = tui.NewApp(o.ctx, o.store, o.cfg.SessionName, o.cfg.WorkDir, o.cfg.DataDir, o.nc, o.sendChan, o)
+	if o.cfg.Observe {
+		o.tuiApp.SetObserver(o.owner)
+	}
 
 	// Create Bubbletea program with context for graceful shutdown
 	o.tuiProgram = tea.NewProgram(o.tuiApp, tea.WithContext(o.ctx))
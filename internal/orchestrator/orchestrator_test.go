@@ -2,9 +2,11 @@ package orchestrator
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"syscall"
 	"testing"
 	"time"
@@ -280,13 +282,13 @@ Resumable session test.
 		if err := orch.store.IterationStart(orch.ctx, sessionName, 1); err != nil {
 			t.Fatalf("failed to start iteration 1: %v", err)
 		}
-		if err := orch.store.IterationComplete(orch.ctx, sessionName, 1); err != nil {
+		if err := orch.store.IterationComplete(orch.ctx, sessionName, 1, session.IterationResult{}); err != nil {
 			t.Fatalf("failed to complete iteration 1: %v", err)
 		}
 		if err := orch.store.IterationStart(orch.ctx, sessionName, 2); err != nil {
 			t.Fatalf("failed to start iteration 2: %v", err)
 		}
-		if err := orch.store.IterationComplete(orch.ctx, sessionName, 2); err != nil {
+		if err := orch.store.IterationComplete(orch.ctx, sessionName, 2, session.IterationResult{}); err != nil {
 			t.Fatalf("failed to complete iteration 2: %v", err)
 		}
 
@@ -331,6 +333,157 @@ Resumable session test.
 	}
 }
 
+// TestSessionLease verifies that a second orchestrator can't run a session
+// that is already running, and can once the first one stopped.
+func TestSessionLease(t *testing.T) {
+	tmpDir := t.TempDir()
+	newOrchestrator := func() *Orchestrator {
+		orch, err := New(Config{
+			SessionName: "test-lease",
+			DataDir:     filepath.Join(tmpDir, ".iteratr"),
+			WorkDir:     tmpDir,
+			Headless:    true,
+			Storage:     session.StorageFile,
+		})
+		if err != nil {
+			t.Fatalf("failed to create orchestrator: %v", err)
+		}
+		return orch
+	}
+
+	first := newOrchestrator()
+	if err := first.Start(); err != nil {
+		t.Fatalf("failed to start first orchestrator: %v", err)
+	}
+
+	second := newOrchestrator()
+	err := second.Start()
+	if !errors.Is(err, session.ErrSessionLocked) {
+		t.Fatalf("expected ErrSessionLocked, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "--observe") {
+		t.Errorf("expected error to offer observer mode, got %v", err)
+	}
+	_ = second.Stop()
+
+	if err := first.Stop(); err != nil {
+		t.Fatalf("failed to stop first orchestrator: %v", err)
+	}
+	third := newOrchestrator()
+	if err := third.Start(); err != nil {
+		t.Fatalf("expected session to be free after stop, got %v", err)
+	}
+	_ = third.Stop()
+}
+
+// TestAbandonDanglingIterations verifies that an iteration left open by a
+// killed run is marked abandoned and its in-progress tasks are reset.
+func TestScheduledBackup(t *testing.T) {
+	tmpDir := t.TempDir()
+	backupDir := filepath.Join(tmpDir, "backups")
+	orch, err := New(Config{
+		SessionName: "test-backup",
+		DataDir:     filepath.Join(tmpDir, ".iteratr"),
+		WorkDir:     tmpDir,
+		Headless:    true,
+		Storage:     session.StorageFile,
+		BackupEvery: 2,
+		BackupDir:   backupDir,
+	})
+	if err != nil {
+		t.Fatalf("failed to create orchestrator: %v", err)
+	}
+	if err := orch.Start(); err != nil {
+		t.Fatalf("failed to start orchestrator: %v", err)
+	}
+	defer func() { _ = orch.Stop() }()
+
+	orch.scheduledBackup(1)
+	if backups, _ := session.ListBackups(backupDir); len(backups) != 0 {
+		t.Fatalf("expected no backup after iteration 1, got %v", backups)
+	}
+	orch.scheduledBackup(2)
+	backups, _ := session.ListBackups(backupDir)
+	if len(backups) != 1 {
+		t.Fatalf("expected a backup after iteration 2, got %v", backups)
+	}
+	f, err := os.Open(backups[0])
+	if err != nil {
+		t.Fatalf("failed to open backup: %v", err)
+	}
+	defer func() { _ = f.Close() }()
+	if _, err := session.VerifyBackup(f); err != nil {
+		t.Errorf("expected a valid backup, got %v", err)
+	}
+}
+
+func TestAbandonDanglingIterations(t *testing.T) {
+	tmpDir := t.TempDir()
+	sessionName := "test-abandon-session"
+
+	orch, err := New(Config{
+		SessionName: sessionName,
+		DataDir:     filepath.Join(tmpDir, ".iteratr"),
+		WorkDir:     tmpDir,
+		Headless:    true,
+		ResetTasks:  true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create orchestrator: %v", err)
+	}
+	if err := orch.Start(); err != nil {
+		t.Fatalf("failed to start orchestrator: %v", err)
+	}
+	defer func() { _ = orch.Stop() }()
+
+	// Iteration 1 completes, iteration 2 is killed while working on a task
+	ctx := orch.ctx
+	_ = orch.store.IterationStart(ctx, sessionName, 1)
+	_ = orch.store.IterationComplete(ctx, sessionName, 1, session.IterationResult{})
+	_ = orch.store.IterationStart(ctx, sessionName, 2)
+	working, err := orch.store.TaskAdd(ctx, sessionName, session.TaskAddParams{Content: "Working", Status: "in_progress", Iteration: 2})
+	if err != nil {
+		t.Fatalf("failed to add task: %v", err)
+	}
+	done, _ := orch.store.TaskAdd(ctx, sessionName, session.TaskAddParams{Content: "Done", Status: "completed", Iteration: 1})
+
+	state, err := orch.store.LoadState(ctx, sessionName)
+	if err != nil {
+		t.Fatalf("failed to load state: %v", err)
+	}
+	if len(state.DanglingIterations()) != 1 {
+		t.Fatalf("expected 1 dangling iteration, got %d", len(state.DanglingIterations()))
+	}
+
+	if err := orch.abandonDanglingIterations(state); err != nil {
+		t.Fatalf("abandonDanglingIterations failed: %v", err)
+	}
+
+	state, err = orch.store.LoadState(ctx, sessionName)
+	if err != nil {
+		t.Fatalf("failed to load state: %v", err)
+	}
+	if len(state.DanglingIterations()) != 0 {
+		t.Errorf("expected no dangling iterations, got %d", len(state.DanglingIterations()))
+	}
+	iter := state.Iterations[1]
+	if iter.Outcome != session.IterationAbandoned || iter.Complete || iter.EndedAt.IsZero() {
+		t.Errorf("expected iteration 2 abandoned, got %+v", iter)
+	}
+	if len(iter.InterruptedTasks) != 1 || iter.InterruptedTasks[0] != working.ID {
+		t.Errorf("expected interrupted task %s, got %v", working.ID, iter.InterruptedTasks)
+	}
+	if status := state.Tasks[working.ID].Status; status != "remaining" {
+		t.Errorf("expected interrupted task reset to remaining, got %s", status)
+	}
+	if status := state.Tasks[done.ID].Status; status != "completed" {
+		t.Errorf("expected completed task unchanged, got %s", status)
+	}
+	if state.Iterations[0].Outcome != session.IterationSucceeded {
+		t.Errorf("expected iteration 1 to stay succeeded, got %s", state.Iterations[0].Outcome)
+	}
+}
+
 // TestIterationLoopSessionComplete verifies that the loop stops when session_complete is signaled
 func TestIterationLoopSessionComplete(t *testing.T) {
 	// Create temporary directory for test
@@ -0,0 +1,152 @@
+package session
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/mark3labs/iteratr/internal/logger"
+	"github.com/mark3labs/iteratr/internal/nats"
+)
+
+// maxArchiveLine bounds the size of a single event line read by ImportSession.
+const maxArchiveLine = 16 * 1024 * 1024
+
+// ExportSession writes every event of a session to w as JSON lines, one Event
+// per line in stream sequence order. Returns the number of events written.
+func (s *Store) ExportSession(ctx context.Context, session string, w io.Writer) (int, error) {
+	enc := json.NewEncoder(w)
+	count := 0
+	var writeErr error
+	_, err := s.forEachEvent(ctx, session, 0, func(seq uint64, event Event) bool {
+		if writeErr = enc.Encode(event); writeErr != nil {
+			return false
+		}
+		count++
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+	if writeErr != nil {
+		return 0, fmt.Errorf("failed to write event: %w", writeErr)
+	}
+	if count == 0 {
+		return 0, fmt.Errorf("session %q has no events", session)
+	}
+	logger.Debug("Exported %d events from session %s", count, session)
+	return count, nil
+}
+
+// ExportTranscripts writes every transcript entry of a session to w as JSON
+// lines, exactly as they were recorded, in stream sequence order. Each entry
+// carries its iteration. Returns the number of entries written.
+func (s *Store) ExportTranscripts(ctx context.Context, session string, w io.Writer) (int, error) {
+	count := 0
+	var writeErr error
+	_, err := s.forEachMsg(ctx, nats.SubjectForTranscripts(session), 0, func(seq uint64, data []byte) bool {
+		if _, writeErr = w.Write(data); writeErr == nil {
+			_, writeErr = w.Write([]byte{'\n'})
+		}
+		if writeErr != nil {
+			return false
+		}
+		count++
+		return true
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to read transcripts: %w", err)
+	}
+	if writeErr != nil {
+		return 0, fmt.Errorf("failed to write transcript entry: %w", writeErr)
+	}
+	logger.Debug("Exported %d transcript entries from session %s", count, session)
+	return count, nil
+}
+
+// MessageCount returns the number of messages of a session: its events and
+// transcript entries.
+func (s *Store) MessageCount(ctx context.Context, session string) (uint64, error) {
+	count, err := s.log.Count(ctx, nats.SubjectForSession(session))
+	if err != nil {
+		return 0, fmt.Errorf("failed to count session messages: %w", err)
+	}
+	return count, nil
+}
+
+// ImportSession reads an archive written by ExportSession and republishes its
+// events in order, keeping their original IDs and timestamps so that task and
+// note references stay intact. The events are published under name, or under
+// the session recorded in the archive if name is empty. The whole archive is
+// validated before anything is published, and the target session must not
+// already have events. Returns the session name and the number of events imported.
+func (s *Store) ImportSession(ctx context.Context, r io.Reader, name string) (string, int, error) {
+	events, err := readArchive(r)
+	if err != nil {
+		return "", 0, err
+	}
+
+	if name == "" {
+		name = events[0].Session
+	}
+	if err := ValidateName(name); err != nil {
+		return "", 0, err
+	}
+
+	// Refuse to interleave with an existing session's log
+	if exists, err := s.SessionExists(ctx, name); err != nil {
+		return "", 0, err
+	} else if exists {
+		return "", 0, fmt.Errorf("session %q already exists", name)
+	}
+
+	if err := s.publishAll(ctx, name, events); err != nil {
+		s.discardCopy(ctx, name)
+		return "", 0, fmt.Errorf("failed to import: %w", err)
+	}
+	logger.Debug("Imported %d events into session %s", len(events), name)
+	return name, len(events), nil
+}
+
+// readArchive parses and validates the JSON lines of a session archive.
+// All events must belong to one session and have a known type and an action.
+func readArchive(r io.Reader) ([]Event, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxArchiveLine)
+
+	var events []Event
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return nil, fmt.Errorf("line %d: invalid event: %w", line, err)
+		}
+		if !nats.IsEventType(event.Type) {
+			return nil, fmt.Errorf("line %d: unknown event type %q", line, event.Type)
+		}
+		if event.Action == "" {
+			return nil, fmt.Errorf("line %d: event has no action", line)
+		}
+		event, err := Upcast(event)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if len(events) > 0 && event.Session != events[0].Session {
+			return nil, fmt.Errorf("line %d: event belongs to session %q, expected %q", line, event.Session, events[0].Session)
+		}
+		events = append(events, event)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	if len(events) == 0 {
+		return nil, fmt.Errorf("archive contains no events")
+	}
+	return events, nil
+}
@@ -0,0 +1,128 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/iteratr/internal/nats"
+)
+
+func TestExportImportSession(t *testing.T) {
+	// Setup: Create embedded NATS and store
+	ctx := context.Background()
+	ns, _, err := nats.StartEmbeddedNATS(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to start NATS: %v", err)
+	}
+	defer ns.Shutdown()
+
+	nc, err := nats.ConnectInProcess(ns)
+	if err != nil {
+		t.Fatalf("failed to connect to NATS: %v", err)
+	}
+	defer nc.Close()
+
+	js, err := nats.CreateJetStream(nc)
+	if err != nil {
+		t.Fatalf("failed to create JetStream: %v", err)
+	}
+
+	stream, err := nats.SetupStream(ctx, js)
+	if err != nil {
+		t.Fatalf("failed to setup stream: %v", err)
+	}
+
+	store := NewStore(NewJetStreamLog(js, stream))
+	session := "test-export"
+
+	_ = store.IterationStart(ctx, session, 1)
+	task1, _ := store.TaskAdd(ctx, session, TaskAddParams{Content: "First", Iteration: 1})
+	_ = store.TaskStatus(ctx, session, TaskStatusParams{ID: task1.ID, Status: "completed", Iteration: 1})
+	_, _ = store.NoteAdd(ctx, session, NoteAddParams{Content: "Learned something", Type: "learning", Iteration: 1})
+	_ = store.IterationComplete(ctx, session, 1, IterationResult{})
+
+	var archive bytes.Buffer
+	count, err := store.ExportSession(ctx, session, &archive)
+	if err != nil {
+		t.Fatalf("ExportSession failed: %v", err)
+	}
+	if count != 5 {
+		t.Fatalf("expected 5 events exported, got %d", count)
+	}
+	if lines := strings.Count(archive.String(), "\n"); lines != 5 {
+		t.Fatalf("expected 5 lines, got %d", lines)
+	}
+
+	t.Run("round trip under new name", func(t *testing.T) {
+		name, n, err := store.ImportSession(ctx, bytes.NewReader(archive.Bytes()), "test-import")
+		if err != nil {
+			t.Fatalf("ImportSession failed: %v", err)
+		}
+		if name != "test-import" || n != 5 {
+			t.Fatalf("expected 5 events in test-import, got %d in %s", n, name)
+		}
+
+		original, _ := store.History(ctx, session)
+		imported, _ := store.History(ctx, name)
+		if len(imported) != len(original) {
+			t.Fatalf("expected %d imported events, got %d", len(original), len(imported))
+		}
+		for i := range original {
+			if imported[i].Session != name {
+				t.Errorf("event %d: expected session %s, got %s", i, name, imported[i].Session)
+			}
+			if imported[i].Action != original[i].Action || !imported[i].Timestamp.Equal(original[i].Timestamp) {
+				t.Errorf("event %d: expected %s at %v, got %s at %v", i,
+					original[i].Action, original[i].Timestamp, imported[i].Action, imported[i].Timestamp)
+			}
+		}
+
+		state, _ := store.LoadState(ctx, name)
+		if state.Tasks[task1.ID] == nil || state.Tasks[task1.ID].Status != "completed" {
+			t.Errorf("expected %s completed in imported state", task1.ID)
+		}
+		if len(state.Notes) != 1 || len(state.Iterations) != 1 {
+			t.Errorf("expected 1 note and 1 iteration, got %d and %d", len(state.Notes), len(state.Iterations))
+		}
+	})
+
+	t.Run("rejects existing session", func(t *testing.T) {
+		if _, _, err := store.ImportSession(ctx, bytes.NewReader(archive.Bytes()), ""); err == nil {
+			t.Error("expected error importing over existing session")
+		}
+	})
+
+	t.Run("rejects invalid archives", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			archive string
+		}{
+			{"empty", ""},
+			{"not json", "not json\n"},
+			{"unknown type", `{"session":"s","type":"inbox","action":"add"}` + "\n"},
+			{"missing action", `{"session":"s","type":"task"}` + "\n"},
+			{"mixed sessions", `{"session":"a","type":"task","action":"add"}` + "\n" +
+				`{"session":"b","type":"task","action":"add"}` + "\n"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				if _, _, err := store.ImportSession(ctx, strings.NewReader(tt.archive), "test-invalid"); err == nil {
+					t.Error("expected error")
+				}
+			})
+		}
+
+		// Nothing was published for the rejected archives
+		if events, _ := store.History(ctx, "test-invalid"); len(events) != 0 {
+			t.Errorf("expected no events, got %d", len(events))
+		}
+	})
+
+	t.Run("rejects invalid name", func(t *testing.T) {
+		if _, _, err := store.ImportSession(ctx, bytes.NewReader(archive.Bytes()), "bad.name"); err == nil {
+			t.Error("expected error for invalid session name")
+		}
+	})
+}
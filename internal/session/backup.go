@@ -0,0 +1,362 @@
+package session
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/mark3labs/iteratr/internal/logger"
+)
+
+// backupVersion is the format version of backup archives. Restore refuses
+// archives written by a newer version.
+const backupVersion = 1
+
+// Members of a backup archive, in this order.
+const (
+	backupManifestName = "manifest.json"
+	backupDataName     = "data"
+)
+
+// backupFilePrefix and backupFileExt name the archives written by
+// BackupToDir: iteratr-20060102-150405.000.tar.
+const (
+	backupFilePrefix = "iteratr-"
+	backupFileExt    = ".tar"
+)
+
+// ErrBackupCorrupt is returned when a backup archive is malformed or its data
+// does not match the checksum recorded when it was written.
+var ErrBackupCorrupt = errors.New("backup is corrupt")
+
+// BackupLog is implemented by event logs that can copy all their messages
+// while in use, and replace them with such a copy.
+type BackupLog interface {
+	// BackupKind names the format of the copies. Only copies of the same kind
+	// can be restored.
+	BackupKind() string
+
+	// Backup writes a consistent copy of the whole log to w.
+	Backup(ctx context.Context, w io.Writer) (*BackupData, error)
+
+	// Restore replaces the whole log with a copy written by Backup, once the
+	// copy is found to hold data.Messages messages up to data.LastSequence.
+	// If the copy can't be restored, the log is left as it was.
+	Restore(ctx context.Context, data *BackupData, r io.Reader) error
+}
+
+// checkRestored fails unless a restored copy holds the messages its backup
+// recorded.
+func checkRestored(messages, lastSeq uint64, data *BackupData) error {
+	if messages != data.Messages || lastSeq != data.LastSequence {
+		return fmt.Errorf("restored log has %d messages up to sequence %d, backup has %d up to %d",
+			messages, lastSeq, data.Messages, data.LastSequence)
+	}
+	return nil
+}
+
+// BackupData describes the copy written by BackupLog.Backup.
+type BackupData struct {
+	Messages     uint64          `json:"messages"`       // Messages in the copy
+	LastSequence uint64          `json:"last_sequence"`  // Last sequence of the log when copied
+	Meta         json.RawMessage `json:"meta,omitempty"` // Whatever else Restore needs, e.g. the stream config
+}
+
+// BackupManifest is the first member of a backup archive. It describes the
+// data member that follows and carries its checksum.
+type BackupManifest struct {
+	Version   int       `json:"version"`
+	Kind      string    `json:"kind"` // BackupLog.BackupKind of the log
+	CreatedAt time.Time `json:"created_at"`
+	Sessions  []string  `json:"sessions"`
+	BackupData
+	Size   int64  `json:"size"`   // Bytes of the data member
+	SHA256 string `json:"sha256"` // Checksum of the data member
+}
+
+// Backup writes a backup archive of the whole event log to w: every session,
+// transcript and the knowledge base, as of one point in time. Sessions can
+// keep running meanwhile. The archive is a tar file holding the manifest and
+// the log's copy.
+func (s *Store) Backup(ctx context.Context, w io.Writer) (*BackupManifest, error) {
+	backups, ok := s.log.(BackupLog)
+	if !ok {
+		return nil, fmt.Errorf("storage backend does not support backups")
+	}
+
+	// The data member's size goes in its tar header, so spool it first
+	spool, err := os.CreateTemp("", "iteratr-backup-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer func() {
+		_ = spool.Close()
+		_ = os.Remove(spool.Name())
+	}()
+
+	hash := sha256.New()
+	data, err := backups.Backup(ctx, io.MultiWriter(spool, hash))
+	if err != nil {
+		return nil, fmt.Errorf("failed to back up event log: %w", err)
+	}
+	size, err := spool.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := s.log.Sessions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	sort.Strings(sessions)
+
+	manifest := &BackupManifest{
+		Version:    backupVersion,
+		Kind:       backups.BackupKind(),
+		CreatedAt:  time.Now().UTC(),
+		Sessions:   sessions,
+		BackupData: *data,
+		Size:       size,
+		SHA256:     hex.EncodeToString(hash.Sum(nil)),
+	}
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	tw := tar.NewWriter(w)
+	if err := writeTarMember(tw, backupManifestName, int64(len(manifestData)), bytes.NewReader(manifestData)); err != nil {
+		return nil, err
+	}
+	if err := writeTarMember(tw, backupDataName, size, spool); err != nil {
+		return nil, err
+	}
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write backup: %w", err)
+	}
+	logger.Debug("Backed up %d messages of %d sessions (%d bytes)", manifest.Messages, len(sessions), size)
+	return manifest, nil
+}
+
+// writeTarMember writes one file of size bytes read from r to tw.
+func writeTarMember(tw *tar.Writer, name string, size int64, r io.Reader) error {
+	header := &tar.Header{Name: name, Mode: 0644, Size: size, ModTime: time.Now()}
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to write backup: %w", err)
+	}
+	if _, err := io.Copy(tw, r); err != nil {
+		return fmt.Errorf("failed to write backup: %w", err)
+	}
+	return nil
+}
+
+// VerifyBackup reads a whole backup archive and checks it against its
+// manifest, without restoring anything.
+func VerifyBackup(r io.Reader) (*BackupManifest, error) {
+	return readBackup(r, io.Discard)
+}
+
+// readBackup reads a backup archive, copying its data member to data, and
+// returns the manifest once the data matched its checksum.
+func readBackup(r io.Reader, data io.Writer) (*BackupManifest, error) {
+	tr := tar.NewReader(r)
+
+	header, err := tr.Next()
+	if err != nil || header.Name != backupManifestName {
+		return nil, fmt.Errorf("%w: missing manifest", ErrBackupCorrupt)
+	}
+	var manifest BackupManifest
+	if err := json.NewDecoder(tr).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("%w: invalid manifest: %v", ErrBackupCorrupt, err)
+	}
+	if manifest.Version > backupVersion {
+		return nil, fmt.Errorf("backup was written by a newer version of iteratr (format %d, supported %d)", manifest.Version, backupVersion)
+	}
+
+	header, err = tr.Next()
+	if err != nil || header.Name != backupDataName {
+		return nil, fmt.Errorf("%w: missing data", ErrBackupCorrupt)
+	}
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(data, hash), tr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBackupCorrupt, err)
+	}
+	if size != manifest.Size || hex.EncodeToString(hash.Sum(nil)) != manifest.SHA256 {
+		return nil, fmt.Errorf("%w: data does not match its checksum", ErrBackupCorrupt)
+	}
+	return &manifest, nil
+}
+
+// Restore replaces the whole event log with the contents of a backup archive
+// written by Backup. The archive is verified before anything is changed. It
+// refuses to restore while any session, in the log or in the backup, is run
+// by another process: the sessions' leases are held until the restore is
+// done. Snapshots and summaries of those sessions are dropped, as they were
+// built from the replaced events.
+func (s *Store) Restore(ctx context.Context, r io.Reader) (*BackupManifest, error) {
+	backups, ok := s.log.(BackupLog)
+	if !ok {
+		return nil, fmt.Errorf("storage backend does not support backups")
+	}
+
+	spool, err := os.CreateTemp("", "iteratr-restore-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer func() {
+		_ = spool.Close()
+		_ = os.Remove(spool.Name())
+	}()
+	manifest, err := readBackup(r, spool)
+	if err != nil {
+		return nil, err
+	}
+	if manifest.Kind != backups.BackupKind() {
+		return nil, fmt.Errorf("backup of %s storage can't be restored into %s storage", manifest.Kind, backups.BackupKind())
+	}
+
+	current, err := s.log.Sessions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	sessions := unionSorted(current, manifest.Sessions)
+	release, err := s.lockSessions(ctx, sessions)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	// The backend checks the copy against the manifest before it replaces
+	// anything
+	if err := backups.Restore(ctx, &manifest.BackupData, spool); err != nil {
+		return nil, fmt.Errorf("failed to restore event log: %w", err)
+	}
+
+	if snapshots, ok := s.log.(SnapshotStore); ok {
+		if err := snapshots.SetupSnapshots(ctx); err != nil {
+			return nil, err
+		}
+		for _, session := range sessions {
+			if err := snapshots.DeleteSnapshot(ctx, session); err != nil {
+				return nil, fmt.Errorf("failed to delete snapshot of session %s: %w", session, err)
+			}
+			if err := snapshots.DeleteSnapshot(ctx, summaryKey(session)); err != nil {
+				return nil, fmt.Errorf("failed to delete summary of session %s: %w", session, err)
+			}
+		}
+	}
+	logger.Info("Restored %d messages of %d sessions from backup of %s", manifest.Messages, len(manifest.Sessions), manifest.CreatedAt.Format(time.RFC3339))
+	return manifest, nil
+}
+
+// lockSessions acquires the leases of sessions, failing if any of them is run
+// by another process. The returned function releases them.
+func (s *Store) lockSessions(ctx context.Context, sessions []string) (func(), error) {
+	var leases []*Lease
+	release := func() {
+		for _, lease := range leases {
+			_ = lease.Release(context.Background())
+		}
+	}
+	for _, session := range sessions {
+		lease, err := s.AcquireLease(ctx, session)
+		if err != nil {
+			release()
+			return nil, fmt.Errorf("session %s is running, stop it first: %w", session, err)
+		}
+		leases = append(leases, lease)
+	}
+	return release, nil
+}
+
+// unionSorted returns the sorted, unique elements of a and b.
+func unionSorted(a, b []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, list := range [][]string{a, b} {
+		for _, item := range list {
+			if !seen[item] {
+				seen[item] = true
+				result = append(result, item)
+			}
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// BackupToDir writes a backup archive into dir, named after the current time,
+// and then deletes the oldest archives in dir beyond keep (0 = keep all).
+// Returns the archive's path.
+func (s *Store) BackupToDir(ctx context.Context, dir string, keep int) (string, *BackupManifest, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", nil, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	// Named to the millisecond, later if taken, so names sort by age
+	var path string
+	for at := time.Now(); ; at = at.Add(time.Millisecond) {
+		path = filepath.Join(dir, backupFilePrefix+at.Format("20060102-150405.000")+backupFileExt)
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			break
+		}
+	}
+
+	// Written under a temporary name so that a failed backup never looks complete
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create backup file: %w", err)
+	}
+	manifest, err := s.Backup(ctx, f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return "", nil, err
+	}
+
+	if keep > 0 {
+		backups, err := ListBackups(dir)
+		if err != nil {
+			logger.Warn("Failed to list old backups: %v", err)
+		}
+		for len(backups) > keep {
+			if err := os.Remove(backups[0]); err != nil {
+				logger.Warn("Failed to delete old backup %s: %v", backups[0], err)
+			}
+			backups = backups[1:]
+		}
+	}
+	return path, manifest, nil
+}
+
+// ListBackups returns the paths of the archives written by BackupToDir in
+// dir, oldest first.
+func ListBackups(dir string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, backupFilePrefix+"*"+backupFileExt))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths) // The timestamp in the name sorts chronologically
+	return paths, nil
+}
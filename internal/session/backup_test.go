@@ -0,0 +1,242 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestBackupRestore(t *testing.T) {
+	for name, open := range eventLogBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			store := NewStore(open(t))
+			if err := store.EnableSnapshots(ctx); err != nil {
+				t.Fatalf("EnableSnapshots failed: %v", err)
+			}
+			_, _ = store.TaskAdd(ctx, "alpha", TaskAddParams{Content: "First"})
+			_, _ = store.TaskAdd(ctx, "alpha", TaskAddParams{Content: "Second"})
+			_, _ = store.NoteAdd(ctx, "beta", NoteAddParams{Content: "Note", Type: "tip"})
+
+			var buf bytes.Buffer
+			manifest, err := store.Backup(ctx, &buf)
+			if err != nil {
+				t.Fatalf("Backup failed: %v", err)
+			}
+			if !reflect.DeepEqual(manifest.Sessions, []string{"alpha", "beta"}) || manifest.Messages != 3 {
+				t.Fatalf("unexpected manifest: %+v", manifest)
+			}
+			if _, err := VerifyBackup(bytes.NewReader(buf.Bytes())); err != nil {
+				t.Fatalf("VerifyBackup failed: %v", err)
+			}
+
+			// Changes made after the backup are undone by the restore
+			_, _ = store.TaskAdd(ctx, "alpha", TaskAddParams{Content: "Third"})
+			_, _ = store.TaskAdd(ctx, "gamma", TaskAddParams{Content: "Other"})
+			if _, err := store.LoadState(ctx, "alpha"); err != nil {
+				t.Fatalf("LoadState failed: %v", err)
+			}
+
+			// A running session blocks the restore
+			lease, err := store.AcquireLease(ctx, "gamma")
+			if err != nil {
+				t.Fatalf("AcquireLease failed: %v", err)
+			}
+			if _, err := store.Restore(ctx, bytes.NewReader(buf.Bytes())); !errors.Is(err, ErrSessionLocked) {
+				t.Fatalf("expected ErrSessionLocked, got %v", err)
+			}
+			_ = lease.Release(ctx)
+
+			if _, err := store.Restore(ctx, bytes.NewReader(buf.Bytes())); err != nil {
+				t.Fatalf("Restore failed: %v", err)
+			}
+			if sessions, _ := store.log.Sessions(ctx); !reflect.DeepEqual(sortedCopy(sessions), []string{"alpha", "beta"}) {
+				t.Errorf("expected sessions of the backup, got %v", sessions)
+			}
+			state, err := store.LoadState(ctx, "alpha")
+			if err != nil {
+				t.Fatalf("LoadState failed: %v", err)
+			}
+			if len(state.Tasks) != 2 {
+				t.Errorf("expected 2 tasks after restore, got %d", len(state.Tasks))
+			}
+
+			// The restored log takes new writes
+			task, err := store.TaskAdd(ctx, "alpha", TaskAddParams{Content: "After"})
+			if err != nil {
+				t.Fatalf("TaskAdd after restore failed: %v", err)
+			}
+			if state, _ := store.LoadState(ctx, "alpha"); len(state.Tasks) != 3 || state.Tasks[task.ID] == nil {
+				t.Errorf("expected new task after restore, got %d tasks", len(state.Tasks))
+			}
+		})
+	}
+}
+
+func TestRestore_Rejected(t *testing.T) {
+	ctx := context.Background()
+	source := NewStore(NewMemoryLog())
+	_, _ = source.TaskAdd(ctx, "alpha", TaskAddParams{Content: "Task"})
+	var buf bytes.Buffer
+	if _, err := source.Backup(ctx, &buf); err != nil {
+		t.Fatalf("Backup failed: %v", err)
+	}
+
+	t.Run("corrupt", func(t *testing.T) {
+		corrupt := bytes.Replace(buf.Bytes(), []byte("iteratr.alpha.task"), []byte("iteratr.alpha.tusk"), 1)
+		if _, err := VerifyBackup(bytes.NewReader(corrupt)); !errors.Is(err, ErrBackupCorrupt) {
+			t.Fatalf("expected ErrBackupCorrupt, got %v", err)
+		}
+		target := NewStore(NewMemoryLog())
+		_, _ = target.TaskAdd(ctx, "beta", TaskAddParams{Content: "Kept"})
+		if _, err := target.Restore(ctx, bytes.NewReader(corrupt)); !errors.Is(err, ErrBackupCorrupt) {
+			t.Fatalf("expected ErrBackupCorrupt, got %v", err)
+		}
+		if sessions, _ := target.log.Sessions(ctx); !reflect.DeepEqual(sessions, []string{"beta"}) {
+			t.Errorf("expected log untouched, got sessions %v", sessions)
+		}
+	})
+
+	t.Run("other storage", func(t *testing.T) {
+		log, err := OpenFileLog(t.TempDir())
+		if err != nil {
+			t.Fatalf("OpenFileLog failed: %v", err)
+		}
+		defer func() { _ = log.Close() }()
+		if _, err := NewStore(log).Restore(ctx, bytes.NewReader(buf.Bytes())); err == nil {
+			t.Error("expected memory backup to be rejected by file storage")
+		}
+	})
+}
+
+func TestFileLog_RestoreSharedFile(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	open := func() *FileLog {
+		log, err := OpenFileLog(dir)
+		if err != nil {
+			t.Fatalf("OpenFileLog failed: %v", err)
+		}
+		t.Cleanup(func() { _ = log.Close() })
+		return log
+	}
+	restorer, other := open(), open()
+	store := NewStore(restorer)
+	_, _ = store.TaskAdd(ctx, "alpha", TaskAddParams{Content: "Task"})
+
+	var buf bytes.Buffer
+	if _, err := store.Backup(ctx, &buf); err != nil {
+		t.Fatalf("Backup failed: %v", err)
+	}
+	// Grows the file past the backup, so a stale offset would land mid-line
+	for i := 0; i < 3; i++ {
+		_, _ = NewStore(other).TaskAdd(ctx, "beta", TaskAddParams{Content: "Long task content to grow the file"})
+	}
+	if _, err := store.Restore(ctx, bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	_, _ = store.TaskAdd(ctx, "alpha", TaskAddParams{Content: "After"})
+
+	if sessions, _ := other.Sessions(ctx); !reflect.DeepEqual(sessions, []string{"alpha"}) {
+		t.Errorf("expected other process to see the restored file, got sessions %v", sessions)
+	}
+	if count, _ := other.Count(ctx, "iteratr.alpha.>"); count != 2 {
+		t.Errorf("expected 2 messages of alpha, got %d", count)
+	}
+}
+
+func TestRestore_MismatchKeepsLog(t *testing.T) {
+	for name, open := range eventLogBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			log := open(t)
+			store := NewStore(log)
+			_, _ = store.TaskAdd(ctx, "alpha", TaskAddParams{Content: "Backed up"})
+
+			var data bytes.Buffer
+			backup, err := log.(BackupLog).Backup(ctx, &data)
+			if err != nil {
+				t.Fatalf("Backup failed: %v", err)
+			}
+			_, _ = store.TaskAdd(ctx, "alpha", TaskAddParams{Content: "Current"})
+
+			// A manifest that doesn't match its data, e.g. edited by hand
+			wrong := *backup
+			wrong.Messages++
+			if err := log.(BackupLog).Restore(ctx, &wrong, bytes.NewReader(data.Bytes())); err == nil {
+				t.Fatal("expected restore of mismatched copy to fail")
+			}
+
+			state, err := store.LoadState(ctx, "alpha")
+			if err != nil {
+				t.Fatalf("LoadState failed: %v", err)
+			}
+			if len(state.Tasks) != 2 {
+				t.Errorf("expected the current 2 tasks to be kept, got %d", len(state.Tasks))
+			}
+		})
+	}
+}
+
+func TestJetStreamLog_RestoreFailureKeepsStream(t *testing.T) {
+	ctx := context.Background()
+	log := eventLogBackends(t)["jetstream"](t).(*JetStreamLog)
+	store := NewStore(log)
+	_, _ = store.TaskAdd(ctx, "alpha", TaskAddParams{Content: "Backed up"})
+
+	var data bytes.Buffer
+	backup, err := log.Backup(ctx, &data)
+	if err != nil {
+		t.Fatalf("Backup failed: %v", err)
+	}
+	_, _ = store.TaskAdd(ctx, "alpha", TaskAddParams{Content: "Current"})
+
+	// Cut short, so the server fails the restore after the stream was deleted
+	truncated := bytes.NewReader(data.Bytes()[:data.Len()/2])
+	if err := log.Restore(ctx, backup, truncated); err == nil {
+		t.Fatal("expected truncated snapshot to fail")
+	}
+
+	state, err := store.LoadState(ctx, "alpha")
+	if err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+	if len(state.Tasks) != 2 {
+		t.Errorf("expected the current 2 tasks to be put back, got %d", len(state.Tasks))
+	}
+	if _, err := store.TaskAdd(ctx, "alpha", TaskAddParams{Content: "After"}); err != nil {
+		t.Errorf("TaskAdd after failed restore failed: %v", err)
+	}
+}
+
+func TestBackupToDir(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	for _, name := range []string{"iteratr-20200101-000000.000.tar", "iteratr-20200102-000000.000.tar", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+	}
+	store := NewStore(NewMemoryLog())
+	_, _ = store.TaskAdd(ctx, "alpha", TaskAddParams{Content: "Task"})
+
+	path, _, err := store.BackupToDir(ctx, dir, 2)
+	if err != nil {
+		t.Fatalf("BackupToDir failed: %v", err)
+	}
+	backups, err := ListBackups(dir)
+	if err != nil {
+		t.Fatalf("ListBackups failed: %v", err)
+	}
+	want := []string{filepath.Join(dir, "iteratr-20200102-000000.000.tar"), path}
+	if !reflect.DeepEqual(backups, want) {
+		t.Errorf("expected oldest backup pruned, got %v", backups)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "notes.txt")); err != nil {
+		t.Errorf("expected other files kept: %v", err)
+	}
+}
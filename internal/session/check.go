@@ -0,0 +1,93 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/iteratr/internal/nats"
+)
+
+// KnowledgeCheckName is the EventCheck name of the knowledge base.
+const KnowledgeCheckName = "knowledge"
+
+// EventCheck is the result of validating the events of one session or of
+// the knowledge base.
+type EventCheck struct {
+	Name     string         // Session name, or KnowledgeCheckName
+	Events   int            // Events read
+	Outdated int            // Events of older schema versions, upcast on read
+	Problems []EventProblem // Events that cannot be applied
+}
+
+// EventProblem is an event that cannot be applied. Err wraps ErrStateCorruption.
+type EventProblem struct {
+	Sequence uint64
+	Err      error
+}
+
+// CheckEvents replays the state events of every session and the knowledge
+// base through their reducers and reports the events that cannot be applied:
+// malformed JSON, bad or incomplete metadata, unknown types or actions, and
+// schema versions newer than this build. Transcripts are not checked.
+func (s *Store) CheckEvents(ctx context.Context) ([]EventCheck, error) {
+	sessions, err := s.log.Sessions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	var checks []EventCheck
+	for _, name := range sessions {
+		state := &State{Session: name, Tasks: make(map[string]*Task)}
+		check, err := s.checkEvents(ctx, name, nats.SubjectForSessionEvents(name), func(event Event) error {
+			if event.Session != name {
+				return fmt.Errorf("%w: event %s belongs to session %q", ErrStateCorruption, event.ID, event.Session)
+			}
+			return state.Apply(event)
+		})
+		if err != nil {
+			return nil, err
+		}
+		checks = append(checks, check)
+	}
+
+	kb := &Knowledge{}
+	check, err := s.checkEvents(ctx, KnowledgeCheckName, nats.SubjectKnowledge, kb.Apply)
+	if err != nil {
+		return nil, err
+	}
+	if check.Events > 0 {
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
+
+// checkEvents applies each event on filter with apply and collects the failures.
+func (s *Store) checkEvents(ctx context.Context, name, filter string, apply func(Event) error) (EventCheck, error) {
+	check := EventCheck{Name: name}
+	_, err := s.forEachMsg(ctx, filter, 0, func(seq uint64, data []byte) bool {
+		check.Events++
+		var event Event
+		if err := json.Unmarshal(data, &event); err != nil {
+			check.Problems = append(check.Problems, EventProblem{
+				Sequence: seq,
+				Err:      fmt.Errorf("%w: malformed event: %v", ErrStateCorruption, err),
+			})
+			return true
+		}
+		if event.ID == "" {
+			event.ID = fmt.Sprintf("%d", seq)
+		}
+		if event.Version < EventVersion {
+			check.Outdated++
+		}
+		if err := apply(event); err != nil {
+			check.Problems = append(check.Problems, EventProblem{Sequence: seq, Err: err})
+		}
+		return true
+	})
+	if err != nil {
+		return check, fmt.Errorf("failed to read events of %s: %w", name, err)
+	}
+	return check, nil
+}
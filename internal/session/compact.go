@@ -0,0 +1,211 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/iteratr/internal/logger"
+	"github.com/mark3labs/iteratr/internal/nats"
+)
+
+// CompactSession rewrites the state events of a session into the smallest
+// event set that rebuilds the same state: one add per task and note plus
+// whatever events are needed for dependencies, last-update times, iterations
+// and completion. The rewritten events follow a "compacted" control event that
+// resets the state, and everything before it is purged, so readers see the
+// same state before, during and after the rewrite. Transcripts are not touched.
+// Returns the number of state events before and after compaction.
+//
+// Events published by other writers while compacting are republished after the
+// rewritten ones, but the session should not be running.
+func (s *Store) CompactSession(ctx context.Context, session string) (before, after int, err error) {
+	state, loadSeq, err := s.loadState(ctx, session)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to load session state: %w", err)
+	}
+	count, err := s.log.Count(ctx, nats.SubjectForSessionEvents(session))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to count session events: %w", err)
+	}
+	if count == 0 {
+		return 0, 0, fmt.Errorf("session %q has no events", session)
+	}
+
+	events := compactEvents(state)
+	if err := verifyCompaction(state, events); err != nil {
+		return 0, 0, err
+	}
+
+	// The marker resets the state, so nothing before it counts from here on
+	markerSeq, err := s.PublishEvent(ctx, events[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to publish compaction marker: %w", err)
+	}
+	if err := s.publishAll(ctx, session, events[1:]); err != nil {
+		return 0, 0, fmt.Errorf("failed to publish compacted events: %w", err)
+	}
+
+	// Carry over events other writers published since the state was loaded
+	var missed []Event
+	_, err = s.forEachEvent(ctx, session, loadSeq, func(seq uint64, event Event) bool {
+		if seq >= markerSeq {
+			return false
+		}
+		missed = append(missed, event)
+		return true
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read concurrent events: %w", err)
+	}
+	if err := s.publishAll(ctx, session, missed); err != nil {
+		return 0, 0, fmt.Errorf("failed to republish concurrent events: %w", err)
+	}
+
+	if err := s.deleteSnapshot(ctx, session); err != nil {
+		return 0, 0, err
+	}
+	if err := s.log.PurgeBefore(ctx, nats.SubjectForSessionEvents(session), markerSeq); err != nil {
+		return 0, 0, fmt.Errorf("failed to purge compacted events: %w", err)
+	}
+
+	after = len(events) + len(missed)
+	logger.Info("Compacted session '%s' from %d to %d events", session, count, after)
+	return int(count), after, nil
+}
+
+// compactEvents builds the events that rebuild state from scratch, starting
+// with the compaction marker.
+func compactEvents(state *State) []Event {
+	var events []Event
+	add := func(event Event, meta any) {
+		event.Session = state.Session
+		event.Meta = encodeMeta(meta)
+		events = append(events, event)
+	}
+
+	// Counters continue from where the removed tasks left them
+	add(Event{Type: nats.EventTypeControl, Action: "compacted", Data: "Session history compacted"}, CompactedMeta{
+		TaskCounter: state.TaskCounter - len(state.Tasks),
+		NoteCounter: state.NoteCounter - len(state.Notes),
+	})
+
+	// Tasks in creation order, as they were originally added
+	tasks := make([]*Task, 0, len(state.Tasks))
+	for _, task := range state.Tasks {
+		tasks = append(tasks, task)
+	}
+	sortTasksByCreation(tasks)
+	for _, task := range tasks {
+		add(Event{ID: task.ID, Timestamp: task.CreatedAt, Type: nats.EventTypeTask, Action: "add", Data: task.Content}, TaskAddMeta{
+			Status:     task.Status,
+			Priority:   task.Priority,
+			ParentID:   task.ParentID,
+			Acceptance: task.Acceptance,
+			Verify:     task.Verify,
+			Labels:     task.Labels,
+			Iteration:  task.Iteration,
+		})
+		for _, dep := range task.DependsOn {
+			add(Event{Timestamp: task.UpdatedAt, Type: nats.EventTypeTask, Action: "depends", Data: dep},
+				TaskDependsMeta{TaskID: task.ID, DependsOn: dep, Iteration: task.Iteration})
+		}
+		if len(task.DependsOn) == 0 && !task.UpdatedAt.Equal(task.CreatedAt) {
+			// Restore the last update time with a no-op status change
+			add(Event{Timestamp: task.UpdatedAt, Type: nats.EventTypeTask, Action: "status", Data: task.Status},
+				TaskStatusMeta{TaskID: task.ID, Status: task.Status, Iteration: task.Iteration})
+		}
+	}
+
+	for _, note := range state.Notes {
+		add(Event{ID: note.ID, Timestamp: note.CreatedAt, Type: nats.EventTypeNote, Action: "add", Data: note.Content},
+			NoteAddMeta{Type: note.Type, Iteration: note.Iteration})
+	}
+
+	for _, iter := range state.Iterations {
+		add(Event{Timestamp: iter.StartedAt, Type: nats.EventTypeIteration, Action: "start", Data: fmt.Sprintf("Iteration %d started", iter.Number)},
+			IterationStartMeta{Number: iter.Number})
+		if iter.Outcome != "" {
+			add(Event{Timestamp: iter.EndedAt, Type: nats.EventTypeIteration, Action: "complete", Data: fmt.Sprintf("Iteration %d %s", iter.Number, iter.Outcome)},
+				IterationCompleteMeta{Number: iter.Number, IterationResult: iter.IterationResult})
+		}
+		if iter.Summary != "" || len(iter.TasksWorked) > 0 {
+			add(Event{Timestamp: iter.EndedAt, Type: nats.EventTypeIteration, Action: "summary", Data: fmt.Sprintf("Iteration %d: %s", iter.Number, iter.Summary)},
+				IterationSummaryMeta{Number: iter.Number, Summary: iter.Summary, TasksWorked: iter.TasksWorked})
+		}
+		if iter.Usage != (Usage{}) {
+			add(Event{Timestamp: iter.EndedAt, Type: nats.EventTypeIteration, Action: "usage", Data: fmt.Sprintf("Iteration %d usage", iter.Number)},
+				IterationUsageMeta{Number: iter.Number, Usage: iter.Usage})
+		}
+	}
+
+	if state.Complete {
+		add(Event{Type: nats.EventTypeControl, Action: "session_complete", Data: "Session marked as complete"}, nil)
+	}
+	return events
+}
+
+// verifyCompaction checks that replaying events rebuilds state exactly, so a
+// compaction never publishes anything that would change the session.
+func verifyCompaction(state *State, events []Event) error {
+	rebuilt := &State{Session: state.Session, Tasks: make(map[string]*Task)}
+	for _, event := range events {
+		// Round-trip through JSON like events read back from the log
+		data, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to marshal compacted event: %w", err)
+		}
+		var stored Event
+		if err := json.Unmarshal(data, &stored); err != nil {
+			return fmt.Errorf("failed to unmarshal compacted event: %w", err)
+		}
+		if err := rebuilt.Apply(stored); err != nil {
+			return fmt.Errorf("compacted event does not apply: %w", err)
+		}
+	}
+
+	want, err := json.Marshal(normalizeState(state))
+	if err != nil {
+		return fmt.Errorf("failed to marshal state: %w", err)
+	}
+	got, err := json.Marshal(normalizeState(rebuilt))
+	if err != nil {
+		return fmt.Errorf("failed to marshal compacted state: %w", err)
+	}
+	if string(want) != string(got) {
+		return fmt.Errorf("compacted events do not rebuild session %q, nothing was changed", state.Session)
+	}
+	return nil
+}
+
+// normalizeState returns a copy of state with empty lists set to nil, which
+// the reducer does not tell apart.
+func normalizeState(state *State) *State {
+	normalized := *state
+	normalized.Tasks = make(map[string]*Task, len(state.Tasks))
+	for id, task := range state.Tasks {
+		t := *task
+		t.DependsOn = nilIfEmpty(t.DependsOn)
+		t.Labels = nilIfEmpty(t.Labels)
+		normalized.Tasks[id] = &t
+	}
+	normalized.Iterations = make([]*Iteration, len(state.Iterations))
+	for i, iter := range state.Iterations {
+		it := *iter
+		it.TasksWorked = nilIfEmpty(it.TasksWorked)
+		it.InterruptedTasks = nilIfEmpty(it.InterruptedTasks)
+		if len(it.Files) == 0 {
+			it.Files = nil
+		}
+		normalized.Iterations[i] = &it
+	}
+	return &normalized
+}
+
+// nilIfEmpty returns nil for an empty list.
+func nilIfEmpty(list []string) []string {
+	if len(list) == 0 {
+		return nil
+	}
+	return list
+}
@@ -0,0 +1,107 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/iteratr/internal/nats"
+)
+
+func TestCompactSession(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore(NewMemoryLog())
+	if err := store.EnableSnapshots(ctx); err != nil {
+		t.Fatalf("EnableSnapshots failed: %v", err)
+	}
+
+	// Build a history with plenty of superseded events
+	_ = store.IterationStart(ctx, "s", 1)
+	_, _ = store.TaskAdd(ctx, "s", TaskAddParams{Content: "Parent", Priority: 1, Iteration: 1})
+	_, _ = store.TaskAdd(ctx, "s", TaskAddParams{Content: "Child", ParentID: "TAS-1", Labels: []string{"backend"}, Iteration: 1})
+	_, _ = store.TaskAdd(ctx, "s", TaskAddParams{Content: "Dropped", Iteration: 1})
+	_, _ = store.TaskAdd(ctx, "s", TaskAddParams{Content: "Blocker", Priority: 0, Iteration: 1})
+	_ = store.TaskDepends(ctx, "s", TaskDependsParams{ID: "TAS-2", DependsOn: "TAS-3", Iteration: 1})
+	_ = store.TaskDepends(ctx, "s", TaskDependsParams{ID: "TAS-2", DependsOn: "TAS-4", Iteration: 1})
+	_ = store.TaskRemove(ctx, "s", TaskRemoveParams{ID: "TAS-3", Iteration: 1})
+	for _, status := range []string{"in_progress", "blocked", "in_progress", "completed"} {
+		_ = store.TaskStatus(ctx, "s", TaskStatusParams{ID: "TAS-1", Status: status, Iteration: 1})
+	}
+	_ = store.TaskEdit(ctx, "s", TaskEditParams{ID: "TAS-4", Content: "Blocker, edited", Iteration: 1})
+	_ = store.TaskLabel(ctx, "s", TaskLabelParams{ID: "TAS-2", Add: []string{"tests"}, Remove: []string{"backend"}, Iteration: 1})
+	_, _ = store.NoteAdd(ctx, "s", NoteAddParams{Content: "Learned something", Type: "learning", Iteration: 1})
+	_ = store.IterationUsage(ctx, "s", 1, Usage{InputTokens: 10, OutputTokens: 5})
+	_ = store.IterationUsage(ctx, "s", 1, Usage{InputTokens: 20, OutputTokens: 7, Cost: 0.5})
+	_ = store.IterationSummary(ctx, "s", 1, "Did the parent", []string{"TAS-1"})
+	_ = store.IterationComplete(ctx, "s", 1, IterationResult{Model: "anthropic/claude-sonnet-4-5", Duration: time.Minute})
+	_ = store.IterationStart(ctx, "s", 2)
+	_ = store.IterationAbandon(ctx, "s", 2, []string{"TAS-2"})
+	_ = store.AppendTranscript(ctx, "s", TranscriptEntry{Iteration: 1, Kind: "text", Content: "hello"})
+	if err := store.SaveSnapshot(ctx, "s"); err != nil {
+		t.Fatalf("SaveSnapshot failed: %v", err)
+	}
+
+	wantState, _ := store.LoadState(ctx, "s")
+	want, _ := json.Marshal(normalizeState(wantState))
+
+	before, after, err := store.CompactSession(ctx, "s")
+	if err != nil {
+		t.Fatalf("CompactSession failed: %v", err)
+	}
+	if after >= before {
+		t.Errorf("expected fewer events after compaction, got %d -> %d", before, after)
+	}
+	if count, _ := store.log.Count(ctx, nats.SubjectForSessionEvents("s")); count != uint64(after) {
+		t.Errorf("expected %d events in the log, got %d", after, count)
+	}
+
+	t.Run("state unchanged", func(t *testing.T) {
+		state, err := store.LoadState(ctx, "s")
+		if err != nil {
+			t.Fatalf("LoadState failed: %v", err)
+		}
+		if got, _ := json.Marshal(normalizeState(state)); string(got) != string(want) {
+			t.Errorf("state changed by compaction:\nwant %s\ngot  %s", want, got)
+		}
+	})
+
+	t.Run("IDs continue after removed tasks", func(t *testing.T) {
+		task, err := store.TaskAdd(ctx, "s", TaskAddParams{Content: "New"})
+		if err != nil {
+			t.Fatalf("TaskAdd failed: %v", err)
+		}
+		if task.ID != "TAS-5" {
+			t.Errorf("expected TAS-5, got %s", task.ID)
+		}
+		note, _ := store.NoteAdd(ctx, "s", NoteAddParams{Content: "Another", Type: "tip"})
+		if note.ID != "NOT-2" {
+			t.Errorf("expected NOT-2, got %s", note.ID)
+		}
+	})
+
+	t.Run("transcripts kept", func(t *testing.T) {
+		entries, err := store.Transcript(ctx, "s", 1)
+		if err != nil || len(entries) != 1 {
+			t.Errorf("expected transcript to survive compaction, got %d entries (%v)", len(entries), err)
+		}
+	})
+
+	t.Run("compacting again is stable", func(t *testing.T) {
+		state, _ := store.LoadState(ctx, "s")
+		want, _ := json.Marshal(normalizeState(state))
+		if _, _, err := store.CompactSession(ctx, "s"); err != nil {
+			t.Fatalf("CompactSession failed: %v", err)
+		}
+		state, _ = store.LoadState(ctx, "s")
+		if got, _ := json.Marshal(normalizeState(state)); string(got) != string(want) {
+			t.Errorf("state changed by second compaction:\nwant %s\ngot  %s", want, got)
+		}
+	})
+
+	t.Run("unknown session", func(t *testing.T) {
+		if _, _, err := store.CompactSession(ctx, "missing"); err == nil {
+			t.Error("expected error compacting a session without events")
+		}
+	})
+}
@@ -0,0 +1,258 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/mark3labs/iteratr/internal/nats"
+)
+
+// concurrentStores returns n stores on one embedded NATS server, each with its
+// own connection like separate writer processes.
+func concurrentStores(t *testing.T, n int) []*Store {
+	ctx := context.Background()
+	ns, _, err := nats.StartEmbeddedNATS(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to start NATS: %v", err)
+	}
+	t.Cleanup(ns.Shutdown)
+
+	stores := make([]*Store, n)
+	for i := range stores {
+		nc, err := nats.ConnectInProcess(ns)
+		if err != nil {
+			t.Fatalf("failed to connect to NATS: %v", err)
+		}
+		t.Cleanup(nc.Close)
+
+		js, err := nats.CreateJetStream(nc)
+		if err != nil {
+			t.Fatalf("failed to create JetStream: %v", err)
+		}
+
+		stream, err := nats.SetupStream(ctx, js)
+		if err != nil {
+			t.Fatalf("failed to setup stream: %v", err)
+		}
+		stores[i] = NewStore(NewJetStreamLog(js, stream))
+	}
+	return stores
+}
+
+func TestConcurrentIDAllocation(t *testing.T) {
+	ctx := context.Background()
+	const (
+		writers        = 8
+		tasksPerWriter = 10
+		batchWriters   = 2
+		batchSize      = 5
+		noteWriters    = 4
+		notesPerWriter = 10
+	)
+	session := "concurrent"
+	stores := concurrentStores(t, 3)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, writers*tasksPerWriter+batchWriters+noteWriters*notesPerWriter)
+	ids := make(chan string, writers*tasksPerWriter+batchWriters*batchSize+noteWriters*notesPerWriter)
+
+	// Single-task writers (agent via MCP, TUI, CLI)
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			store := stores[w%len(stores)]
+			for i := 0; i < tasksPerWriter; i++ {
+				task, err := store.TaskAdd(ctx, session, TaskAddParams{
+					Content: fmt.Sprintf("writer %d task %d", w, i),
+				})
+				if err != nil {
+					errs <- err
+					continue
+				}
+				ids <- task.ID
+			}
+		}(w)
+	}
+
+	// Batch writers
+	for w := 0; w < batchWriters; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			store := stores[w%len(stores)]
+			params := make([]TaskAddParams, batchSize)
+			for i := range params {
+				params[i] = TaskAddParams{Content: fmt.Sprintf("batch %d task %d", w, i)}
+			}
+			tasks, err := store.TaskBatchAdd(ctx, session, params)
+			if err != nil {
+				errs <- err
+				return
+			}
+			for _, task := range tasks {
+				ids <- task.ID
+			}
+		}(w)
+	}
+
+	// Note writers
+	for w := 0; w < noteWriters; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			store := stores[w%len(stores)]
+			for i := 0; i < notesPerWriter; i++ {
+				note, err := store.NoteAdd(ctx, session, NoteAddParams{
+					Content: fmt.Sprintf("writer %d note %d", w, i),
+					Type:    "learning",
+				})
+				if err != nil {
+					errs <- err
+					continue
+				}
+				ids <- note.ID
+			}
+		}(w)
+	}
+
+	wg.Wait()
+	close(errs)
+	close(ids)
+
+	for err := range errs {
+		t.Errorf("concurrent write failed: %v", err)
+	}
+
+	// Every returned ID must be unique
+	seen := make(map[string]bool)
+	for id := range ids {
+		if seen[id] {
+			t.Errorf("duplicate ID returned: %s", id)
+		}
+		seen[id] = true
+	}
+
+	wantTasks := writers*tasksPerWriter + batchWriters*batchSize
+	wantNotes := noteWriters * notesPerWriter
+
+	state, err := stores[0].LoadState(ctx, session)
+	if err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+
+	// TaskCounter counts add events; len(Tasks) counts distinct IDs.
+	// They only match if no add event reused an existing ID.
+	if state.TaskCounter != wantTasks {
+		t.Errorf("expected %d task add events, got %d", wantTasks, state.TaskCounter)
+	}
+	if len(state.Tasks) != wantTasks {
+		t.Errorf("expected %d distinct tasks, got %d", wantTasks, len(state.Tasks))
+	}
+	for i := 1; i <= wantTasks; i++ {
+		if _, ok := state.Tasks[fmt.Sprintf("TAS-%d", i)]; !ok {
+			t.Errorf("expected TAS-%d to exist", i)
+		}
+	}
+
+	if len(state.Notes) != wantNotes {
+		t.Errorf("expected %d notes, got %d", wantNotes, len(state.Notes))
+	}
+	noteIDs := make(map[string]bool)
+	for _, note := range state.Notes {
+		if noteIDs[note.ID] {
+			t.Errorf("duplicate note ID in state: %s", note.ID)
+		}
+		noteIDs[note.ID] = true
+	}
+}
+
+func TestConcurrentEditRemove(t *testing.T) {
+	ctx := context.Background()
+	stores := concurrentStores(t, 3)
+	session := "concurrent-edit"
+
+	// Each task depends on a base task and is edited, undepended and removed
+	// at the same time by different writers
+	const tasks = 10
+	base, err := stores[0].TaskAdd(ctx, session, TaskAddParams{Content: "Base"})
+	if err != nil {
+		t.Fatalf("TaskAdd failed: %v", err)
+	}
+	ids := make([]string, tasks)
+	for i := range ids {
+		task, err := stores[0].TaskAdd(ctx, session, TaskAddParams{Content: fmt.Sprintf("task %d", i)})
+		if err != nil {
+			t.Fatalf("TaskAdd failed: %v", err)
+		}
+		if err := stores[0].TaskDepends(ctx, session, TaskDependsParams{ID: task.ID, DependsOn: base.ID}); err != nil {
+			t.Fatalf("TaskDepends failed: %v", err)
+		}
+		ids[i] = task.ID
+	}
+
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(4)
+		go func() {
+			defer wg.Done()
+			_ = stores[0].TaskEdit(ctx, session, TaskEditParams{ID: id, Content: fmt.Sprintf("edited %d", i)})
+		}()
+		go func() {
+			defer wg.Done()
+			_ = stores[1].TaskUndepend(ctx, session, TaskDependsParams{ID: id, DependsOn: base.ID})
+		}()
+		go func() {
+			defer wg.Done()
+			_ = stores[2].TaskRemove(ctx, session, TaskRemoveParams{ID: id})
+		}()
+		go func() {
+			// Two writers giving different tasks the same content
+			defer wg.Done()
+			_ = stores[i%3].TaskEdit(ctx, session, TaskEditParams{ID: id, Content: "same"})
+		}()
+	}
+	wg.Wait()
+
+	// No change lands on a task after its removal, and no two tasks share
+	// content at any point
+	events, err := stores[0].History(ctx, session)
+	if err != nil {
+		t.Fatalf("History failed: %v", err)
+	}
+	removed := make(map[string]bool)
+	same := make(map[string]bool) // Live tasks with content "same"
+	for _, event := range events {
+		var meta struct {
+			TaskID string `json:"task_id"`
+		}
+		_ = DecodeMeta(event.Event, &meta)
+		switch event.Action {
+		case "remove":
+			if removed[meta.TaskID] {
+				t.Errorf("%s removed twice", meta.TaskID)
+			}
+			removed[meta.TaskID] = true
+			delete(same, meta.TaskID)
+		case "edit", "undepend":
+			if removed[meta.TaskID] {
+				t.Errorf("%s of %s after its removal", event.Action, meta.TaskID)
+			}
+			if event.Action != "edit" {
+				continue
+			}
+			if event.Data != "same" {
+				delete(same, meta.TaskID)
+			} else if len(same) > 0 && !same[meta.TaskID] {
+				t.Errorf("%s edited to content another task already has", meta.TaskID)
+			} else {
+				same[meta.TaskID] = true
+			}
+		}
+	}
+	if len(removed) != tasks {
+		t.Errorf("expected %d tasks removed, got %d", tasks, len(removed))
+	}
+}
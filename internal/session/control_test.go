@@ -38,7 +38,7 @@ func TestSessionComplete(t *testing.T) {
 	}
 
 	// Create store
-	store := NewStore(js, stream)
+	store := NewStore(NewJetStreamLog(js, stream))
 	sessionName := "test-session"
 
 	// Mark session as complete
@@ -88,7 +88,7 @@ func TestSessionCompleteMultipleTimes(t *testing.T) {
 	}
 
 	// Create store
-	store := NewStore(js, stream)
+	store := NewStore(NewJetStreamLog(js, stream))
 	sessionName := "test-session-multi"
 
 	// Mark session as complete multiple times (should be idempotent)
@@ -143,7 +143,7 @@ func TestSessionCompleteWithTasks(t *testing.T) {
 	}
 
 	// Create store
-	store := NewStore(js, stream)
+	store := NewStore(NewJetStreamLog(js, stream))
 	sessionName := "test-session-tasks"
 
 	// Add tasks in terminal states (completed, blocked, cancelled)
@@ -226,7 +226,7 @@ func TestSessionCompleteWithIncompleteTasks(t *testing.T) {
 	}
 
 	// Create store
-	store := NewStore(js, stream)
+	store := NewStore(NewJetStreamLog(js, stream))
 	sessionName := "test-session-incomplete"
 
 	// Add a remaining task (non-terminal state)
@@ -300,7 +300,7 @@ func TestSessionCompleteWithInProgressTask(t *testing.T) {
 	}
 
 	// Create store
-	store := NewStore(js, stream)
+	store := NewStore(NewJetStreamLog(js, stream))
 	sessionName := "test-session-in-progress"
 
 	// Add an in_progress task (non-terminal state)
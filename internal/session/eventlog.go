@@ -0,0 +1,142 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+)
+
+// Storage backends selectable by name (config key "storage").
+const (
+	StorageNATS = "nats" // JetStream stream on an embedded NATS server (default)
+	StorageFile = "file" // Append-only JSONL file in the data directory
+)
+
+// Message is one entry of an event log: a serialized event or transcript entry
+// together with its subject and position in the log.
+type Message struct {
+	Sequence uint64
+	Subject  string
+	Data     []byte
+}
+
+// ErrSequenceConflict is returned by EventLog.AppendIfLast when another message
+// was appended to the subject after the expected sequence was read.
+var ErrSequenceConflict = errors.New("concurrent write conflict")
+
+// EventLog is the append-only storage behind a Store. Messages are addressed by
+// NATS-style subjects (iteratr.{session}.{type}); filters may use the "*" (one
+// token) and ">" (one or more trailing tokens) wildcards. Sequences increase
+// across the whole log and are never reused, not even after a purge.
+type EventLog interface {
+	// Append stores data on subject and returns its sequence.
+	Append(ctx context.Context, subject string, data []byte) (uint64, error)
+
+	// AppendIfLast is Append that fails with ErrSequenceConflict unless the
+	// last message on subject has sequence lastSeq (0 = no message yet).
+	AppendIfLast(ctx context.Context, subject string, data []byte, lastSeq uint64) (uint64, error)
+
+	// LastSequence returns the sequence of the last message on subject (which
+	// may be a filter), or of the whole log if subject is empty. Returns 0 if
+	// there is none.
+	LastSequence(ctx context.Context, subject string) (uint64, error)
+
+	// Read calls fn for each message matching filter with a sequence greater
+	// than afterSeq, in log order, until fn returns false.
+	Read(ctx context.Context, filter string, afterSeq uint64, fn func(Message) bool) error
+
+	// Count returns the number of messages matching filter.
+	Count(ctx context.Context, filter string) (uint64, error)
+
+	// Purge removes all messages matching filter.
+	Purge(ctx context.Context, filter string) error
+
+	// PurgeBefore removes the messages matching filter with a lower sequence
+	// than seq.
+	PurgeBefore(ctx context.Context, filter string, seq uint64) error
+
+	// Sessions returns the names of all sessions that have messages.
+	Sessions(ctx context.Context) ([]string, error)
+
+	// Watch delivers the messages matching filter with a sequence greater than
+	// afterSeq in log order: first those already stored, then new ones as any
+	// writer appends them. The channel is closed when ctx is done.
+	Watch(ctx context.Context, filter string, afterSeq uint64) (<-chan Message, error)
+
+	// Close releases resources held by the log.
+	Close() error
+}
+
+// SnapshotStore holds the latest state snapshot of each session. Event logs
+// that can also keep snapshots implement it.
+type SnapshotStore interface {
+	// SetupSnapshots prepares snapshot storage. Safe to call repeatedly.
+	SetupSnapshots(ctx context.Context) error
+
+	// GetSnapshot returns the stored snapshot of a session, or ErrNoSnapshot.
+	GetSnapshot(ctx context.Context, session string) ([]byte, error)
+
+	// PutSnapshot replaces the snapshot of a session.
+	PutSnapshot(ctx context.Context, session string, data []byte) error
+
+	// DeleteSnapshot removes the snapshot of a session, if any.
+	DeleteSnapshot(ctx context.Context, session string) error
+}
+
+// LeaseStore holds the ownership lease of each session. Writes compare and
+// set a per-session revision, so of several processes racing for a lease
+// exactly one wins. Event logs shared between processes implement it.
+type LeaseStore interface {
+	// SetupLeases prepares lease storage. Safe to call repeatedly.
+	SetupLeases(ctx context.Context) error
+
+	// GetLease returns the stored lease of a session and its revision, or
+	// ErrNoLease.
+	GetLease(ctx context.Context, session string) ([]byte, uint64, error)
+
+	// PutLease stores the lease of a session and returns its new revision. It
+	// fails with ErrSequenceConflict unless the stored lease still has
+	// revision rev (0 = no lease).
+	PutLease(ctx context.Context, session string, data []byte, rev uint64) (uint64, error)
+
+	// DeleteLease removes the lease of a session, if any. It fails with
+	// ErrSequenceConflict unless the stored lease still has revision rev
+	// (0 = any revision).
+	DeleteLease(ctx context.Context, session string, rev uint64) error
+}
+
+// subjectMatches reports whether subject matches a NATS-style filter.
+func subjectMatches(filter, subject string) bool {
+	filterTokens := strings.Split(filter, ".")
+	subjectTokens := strings.Split(subject, ".")
+	for i, token := range filterTokens {
+		if token == ">" {
+			return len(subjectTokens) > i
+		}
+		if i >= len(subjectTokens) {
+			return false
+		}
+		if token != "*" && token != subjectTokens[i] {
+			return false
+		}
+	}
+	return len(filterTokens) == len(subjectTokens)
+}
+
+// sessionsOf returns the sorted, unique session names of subjects of the form
+// iteratr.{session}.{...}.
+func sessionsOf(subjects []string) []string {
+	seen := make(map[string]bool)
+	var sessions []string
+	for _, subject := range subjects {
+		tokens := strings.SplitN(subject, ".", 3)
+		if len(tokens) < 3 || tokens[0] != "iteratr" || seen[tokens[1]] {
+			continue
+		}
+		seen[tokens[1]] = true
+		sessions = append(sessions, tokens[1])
+	}
+	sort.Strings(sessions)
+	return sessions
+}
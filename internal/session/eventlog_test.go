@@ -0,0 +1,425 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/iteratr/internal/nats"
+	natsgo "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// eventLogBackends returns a constructor for each EventLog implementation.
+func eventLogBackends(t *testing.T) map[string]func(t *testing.T) EventLog {
+	return map[string]func(t *testing.T) EventLog{
+		"jetstream": func(t *testing.T) EventLog {
+			nc, js := startJetStreamConn(t)
+			stream, err := nats.SetupStream(context.Background(), js)
+			if err != nil {
+				t.Fatalf("failed to setup stream: %v", err)
+			}
+			return NewJetStreamLog(js, stream).WithConn(nc)
+		},
+		"jetstream layout": func(t *testing.T) EventLog {
+			// Next to a default stream, as on a server shared with other projects
+			nc, js := startJetStreamConn(t)
+			if _, err := nats.SetupStream(context.Background(), js); err != nil {
+				t.Fatalf("failed to setup default stream: %v", err)
+			}
+			layout := nats.Layout{Stream: "team_events", SubjectPrefix: "team.iteratr", SnapshotBucket: "team_snapshots"}
+			stream, err := nats.SetupLayoutStream(context.Background(), js, layout, nats.Retention{})
+			if err != nil {
+				t.Fatalf("failed to setup stream: %v", err)
+			}
+			return NewJetStreamLogWithLayout(js, stream, layout).WithConn(nc)
+		},
+		"memory": func(t *testing.T) EventLog {
+			return NewMemoryLog()
+		},
+		"file": func(t *testing.T) EventLog {
+			log, err := OpenFileLog(t.TempDir())
+			if err != nil {
+				t.Fatalf("OpenFileLog failed: %v", err)
+			}
+			t.Cleanup(func() { _ = log.Close() })
+			return log
+		},
+	}
+}
+
+// startJetStream starts an embedded NATS server and returns a JetStream
+// context on it.
+func startJetStream(t *testing.T) jetstream.JetStream {
+	_, js := startJetStreamConn(t)
+	return js
+}
+
+// startJetStreamConn is startJetStream that also returns the connection.
+func startJetStreamConn(t *testing.T) (*natsgo.Conn, jetstream.JetStream) {
+	ns, _, err := nats.StartEmbeddedNATS(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to start NATS: %v", err)
+	}
+	t.Cleanup(ns.Shutdown)
+
+	nc, err := nats.ConnectInProcess(ns)
+	if err != nil {
+		t.Fatalf("failed to connect to NATS: %v", err)
+	}
+	t.Cleanup(nc.Close)
+
+	js, err := nats.CreateJetStream(nc)
+	if err != nil {
+		t.Fatalf("failed to create JetStream: %v", err)
+	}
+	return nc, js
+}
+
+// readAll returns the subjects and data of the messages matching filter.
+func readAll(t *testing.T, log EventLog, filter string, afterSeq uint64) []string {
+	t.Helper()
+	var result []string
+	err := log.Read(context.Background(), filter, afterSeq, func(msg Message) bool {
+		result = append(result, msg.Subject+"="+string(msg.Data))
+		return true
+	})
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	return result
+}
+
+func TestEventLogBackends(t *testing.T) {
+	for name, open := range eventLogBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			log := open(t)
+
+			seq1, _ := log.Append(ctx, "iteratr.a.task", []byte(`1`))
+			seq2, _ := log.Append(ctx, "iteratr.a.note", []byte(`2`))
+			seq3, _ := log.Append(ctx, "iteratr.a.transcript.1", []byte(`3`))
+			seq4, err := log.Append(ctx, "iteratr.b.task", []byte(`4`))
+			if err != nil {
+				t.Fatalf("Append failed: %v", err)
+			}
+			if !(seq1 < seq2 && seq2 < seq3 && seq3 < seq4) {
+				t.Fatalf("expected increasing sequences, got %d %d %d %d", seq1, seq2, seq3, seq4)
+			}
+
+			t.Run("read filters subjects", func(t *testing.T) {
+				if got := readAll(t, log, "iteratr.a.*", 0); !reflect.DeepEqual(got, []string{"iteratr.a.task=1", "iteratr.a.note=2"}) {
+					t.Errorf("unexpected state events: %v", got)
+				}
+				if got := readAll(t, log, "iteratr.a.>", seq1); len(got) != 2 {
+					t.Errorf("expected 2 messages after seq %d, got %v", seq1, got)
+				}
+				if got := readAll(t, log, "iteratr.*.task", 0); len(got) != 2 {
+					t.Errorf("expected 2 task messages, got %v", got)
+				}
+			})
+
+			t.Run("read stops early", func(t *testing.T) {
+				count := 0
+				_ = log.Read(ctx, "iteratr.>", 0, func(Message) bool {
+					count++
+					return false
+				})
+				if count != 1 {
+					t.Errorf("expected 1 message, got %d", count)
+				}
+			})
+
+			t.Run("last sequence and count", func(t *testing.T) {
+				if last, _ := log.LastSequence(ctx, "iteratr.a.task"); last != seq1 {
+					t.Errorf("expected last task sequence %d, got %d", seq1, last)
+				}
+				if last, err := log.LastSequence(ctx, "iteratr.a.*"); last != seq2 {
+					t.Errorf("expected last state event sequence %d, got %d (%v)", seq2, last, err)
+				}
+				if last, _ := log.LastSequence(ctx, "iteratr.c.task"); last != 0 {
+					t.Errorf("expected 0 for unknown subject, got %d", last)
+				}
+				if last, _ := log.LastSequence(ctx, ""); last != seq4 {
+					t.Errorf("expected log last sequence %d, got %d", seq4, last)
+				}
+				if count, _ := log.Count(ctx, "iteratr.a.>"); count != 3 {
+					t.Errorf("expected 3 messages in session a, got %d", count)
+				}
+				if sessions, _ := log.Sessions(ctx); !reflect.DeepEqual(sortedCopy(sessions), []string{"a", "b"}) {
+					t.Errorf("expected sessions [a b], got %v", sessions)
+				}
+			})
+
+			t.Run("append if last", func(t *testing.T) {
+				if _, err := log.AppendIfLast(ctx, "iteratr.a.task", []byte(`5`), seq1-1); !errors.Is(err, ErrSequenceConflict) {
+					t.Errorf("expected ErrSequenceConflict, got %v", err)
+				}
+				seq, err := log.AppendIfLast(ctx, "iteratr.a.task", []byte(`5`), seq1)
+				if err != nil {
+					t.Fatalf("AppendIfLast failed: %v", err)
+				}
+				if _, err := log.AppendIfLast(ctx, "iteratr.c.task", []byte(`6`), 0); err != nil {
+					t.Errorf("expected first append to new subject to succeed, got %v", err)
+				}
+				if last, _ := log.LastSequence(ctx, "iteratr.a.task"); last != seq {
+					t.Errorf("expected last task sequence %d, got %d", seq, last)
+				}
+			})
+
+			t.Run("purge keeps sequences", func(t *testing.T) {
+				before, _ := log.LastSequence(ctx, "")
+				if err := log.Purge(ctx, "iteratr.b.>"); err != nil {
+					t.Fatalf("Purge failed: %v", err)
+				}
+				if count, _ := log.Count(ctx, "iteratr.b.>"); count != 0 {
+					t.Errorf("expected session b purged, got %d messages", count)
+				}
+				if count, _ := log.Count(ctx, "iteratr.a.>"); count != 4 {
+					t.Errorf("expected session a untouched, got %d messages", count)
+				}
+				seq, _ := log.Append(ctx, "iteratr.b.task", []byte(`7`))
+				if seq <= before {
+					t.Errorf("expected sequence after %d, got %d", before, seq)
+				}
+			})
+
+			t.Run("purge before sequence", func(t *testing.T) {
+				_, _ = log.Append(ctx, "iteratr.p.task", []byte(`"old"`))
+				keep, _ := log.Append(ctx, "iteratr.p.task", []byte(`"new"`))
+				if err := log.PurgeBefore(ctx, "iteratr.p.>", keep); err != nil {
+					t.Fatalf("PurgeBefore failed: %v", err)
+				}
+				if got := readAll(t, log, "iteratr.p.>", 0); len(got) != 1 || got[0] != `iteratr.p.task="new"` {
+					t.Errorf("expected only the message at the purge sequence, got %v", got)
+				}
+			})
+
+			t.Run("watch delivers stored and new messages", func(t *testing.T) {
+				_, _ = log.Append(ctx, "iteratr.w.task", []byte(`0`))
+				stored, _ := log.Append(ctx, "iteratr.w.task", []byte(`1`))
+
+				watchCtx, cancel := context.WithCancel(ctx)
+				msgs, err := log.Watch(watchCtx, "iteratr.w.*", stored-1)
+				if err != nil {
+					t.Fatalf("Watch failed: %v", err)
+				}
+
+				_, _ = log.Append(ctx, "iteratr.w.transcript.1", []byte(`"skipped"`))
+				_, _ = log.Append(ctx, "iteratr.x.task", []byte(`"skipped"`))
+				for i := 2; i <= 3; i++ {
+					_, _ = log.Append(ctx, "iteratr.w.task", []byte(fmt.Sprintf("%d", i)))
+				}
+
+				for i := 1; i <= 3; i++ {
+					select {
+					case msg := <-msgs:
+						if string(msg.Data) != fmt.Sprintf("%d", i) {
+							t.Errorf("expected message %d, got %s on %s", i, msg.Data, msg.Subject)
+						}
+					case <-time.After(5 * time.Second):
+						t.Fatalf("timed out waiting for message %d", i)
+					}
+				}
+
+				cancel()
+				for range msgs {
+				}
+			})
+
+			t.Run("snapshots", func(t *testing.T) {
+				snapshots := log.(SnapshotStore)
+				if err := snapshots.SetupSnapshots(ctx); err != nil {
+					t.Fatalf("SetupSnapshots failed: %v", err)
+				}
+				if _, err := snapshots.GetSnapshot(ctx, "a"); !errors.Is(err, ErrNoSnapshot) {
+					t.Errorf("expected ErrNoSnapshot, got %v", err)
+				}
+				_ = snapshots.PutSnapshot(ctx, "a", []byte(`{"v":1}`))
+				_ = snapshots.PutSnapshot(ctx, "a", []byte(`{"v":2}`))
+				if data, err := snapshots.GetSnapshot(ctx, "a"); err != nil || string(data) != `{"v":2}` {
+					t.Errorf("expected latest snapshot, got %s (%v)", data, err)
+				}
+				if err := snapshots.DeleteSnapshot(ctx, "a"); err != nil {
+					t.Fatalf("DeleteSnapshot failed: %v", err)
+				}
+				if _, err := snapshots.GetSnapshot(ctx, "a"); !errors.Is(err, ErrNoSnapshot) {
+					t.Errorf("expected ErrNoSnapshot after delete, got %v", err)
+				}
+			})
+		})
+	}
+}
+
+func sortedCopy(list []string) []string {
+	result := append([]string(nil), list...)
+	sort.Strings(result)
+	return result
+}
+
+func TestJetStreamLog_Layout(t *testing.T) {
+	ctx := context.Background()
+	js := startJetStream(t)
+	defaultStream, err := nats.SetupStream(ctx, js)
+	if err != nil {
+		t.Fatalf("failed to setup default stream: %v", err)
+	}
+	layout := nats.Layout{Stream: "team_events", SubjectPrefix: "team"}
+	stream, err := nats.SetupLayoutStream(ctx, js, layout, nats.Retention{})
+	if err != nil {
+		t.Fatalf("failed to setup stream: %v", err)
+	}
+	store := NewStore(NewJetStreamLogWithLayout(js, stream, layout))
+	if err := store.EnableSnapshots(ctx); err != nil {
+		t.Fatalf("EnableSnapshots failed: %v", err)
+	}
+	_, _ = store.TaskAdd(ctx, "shared", TaskAddParams{Content: "Task"})
+
+	info, _ := stream.Info(ctx, jetstream.WithSubjectFilter(">"))
+	if info.State.Subjects["team.shared.task"] != 1 {
+		t.Errorf("expected event on team.shared.task, got subjects %v", info.State.Subjects)
+	}
+	if info, _ := defaultStream.Info(ctx); info.State.Msgs != 0 {
+		t.Errorf("expected default stream untouched, got %d messages", info.State.Msgs)
+	}
+	if _, err := js.KeyValue(ctx, nats.SnapshotBucket); err == nil {
+		t.Error("expected default snapshot bucket not to be created")
+	}
+	if infos, _ := store.ListSessions(ctx); len(infos) != 1 || infos[0].Name != "shared" || infos[0].TasksTotal != 1 {
+		t.Errorf("expected session shared with 1 task, got %+v", infos)
+	}
+
+	if err := (nats.Layout{SubjectPrefix: "team.*"}).Validate(); err == nil {
+		t.Error("expected wildcard prefix to be rejected")
+	}
+	if err := (nats.Layout{Stream: "team.events"}).Validate(); err == nil {
+		t.Error("expected stream name with a dot to be rejected")
+	}
+}
+
+func TestFileLog_SharedBetweenProcesses(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	// Two logs on the same directory stand in for two processes
+	first, err := OpenFileLog(dir)
+	if err != nil {
+		t.Fatalf("OpenFileLog failed: %v", err)
+	}
+	defer func() { _ = first.Close() }()
+	second, err := OpenFileLog(dir)
+	if err != nil {
+		t.Fatalf("OpenFileLog failed: %v", err)
+	}
+	defer func() { _ = second.Close() }()
+
+	firstStore := NewStore(first)
+	secondStore := NewStore(second)
+
+	t.Run("IDs stay unique across writers", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			store := firstStore
+			if i%2 == 1 {
+				store = secondStore
+			}
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				if _, err := store.TaskAdd(ctx, "shared", TaskAddParams{Content: fmt.Sprintf("Task %d", i)}); err != nil {
+					t.Errorf("TaskAdd failed: %v", err)
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		state, err := secondStore.LoadState(ctx, "shared")
+		if err != nil {
+			t.Fatalf("LoadState failed: %v", err)
+		}
+		if len(state.Tasks) != 10 || state.TaskCounter != 10 {
+			t.Errorf("expected 10 distinct tasks, got %d (counter %d)", len(state.Tasks), state.TaskCounter)
+		}
+	})
+
+	t.Run("watch sees other writer", func(t *testing.T) {
+		watchCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		events, err := secondStore.Watch(watchCtx, "shared")
+		if err != nil {
+			t.Fatalf("Watch failed: %v", err)
+		}
+
+		if _, err := firstStore.NoteAdd(ctx, "shared", NoteAddParams{Content: "hello", Type: "tip"}); err != nil {
+			t.Fatalf("NoteAdd failed: %v", err)
+		}
+		select {
+		case event := <-events:
+			if event.Type != nats.EventTypeNote || event.Data != "hello" {
+				t.Errorf("unexpected event: %+v", event)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for event from other writer")
+		}
+	})
+
+	t.Run("reopen replays file", func(t *testing.T) {
+		if err := firstStore.ResetSession(ctx, "shared"); err != nil {
+			t.Fatalf("ResetSession failed: %v", err)
+		}
+		_, _ = firstStore.TaskAdd(ctx, "other", TaskAddParams{Content: "Kept"})
+
+		reopened, err := OpenFileLog(dir)
+		if err != nil {
+			t.Fatalf("OpenFileLog failed: %v", err)
+		}
+		defer func() { _ = reopened.Close() }()
+		store := NewStore(reopened)
+
+		sessions, _ := reopened.Sessions(ctx)
+		if !reflect.DeepEqual(sessions, []string{"other"}) {
+			t.Errorf("expected only session other after reset, got %v", sessions)
+		}
+		state, _ := store.LoadState(ctx, "other")
+		if len(state.Tasks) != 1 || state.Tasks["TAS-1"] == nil {
+			t.Errorf("expected TAS-1 in reopened session, got %+v", state.Tasks)
+		}
+	})
+}
+
+func TestStore_MemoryLog(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore(NewMemoryLog())
+	if err := store.EnableSnapshots(ctx); err != nil {
+		t.Fatalf("EnableSnapshots failed: %v", err)
+	}
+
+	_, _ = store.TaskAdd(ctx, "mem", TaskAddParams{Content: "First"})
+	if err := store.SaveSnapshot(ctx, "mem"); err != nil {
+		t.Fatalf("SaveSnapshot failed: %v", err)
+	}
+	_, _ = store.TaskAdd(ctx, "mem", TaskAddParams{Content: "Second"})
+
+	state, err := store.LoadState(ctx, "mem")
+	if err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+	if len(state.Tasks) != 2 || state.Tasks["TAS-2"].Content != "Second" {
+		t.Errorf("expected two tasks from snapshot and replay, got %+v", state.Tasks)
+	}
+
+	if err := store.RenameSession(ctx, "mem", "renamed"); err != nil {
+		t.Fatalf("RenameSession failed: %v", err)
+	}
+	if exists, _ := store.SessionExists(ctx, "mem"); exists {
+		t.Error("expected old session to be gone after rename")
+	}
+	if infos, _ := store.ListSessions(ctx); len(infos) != 1 || infos[0].Name != "renamed" || infos[0].TasksTotal != 2 {
+		t.Errorf("unexpected sessions after rename: %+v", infos)
+	}
+}
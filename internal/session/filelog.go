@@ -0,0 +1,601 @@
+package session
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/iteratr/internal/logger"
+)
+
+const (
+	// EventFileName is the append-only event file of the file storage backend.
+	EventFileName = "events.jsonl"
+
+	// eventLockName is the lock file guarding writes to the event file.
+	eventLockName = "events.lock"
+
+	// snapshotDirName holds one snapshot file per session.
+	snapshotDirName = "snapshots"
+
+	// leaseDirName holds one lease file per session.
+	leaseDirName = "leases"
+
+	// staleLockAge is how old a lock file must be before it is assumed to be
+	// left behind by a crashed process and removed.
+	staleLockAge = 30 * time.Second
+
+	// lockTimeout bounds how long a write waits for the lock.
+	lockTimeout = 10 * time.Second
+
+	// filePollInterval is how often watchers check the file for writes made
+	// by other processes.
+	filePollInterval = 250 * time.Millisecond
+)
+
+// fileRecord is one line of the event file: either a message or, if Purge is
+// set, a purge of the messages matching that filter (only those with a
+// sequence below Before, if set).
+type fileRecord struct {
+	Seq     uint64          `json:"seq"`
+	Subject string          `json:"subject,omitempty"`
+	Data    json.RawMessage `json:"data,omitempty"`
+	Purge   string          `json:"purge,omitempty"`
+	Before  uint64          `json:"before,omitempty"`
+}
+
+// FileLog is an EventLog stored as a single append-only JSONL file in the
+// data directory, for running without NATS. Several processes can share the
+// file: writes are serialized with a lock file and each process picks up the
+// others' writes by reading the file from where it left off. Purges are
+// appended as records too, so sequences are never reused. Snapshots and
+// leases are kept as one JSON file per session.
+type FileLog struct {
+	mu     sync.Mutex
+	dir    string
+	file   *os.File
+	offset int64 // Bytes of the file applied to index
+	index  *messageIndex
+}
+
+// OpenFileLog opens (or creates) the event file in dataDir and loads it.
+func OpenFileLog(dataDir string) (*FileLog, error) {
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+	path := filepath.Join(dataDir, EventFileName)
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open event file: %w", err)
+	}
+
+	l := &FileLog{
+		dir:   dataDir,
+		file:  file,
+		index: newMessageIndex(),
+	}
+	if err := l.refresh(); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	logger.Debug("Opened event file %s (%d messages)", path, len(l.index.msgs))
+	return l, nil
+}
+
+// refresh applies records written since the last refresh, by this or another
+// process. A trailing partial line is left for the next refresh. The caller
+// must hold l.mu.
+func (l *FileLog) refresh() error {
+	info, err := l.file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat event file: %w", err)
+	}
+	if current, err := os.Stat(l.path()); err == nil && !os.SameFile(info, current) {
+		// Another process restored a backup over the file
+		if err := l.reopen(); err != nil {
+			return err
+		}
+		info = current
+	}
+	size := info.Size()
+	if size < l.offset {
+		// The file was replaced with a shorter one; start over
+		l.resetIndex()
+	}
+	if size == l.offset {
+		return nil
+	}
+
+	buf := make([]byte, size-l.offset)
+	if _, err := l.file.ReadAt(buf, l.offset); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to read event file: %w", err)
+	}
+	for {
+		end := bytes.IndexByte(buf, '\n')
+		if end < 0 {
+			break
+		}
+		l.apply(buf[:end])
+		buf = buf[end+1:]
+		l.offset += int64(end + 1)
+	}
+	return nil
+}
+
+// resetIndex empties the index so the file is read from the start again.
+// Watchers keep their wake channel. The caller must hold l.mu.
+func (l *FileLog) resetIndex() {
+	l.offset = 0
+	changed := l.index.changed
+	l.index = newMessageIndex()
+	l.index.changed = changed
+}
+
+// reopen opens the event file again after it was replaced and resets the
+// index. The caller must hold l.mu.
+func (l *FileLog) reopen() error {
+	file, err := os.OpenFile(l.path(), os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to reopen event file: %w", err)
+	}
+	_ = l.file.Close()
+	l.file = file
+	l.resetIndex()
+	return nil
+}
+
+// path returns the event file's path.
+func (l *FileLog) path() string {
+	return filepath.Join(l.dir, EventFileName)
+}
+
+// apply adds one line of the event file to the index.
+func (l *FileLog) apply(line []byte) {
+	applyRecord(l.index, line, l.offset)
+}
+
+// applyRecord adds one line of an event file, found at offset, to index.
+func applyRecord(index *messageIndex, line []byte, offset int64) {
+	if len(bytes.TrimSpace(line)) == 0 {
+		return
+	}
+	var record fileRecord
+	if err := json.Unmarshal(line, &record); err != nil {
+		logger.Warn("Skipping malformed line in event file at offset %d: %v", offset, err)
+		return
+	}
+	if record.Purge != "" {
+		index.purge(record.Purge, record.Before)
+		return
+	}
+	index.add(Message{Sequence: record.Seq, Subject: record.Subject, Data: record.Data})
+}
+
+// indexFile reads the complete lines of an event file into a new index.
+func indexFile(path string) (*messageIndex, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	index := newMessageIndex()
+	reader := bufio.NewReader(f)
+	var offset int64
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			// A trailing partial line is not applied, as by refresh
+			return index, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		applyRecord(index, line[:len(line)-1], offset)
+		offset += int64(len(line))
+	}
+}
+
+// write appends a record while holding the lock file. check, if set, runs
+// against the up-to-date index and can reject the write.
+func (l *FileLog) write(ctx context.Context, record fileRecord, check func() error) (uint64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	unlock, err := l.lock(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	if err := l.refresh(); err != nil {
+		return 0, err
+	}
+	if check != nil {
+		if err := check(); err != nil {
+			return 0, err
+		}
+	}
+
+	if record.Purge == "" {
+		record.Seq = l.index.lastSeq + 1
+	} else {
+		record.Seq = l.index.lastSeq
+	}
+	line, err := json.Marshal(record)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode record: %w", err)
+	}
+	line = append(line, '\n')
+	if _, err := l.file.Write(line); err != nil {
+		return 0, fmt.Errorf("failed to write event file: %w", err)
+	}
+
+	l.apply(line[:len(line)-1])
+	l.offset += int64(len(line))
+	return record.Seq, nil
+}
+
+// lock creates the lock file, waiting while another process holds it.
+// Returns a function that releases the lock.
+func (l *FileLog) lock(ctx context.Context) (func(), error) {
+	path := filepath.Join(l.dir, eventLockName)
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			_, _ = fmt.Fprintf(f, "%d\n", os.Getpid())
+			_ = f.Close()
+			return func() { _ = os.Remove(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to create lock file: %w", err)
+		}
+
+		if info, statErr := os.Stat(path); statErr == nil && time.Since(info.ModTime()) > staleLockAge {
+			logger.Warn("Removing stale event file lock %s", path)
+			_ = os.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for event file lock %s", path)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+}
+
+// Append writes data to subject. data must be JSON.
+func (l *FileLog) Append(ctx context.Context, subject string, data []byte) (uint64, error) {
+	if !json.Valid(data) {
+		return 0, fmt.Errorf("file storage only accepts JSON data")
+	}
+	return l.write(ctx, fileRecord{Subject: subject, Data: data}, nil)
+}
+
+// AppendIfLast writes data to subject if its last sequence is lastSeq.
+func (l *FileLog) AppendIfLast(ctx context.Context, subject string, data []byte, lastSeq uint64) (uint64, error) {
+	if !json.Valid(data) {
+		return 0, fmt.Errorf("file storage only accepts JSON data")
+	}
+	return l.write(ctx, fileRecord{Subject: subject, Data: data}, func() error {
+		if l.index.last(subject) != lastSeq {
+			return ErrSequenceConflict
+		}
+		return nil
+	})
+}
+
+// view runs fn against the up-to-date index.
+func (l *FileLog) view(fn func(index *messageIndex)) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.refresh(); err != nil {
+		return err
+	}
+	fn(l.index)
+	return nil
+}
+
+// LastSequence returns the last sequence of subject, or of the log.
+func (l *FileLog) LastSequence(ctx context.Context, subject string) (uint64, error) {
+	var seq uint64
+	err := l.view(func(index *messageIndex) { seq = index.last(subject) })
+	return seq, err
+}
+
+// Read calls fn for the matching messages. The log is not locked while fn runs.
+func (l *FileLog) Read(ctx context.Context, filter string, afterSeq uint64, fn func(Message) bool) error {
+	var msgs []Message
+	if err := l.view(func(index *messageIndex) { msgs = index.matching(filter, afterSeq) }); err != nil {
+		return err
+	}
+	for _, msg := range msgs {
+		if !fn(msg) {
+			break
+		}
+	}
+	return nil
+}
+
+// Count returns the number of messages matching filter.
+func (l *FileLog) Count(ctx context.Context, filter string) (uint64, error) {
+	var count uint64
+	err := l.view(func(index *messageIndex) { count = uint64(len(index.matching(filter, 0))) })
+	return count, err
+}
+
+// Purge appends a purge record for filter.
+func (l *FileLog) Purge(ctx context.Context, filter string) error {
+	_, err := l.write(ctx, fileRecord{Purge: filter}, nil)
+	return err
+}
+
+// PurgeBefore appends a purge record for the messages matching filter with a
+// lower sequence than seq.
+func (l *FileLog) PurgeBefore(ctx context.Context, filter string, seq uint64) error {
+	_, err := l.write(ctx, fileRecord{Purge: filter, Before: seq}, nil)
+	return err
+}
+
+// Sessions returns the sessions that have messages.
+func (l *FileLog) Sessions(ctx context.Context) ([]string, error) {
+	var sessions []string
+	err := l.view(func(index *messageIndex) { sessions = index.sessions() })
+	return sessions, err
+}
+
+// Watch delivers stored and new messages after afterSeq, polling the file for
+// writes by other processes.
+func (l *FileLog) Watch(ctx context.Context, filter string, afterSeq uint64) (<-chan Message, error) {
+	return watchLog(ctx, l, filter, afterSeq, l.wake, filePollInterval), nil
+}
+
+// wake returns a channel that is closed on the next append seen by this process.
+func (l *FileLog) wake() <-chan struct{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.index.changed
+}
+
+// Close closes the event file.
+func (l *FileLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.file.Close()
+}
+
+// SetupSnapshots creates the snapshot directory.
+func (l *FileLog) SetupSnapshots(ctx context.Context) error {
+	return os.MkdirAll(filepath.Join(l.dir, snapshotDirName), 0755)
+}
+
+// snapshotPath returns the snapshot file of a session.
+func (l *FileLog) snapshotPath(session string) string {
+	return filepath.Join(l.dir, snapshotDirName, session+".json")
+}
+
+// GetSnapshot reads a session's snapshot file.
+func (l *FileLog) GetSnapshot(ctx context.Context, session string) ([]byte, error) {
+	data, err := os.ReadFile(l.snapshotPath(session))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNoSnapshot
+	}
+	return data, err
+}
+
+// PutSnapshot replaces a session's snapshot file atomically.
+func (l *FileLog) PutSnapshot(ctx context.Context, session string, data []byte) error {
+	path := l.snapshotPath(session)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// DeleteSnapshot removes a session's snapshot file, if any.
+func (l *FileLog) DeleteSnapshot(ctx context.Context, session string) error {
+	if err := os.Remove(l.snapshotPath(session)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// fileLease is the content of a lease file: the lease and its revision. A
+// deleted lease leaves the file without a lease, so revisions keep increasing
+// and a former holder can't take the lease back with its old revision.
+type fileLease struct {
+	Revision uint64          `json:"revision"`
+	Lease    json.RawMessage `json:"lease,omitempty"`
+}
+
+// SetupLeases creates the lease directory.
+func (l *FileLog) SetupLeases(ctx context.Context) error {
+	return os.MkdirAll(filepath.Join(l.dir, leaseDirName), 0755)
+}
+
+// leasePath returns the lease file of a session.
+func (l *FileLog) leasePath(session string) string {
+	return filepath.Join(l.dir, leaseDirName, session+".json")
+}
+
+// readLease reads a session's lease file. A missing file is a deleted lease
+// at revision 0.
+func (l *FileLog) readLease(session string) (fileLease, error) {
+	var lease fileLease
+	data, err := os.ReadFile(l.leasePath(session))
+	if errors.Is(err, os.ErrNotExist) {
+		return lease, nil
+	}
+	if err != nil {
+		return lease, err
+	}
+	if err := json.Unmarshal(data, &lease); err != nil {
+		return lease, fmt.Errorf("invalid lease file: %w", err)
+	}
+	return lease, nil
+}
+
+// writeLease replaces a session's lease file atomically.
+func (l *FileLog) writeLease(session string, lease fileLease) error {
+	data, err := json.Marshal(lease)
+	if err != nil {
+		return fmt.Errorf("failed to encode lease: %w", err)
+	}
+	path := l.leasePath(session)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// GetLease reads a session's lease file.
+func (l *FileLog) GetLease(ctx context.Context, session string) ([]byte, uint64, error) {
+	lease, err := l.readLease(session)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(lease.Lease) == 0 {
+		return nil, 0, ErrNoLease
+	}
+	return lease.Lease, lease.Revision, nil
+}
+
+// PutLease writes a session's lease file if its revision is still rev. The
+// check and write hold the event file lock.
+func (l *FileLog) PutLease(ctx context.Context, session string, data []byte, rev uint64) (uint64, error) {
+	unlock, err := l.lock(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	current, err := l.readLease(session)
+	if err != nil {
+		return 0, err
+	}
+	held := current.Revision
+	if len(current.Lease) == 0 {
+		held = 0
+	}
+	if held != rev {
+		return 0, ErrSequenceConflict
+	}
+	lease := fileLease{Revision: current.Revision + 1, Lease: data}
+	if err := l.writeLease(session, lease); err != nil {
+		return 0, err
+	}
+	return lease.Revision, nil
+}
+
+// DeleteLease clears a session's lease file if its revision is still rev.
+func (l *FileLog) DeleteLease(ctx context.Context, session string, rev uint64) error {
+	unlock, err := l.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	current, err := l.readLease(session)
+	if err != nil {
+		return err
+	}
+	if len(current.Lease) == 0 {
+		return nil
+	}
+	if rev != 0 && current.Revision != rev {
+		return ErrSequenceConflict
+	}
+	return l.writeLease(session, fileLease{Revision: current.Revision + 1})
+}
+
+// BackupKind returns "file".
+func (l *FileLog) BackupKind() string {
+	return "file"
+}
+
+// Backup copies the event file while holding the lock file, so no write of
+// this or another process ends up half in the copy.
+func (l *FileLog) Backup(ctx context.Context, w io.Writer) (*BackupData, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	unlock, err := l.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	if err := l.refresh(); err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(w, io.NewSectionReader(l.file, 0, l.offset)); err != nil {
+		return nil, fmt.Errorf("failed to copy event file: %w", err)
+	}
+	return &BackupData{Messages: uint64(len(l.index.msgs)), LastSequence: l.index.lastSeq}, nil
+}
+
+// Restore replaces the event file with a copy written by Backup. Other
+// processes sharing the file switch to the new one on their next read.
+func (l *FileLog) Restore(ctx context.Context, data *BackupData, r io.Reader) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	unlock, err := l.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	tmp := l.path() + ".restore"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create event file: %w", err)
+	}
+	_, err = io.Copy(f, r)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to write event file: %w", err)
+	}
+
+	// Checked before it replaces anything
+	index, err := indexFile(tmp)
+	if err == nil {
+		err = checkRestored(uint64(len(index.msgs)), index.lastSeq, data)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to check event file: %w", err)
+	}
+
+	// Closed first, as open files can't be replaced on Windows
+	_ = l.file.Close()
+	renameErr := os.Rename(tmp, l.path())
+	if err := l.reopen(); err != nil {
+		return err
+	}
+	if renameErr != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to replace event file: %w", renameErr)
+	}
+	return l.refresh()
+}
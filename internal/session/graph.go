@@ -0,0 +1,269 @@
+package session
+
+import (
+	"slices"
+	"sort"
+	"strings"
+)
+
+// DependencyPath returns the chain of task IDs leading from one task to another
+// by following DependsOn links (from first, to last), or nil if to cannot be
+// reached from from.
+func (st *State) DependencyPath(from, to string) []string {
+	visited := make(map[string]bool)
+	var path []string
+	var walk func(id string) bool
+	walk = func(id string) bool {
+		if visited[id] {
+			return false
+		}
+		visited[id] = true
+		path = append(path, id)
+		if id == to {
+			return true
+		}
+		if task, ok := st.Tasks[id]; ok {
+			for _, depID := range task.DependsOn {
+				if walk(depID) {
+					return true
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		return false
+	}
+
+	if walk(from) {
+		return path
+	}
+	return nil
+}
+
+// DependencyCycles returns the dependency cycles among tasks, each as a chain of
+// task IDs that starts and ends with the same task. No task in a cycle can ever
+// become ready. TaskDepends rejects new cycles, so these can only come from
+// sessions recorded before cycle detection existed.
+func (st *State) DependencyCycles() [][]string {
+	const (
+		unvisited = iota
+		onStack
+		done
+	)
+	color := make(map[string]int, len(st.Tasks))
+	var stack []string
+	var cycles [][]string
+
+	var walk func(task *Task)
+	walk = func(task *Task) {
+		color[task.ID] = onStack
+		stack = append(stack, task.ID)
+		for _, depID := range task.DependsOn {
+			dep, ok := st.Tasks[depID]
+			if !ok {
+				continue
+			}
+			switch color[depID] {
+			case unvisited:
+				walk(dep)
+			case onStack:
+				// Back edge: the stack from depID to here is a cycle
+				start := len(stack) - 1
+				for stack[start] != depID {
+					start--
+				}
+				cycle := append([]string{}, stack[start:]...)
+				cycles = append(cycles, append(cycle, depID))
+			}
+		}
+		stack = stack[:len(stack)-1]
+		color[task.ID] = done
+	}
+
+	for _, task := range st.sortedTasks() {
+		if color[task.ID] == unvisited {
+			walk(task)
+		}
+	}
+	return cycles
+}
+
+// TopologicalOrder returns the open tasks ordered so that every task comes after
+// the open tasks it depends on. Among tasks that are free to go next, higher
+// priority (lower number) comes first, then creation order. Tasks caught in a
+// dependency cycle cannot be ordered and are appended at the end.
+func (st *State) TopologicalOrder() []*Task {
+	open := make(map[string]*Task)
+	for _, task := range st.Tasks {
+		if isOpenStatus(task.Status) {
+			open[task.ID] = task
+		}
+	}
+
+	// Count unfinished dependencies and record reverse edges
+	pending := make(map[string]int, len(open))
+	dependents := make(map[string][]*Task)
+	for _, task := range open {
+		for _, depID := range task.DependsOn {
+			if _, ok := open[depID]; ok {
+				pending[task.ID]++
+				dependents[depID] = append(dependents[depID], task)
+			}
+		}
+	}
+
+	var ready []*Task
+	for _, task := range open {
+		if pending[task.ID] == 0 {
+			ready = append(ready, task)
+		}
+	}
+
+	result := make([]*Task, 0, len(open))
+	placed := make(map[string]bool, len(open))
+	for len(ready) > 0 {
+		sortTasksByPriority(ready)
+		task := ready[0]
+		ready = ready[1:]
+		result = append(result, task)
+		placed[task.ID] = true
+		for _, dependent := range dependents[task.ID] {
+			pending[dependent.ID]--
+			if pending[dependent.ID] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+
+	// Whatever is left is in (or behind) a cycle
+	var stuck []*Task
+	for _, task := range open {
+		if !placed[task.ID] {
+			stuck = append(stuck, task)
+		}
+	}
+	sortTasksByCreation(stuck)
+	return append(result, stuck...)
+}
+
+// CriticalPath returns the longest chain of open tasks linked by dependencies,
+// in the order they must be done. Its length is the minimum number of tasks that
+// still have to be finished one after another. Tasks in cycles are ignored.
+func (st *State) CriticalPath() []*Task {
+	order := st.TopologicalOrder()
+	position := make(map[string]int, len(order))
+	for i, task := range order {
+		position[task.ID] = i
+	}
+
+	// length[i] is the longest chain ending at order[i]; prev links back along it
+	length := make([]int, len(order))
+	prev := make([]int, len(order))
+	best := -1
+	for i, task := range order {
+		length[i], prev[i] = 1, -1
+		for _, depID := range task.DependsOn {
+			j, ok := position[depID]
+			if !ok || j >= i {
+				// Closed dependency, or a cycle member ordered after us
+				continue
+			}
+			if length[j]+1 > length[i] {
+				length[i], prev[i] = length[j]+1, j
+			}
+		}
+		if best == -1 || length[i] > length[best] {
+			best = i
+		}
+	}
+
+	var path []*Task
+	for i := best; i != -1; i = prev[i] {
+		path = append([]*Task{order[i]}, path...)
+	}
+	return path
+}
+
+// BlockedBy returns the unfinished dependencies holding up a task, as one
+// shortest chain per blocker. Each chain starts with the task itself and
+// follows dependencies that are not completed down to a blocker: a task with
+// no unfinished dependencies of its own, a dependency that no longer exists,
+// or (for cycles) a task already in the chain. Tasks reachable along several
+// paths are only visited once, so shared dependencies don't multiply chains.
+// Returns nil if the task is not blocked by any dependency.
+func (st *State) BlockedBy(id string) [][]string {
+	// prev links each reached task back to the task that first depended on it
+	prev := map[string]string{id: ""}
+	chainTo := func(to string) []string {
+		chain := []string{to}
+		for cur := to; cur != id; {
+			cur = prev[cur]
+			chain = append(chain, cur)
+		}
+		slices.Reverse(chain)
+		return chain
+	}
+
+	var chains [][]string
+	queue := []string{id}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		task, ok := st.Tasks[cur]
+		if !ok {
+			if cur != id {
+				chains = append(chains, chainTo(cur))
+			}
+			continue
+		}
+
+		extended := false
+		for _, depID := range task.DependsOn {
+			if dep, ok := st.Tasks[depID]; ok && dep.Status == "completed" {
+				continue
+			}
+			extended = true
+			if _, seen := prev[depID]; seen {
+				// Already reached by a path no longer than this one, unless
+				// it's in our own chain and closes a cycle
+				if chain := chainTo(cur); slices.Contains(chain, depID) {
+					chains = append(chains, append(chain, depID))
+				}
+				continue
+			}
+			prev[depID] = cur
+			queue = append(queue, depID)
+		}
+		if !extended && cur != id {
+			chains = append(chains, chainTo(cur))
+		}
+	}
+	return chains
+}
+
+// FormatChain joins task IDs into a readable dependency chain ("TAS-1 -> TAS-2").
+func FormatChain(ids []string) string {
+	return strings.Join(ids, " -> ")
+}
+
+// sortedTasks returns all tasks in creation order.
+func (st *State) sortedTasks() []*Task {
+	tasks := make([]*Task, 0, len(st.Tasks))
+	for _, task := range st.Tasks {
+		tasks = append(tasks, task)
+	}
+	sortTasksByCreation(tasks)
+	return tasks
+}
+
+// sortTasksByPriority sorts tasks by priority (0 first), then creation order.
+func sortTasksByPriority(tasks []*Task) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if tasks[i].Priority != tasks[j].Priority {
+			return tasks[i].Priority < tasks[j].Priority
+		}
+		if !tasks[i].CreatedAt.Equal(tasks[j].CreatedAt) {
+			return tasks[i].CreatedAt.Before(tasks[j].CreatedAt)
+		}
+		return taskNumber(tasks[i].ID) < taskNumber(tasks[j].ID)
+	})
+}
@@ -0,0 +1,150 @@
+package session
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// graphState builds a state from task ID -> dependencies, with creation order
+// following the numeric ID and all tasks remaining at priority 2.
+func graphState(deps map[string][]string) *State {
+	now := time.Now()
+	state := &State{Tasks: make(map[string]*Task)}
+	for id, dependsOn := range deps {
+		state.Tasks[id] = &Task{
+			ID:        id,
+			Status:    "remaining",
+			Priority:  2,
+			DependsOn: dependsOn,
+			CreatedAt: now.Add(time.Duration(taskNumber(id)) * time.Second),
+		}
+	}
+	return state
+}
+
+func taskIDs(tasks []*Task) []string {
+	ids := make([]string, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+	return ids
+}
+
+func TestTopologicalOrder(t *testing.T) {
+	state := graphState(map[string][]string{
+		"TAS-1": {"TAS-3"},
+		"TAS-2": nil,
+		"TAS-3": nil,
+		"TAS-4": {"TAS-1", "TAS-5"},
+		"TAS-5": nil,
+	})
+	state.Tasks["TAS-5"].Status = "completed" // Closed tasks are not ordered
+	state.Tasks["TAS-2"].Priority = 3         // Lower priority goes after TAS-3
+
+	got := taskIDs(state.TopologicalOrder())
+	want := []string{"TAS-3", "TAS-1", "TAS-4", "TAS-2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TopologicalOrder() = %v, want %v", got, want)
+	}
+}
+
+func TestCriticalPath(t *testing.T) {
+	state := graphState(map[string][]string{
+		"TAS-1": nil,
+		"TAS-2": {"TAS-1"},
+		"TAS-3": {"TAS-2"},
+		"TAS-4": {"TAS-1"},
+		"TAS-5": nil,
+	})
+
+	got := taskIDs(state.CriticalPath())
+	want := []string{"TAS-1", "TAS-2", "TAS-3"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CriticalPath() = %v, want %v", got, want)
+	}
+}
+
+func TestDependencyCycles(t *testing.T) {
+	state := graphState(map[string][]string{
+		"TAS-1": {"TAS-2"},
+		"TAS-2": {"TAS-1"},
+		"TAS-3": {"TAS-1"},
+	})
+
+	want := [][]string{{"TAS-1", "TAS-2", "TAS-1"}}
+	if got := state.DependencyCycles(); !reflect.DeepEqual(got, want) {
+		t.Errorf("DependencyCycles() = %v, want %v", got, want)
+	}
+
+	// Cycle members are still returned by TopologicalOrder, after everything else
+	order := taskIDs(state.TopologicalOrder())
+	if len(order) != 3 {
+		t.Errorf("expected all 3 tasks in order, got %v", order)
+	}
+
+	if path := state.DependencyPath("TAS-3", "TAS-2"); !reflect.DeepEqual(path, []string{"TAS-3", "TAS-1", "TAS-2"}) {
+		t.Errorf("DependencyPath() = %v", path)
+	}
+}
+
+func TestBlockedBy(t *testing.T) {
+	state := graphState(map[string][]string{
+		"TAS-1": {"TAS-2", "TAS-3"},
+		"TAS-2": {"TAS-4"},
+		"TAS-3": nil,
+		"TAS-4": nil,
+		"TAS-5": {"TAS-9"}, // Missing dependency
+	})
+	state.Tasks["TAS-3"].Status = "completed"
+
+	want := [][]string{{"TAS-1", "TAS-2", "TAS-4"}}
+	if got := state.BlockedBy("TAS-1"); !reflect.DeepEqual(got, want) {
+		t.Errorf("BlockedBy(TAS-1) = %v, want %v", got, want)
+	}
+	if got := state.BlockedBy("TAS-5"); !reflect.DeepEqual(got, [][]string{{"TAS-5", "TAS-9"}}) {
+		t.Errorf("BlockedBy(TAS-5) = %v", got)
+	}
+	if got := state.BlockedBy("TAS-4"); got != nil {
+		t.Errorf("expected TAS-4 to be unblocked, got %v", got)
+	}
+}
+
+func TestBlockedByCycle(t *testing.T) {
+	state := graphState(map[string][]string{
+		"TAS-1": {"TAS-2"},
+		"TAS-2": {"TAS-3"},
+		"TAS-3": {"TAS-2"},
+	})
+
+	want := [][]string{{"TAS-1", "TAS-2", "TAS-3", "TAS-2"}}
+	if got := state.BlockedBy("TAS-1"); !reflect.DeepEqual(got, want) {
+		t.Errorf("BlockedBy(TAS-1) = %v, want %v", got, want)
+	}
+}
+
+func TestBlockedByDiamonds(t *testing.T) {
+	// 30 diamonds in a row: over a billion paths down to the last task, but a
+	// single blocker reached by one shortest chain
+	deps := make(map[string][]string)
+	const diamonds = 30
+	for i := 0; i < diamonds; i++ {
+		top, bottom := fmt.Sprintf("TAS-%d", 3*i+1), fmt.Sprintf("TAS-%d", 3*i+4)
+		left, right := fmt.Sprintf("TAS-%d", 3*i+2), fmt.Sprintf("TAS-%d", 3*i+3)
+		deps[top] = []string{left, right}
+		deps[left] = []string{bottom}
+		deps[right] = []string{bottom}
+	}
+	last := fmt.Sprintf("TAS-%d", 3*diamonds+1)
+	deps[last] = nil
+	state := graphState(deps)
+
+	chains := state.BlockedBy("TAS-1")
+	if len(chains) != 1 {
+		t.Fatalf("expected one chain, got %d", len(chains))
+	}
+	if chain := chains[0]; len(chain) != 2*diamonds+1 || chain[len(chain)-1] != last {
+		t.Errorf("expected shortest chain to %s, got %v", last, FormatChain(chain))
+	}
+}
@@ -0,0 +1,120 @@
+package session
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TaskTreeNode is a task together with its nesting depth in a task tree.
+type TaskTreeNode struct {
+	Task  *Task
+	Depth int // 0 for tasks whose parent is not in the tree
+}
+
+// isOpenStatus reports whether a task with the given status still has work left.
+// Completed and cancelled tasks are closed; everything else is open.
+func isOpenStatus(status string) bool {
+	return status != "completed" && status != "cancelled"
+}
+
+// Children returns the direct subtasks of a task in creation order.
+func (st *State) Children(id string) []*Task {
+	var children []*Task
+	for _, task := range st.Tasks {
+		if task.ParentID == id {
+			children = append(children, task)
+		}
+	}
+	sortTasksByCreation(children)
+	return children
+}
+
+// OpenChildren returns the direct subtasks of a task that are not completed or cancelled.
+func (st *State) OpenChildren(id string) []*Task {
+	var open []*Task
+	for _, child := range st.Children(id) {
+		if isOpenStatus(child.Status) {
+			open = append(open, child)
+		}
+	}
+	return open
+}
+
+// IsLeaf reports whether a task has no open subtasks, i.e. it can be worked on
+// directly. A parent becomes a leaf again once all of its subtasks are closed.
+func (st *State) IsLeaf(id string) bool {
+	for _, task := range st.Tasks {
+		if task.ParentID == id && isOpenStatus(task.Status) {
+			return false
+		}
+	}
+	return true
+}
+
+// SurfacedParent returns the parent of the given task if that parent is still
+// open and no longer has any open subtasks. Returns nil otherwise. Used after a
+// subtask is closed to report that its parent is ready to be completed.
+func (st *State) SurfacedParent(id string) *Task {
+	task, ok := st.Tasks[id]
+	if !ok || task.ParentID == "" {
+		return nil
+	}
+	parent, ok := st.Tasks[task.ParentID]
+	if !ok || !isOpenStatus(parent.Status) || !st.IsLeaf(parent.ID) {
+		return nil
+	}
+	return parent
+}
+
+// TaskTree orders tasks depth-first so that every subtask directly follows its
+// parent (or its earlier siblings), with siblings in creation order. Only parent
+// links between tasks in the given slice are followed; a task whose parent is
+// not in the slice is treated as a root.
+func TaskTree(tasks []*Task) []TaskTreeNode {
+	inSet := make(map[string]bool, len(tasks))
+	for _, task := range tasks {
+		inSet[task.ID] = true
+	}
+
+	var roots []*Task
+	children := make(map[string][]*Task)
+	for _, task := range tasks {
+		if task.ParentID != "" && inSet[task.ParentID] {
+			children[task.ParentID] = append(children[task.ParentID], task)
+		} else {
+			roots = append(roots, task)
+		}
+	}
+
+	result := make([]TaskTreeNode, 0, len(tasks))
+	var walk func(level []*Task, depth int)
+	walk = func(level []*Task, depth int) {
+		sortTasksByCreation(level)
+		for _, task := range level {
+			result = append(result, TaskTreeNode{Task: task, Depth: depth})
+			walk(children[task.ID], depth+1)
+		}
+	}
+	walk(roots, 0)
+
+	return result
+}
+
+// sortTasksByCreation sorts tasks by creation time, breaking ties by the
+// numeric part of their ID (TAS-2 before TAS-10).
+func sortTasksByCreation(tasks []*Task) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if !tasks[i].CreatedAt.Equal(tasks[j].CreatedAt) {
+			return tasks[i].CreatedAt.Before(tasks[j].CreatedAt)
+		}
+		return taskNumber(tasks[i].ID) < taskNumber(tasks[j].ID)
+	})
+}
+
+// taskNumber returns the numeric suffix of a task ID (e.g. 12 for "TAS-12"),
+// or 0 if the ID has no numeric suffix.
+func taskNumber(id string) int {
+	n, _ := strconv.Atoi(id[strings.LastIndex(id, "-")+1:])
+	return n
+}
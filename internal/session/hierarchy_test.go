@@ -0,0 +1,203 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/iteratr/internal/nats"
+)
+
+func TestTaskTree(t *testing.T) {
+	now := time.Now()
+	tasks := []*Task{
+		{ID: "TAS-10", CreatedAt: now.Add(3 * time.Second), ParentID: "TAS-1"},
+		{ID: "TAS-3", CreatedAt: now.Add(2 * time.Second), ParentID: "TAS-2"},
+		{ID: "TAS-2", CreatedAt: now.Add(time.Second), ParentID: "TAS-1"},
+		{ID: "TAS-1", CreatedAt: now},
+		{ID: "TAS-4", CreatedAt: now, ParentID: "TAS-99"}, // parent outside the set
+	}
+
+	nodes := TaskTree(tasks)
+
+	want := []struct {
+		id    string
+		depth int
+	}{
+		{"TAS-1", 0},
+		{"TAS-2", 1},
+		{"TAS-3", 2},
+		{"TAS-10", 1},
+		{"TAS-4", 0},
+	}
+	if len(nodes) != len(want) {
+		t.Fatalf("expected %d nodes, got %d", len(want), len(nodes))
+	}
+	for i, w := range want {
+		if nodes[i].Task.ID != w.id || nodes[i].Depth != w.depth {
+			t.Errorf("node %d: expected %s at depth %d, got %s at depth %d",
+				i, w.id, w.depth, nodes[i].Task.ID, nodes[i].Depth)
+		}
+	}
+}
+
+func TestSubtasks(t *testing.T) {
+	// Setup: Create embedded NATS and store
+	ctx := context.Background()
+	ns, _, err := nats.StartEmbeddedNATS(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to start NATS: %v", err)
+	}
+	defer ns.Shutdown()
+
+	nc, err := nats.ConnectInProcess(ns)
+	if err != nil {
+		t.Fatalf("failed to connect to NATS: %v", err)
+	}
+	defer nc.Close()
+
+	js, err := nats.CreateJetStream(nc)
+	if err != nil {
+		t.Fatalf("failed to create JetStream: %v", err)
+	}
+
+	stream, err := nats.SetupStream(ctx, js)
+	if err != nil {
+		t.Fatalf("failed to setup stream: %v", err)
+	}
+
+	store := NewStore(NewJetStreamLog(js, stream))
+
+	t.Run("TaskAdd records parent", func(t *testing.T) {
+		session := "test-subtask-add"
+		parent, _ := store.TaskAdd(ctx, session, TaskAddParams{Content: "Epic"})
+
+		child, err := store.TaskAdd(ctx, session, TaskAddParams{Content: "Step", ParentID: parent.ID})
+		if err != nil {
+			t.Fatalf("TaskAdd failed: %v", err)
+		}
+		if child.ParentID != parent.ID {
+			t.Errorf("expected returned parent %s, got %q", parent.ID, child.ParentID)
+		}
+
+		state, err := store.LoadState(ctx, session)
+		if err != nil {
+			t.Fatalf("LoadState failed: %v", err)
+		}
+		if state.Tasks[child.ID].ParentID != parent.ID {
+			t.Errorf("expected parent %s in state, got %q", parent.ID, state.Tasks[child.ID].ParentID)
+		}
+		if children := state.Children(parent.ID); len(children) != 1 || children[0].ID != child.ID {
+			t.Errorf("expected %s as only child, got %v", child.ID, children)
+		}
+	})
+
+	t.Run("TaskAdd rejects unknown or closed parent", func(t *testing.T) {
+		session := "test-subtask-bad-parent"
+		_, err := store.TaskAdd(ctx, session, TaskAddParams{Content: "Orphan", ParentID: "TAS-99"})
+		if err == nil || !strings.Contains(err.Error(), "parent") {
+			t.Errorf("expected parent resolution error, got %v", err)
+		}
+
+		done, _ := store.TaskAdd(ctx, session, TaskAddParams{Content: "Done epic", Status: "completed"})
+		_, err = store.TaskAdd(ctx, session, TaskAddParams{Content: "Late step", ParentID: done.ID})
+		if err == nil {
+			t.Error("expected error adding subtask to completed parent")
+		}
+	})
+
+	t.Run("parent cannot complete while children are open", func(t *testing.T) {
+		session := "test-subtask-rollup"
+		parent, _ := store.TaskAdd(ctx, session, TaskAddParams{Content: "Epic"})
+		child1, _ := store.TaskAdd(ctx, session, TaskAddParams{Content: "Step 1", ParentID: parent.ID})
+		child2, _ := store.TaskAdd(ctx, session, TaskAddParams{Content: "Step 2", ParentID: parent.ID})
+
+		err := store.TaskStatus(ctx, session, TaskStatusParams{ID: parent.ID, Status: "completed"})
+		if err == nil || !strings.Contains(err.Error(), child1.ID) || !strings.Contains(err.Error(), child2.ID) {
+			t.Fatalf("expected error listing open subtasks, got %v", err)
+		}
+
+		_ = store.TaskStatus(ctx, session, TaskStatusParams{ID: child1.ID, Status: "completed"})
+		state, _ := store.LoadState(ctx, session)
+		if state.SurfacedParent(child1.ID) != nil {
+			t.Error("parent should not surface while a subtask is open")
+		}
+
+		_ = store.TaskStatus(ctx, session, TaskStatusParams{ID: child2.ID, Status: "cancelled"})
+		state, _ = store.LoadState(ctx, session)
+		if p := state.SurfacedParent(child2.ID); p == nil || p.ID != parent.ID {
+			t.Errorf("expected parent %s to surface after last subtask closed, got %v", parent.ID, p)
+		}
+
+		if err := store.TaskStatus(ctx, session, TaskStatusParams{ID: parent.ID, Status: "completed"}); err != nil {
+			t.Errorf("expected parent to complete once subtasks are closed, got %v", err)
+		}
+	})
+
+	t.Run("parent completion races subtask adds", func(t *testing.T) {
+		for i := 0; i < 50; i++ {
+			session := fmt.Sprintf("test-subtask-race-%d", i)
+			parent, _ := store.TaskAdd(ctx, session, TaskAddParams{Content: "Epic"})
+
+			var wg sync.WaitGroup
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				_ = store.TaskStatus(ctx, session, TaskStatusParams{ID: parent.ID, Status: "completed"})
+			}()
+			go func() {
+				defer wg.Done()
+				_, _ = store.TaskAdd(ctx, session, TaskAddParams{Content: "Late step", ParentID: parent.ID})
+			}()
+			wg.Wait()
+
+			state, _ := store.LoadState(ctx, session)
+			if state.Tasks[parent.ID].Status == "completed" && len(state.OpenChildren(parent.ID)) > 0 {
+				t.Fatalf("parent %s completed with open subtasks", parent.ID)
+			}
+		}
+	})
+
+	t.Run("TaskNext picks leaf tasks only", func(t *testing.T) {
+		session := "test-subtask-next"
+		parent, _ := store.TaskAdd(ctx, session, TaskAddParams{Content: "Epic", Priority: 0})
+		child, _ := store.TaskAdd(ctx, session, TaskAddParams{Content: "Step", Priority: 3, ParentID: parent.ID})
+
+		next, err := store.TaskNext(ctx, session, TaskNextParams{})
+		if err != nil {
+			t.Fatalf("TaskNext failed: %v", err)
+		}
+		if next == nil || next.ID != child.ID {
+			t.Fatalf("expected leaf %s, got %v", child.ID, next)
+		}
+
+		// Completing the last child surfaces the parent
+		_ = store.TaskStatus(ctx, session, TaskStatusParams{ID: child.ID, Status: "completed"})
+		next, err = store.TaskNext(ctx, session, TaskNextParams{})
+		if err != nil {
+			t.Fatalf("TaskNext failed: %v", err)
+		}
+		if next == nil || next.ID != parent.ID {
+			t.Errorf("expected surfaced parent %s, got %v", parent.ID, next)
+		}
+	})
+
+	t.Run("TaskRemove reparents subtasks", func(t *testing.T) {
+		session := "test-subtask-remove"
+		root, _ := store.TaskAdd(ctx, session, TaskAddParams{Content: "Root"})
+		middle, _ := store.TaskAdd(ctx, session, TaskAddParams{Content: "Middle", ParentID: root.ID})
+		leaf, _ := store.TaskAdd(ctx, session, TaskAddParams{Content: "Leaf", ParentID: middle.ID})
+
+		if err := store.TaskRemove(ctx, session, TaskRemoveParams{ID: middle.ID}); err != nil {
+			t.Fatalf("TaskRemove failed: %v", err)
+		}
+
+		state, _ := store.LoadState(ctx, session)
+		if state.Tasks[leaf.ID].ParentID != root.ID {
+			t.Errorf("expected leaf reparented to %s, got %q", root.ID, state.Tasks[leaf.ID].ParentID)
+		}
+	})
+}
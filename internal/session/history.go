@@ -0,0 +1,117 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/iteratr/internal/logger"
+	"github.com/mark3labs/iteratr/internal/nats"
+)
+
+// StatePoint identifies a point in a session's history for LoadStateAt.
+// Exactly one of the fields must be set.
+type StatePoint struct {
+	Sequence  uint64    // State after the event with this stream sequence
+	Time      time.Time // State after all events published at or before this time
+	Iteration int       // State at the end of this iteration (before the next one starts)
+}
+
+// String describes the point for display (e.g. "seq 42", "iteration 12").
+func (p StatePoint) String() string {
+	switch {
+	case p.Sequence > 0:
+		return fmt.Sprintf("seq %d", p.Sequence)
+	case !p.Time.IsZero():
+		return p.Time.Format(time.RFC3339)
+	case p.Iteration > 0:
+		return fmt.Sprintf("iteration %d", p.Iteration)
+	default:
+		return "start"
+	}
+}
+
+// Validate checks that exactly one field of the point is set.
+func (p StatePoint) Validate() error {
+	set := 0
+	if p.Sequence > 0 {
+		set++
+	}
+	if !p.Time.IsZero() {
+		set++
+	}
+	if p.Iteration > 0 {
+		set++
+	}
+	if set != 1 {
+		return fmt.Errorf("exactly one of sequence, time or iteration is required")
+	}
+	return nil
+}
+
+// Includes reports whether the event with the given sequence happened at or
+// before the point. Events are in stream order, so the first event that is not
+// included ends the history up to the point.
+func (p StatePoint) Includes(seq uint64, event Event) bool {
+	switch {
+	case p.Sequence > 0:
+		return seq <= p.Sequence
+	case !p.Time.IsZero():
+		return !event.Timestamp.After(p.Time)
+	default:
+		// Stop at the start of the next iteration
+		if event.Type == nats.EventTypeIteration && event.Action == "start" {
+			var meta IterationStartMeta
+			_ = DecodeMeta(event, &meta)
+			return meta.Number <= p.Iteration
+		}
+		return true
+	}
+}
+
+// HistoryEvent is an event together with its position in the stream.
+type HistoryEvent struct {
+	Sequence uint64 `json:"seq"`
+	Event
+}
+
+// LoadStateAt reconstructs a session's state as it was at the given point in its
+// history by replaying events from the beginning through the State.Apply reducer.
+// Snapshots are not used since they only hold the latest state. Returns the state
+// and the sequence of the last event applied (0 if none).
+func (s *Store) LoadStateAt(ctx context.Context, session string, at StatePoint) (*State, uint64, error) {
+	if err := at.Validate(); err != nil {
+		return nil, 0, err
+	}
+
+	state := &State{
+		Session: session,
+		Tasks:   make(map[string]*Task),
+	}
+	lastSeq, err := s.forEachEvent(ctx, session, 0, func(seq uint64, event Event) bool {
+		if !at.Includes(seq, event) {
+			return false
+		}
+		if err := state.Apply(event); err != nil {
+			logger.Warn("Skipping event seq=%d: %v", seq, err)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return state, lastSeq, nil
+}
+
+// History returns the session's event log in stream order.
+func (s *Store) History(ctx context.Context, session string) ([]HistoryEvent, error) {
+	var events []HistoryEvent
+	_, err := s.forEachEvent(ctx, session, 0, func(seq uint64, event Event) bool {
+		events = append(events, HistoryEvent{Sequence: seq, Event: event})
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
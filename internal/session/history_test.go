@@ -0,0 +1,111 @@
+package session
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/iteratr/internal/nats"
+)
+
+func TestLoadStateAt(t *testing.T) {
+	// Setup: Create embedded NATS and store
+	ctx := context.Background()
+	ns, _, err := nats.StartEmbeddedNATS(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to start NATS: %v", err)
+	}
+	defer ns.Shutdown()
+
+	nc, err := nats.ConnectInProcess(ns)
+	if err != nil {
+		t.Fatalf("failed to connect to NATS: %v", err)
+	}
+	defer nc.Close()
+
+	js, err := nats.CreateJetStream(nc)
+	if err != nil {
+		t.Fatalf("failed to create JetStream: %v", err)
+	}
+
+	stream, err := nats.SetupStream(ctx, js)
+	if err != nil {
+		t.Fatalf("failed to setup stream: %v", err)
+	}
+
+	store := NewStore(NewJetStreamLog(js, stream))
+	session := "test-history"
+
+	// Iteration 1: add a task; iteration 2: complete it and add another
+	_ = store.IterationStart(ctx, session, 1)
+	task1, _ := store.TaskAdd(ctx, session, TaskAddParams{Content: "First", Iteration: 1})
+	_ = store.IterationComplete(ctx, session, 1, IterationResult{})
+	_ = store.IterationStart(ctx, session, 2)
+	_ = store.TaskStatus(ctx, session, TaskStatusParams{ID: task1.ID, Status: "completed", Iteration: 2})
+	_, _ = store.TaskAdd(ctx, session, TaskAddParams{Content: "Second", Iteration: 2})
+
+	events, err := store.History(ctx, session)
+	if err != nil {
+		t.Fatalf("History failed: %v", err)
+	}
+	if len(events) != 6 {
+		t.Fatalf("expected 6 events, got %d", len(events))
+	}
+	for i := 1; i < len(events); i++ {
+		if events[i].Sequence <= events[i-1].Sequence {
+			t.Fatalf("expected increasing sequences, got %d after %d", events[i].Sequence, events[i-1].Sequence)
+		}
+	}
+
+	t.Run("at iteration", func(t *testing.T) {
+		state, lastSeq, err := store.LoadStateAt(ctx, session, StatePoint{Iteration: 1})
+		if err != nil {
+			t.Fatalf("LoadStateAt failed: %v", err)
+		}
+		if len(state.Tasks) != 1 || state.Tasks[task1.ID].Status != "remaining" {
+			t.Errorf("expected only %s remaining at end of iteration 1, got %v", task1.ID, state.Tasks)
+		}
+		if len(state.Iterations) != 1 || !state.Iterations[0].Complete {
+			t.Errorf("expected one completed iteration, got %v", state.Iterations)
+		}
+		if lastSeq != events[2].Sequence {
+			t.Errorf("expected last seq %d, got %d", events[2].Sequence, lastSeq)
+		}
+	})
+
+	t.Run("at sequence", func(t *testing.T) {
+		// Right before the status change
+		state, _, err := store.LoadStateAt(ctx, session, StatePoint{Sequence: events[4].Sequence - 1})
+		if err != nil {
+			t.Fatalf("LoadStateAt failed: %v", err)
+		}
+		if state.Tasks[task1.ID].Status != "remaining" || len(state.Iterations) != 2 {
+			t.Errorf("unexpected state before status change: %v, %d iterations", state.Tasks[task1.ID], len(state.Iterations))
+		}
+	})
+
+	t.Run("at time", func(t *testing.T) {
+		state, _, err := store.LoadStateAt(ctx, session, StatePoint{Time: time.Now()})
+		if err != nil {
+			t.Fatalf("LoadStateAt failed: %v", err)
+		}
+		latest, _ := store.LoadState(ctx, session)
+		if len(state.Tasks) != len(latest.Tasks) || state.Tasks[task1.ID].Status != "completed" {
+			t.Errorf("expected state at now to match latest state")
+		}
+
+		state, lastSeq, _ := store.LoadStateAt(ctx, session, StatePoint{Time: events[0].Timestamp.Add(-time.Second)})
+		if len(state.Tasks) != 0 || lastSeq != 0 {
+			t.Errorf("expected empty state before first event, got %d tasks, seq %d", len(state.Tasks), lastSeq)
+		}
+	})
+
+	t.Run("requires exactly one point", func(t *testing.T) {
+		if _, _, err := store.LoadStateAt(ctx, session, StatePoint{}); err == nil {
+			t.Error("expected error for empty point")
+		}
+		if _, _, err := store.LoadStateAt(ctx, session, StatePoint{Sequence: 1, Iteration: 1}); err == nil {
+			t.Error("expected error for multiple points")
+		}
+	})
+}
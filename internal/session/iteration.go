@@ -4,17 +4,44 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/mark3labs/iteratr/internal/nats"
 )
 
+// Iteration outcomes.
+const (
+	IterationSucceeded = "succeeded"
+	IterationFailed    = "failed"
+	IterationCancelled = "cancelled"
+	IterationAbandoned = "abandoned" // iteratr exited before the iteration ended
+)
+
+// IterationResult describes how an iteration ended.
+type IterationResult struct {
+	Outcome    string        `json:"outcome,omitempty"`     // succeeded, failed, cancelled or abandoned
+	StopReason string        `json:"stop_reason,omitempty"` // Why the last agent turn stopped (end_turn, cancelled, ...)
+	Error      string        `json:"error,omitempty"`       // Error that failed the iteration
+	Duration   time.Duration `json:"duration,omitempty"`
+	Model      string        `json:"model,omitempty"`
+	Files      []FileChange  `json:"files,omitempty"` // Files modified by the agent
+
+	InterruptedTasks []string `json:"interrupted_tasks,omitempty"` // Tasks in progress when an abandoned iteration stopped
+}
+
+// FileChange is a file modified during an iteration.
+type FileChange struct {
+	Path      string `json:"path"`
+	IsNew     bool   `json:"is_new,omitempty"`
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+}
+
 // IterationStart logs the start of a new iteration.
 // Creates an event of type "iteration" with action "start".
 func (s *Store) IterationStart(ctx context.Context, session string, number int) error {
 	// Build metadata
-	meta, err := json.Marshal(map[string]any{
-		"number": number,
-	})
+	meta, err := json.Marshal(IterationStartMeta{Number: number})
 	if err != nil {
 		return fmt.Errorf("failed to marshal iteration start metadata: %w", err)
 	}
@@ -37,13 +64,16 @@ func (s *Store) IterationStart(ctx context.Context, session string, number int)
 	return nil
 }
 
-// IterationComplete logs the completion of an iteration.
+// IterationComplete logs the end of an iteration and how it ended. An empty
+// outcome is recorded as succeeded.
 // Creates an event of type "iteration" with action "complete".
-func (s *Store) IterationComplete(ctx context.Context, session string, number int) error {
+func (s *Store) IterationComplete(ctx context.Context, session string, number int, result IterationResult) error {
+	if result.Outcome == "" {
+		result.Outcome = IterationSucceeded
+	}
+
 	// Build metadata
-	meta, err := json.Marshal(map[string]any{
-		"number": number,
-	})
+	meta, err := json.Marshal(IterationCompleteMeta{Number: number, IterationResult: result})
 	if err != nil {
 		return fmt.Errorf("failed to marshal iteration complete metadata: %w", err)
 	}
@@ -54,7 +84,7 @@ func (s *Store) IterationComplete(ctx context.Context, session string, number in
 		Type:    nats.EventTypeIteration,
 		Action:  "complete",
 		Meta:    meta,
-		Data:    fmt.Sprintf("Iteration %d completed", number),
+		Data:    fmt.Sprintf("Iteration %d %s", number, result.Outcome),
 	}
 
 	// Publish event
@@ -66,15 +96,40 @@ func (s *Store) IterationComplete(ctx context.Context, session string, number in
 	return nil
 }
 
+// IterationAbandon marks an iteration that never ended, e.g. because iteratr
+// was killed, as abandoned. interruptedTasks lists the tasks that were in
+// progress at the time.
+// Creates an event of type "iteration" with action "abandoned".
+func (s *Store) IterationAbandon(ctx context.Context, session string, number int, interruptedTasks []string) error {
+	// Build metadata
+	meta, err := json.Marshal(IterationAbandonedMeta{Number: number, InterruptedTasks: interruptedTasks})
+	if err != nil {
+		return fmt.Errorf("failed to marshal iteration abandoned metadata: %w", err)
+	}
+
+	// Create event
+	event := Event{
+		Session: session,
+		Type:    nats.EventTypeIteration,
+		Action:  "abandoned",
+		Meta:    meta,
+		Data:    fmt.Sprintf("Iteration %d abandoned", number),
+	}
+
+	// Publish event
+	_, err = s.PublishEvent(ctx, event)
+	if err != nil {
+		return fmt.Errorf("failed to publish iteration abandoned event: %w", err)
+	}
+
+	return nil
+}
+
 // IterationSummary logs a summary for an iteration with tasks worked.
 // Creates an event of type "iteration" with action "summary".
 func (s *Store) IterationSummary(ctx context.Context, session string, number int, summary string, tasksWorked []string) error {
 	// Build metadata
-	meta, err := json.Marshal(map[string]any{
-		"number":       number,
-		"summary":      summary,
-		"tasks_worked": tasksWorked,
-	})
+	meta, err := json.Marshal(IterationSummaryMeta{Number: number, Summary: summary, TasksWorked: tasksWorked})
 	if err != nil {
 		return fmt.Errorf("failed to marshal iteration summary metadata: %w", err)
 	}
@@ -96,3 +151,15 @@ func (s *Store) IterationSummary(ctx context.Context, session string, number int
 
 	return nil
 }
+
+// DanglingIterations returns iterations that started but never ended, which
+// happens when iteratr exits mid-iteration.
+func (st *State) DanglingIterations() []*Iteration {
+	var dangling []*Iteration
+	for _, iter := range st.Iterations {
+		if iter.EndedAt.IsZero() {
+			dangling = append(dangling, iter)
+		}
+	}
+	return dangling
+}
@@ -3,6 +3,7 @@ package session
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/mark3labs/iteratr/internal/nats"
 )
@@ -32,7 +33,7 @@ func TestIterationOperations(t *testing.T) {
 		t.Fatalf("failed to setup stream: %v", err)
 	}
 
-	store := NewStore(js, stream)
+	store := NewStore(NewJetStreamLog(js, stream))
 	session := "test-session"
 
 	t.Run("IterationStart creates iteration event", func(t *testing.T) {
@@ -71,7 +72,7 @@ func TestIterationOperations(t *testing.T) {
 		}
 
 		// Complete it
-		err = store.IterationComplete(ctx, session, 2)
+		err = store.IterationComplete(ctx, session, 2, IterationResult{})
 		if err != nil {
 			t.Fatalf("IterationComplete failed: %v", err)
 		}
@@ -110,7 +111,7 @@ func TestIterationOperations(t *testing.T) {
 			t.Fatalf("IterationStart failed: %v", err)
 		}
 
-		err = store.IterationComplete(ctx, session, 3)
+		err = store.IterationComplete(ctx, session, 3, IterationResult{})
 		if err != nil {
 			t.Fatalf("IterationComplete failed: %v", err)
 		}
@@ -159,13 +160,57 @@ func TestIterationOperations(t *testing.T) {
 		}
 	})
 
+	t.Run("IterationComplete records result", func(t *testing.T) {
+		_ = store.IterationStart(ctx, session, 5)
+		err := store.IterationComplete(ctx, session, 5, IterationResult{
+			Outcome:    IterationFailed,
+			StopReason: "end_turn",
+			Error:      "agent crashed",
+			Duration:   90 * time.Second,
+			Model:      "anthropic/claude-sonnet-4-5",
+			Files:      []FileChange{{Path: "main.go", Additions: 3, Deletions: 1}},
+		})
+		if err != nil {
+			t.Fatalf("IterationComplete failed: %v", err)
+		}
+
+		state, err := store.LoadState(ctx, session)
+		if err != nil {
+			t.Fatalf("LoadState failed: %v", err)
+		}
+		iter5 := state.Iterations[len(state.Iterations)-1]
+		if iter5.Number != 5 {
+			t.Fatalf("expected iteration 5 last, got %d", iter5.Number)
+		}
+		if iter5.Complete {
+			t.Error("expected failed iteration to not be complete")
+		}
+		if iter5.EndedAt.IsZero() {
+			t.Error("expected EndedAt to be set")
+		}
+		if iter5.Outcome != IterationFailed || iter5.Error != "agent crashed" || iter5.StopReason != "end_turn" {
+			t.Errorf("unexpected result: %+v", iter5.IterationResult)
+		}
+		if iter5.Duration != 90*time.Second || iter5.Model != "anthropic/claude-sonnet-4-5" {
+			t.Errorf("unexpected duration or model: %+v", iter5.IterationResult)
+		}
+		if len(iter5.Files) != 1 || iter5.Files[0].Additions != 3 {
+			t.Errorf("unexpected files: %+v", iter5.Files)
+		}
+
+		// Iterations completed without a result count as succeeded
+		if state.Iterations[1].Outcome != IterationSucceeded {
+			t.Errorf("expected default outcome succeeded, got %q", state.Iterations[1].Outcome)
+		}
+	})
+
 	t.Run("Iterations persist via event sourcing", func(t *testing.T) {
 		// Use a dedicated session
 		iterSession := "test-iteration-persistence"
 
 		// Add iterations
 		_ = store.IterationStart(ctx, iterSession, 1)
-		_ = store.IterationComplete(ctx, iterSession, 1)
+		_ = store.IterationComplete(ctx, iterSession, 1, IterationResult{})
 		_ = store.IterationStart(ctx, iterSession, 2)
 
 		// Load state multiple times - should get same result from event log
@@ -0,0 +1,469 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mark3labs/iteratr/internal/logger"
+	"github.com/mark3labs/iteratr/internal/nats"
+	natsgo "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// JetStreamLog is the default EventLog, backed by the iteratr_events stream.
+// Snapshots are kept in the iteratr_snapshots KV bucket, leases in the
+// iteratr_leases one. With another
+// nats.Layout, the stream's subjects start with the layout's prefix instead of
+// "iteratr"; callers keep using iteratr.{session}.{type} subjects.
+type JetStreamLog struct {
+	js     jetstream.JetStream // JetStream context for operations
+	stream jetstream.Stream    // The iteratr_events stream
+	kv     jetstream.KeyValue  // Snapshot bucket (nil until SetupSnapshots is called)
+	leases jetstream.KeyValue  // Lease bucket (nil until SetupLeases is called)
+	layout nats.Layout         // Stream, subject prefix and bucket names
+	nc     *natsgo.Conn        // Connection for stream snapshots (nil until WithConn is called)
+}
+
+// NewJetStreamLog creates an event log on the given JetStream context and stream.
+func NewJetStreamLog(js jetstream.JetStream, stream jetstream.Stream) *JetStreamLog {
+	return NewJetStreamLogWithLayout(js, stream, nats.Layout{})
+}
+
+// NewJetStreamLogWithLayout creates an event log on a stream set up with
+// nats.SetupLayoutStream for layout.
+func NewJetStreamLogWithLayout(js jetstream.JetStream, stream jetstream.Stream, layout nats.Layout) *JetStreamLog {
+	return &JetStreamLog{
+		js:     js,
+		stream: stream,
+		layout: layout.WithDefaults(),
+	}
+}
+
+// WithConn sets the connection the log's JetStream context uses, which stream
+// snapshots for Backup and Restore are sent over. Returns the log.
+func (l *JetStreamLog) WithConn(nc *natsgo.Conn) *JetStreamLog {
+	l.nc = nc
+	return l
+}
+
+// toStream maps an iteratr subject or filter to the stream's subject prefix.
+func (l *JetStreamLog) toStream(subject string) string {
+	if l.layout.SubjectPrefix == nats.SubjectPrefix {
+		return subject
+	}
+	if rest, ok := strings.CutPrefix(subject, nats.SubjectPrefix+"."); ok {
+		return l.layout.SubjectPrefix + "." + rest
+	}
+	return subject
+}
+
+// fromStream maps a stream subject back to its iteratr subject.
+func (l *JetStreamLog) fromStream(subject string) string {
+	if l.layout.SubjectPrefix == nats.SubjectPrefix {
+		return subject
+	}
+	if rest, ok := strings.CutPrefix(subject, l.layout.SubjectPrefix+"."); ok {
+		return nats.SubjectPrefix + "." + rest
+	}
+	return subject
+}
+
+// Append publishes data to subject.
+func (l *JetStreamLog) Append(ctx context.Context, subject string, data []byte) (uint64, error) {
+	ack, err := l.js.Publish(ctx, l.toStream(subject), data)
+	if err != nil {
+		return 0, err
+	}
+	return ack.Sequence, nil
+}
+
+// AppendIfLast publishes data to subject with an expected last subject sequence.
+func (l *JetStreamLog) AppendIfLast(ctx context.Context, subject string, data []byte, lastSeq uint64) (uint64, error) {
+	ack, err := l.js.Publish(ctx, l.toStream(subject), data, jetstream.WithExpectLastSequencePerSubject(lastSeq))
+	if err != nil {
+		if isWrongLastSequence(err) {
+			return 0, ErrSequenceConflict
+		}
+		return 0, err
+	}
+	return ack.Sequence, nil
+}
+
+// isWrongLastSequence reports whether err is JetStream rejecting a publish
+// because its expected-last-subject-sequence header did not match.
+func isWrongLastSequence(err error) bool {
+	var apiErr *jetstream.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode == jetstream.JSErrCodeStreamWrongLastSequence
+}
+
+// LastSequence returns the last sequence of subject, or of the stream.
+func (l *JetStreamLog) LastSequence(ctx context.Context, subject string) (uint64, error) {
+	if subject == "" {
+		info, err := l.stream.Info(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get stream info: %w", err)
+		}
+		return info.State.LastSeq, nil
+	}
+
+	msg, err := l.stream.GetLastMsgForSubject(ctx, l.toStream(subject))
+	if err != nil {
+		if errors.Is(err, jetstream.ErrMsgNotFound) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to read last sequence: %w", err)
+	}
+	return msg.Sequence, nil
+}
+
+// Read fetches the messages matching filter with an ephemeral consumer.
+func (l *JetStreamLog) Read(ctx context.Context, filter string, afterSeq uint64, fn func(Message) bool) error {
+	// Create a consumer filtered to the requested subjects
+	cfg := jetstream.ConsumerConfig{
+		FilterSubject: l.toStream(filter),
+		DeliverPolicy: jetstream.DeliverAllPolicy, // Start from beginning
+		AckPolicy:     jetstream.AckExplicitPolicy,
+	}
+	if afterSeq > 0 {
+		cfg.DeliverPolicy = jetstream.DeliverByStartSequencePolicy
+		cfg.OptStartSeq = afterSeq + 1
+	}
+	consumer, err := l.stream.CreateOrUpdateConsumer(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create consumer: %w", err)
+	}
+
+	// Fetch messages in batches
+	// Using a large batch size to minimize round trips
+	const batchSize = 1000
+	stopped := false
+	for !stopped {
+		// Fetch with short timeout to avoid blocking forever
+		msgs, err := consumer.FetchNoWait(batchSize)
+		if err != nil {
+			// No more messages or error - we've read everything
+			logger.Debug("Finished reading messages (batch fetch complete)")
+			break
+		}
+
+		msgCount := 0
+		for msg := range msgs.Messages() {
+			msgCount++
+			if stopped {
+				// Drain the rest of the batch without processing
+				continue
+			}
+			meta, _ := msg.Metadata()
+			var seq uint64
+			if meta != nil {
+				seq = meta.Sequence.Stream
+			}
+
+			if !fn(Message{Sequence: seq, Subject: l.fromStream(msg.Subject()), Data: msg.Data()}) {
+				stopped = true
+				continue
+			}
+
+			// Acknowledge message
+			_ = msg.Ack()
+		}
+
+		logger.Debug("Processed batch: %d messages", msgCount)
+
+		// If we got fewer messages than batch size, we've reached the end
+		if msgCount < batchSize {
+			break
+		}
+	}
+
+	return nil
+}
+
+// Count sums the per-subject message counts of the stream matching filter.
+func (l *JetStreamLog) Count(ctx context.Context, filter string) (uint64, error) {
+	info, err := l.stream.Info(ctx, jetstream.WithSubjectFilter(l.toStream(filter)))
+	if err != nil {
+		return 0, err
+	}
+
+	var count uint64
+	for _, n := range info.State.Subjects {
+		count += n
+	}
+	return count, nil
+}
+
+// Purge removes the messages matching filter from the stream.
+func (l *JetStreamLog) Purge(ctx context.Context, filter string) error {
+	return l.stream.Purge(ctx, jetstream.WithPurgeSubject(l.toStream(filter)))
+}
+
+// PurgeBefore removes the messages matching filter with a lower sequence than
+// seq from the stream.
+func (l *JetStreamLog) PurgeBefore(ctx context.Context, filter string, seq uint64) error {
+	return l.stream.Purge(ctx, jetstream.WithPurgeSubject(l.toStream(filter)), jetstream.WithPurgeSequence(seq))
+}
+
+// Sessions lists the sessions found in the stream subjects.
+func (l *JetStreamLog) Sessions(ctx context.Context) ([]string, error) {
+	return nats.ListSessionsWithPrefix(ctx, l.stream, l.layout.SubjectPrefix)
+}
+
+// Watch delivers messages matching filter through an ordered consumer.
+func (l *JetStreamLog) Watch(ctx context.Context, filter string, afterSeq uint64) (<-chan Message, error) {
+	cfg := jetstream.OrderedConsumerConfig{
+		FilterSubjects: []string{l.toStream(filter)},
+		DeliverPolicy:  jetstream.DeliverAllPolicy,
+	}
+	if afterSeq > 0 {
+		cfg.DeliverPolicy = jetstream.DeliverByStartSequencePolicy
+		cfg.OptStartSeq = afterSeq + 1
+	}
+	consumer, err := l.stream.OrderedConsumer(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create watch consumer: %w", err)
+	}
+	iter, err := consumer.Messages()
+	if err != nil {
+		return nil, fmt.Errorf("failed to watch messages: %w", err)
+	}
+
+	// Next blocks, so stop the iterator to unblock it once ctx is done
+	go func() {
+		<-ctx.Done()
+		iter.Stop()
+	}()
+
+	ch := make(chan Message, 64)
+	go func() {
+		defer close(ch)
+		for {
+			msg, err := iter.Next()
+			if err != nil {
+				if ctx.Err() == nil {
+					logger.Warn("Watch on %s stopped: %v", filter, err)
+				}
+				return
+			}
+			meta, _ := msg.Metadata()
+			var seq uint64
+			if meta != nil {
+				seq = meta.Sequence.Stream
+			}
+			select {
+			case ch <- Message{Sequence: seq, Subject: l.fromStream(msg.Subject()), Data: msg.Data()}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// Close is a no-op; the NATS connection is owned by the caller.
+func (l *JetStreamLog) Close() error {
+	return nil
+}
+
+// SetupSnapshots creates the snapshot KV bucket if it does not exist.
+func (l *JetStreamLog) SetupSnapshots(ctx context.Context) error {
+	kv, err := nats.SetupNamedSnapshotBucket(ctx, l.js, l.layout.SnapshotBucket)
+	if err != nil {
+		return fmt.Errorf("failed to setup snapshot bucket: %w", err)
+	}
+	l.kv = kv
+	return nil
+}
+
+// GetSnapshot reads a session's snapshot from the KV bucket.
+func (l *JetStreamLog) GetSnapshot(ctx context.Context, session string) ([]byte, error) {
+	if l.kv == nil {
+		return nil, ErrNoSnapshot
+	}
+	entry, err := l.kv.Get(ctx, session)
+	if err != nil {
+		if errors.Is(err, jetstream.ErrKeyNotFound) {
+			return nil, ErrNoSnapshot
+		}
+		return nil, err
+	}
+	return entry.Value(), nil
+}
+
+// PutSnapshot writes a session's snapshot to the KV bucket.
+func (l *JetStreamLog) PutSnapshot(ctx context.Context, session string, data []byte) error {
+	if l.kv == nil {
+		return fmt.Errorf("snapshot bucket not initialized")
+	}
+	_, err := l.kv.Put(ctx, session, data)
+	return err
+}
+
+// DeleteSnapshot purges a session's snapshot from the KV bucket.
+func (l *JetStreamLog) DeleteSnapshot(ctx context.Context, session string) error {
+	if l.kv == nil {
+		return nil
+	}
+	if err := l.kv.Purge(ctx, session); err != nil && !errors.Is(err, jetstream.ErrKeyNotFound) {
+		return err
+	}
+	return nil
+}
+
+// SetupLeases creates the lease KV bucket if it does not exist.
+func (l *JetStreamLog) SetupLeases(ctx context.Context) error {
+	kv, err := nats.SetupLeaseBucket(ctx, l.js, l.layout.LeaseBucket)
+	if err != nil {
+		return fmt.Errorf("failed to setup lease bucket: %w", err)
+	}
+	l.leases = kv
+	return nil
+}
+
+// GetLease reads a session's lease from the KV bucket. The revision is the
+// key's revision.
+func (l *JetStreamLog) GetLease(ctx context.Context, session string) ([]byte, uint64, error) {
+	if l.leases == nil {
+		return nil, 0, fmt.Errorf("lease bucket not initialized")
+	}
+	entry, err := l.leases.Get(ctx, session)
+	if err != nil {
+		if errors.Is(err, jetstream.ErrKeyNotFound) {
+			return nil, 0, ErrNoLease
+		}
+		return nil, 0, err
+	}
+	return entry.Value(), entry.Revision(), nil
+}
+
+// PutLease creates or updates a session's lease in the KV bucket, expecting
+// the key to be at revision rev.
+func (l *JetStreamLog) PutLease(ctx context.Context, session string, data []byte, rev uint64) (uint64, error) {
+	if l.leases == nil {
+		return 0, fmt.Errorf("lease bucket not initialized")
+	}
+	var err error
+	var newRev uint64
+	if rev == 0 {
+		newRev, err = l.leases.Create(ctx, session, data)
+	} else {
+		newRev, err = l.leases.Update(ctx, session, data, rev)
+	}
+	if errors.Is(err, jetstream.ErrKeyExists) || isWrongLastSequence(err) {
+		return 0, ErrSequenceConflict
+	}
+	return newRev, err
+}
+
+// DeleteLease purges a session's lease from the KV bucket, expecting the key
+// to be at revision rev unless it is 0.
+func (l *JetStreamLog) DeleteLease(ctx context.Context, session string, rev uint64) error {
+	if l.leases == nil {
+		return nil
+	}
+	var opts []jetstream.KVDeleteOpt
+	if rev != 0 {
+		opts = append(opts, jetstream.LastRevision(rev))
+	}
+	err := l.leases.Purge(ctx, session, opts...)
+	if isWrongLastSequence(err) {
+		return ErrSequenceConflict
+	}
+	if err != nil && !errors.Is(err, jetstream.ErrKeyNotFound) {
+		return err
+	}
+	return nil
+}
+
+// BackupKind returns "jetstream".
+func (l *JetStreamLog) BackupKind() string {
+	return "jetstream"
+}
+
+// Backup writes a snapshot of the stream, taken by the server at one point in
+// time. The stream's config and state go in the meta data.
+func (l *JetStreamLog) Backup(ctx context.Context, w io.Writer) (*BackupData, error) {
+	if l.nc == nil {
+		return nil, fmt.Errorf("stream snapshots need the NATS connection")
+	}
+	snapshot, err := nats.SnapshotStream(ctx, l.nc, l.layout.Stream, w)
+	if err != nil {
+		return nil, err
+	}
+	meta, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode stream snapshot: %w", err)
+	}
+	return &BackupData{Messages: snapshot.State.Msgs, LastSequence: snapshot.State.LastSeq, Meta: meta}, nil
+}
+
+// Restore replaces the stream with a snapshot of a stream of the same name.
+// The server restores a snapshot only under the name it was taken with, and
+// not while that stream exists, so the stream has to be deleted first: it is
+// snapshotted beforehand and put back if the restore fails.
+func (l *JetStreamLog) Restore(ctx context.Context, data *BackupData, r io.Reader) error {
+	if l.nc == nil {
+		return fmt.Errorf("stream snapshots need the NATS connection")
+	}
+	var snapshot nats.StreamSnapshot
+	if err := json.Unmarshal(data.Meta, &snapshot); err != nil {
+		return fmt.Errorf("invalid stream snapshot: %w", err)
+	}
+	if snapshot.Config.Name != l.layout.Stream {
+		return fmt.Errorf("backup is of stream %s, not %s", snapshot.Config.Name, l.layout.Stream)
+	}
+
+	saved, err := os.CreateTemp("", "iteratr-stream-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer func() {
+		_ = saved.Close()
+		_ = os.Remove(saved.Name())
+	}()
+	current, err := nats.SnapshotStream(ctx, l.nc, l.layout.Stream, saved)
+	if err != nil {
+		return fmt.Errorf("failed to save current stream: %w", err)
+	}
+
+	restoreErr := l.replaceStream(ctx, &snapshot, r, data)
+	if restoreErr == nil {
+		return nil
+	}
+	if _, err := saved.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("%w (and the current stream could not be put back: %v)", restoreErr, err)
+	}
+	currentData := &BackupData{Messages: current.State.Msgs, LastSequence: current.State.LastSeq}
+	if err := l.replaceStream(ctx, current, saved, currentData); err != nil {
+		return fmt.Errorf("%w (and the current stream could not be put back: %v)", restoreErr, err)
+	}
+	logger.Warn("Restore failed, put back the current stream: %v", restoreErr)
+	return restoreErr
+}
+
+// replaceStream deletes the stream, recreates it from a snapshot and checks
+// that it holds the messages of data.
+func (l *JetStreamLog) replaceStream(ctx context.Context, snapshot *nats.StreamSnapshot, r io.Reader, data *BackupData) error {
+	if err := l.js.DeleteStream(ctx, l.layout.Stream); err != nil && !errors.Is(err, jetstream.ErrStreamNotFound) {
+		return fmt.Errorf("failed to delete stream: %w", err)
+	}
+	if err := nats.RestoreStream(ctx, l.nc, snapshot, r); err != nil {
+		return err
+	}
+	stream, err := l.js.Stream(ctx, l.layout.Stream)
+	if err != nil {
+		return fmt.Errorf("failed to open restored stream: %w", err)
+	}
+	l.stream = stream
+
+	info, err := stream.Info(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read restored stream: %w", err)
+	}
+	return checkRestored(info.State.Msgs, info.State.LastSeq, data)
+}
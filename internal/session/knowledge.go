@@ -0,0 +1,389 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/iteratr/internal/logger"
+	"github.com/mark3labs/iteratr/internal/nats"
+)
+
+// EventTypeKnowledge is the type of knowledge base events. They are stored on
+// nats.SubjectKnowledge rather than under a session.
+const EventTypeKnowledge = "knowledge"
+
+// KnowledgeEntry is a learning, tip or decision kept at project level, so
+// that it outlives the session it was recorded in.
+type KnowledgeEntry struct {
+	ID           string    `json:"id"` // KNO-N
+	Content      string    `json:"content"`
+	Type         string    `json:"type"`             // learning, tip, decision
+	Source       string    `json:"source,omitempty"` // session/NOT-N the entry was promoted from
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	Retired      bool      `json:"retired,omitempty"`       // No longer injected into prompts
+	RetireReason string    `json:"retire_reason,omitempty"` // Why the entry was retired
+}
+
+// Knowledge is the project knowledge base, reconstructed from its events.
+type Knowledge struct {
+	Entries []*KnowledgeEntry `json:"entries"` // In creation order
+	Counter int               `json:"counter"` // Incrementing counter for KNO-N IDs
+}
+
+// Apply applies a knowledge event to the knowledge base. Like State.Apply,
+// it returns an ErrStateCorruption error for events it cannot apply.
+func (k *Knowledge) Apply(event Event) error {
+	event, err := Upcast(event)
+	if err != nil {
+		return err
+	}
+	switch event.Action {
+	case "add":
+		var meta KnowledgeAddMeta
+		if err := decodeMeta(event, &meta); err != nil {
+			return err
+		}
+		k.Counter++
+		k.Entries = append(k.Entries, &KnowledgeEntry{
+			ID:        event.ID,
+			Content:   event.Data,
+			Type:      meta.Type,
+			Source:    meta.Source,
+			CreatedAt: event.Timestamp,
+			UpdatedAt: event.Timestamp,
+		})
+
+	case "edit":
+		var meta KnowledgeEntryMeta
+		if err := decodeMeta(event, &meta); err != nil {
+			return err
+		}
+		if entry := k.Entry(meta.EntryID); entry != nil {
+			if event.Data != "" {
+				entry.Content = event.Data
+			}
+			if meta.Type != "" {
+				entry.Type = meta.Type
+			}
+			entry.UpdatedAt = event.Timestamp
+		}
+
+	case "retire", "restore":
+		var meta KnowledgeEntryMeta
+		if err := decodeMeta(event, &meta); err != nil {
+			return err
+		}
+		if entry := k.Entry(meta.EntryID); entry != nil {
+			entry.Retired = event.Action == "retire"
+			entry.RetireReason = ""
+			if entry.Retired {
+				entry.RetireReason = event.Data
+			}
+			entry.UpdatedAt = event.Timestamp
+		}
+
+	default:
+		return corruptEvent(event, "unknown action")
+	}
+	return nil
+}
+
+// Entry returns the entry with the given ID (case-insensitive), or nil.
+func (k *Knowledge) Entry(id string) *KnowledgeEntry {
+	for _, entry := range k.Entries {
+		if strings.EqualFold(entry.ID, id) {
+			return entry
+		}
+	}
+	return nil
+}
+
+// Active returns the entries that are not retired, in creation order.
+func (k *Knowledge) Active() []*KnowledgeEntry {
+	var active []*KnowledgeEntry
+	for _, entry := range k.Entries {
+		if !entry.Retired {
+			active = append(active, entry)
+		}
+	}
+	return active
+}
+
+// Relevant returns up to limit active entries, preferring those sharing the
+// most words with text (e.g. the spec) and then the most recently updated.
+// All active entries are returned, in creation order, if they fit.
+func (k *Knowledge) Relevant(text string, limit int) []*KnowledgeEntry {
+	active := k.Active()
+	if limit <= 0 || len(active) <= limit {
+		return active
+	}
+
+	words := make(map[string]bool)
+	for _, word := range knowledgeWords(text) {
+		words[word] = true
+	}
+	score := make(map[*KnowledgeEntry]int, len(active))
+	for _, entry := range active {
+		for _, word := range knowledgeWords(entry.Content) {
+			if words[word] {
+				score[entry]++
+			}
+		}
+	}
+
+	ranked := append([]*KnowledgeEntry(nil), active...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if score[ranked[i]] != score[ranked[j]] {
+			return score[ranked[i]] > score[ranked[j]]
+		}
+		return ranked[i].UpdatedAt.After(ranked[j].UpdatedAt)
+	})
+	ranked = ranked[:limit]
+
+	// Present the selection in creation order
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].CreatedAt.Before(ranked[j].CreatedAt)
+	})
+	return ranked
+}
+
+// knowledgeWords returns the distinct lowercase words of text that are long
+// enough to carry meaning for relevance ranking.
+func knowledgeWords(text string) []string {
+	seen := make(map[string]bool)
+	var words []string
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '_' && r != '-'
+	})
+	for _, word := range fields {
+		if len(word) >= 4 && !seen[word] {
+			seen[word] = true
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// KnowledgeAddParams represents the parameters for adding a knowledge entry.
+type KnowledgeAddParams struct {
+	Content string `json:"content"`
+	Type    string `json:"type"`             // learning, tip, decision
+	Source  string `json:"source,omitempty"` // session/NOT-N the entry comes from
+}
+
+// KnowledgeEditParams represents the parameters for editing a knowledge entry.
+type KnowledgeEditParams struct {
+	ID      string `json:"id"`
+	Content string `json:"content,omitempty"` // New content (empty keeps it)
+	Type    string `json:"type,omitempty"`    // New type (empty keeps it)
+}
+
+// LoadKnowledge reconstructs the project knowledge base from its events.
+func (s *Store) LoadKnowledge(ctx context.Context) (*Knowledge, error) {
+	kb, _, err := s.loadKnowledge(ctx)
+	return kb, err
+}
+
+// loadKnowledge also returns the sequence of the last knowledge event, for
+// publishing with publishIfLast.
+func (s *Store) loadKnowledge(ctx context.Context) (*Knowledge, uint64, error) {
+	kb := &Knowledge{}
+	var lastSeq uint64
+	_, err := s.forEachMsg(ctx, nats.SubjectKnowledge, 0, func(seq uint64, data []byte) bool {
+		lastSeq = seq // Skipped events count too, or publishIfLast would never match
+		var event Event
+		if err := json.Unmarshal(data, &event); err != nil {
+			logger.Warn("Skipping malformed knowledge event at seq %d: %v", seq, err)
+			return true
+		}
+		if err := kb.Apply(event); err != nil {
+			logger.Warn("Skipping knowledge event at seq %d: %v", seq, err)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to load knowledge base: %w", err)
+	}
+	return kb, lastSeq, nil
+}
+
+// withKnowledge runs fn against the latest knowledge base. Like
+// withAllocation, fn must publish with publishIfLast(ctx, event, expectedSeq)
+// and is retried if another writer got there first.
+func (s *Store) withKnowledge(ctx context.Context, fn func(kb *Knowledge, expectedSeq uint64) error) error {
+	return retryOnConflict(ctx, "knowledge entry", func() error {
+		kb, expectedSeq, err := s.loadKnowledge(ctx)
+		if err != nil {
+			return err
+		}
+		return fn(kb, expectedSeq)
+	})
+}
+
+// KnowledgeAdd adds an entry to the project knowledge base.
+// The KNO-N ID is allocated atomically: concurrent writers never receive the same ID.
+func (s *Store) KnowledgeAdd(ctx context.Context, params KnowledgeAddParams) (*KnowledgeEntry, error) {
+	if strings.TrimSpace(params.Content) == "" {
+		return nil, fmt.Errorf("content is required")
+	}
+	if !isValidKnowledgeType(params.Type) {
+		return nil, fmt.Errorf("invalid type: %s (must be learning, tip, or decision)", params.Type)
+	}
+
+	var entry *KnowledgeEntry
+	now := time.Now()
+	err := s.withKnowledge(ctx, func(kb *Knowledge, expectedSeq uint64) error {
+		if params.Source != "" {
+			for _, existing := range kb.Active() {
+				if existing.Source == params.Source {
+					return fmt.Errorf("%s is already in the knowledge base as %s", params.Source, existing.ID)
+				}
+			}
+		}
+
+		id := fmt.Sprintf("KNO-%d", kb.Counter+1)
+		meta := encodeMeta(KnowledgeAddMeta{Type: params.Type, Source: params.Source})
+		event := Event{
+			ID:        id,
+			Timestamp: now,
+			Type:      EventTypeKnowledge,
+			Action:    "add",
+			Data:      params.Content,
+			Meta:      meta,
+		}
+		if _, err := s.publishIfLast(ctx, event, expectedSeq); err != nil {
+			return err
+		}
+
+		entry = &KnowledgeEntry{
+			ID:        id,
+			Content:   params.Content,
+			Type:      params.Type,
+			Source:    params.Source,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// KnowledgePromote copies a note of a session into the project knowledge
+// base. Stuck notes describe a session's situation rather than the project
+// and cannot be promoted.
+func (s *Store) KnowledgePromote(ctx context.Context, session, noteID string) (*KnowledgeEntry, error) {
+	state, err := s.LoadState(ctx, session)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load state: %w", err)
+	}
+
+	var note *Note
+	for _, n := range state.Notes {
+		if strings.EqualFold(n.ID, noteID) {
+			note = n
+			break
+		}
+	}
+	if note == nil {
+		return nil, fmt.Errorf("note %s not found in session %s", noteID, session)
+	}
+	if !isValidKnowledgeType(note.Type) {
+		return nil, fmt.Errorf("%s notes cannot be promoted (only learning, tip, or decision)", note.Type)
+	}
+
+	return s.KnowledgeAdd(ctx, KnowledgeAddParams{
+		Content: note.Content,
+		Type:    note.Type,
+		Source:  session + "/" + note.ID,
+	})
+}
+
+// KnowledgeEdit changes the content and/or type of a knowledge entry.
+func (s *Store) KnowledgeEdit(ctx context.Context, params KnowledgeEditParams) error {
+	if params.ID == "" {
+		return fmt.Errorf("entry ID is required")
+	}
+	if strings.TrimSpace(params.Content) == "" && params.Type == "" {
+		return fmt.Errorf("new content or type is required")
+	}
+	if params.Type != "" && !isValidKnowledgeType(params.Type) {
+		return fmt.Errorf("invalid type: %s (must be learning, tip, or decision)", params.Type)
+	}
+
+	return s.withKnowledge(ctx, func(kb *Knowledge, expectedSeq uint64) error {
+		entry := kb.Entry(params.ID)
+		if entry == nil {
+			return fmt.Errorf("knowledge entry %s not found", params.ID)
+		}
+
+		meta := encodeMeta(KnowledgeEntryMeta{EntryID: entry.ID, Type: params.Type})
+		_, err := s.publishIfLast(ctx, Event{
+			Type:   EventTypeKnowledge,
+			Action: "edit",
+			Data:   params.Content,
+			Meta:   meta,
+		}, expectedSeq)
+		return err
+	})
+}
+
+// KnowledgeRetire stops a knowledge entry from being injected into prompts,
+// e.g. because it is outdated. The entry is kept and can be restored.
+func (s *Store) KnowledgeRetire(ctx context.Context, id, reason string) error {
+	return s.setKnowledgeRetired(ctx, id, true, reason)
+}
+
+// KnowledgeRestore makes a retired knowledge entry active again.
+func (s *Store) KnowledgeRestore(ctx context.Context, id string) error {
+	return s.setKnowledgeRetired(ctx, id, false, "")
+}
+
+func (s *Store) setKnowledgeRetired(ctx context.Context, id string, retired bool, reason string) error {
+	if id == "" {
+		return fmt.Errorf("entry ID is required")
+	}
+
+	return s.withKnowledge(ctx, func(kb *Knowledge, expectedSeq uint64) error {
+		entry := kb.Entry(id)
+		if entry == nil {
+			return fmt.Errorf("knowledge entry %s not found", id)
+		}
+		if entry.Retired == retired {
+			if retired {
+				return fmt.Errorf("knowledge entry %s is already retired", entry.ID)
+			}
+			return fmt.Errorf("knowledge entry %s is not retired", entry.ID)
+		}
+
+		action := "restore"
+		if retired {
+			action = "retire"
+		}
+		meta := encodeMeta(KnowledgeEntryMeta{EntryID: entry.ID})
+		_, err := s.publishIfLast(ctx, Event{
+			Type:   EventTypeKnowledge,
+			Action: action,
+			Data:   reason,
+			Meta:   meta,
+		}, expectedSeq)
+		return err
+	})
+}
+
+// isValidKnowledgeType checks if a note type can be kept in the knowledge base.
+func isValidKnowledgeType(entryType string) bool {
+	switch entryType {
+	case "learning", "tip", "decision":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,131 @@
+package session
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/iteratr/internal/nats"
+)
+
+func TestKnowledge(t *testing.T) {
+	// Setup: Create embedded NATS and store
+	ctx := context.Background()
+	ns, _, err := nats.StartEmbeddedNATS(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to start NATS: %v", err)
+	}
+	defer ns.Shutdown()
+
+	nc, err := nats.ConnectInProcess(ns)
+	if err != nil {
+		t.Fatalf("failed to connect to NATS: %v", err)
+	}
+	defer nc.Close()
+
+	js, err := nats.CreateJetStream(nc)
+	if err != nil {
+		t.Fatalf("failed to create JetStream: %v", err)
+	}
+
+	stream, err := nats.SetupStream(ctx, js)
+	if err != nil {
+		t.Fatalf("failed to setup stream: %v", err)
+	}
+
+	store := NewStore(NewJetStreamLog(js, stream))
+
+	note, _ := store.NoteAdd(ctx, "first", NoteAddParams{Content: "JetStream consumers need explicit acks", Type: "learning"})
+	promoted, err := store.KnowledgePromote(ctx, "first", strings.ToLower(note.ID))
+	if err != nil {
+		t.Fatalf("KnowledgePromote failed: %v", err)
+	}
+	added, err := store.KnowledgeAdd(ctx, KnowledgeAddParams{Content: "Use table-driven tests", Type: "decision"})
+	if err != nil {
+		t.Fatalf("KnowledgeAdd failed: %v", err)
+	}
+	if promoted.ID != "KNO-1" || added.ID != "KNO-2" {
+		t.Fatalf("expected KNO-1 and KNO-2, got %s and %s", promoted.ID, added.ID)
+	}
+
+	t.Run("not listed as a session", func(t *testing.T) {
+		names, err := store.log.Sessions(ctx)
+		if err != nil {
+			t.Fatalf("Sessions failed: %v", err)
+		}
+		if len(names) != 1 || names[0] != "first" {
+			t.Errorf("expected only session 'first', got %v", names)
+		}
+	})
+
+	t.Run("edit", func(t *testing.T) {
+		err := store.KnowledgeEdit(ctx, KnowledgeEditParams{ID: "KNO-2", Content: "Prefer table-driven tests", Type: "tip"})
+		if err != nil {
+			t.Fatalf("KnowledgeEdit failed: %v", err)
+		}
+		kb, _ := store.LoadKnowledge(ctx)
+		entry := kb.Entry("KNO-2")
+		if entry.Content != "Prefer table-driven tests" || entry.Type != "tip" {
+			t.Errorf("unexpected entry after edit: %+v", entry)
+		}
+		if err := store.KnowledgeEdit(ctx, KnowledgeEditParams{ID: "KNO-9", Content: "x"}); err == nil {
+			t.Error("expected error editing unknown entry")
+		}
+		if err := store.KnowledgeEdit(ctx, KnowledgeEditParams{ID: "KNO-2", Type: "stuck"}); err == nil {
+			t.Error("expected error for stuck type")
+		}
+	})
+
+	t.Run("retire and restore", func(t *testing.T) {
+		if err := store.KnowledgeRetire(ctx, "KNO-1", "acks are automatic now"); err != nil {
+			t.Fatalf("KnowledgeRetire failed: %v", err)
+		}
+		if err := store.KnowledgeRetire(ctx, "KNO-1", ""); err == nil {
+			t.Error("expected error retiring a retired entry")
+		}
+		kb, _ := store.LoadKnowledge(ctx)
+		if active := kb.Active(); len(active) != 1 || active[0].ID != "KNO-2" {
+			t.Errorf("expected only KNO-2 active, got %+v", active)
+		}
+		if kb.Entry("KNO-1").RetireReason != "acks are automatic now" {
+			t.Errorf("expected retire reason, got %q", kb.Entry("KNO-1").RetireReason)
+		}
+
+		// A retired entry's note can be promoted again
+		if _, err := store.KnowledgePromote(ctx, "first", note.ID); err != nil {
+			t.Errorf("expected promoting a retired note again to work: %v", err)
+		}
+
+		if err := store.KnowledgeRestore(ctx, "KNO-1"); err != nil {
+			t.Fatalf("KnowledgeRestore failed: %v", err)
+		}
+		kb, _ = store.LoadKnowledge(ctx)
+		if len(kb.Active()) != 3 || kb.Entry("KNO-1").RetireReason != "" {
+			t.Errorf("expected all entries active after restore, got %+v", kb.Entries)
+		}
+	})
+}
+
+func TestKnowledge_Relevant(t *testing.T) {
+	base := time.Now()
+	kb := &Knowledge{Entries: []*KnowledgeEntry{
+		{ID: "KNO-1", Content: "Frontend uses pnpm workspaces", CreatedAt: base, UpdatedAt: base},
+		{ID: "KNO-2", Content: "NATS subjects are lowercase", CreatedAt: base.Add(time.Minute), UpdatedAt: base.Add(time.Minute)},
+		{ID: "KNO-3", Content: "Retired entry about nats", CreatedAt: base.Add(2 * time.Minute), Retired: true},
+		{ID: "KNO-4", Content: "Release notes go in CHANGELOG", CreatedAt: base.Add(3 * time.Minute), UpdatedAt: base.Add(3 * time.Minute)},
+	}}
+
+	if got := kb.Relevant("anything", 10); len(got) != 3 {
+		t.Errorf("expected all 3 active entries when under the limit, got %d", len(got))
+	}
+
+	got := kb.Relevant("Add NATS subjects for the frontend", 2)
+	if len(got) != 2 || got[0].ID != "KNO-1" || got[1].ID != "KNO-2" {
+		ids := make([]string, len(got))
+		for i, e := range got {
+			ids[i] = e.ID
+		}
+		t.Errorf("expected KNO-1 and KNO-2 in creation order, got %v", ids)
+	}
+}
@@ -0,0 +1,149 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/iteratr/internal/nats"
+)
+
+// LabelUserAdded is applied to tasks created by the user from the TUI.
+const LabelUserAdded = "user-added"
+
+// TaskLabelParams represents the parameters for changing task labels.
+type TaskLabelParams struct {
+	ID        string   `json:"id"`               // Task ID or prefix
+	Add       []string `json:"add,omitempty"`    // Labels to add
+	Remove    []string `json:"remove,omitempty"` // Labels to remove
+	Iteration int      `json:"iteration"`
+}
+
+// TaskLabel adds and/or removes labels on an existing task.
+// The ID parameter supports prefix matching (minimum 3 characters).
+func (s *Store) TaskLabel(ctx context.Context, session string, params TaskLabelParams) error {
+	// Validate required fields
+	if params.ID == "" {
+		return fmt.Errorf("task ID is required")
+	}
+
+	add, err := normalizeLabels(params.Add)
+	if err != nil {
+		return err
+	}
+	remove, err := normalizeLabels(params.Remove)
+	if err != nil {
+		return err
+	}
+	if len(add) == 0 && len(remove) == 0 {
+		return fmt.Errorf("at least one label to add or remove is required")
+	}
+
+	// Load current state to resolve task ID prefix
+	state, err := s.LoadState(ctx, session)
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+
+	// Resolve task ID (supports prefix matching)
+	taskID, err := resolveTaskID(state, params.ID)
+	if err != nil {
+		return err
+	}
+
+	// Create event metadata
+	meta := encodeMeta(TaskLabelMeta{TaskID: taskID, Add: add, Remove: remove, Iteration: params.Iteration})
+
+	// Create and publish event
+	event := Event{
+		Session: session,
+		Type:    nats.EventTypeTask,
+		Action:  "label",
+		Data:    strings.Join(add, ","), // Store added labels in data field for convenience
+		Meta:    meta,
+	}
+
+	_, err = s.PublishEvent(ctx, event)
+	return err
+}
+
+// ParseLabels splits a comma-separated label list and normalizes each label.
+// Empty entries are ignored.
+func ParseLabels(raw string) ([]string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	return normalizeLabels(strings.Split(raw, ","))
+}
+
+// Labels returns all labels used by tasks in the session, sorted alphabetically.
+func (st *State) Labels() []string {
+	seen := make(map[string]bool)
+	for _, task := range st.Tasks {
+		for _, label := range task.Labels {
+			seen[label] = true
+		}
+	}
+
+	labels := make([]string, 0, len(seen))
+	for label := range seen {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+// HasLabels reports whether the task carries every one of the given labels.
+// An empty label list matches every task.
+func (t *Task) HasLabels(labels []string) bool {
+	for _, want := range labels {
+		found := false
+		for _, label := range t.Labels {
+			if label == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeLabels lowercases and trims labels, drops empty entries and duplicates,
+// and rejects labels containing whitespace or commas.
+func normalizeLabels(labels []string) ([]string, error) {
+	var result []string
+	seen := make(map[string]bool)
+	for _, label := range labels {
+		label = strings.ToLower(strings.TrimSpace(label))
+		if label == "" || seen[label] {
+			continue
+		}
+		if strings.ContainsAny(label, " \t\n,") {
+			return nil, fmt.Errorf("invalid label %q: labels cannot contain whitespace or commas", label)
+		}
+		seen[label] = true
+		result = append(result, label)
+	}
+	return result, nil
+}
+
+// addLabels returns labels with each of add appended if not already present.
+func addLabels(labels, add []string) []string {
+	for _, label := range add {
+		found := false
+		for _, existing := range labels {
+			if existing == label {
+				found = true
+				break
+			}
+		}
+		if !found {
+			labels = append(labels, label)
+		}
+	}
+	return labels
+}
@@ -0,0 +1,135 @@
+package session
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/mark3labs/iteratr/internal/nats"
+)
+
+func TestParseLabels(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    []string
+		wantErr bool
+	}{
+		{name: "empty", raw: "", want: nil},
+		{name: "single", raw: "backend", want: []string{"backend"}},
+		{name: "normalizes and dedupes", raw: " Backend, bug,,backend ", want: []string{"backend", "bug"}},
+		{name: "rejects whitespace", raw: "needs review", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLabels(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLabels(%q) error = %v, wantErr %v", tt.raw, err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseLabels(%q) = %v, want %v", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTaskLabels(t *testing.T) {
+	// Setup: Create embedded NATS and store
+	ctx := context.Background()
+	ns, _, err := nats.StartEmbeddedNATS(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to start NATS: %v", err)
+	}
+	defer ns.Shutdown()
+
+	nc, err := nats.ConnectInProcess(ns)
+	if err != nil {
+		t.Fatalf("failed to connect to NATS: %v", err)
+	}
+	defer nc.Close()
+
+	js, err := nats.CreateJetStream(nc)
+	if err != nil {
+		t.Fatalf("failed to create JetStream: %v", err)
+	}
+
+	stream, err := nats.SetupStream(ctx, js)
+	if err != nil {
+		t.Fatalf("failed to setup stream: %v", err)
+	}
+
+	store := NewStore(NewJetStreamLog(js, stream))
+
+	t.Run("TaskAdd records labels", func(t *testing.T) {
+		session := "test-labels-add"
+		task, err := store.TaskAdd(ctx, session, TaskAddParams{Content: "API", Labels: []string{"Backend", "bug"}})
+		if err != nil {
+			t.Fatalf("TaskAdd failed: %v", err)
+		}
+		if !reflect.DeepEqual(task.Labels, []string{"backend", "bug"}) {
+			t.Errorf("expected normalized labels, got %v", task.Labels)
+		}
+
+		state, err := store.LoadState(ctx, session)
+		if err != nil {
+			t.Fatalf("LoadState failed: %v", err)
+		}
+		if !reflect.DeepEqual(state.Tasks[task.ID].Labels, []string{"backend", "bug"}) {
+			t.Errorf("expected labels in state, got %v", state.Tasks[task.ID].Labels)
+		}
+
+		if _, err := store.TaskAdd(ctx, session, TaskAddParams{Content: "Bad", Labels: []string{"two words"}}); err == nil {
+			t.Error("expected error for label containing whitespace")
+		}
+	})
+
+	t.Run("TaskLabel adds and removes labels", func(t *testing.T) {
+		session := "test-labels-update"
+		task, _ := store.TaskAdd(ctx, session, TaskAddParams{Content: "API", Labels: []string{"backend"}})
+
+		err := store.TaskLabel(ctx, session, TaskLabelParams{ID: task.ID, Add: []string{"urgent", "backend"}, Remove: []string{"backend"}})
+		if err != nil {
+			t.Fatalf("TaskLabel failed: %v", err)
+		}
+
+		state, _ := store.LoadState(ctx, session)
+		if !reflect.DeepEqual(state.Tasks[task.ID].Labels, []string{"urgent"}) {
+			t.Errorf("expected labels [urgent], got %v", state.Tasks[task.ID].Labels)
+		}
+		if !reflect.DeepEqual(state.Labels(), []string{"urgent"}) {
+			t.Errorf("expected session labels [urgent], got %v", state.Labels())
+		}
+
+		if err := store.TaskLabel(ctx, session, TaskLabelParams{ID: task.ID}); err == nil {
+			t.Error("expected error when no labels given")
+		}
+	})
+
+	t.Run("TaskList and TaskNext filter by label", func(t *testing.T) {
+		session := "test-labels-filter"
+		backend, _ := store.TaskAdd(ctx, session, TaskAddParams{Content: "API", Priority: 2, Labels: []string{"backend"}})
+		_, _ = store.TaskAdd(ctx, session, TaskAddParams{Content: "Button", Priority: 0, Labels: []string{"frontend"}})
+
+		list, err := store.TaskList(ctx, session, TaskListParams{Labels: []string{"backend"}})
+		if err != nil {
+			t.Fatalf("TaskList failed: %v", err)
+		}
+		if len(list.Remaining) != 1 || list.Remaining[0].ID != backend.ID {
+			t.Errorf("expected only %s, got %v", backend.ID, list.Remaining)
+		}
+
+		next, err := store.TaskNext(ctx, session, TaskNextParams{Labels: []string{"backend"}})
+		if err != nil {
+			t.Fatalf("TaskNext failed: %v", err)
+		}
+		if next == nil || next.ID != backend.ID {
+			t.Errorf("expected %s, got %v", backend.ID, next)
+		}
+
+		next, _ = store.TaskNext(ctx, session, TaskNextParams{Labels: []string{"docs"}})
+		if next != nil {
+			t.Errorf("expected no task for unused label, got %v", next)
+		}
+	})
+}
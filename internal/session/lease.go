@@ -0,0 +1,252 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/iteratr/internal/logger"
+)
+
+const (
+	// LeaseTTL is how long a session lease stays valid without a renewal.
+	// The holder renews it every leaseRenewInterval, so a lease only expires
+	// when its process crashed, hung or lost its connection to the storage.
+	LeaseTTL = 30 * time.Second
+
+	// leaseRenewInterval is how often the holder renews its lease.
+	leaseRenewInterval = LeaseTTL / 3
+)
+
+// ErrSessionLocked is returned by AcquireLease while another live process
+// holds the session's lease.
+var ErrSessionLocked = errors.New("session is locked")
+
+// ErrNoLease is returned when a session has no lease.
+var ErrNoLease = errors.New("no lease")
+
+// LeaseHolder identifies the process holding a session's lease.
+type LeaseHolder struct {
+	Host       string    `json:"host"`
+	PID        int       `json:"pid"`
+	AcquiredAt time.Time `json:"acquired_at"`
+	ExpiresAt  time.Time `json:"expires_at"` // Pushed forward by every renewal
+}
+
+// String describes the holder, e.g. "pid 4242 on build-box since 14:03:12".
+func (h LeaseHolder) String() string {
+	return fmt.Sprintf("pid %d on %s since %s", h.PID, h.Host, h.AcquiredAt.Local().Format(time.TimeOnly))
+}
+
+// Stale reports whether the lease can be taken over: it has expired, or it is
+// held by a process on this host that no longer exists.
+func (h LeaseHolder) Stale(now time.Time) bool {
+	if now.After(h.ExpiresAt) {
+		return true
+	}
+	host, _ := os.Hostname()
+	return h.Host == host && h.PID != os.Getpid() && !processAlive(h.PID)
+}
+
+// Lease is a session's ownership lease held by this process. It is renewed in
+// the background until Release is called or it is lost.
+type Lease struct {
+	leases  LeaseStore
+	session string
+
+	mu     sync.Mutex
+	holder LeaseHolder
+	rev    uint64
+
+	lost    chan struct{} // Closed when another process took the lease over
+	stop    chan struct{} // Closed by Release to end the heartbeat
+	done    chan struct{} // Closed when the heartbeat has ended
+	release sync.Once
+}
+
+// AcquireLease takes the ownership lease of a session for this process, so
+// that only one build loop runs the session at a time. It fails with
+// ErrSessionLocked while another live process holds the lease. Stale leases,
+// left behind by crashed processes, are taken over.
+func (s *Store) AcquireLease(ctx context.Context, session string) (*Lease, error) {
+	leases, err := s.leaseStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	data, rev, err := leases.GetLease(ctx, session)
+	switch {
+	case errors.Is(err, ErrNoLease):
+	case err != nil:
+		return nil, fmt.Errorf("failed to read lease: %w", err)
+	default:
+		var current LeaseHolder
+		if err := json.Unmarshal(data, &current); err != nil {
+			logger.Warn("Replacing unreadable lease of session %s: %v", session, err)
+		} else if !current.Stale(time.Now()) {
+			return nil, fmt.Errorf("%w by %s", ErrSessionLocked, current)
+		} else {
+			logger.Warn("Taking over stale lease of session %s held by %s", session, current)
+		}
+	}
+
+	host, _ := os.Hostname()
+	now := time.Now()
+	holder := LeaseHolder{Host: host, PID: os.Getpid(), AcquiredAt: now, ExpiresAt: now.Add(LeaseTTL)}
+	data, err = json.Marshal(holder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal lease: %w", err)
+	}
+	rev, err = leases.PutLease(ctx, session, data, rev)
+	if errors.Is(err, ErrSequenceConflict) {
+		// Another process got there first
+		return nil, fmt.Errorf("%w by another process", ErrSessionLocked)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write lease: %w", err)
+	}
+
+	lease := &Lease{
+		leases:  leases,
+		session: session,
+		holder:  holder,
+		rev:     rev,
+		lost:    make(chan struct{}),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go lease.heartbeat()
+	logger.Debug("Acquired lease of session %s (rev=%d)", session, rev)
+	return lease, nil
+}
+
+// ReadLease returns the holder of a session's lease, or ErrNoLease.
+func (s *Store) ReadLease(ctx context.Context, session string) (*LeaseHolder, error) {
+	leases, err := s.leaseStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+	data, _, err := leases.GetLease(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+	var holder LeaseHolder
+	if err := json.Unmarshal(data, &holder); err != nil {
+		return nil, fmt.Errorf("invalid lease: %w", err)
+	}
+	return &holder, nil
+}
+
+// BreakLease removes a session's lease whoever holds it, to recover from a
+// holder that can't release it. A holder that is still running loses the
+// lease on its next renewal.
+func (s *Store) BreakLease(ctx context.Context, session string) error {
+	leases, err := s.leaseStore(ctx)
+	if err != nil {
+		return err
+	}
+	if err := leases.DeleteLease(ctx, session, 0); err != nil {
+		return fmt.Errorf("failed to delete lease: %w", err)
+	}
+	return nil
+}
+
+// leaseStore returns the event log's lease storage, prepared for use.
+func (s *Store) leaseStore(ctx context.Context) (LeaseStore, error) {
+	leases, ok := s.log.(LeaseStore)
+	if !ok {
+		return nil, fmt.Errorf("storage backend does not support session leases")
+	}
+	if err := leases.SetupLeases(ctx); err != nil {
+		return nil, err
+	}
+	return leases, nil
+}
+
+// Holder returns this process's lease holder record.
+func (l *Lease) Holder() LeaseHolder {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.holder
+}
+
+// Lost returns a channel that is closed if another process takes the lease
+// over, after it expired or was broken. The session must not be run any
+// further then.
+func (l *Lease) Lost() <-chan struct{} {
+	return l.lost
+}
+
+// Release stops renewing the lease and deletes it, unless another process has
+// taken it over. Safe to call more than once.
+func (l *Lease) Release(ctx context.Context) error {
+	var err error
+	l.release.Do(func() {
+		close(l.stop)
+		<-l.done
+		select {
+		case <-l.lost:
+			return
+		default:
+		}
+		l.mu.Lock()
+		rev := l.rev
+		l.mu.Unlock()
+		if err = l.leases.DeleteLease(ctx, l.session, rev); errors.Is(err, ErrSequenceConflict) {
+			err = nil // Taken over since the last renewal
+		}
+		logger.Debug("Released lease of session %s", l.session)
+	})
+	return err
+}
+
+// heartbeat renews the lease until Release is called or the lease is lost.
+// Failed renewals are retried; the lease is only lost once another process
+// has written it.
+func (l *Lease) heartbeat() {
+	defer close(l.done)
+	ticker := time.NewTicker(leaseRenewInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+		}
+		err := l.renew(context.Background())
+		if errors.Is(err, ErrSequenceConflict) {
+			logger.Error("Lost lease of session %s: another process took it over", l.session)
+			close(l.lost)
+			return
+		}
+		if err != nil {
+			logger.Warn("Failed to renew lease of session %s: %v", l.session, err)
+		}
+	}
+}
+
+// renew pushes the lease's expiry forward.
+func (l *Lease) renew(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, leaseRenewInterval)
+	defer cancel()
+
+	holder := l.holder
+	holder.ExpiresAt = time.Now().Add(LeaseTTL)
+	data, err := json.Marshal(holder)
+	if err != nil {
+		return err
+	}
+	rev, err := l.leases.PutLease(ctx, l.session, data, l.rev)
+	if err != nil {
+		return err
+	}
+	l.holder, l.rev = holder, rev
+	return nil
+}
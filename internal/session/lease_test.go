@@ -0,0 +1,112 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestLease(t *testing.T) {
+	for name, open := range eventLogBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			store := NewStore(open(t))
+
+			lease, err := store.AcquireLease(ctx, "s")
+			if err != nil {
+				t.Fatalf("AcquireLease failed: %v", err)
+			}
+			if _, err := store.AcquireLease(ctx, "s"); !errors.Is(err, ErrSessionLocked) {
+				t.Fatalf("expected ErrSessionLocked, got %v", err)
+			}
+			if _, err := store.AcquireLease(ctx, "other"); err != nil {
+				t.Errorf("expected other session to be free, got %v", err)
+			}
+			holder, err := store.ReadLease(ctx, "s")
+			if err != nil || holder.PID != os.Getpid() {
+				t.Fatalf("expected lease held by this process, got %+v (%v)", holder, err)
+			}
+
+			// Renewing pushes the expiry forward
+			before := lease.Holder().ExpiresAt
+			time.Sleep(5 * time.Millisecond)
+			if err := lease.renew(ctx); err != nil {
+				t.Fatalf("renew failed: %v", err)
+			}
+			if !lease.Holder().ExpiresAt.After(before) {
+				t.Error("expected expiry to move forward")
+			}
+
+			// Released leases can be taken again
+			if err := lease.Release(ctx); err != nil {
+				t.Fatalf("Release failed: %v", err)
+			}
+			if _, err := store.ReadLease(ctx, "s"); !errors.Is(err, ErrNoLease) {
+				t.Fatalf("expected ErrNoLease after release, got %v", err)
+			}
+			lease, err = store.AcquireLease(ctx, "s")
+			if err != nil {
+				t.Fatalf("AcquireLease after release failed: %v", err)
+			}
+
+			// A broken lease is lost by its holder, whose release leaves the
+			// new holder's lease alone
+			if err := store.BreakLease(ctx, "s"); err != nil {
+				t.Fatalf("BreakLease failed: %v", err)
+			}
+			if err := lease.renew(ctx); !errors.Is(err, ErrSequenceConflict) {
+				t.Errorf("expected renew of broken lease to conflict, got %v", err)
+			}
+			if _, err := store.AcquireLease(ctx, "s"); err != nil {
+				t.Fatalf("AcquireLease after break failed: %v", err)
+			}
+			if err := lease.Release(ctx); err != nil {
+				t.Errorf("Release of broken lease failed: %v", err)
+			}
+			if _, err := store.ReadLease(ctx, "s"); err != nil {
+				t.Errorf("expected new lease to survive old holder's release, got %v", err)
+			}
+		})
+	}
+}
+
+func TestLease_Stale(t *testing.T) {
+	ctx := context.Background()
+	host, _ := os.Hostname()
+	now := time.Now()
+
+	tests := []struct {
+		name   string
+		holder LeaseHolder
+		stale  bool
+	}{
+		{"live process elsewhere", LeaseHolder{Host: "elsewhere", PID: 1, ExpiresAt: now.Add(time.Minute)}, false},
+		{"expired", LeaseHolder{Host: "elsewhere", PID: 1, ExpiresAt: now.Add(-time.Second)}, true},
+		{"dead process on this host", LeaseHolder{Host: host, PID: 999999999, ExpiresAt: now.Add(time.Minute)}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if stale := tt.holder.Stale(now); stale != tt.stale {
+				t.Fatalf("expected stale=%v, got %v", tt.stale, stale)
+			}
+
+			log := NewMemoryLog()
+			data, _ := json.Marshal(tt.holder)
+			if _, err := log.PutLease(ctx, "s", data, 0); err != nil {
+				t.Fatalf("PutLease failed: %v", err)
+			}
+			lease, err := NewStore(log).AcquireLease(ctx, "s")
+			if tt.stale {
+				if err != nil {
+					t.Fatalf("expected stale lease to be taken over, got %v", err)
+				}
+				_ = lease.Release(ctx)
+			} else if !errors.Is(err, ErrSessionLocked) {
+				t.Fatalf("expected ErrSessionLocked, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package session
+
+import "os"
+
+// processAlive reports whether a process with the given PID exists. Opening
+// a process fails on Windows once it has exited.
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	_ = p.Release()
+	return true
+}
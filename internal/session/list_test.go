@@ -2,7 +2,10 @@ package session
 
 import (
 	"context"
+	"encoding/json"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/mark3labs/iteratr/internal/nats"
 )
@@ -32,7 +35,7 @@ func TestListSessions(t *testing.T) {
 		t.Fatalf("failed to setup stream: %v", err)
 	}
 
-	store := NewStore(js, stream)
+	store := NewStore(NewJetStreamLog(js, stream))
 
 	t.Run("ListSessions returns empty when no sessions exist", func(t *testing.T) {
 		infos, err := store.ListSessions(ctx)
@@ -187,3 +190,121 @@ func TestListSessions(t *testing.T) {
 		}
 	})
 }
+
+func TestListSessions_Summaries(t *testing.T) {
+	ctx := context.Background()
+	log := NewMemoryLog()
+	store := NewStore(log)
+	if err := store.EnableSnapshots(ctx); err != nil {
+		t.Fatalf("EnableSnapshots failed: %v", err)
+	}
+
+	_, _ = store.TaskAdd(ctx, "alpha", TaskAddParams{Content: "One"})
+	_, _ = store.TaskAdd(ctx, "alpha", TaskAddParams{Content: "Two"})
+	_ = store.TaskStatus(ctx, "alpha", TaskStatusParams{ID: "TAS-1", Status: "completed"})
+
+	// matches compares the listed info of a session with its replayed state
+	matches := func(t *testing.T, name string) {
+		t.Helper()
+		infos, err := store.ListSessions(ctx)
+		if err != nil {
+			t.Fatalf("ListSessions failed: %v", err)
+		}
+		state, _ := store.LoadState(ctx, name)
+		completed := 0
+		for _, task := range state.Tasks {
+			if task.Status == "completed" {
+				completed++
+			}
+		}
+		for _, info := range infos {
+			if info.Name != name {
+				continue
+			}
+			if info.TasksTotal != len(state.Tasks) || info.TasksCompleted != completed || info.Complete != state.Complete {
+				t.Errorf("summary %+v does not match state: %d tasks, %d completed, complete=%v",
+					info, len(state.Tasks), completed, state.Complete)
+			}
+			return
+		}
+		t.Fatalf("session %s not listed", name)
+	}
+
+	t.Run("publish stores an up to date summary", func(t *testing.T) {
+		data, err := log.GetSnapshot(ctx, summaryKey("alpha"))
+		if err != nil {
+			t.Fatalf("expected stored summary: %v", err)
+		}
+		var sum sessionSummary
+		_ = json.Unmarshal(data, &sum)
+		last, _ := log.LastSequence(ctx, nats.SubjectForSessionEvents("alpha"))
+		if sum.AfterSequence != last || len(sum.Tasks) != 2 {
+			t.Errorf("expected summary after seq %d with 2 tasks, got %+v", last, sum)
+		}
+		matches(t, "alpha")
+	})
+
+	t.Run("events from other writers are caught up", func(t *testing.T) {
+		other := NewStore(log) // Snapshots disabled: leaves the summary behind
+		_ = other.TaskRemove(ctx, "alpha", TaskRemoveParams{ID: "TAS-2"})
+		_ = other.SessionComplete(ctx, "alpha")
+		matches(t, "alpha")
+	})
+
+	t.Run("compaction and reset", func(t *testing.T) {
+		if _, _, err := store.CompactSession(ctx, "alpha"); err != nil {
+			t.Fatalf("CompactSession failed: %v", err)
+		}
+		matches(t, "alpha")
+
+		_ = store.ResetSession(ctx, "alpha")
+		_, _ = store.TaskAdd(ctx, "alpha", TaskAddParams{Content: "Fresh"})
+		matches(t, "alpha")
+	})
+
+	t.Run("stale summary is rebuilt", func(t *testing.T) {
+		_ = log.PutSnapshot(ctx, summaryKey("alpha"), []byte(`{"version":99}`))
+		matches(t, "alpha")
+	})
+}
+
+func TestListSessionsPage(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore(NewMemoryLog())
+	for _, name := range []string{"b", "c", "a"} {
+		_, _ = store.TaskAdd(ctx, name, TaskAddParams{Content: "Task"})
+		time.Sleep(2 * time.Millisecond) // Distinct timestamps
+	}
+
+	names := func(infos []SessionInfo) []string {
+		var result []string
+		for _, info := range infos {
+			result = append(result, info.Name)
+		}
+		return result
+	}
+
+	tests := []struct {
+		params SessionListParams
+		want   []string
+	}{
+		{SessionListParams{}, []string{"a", "c", "b"}},
+		{SessionListParams{Sort: SortByName}, []string{"a", "b", "c"}},
+		{SessionListParams{Sort: SortByCreated, Limit: 2}, []string{"a", "c"}},
+		{SessionListParams{Sort: SortByName, Offset: 1, Limit: 1}, []string{"b"}},
+		{SessionListParams{Offset: 5}, nil},
+	}
+	for _, tt := range tests {
+		infos, total, err := store.ListSessionsPage(ctx, tt.params)
+		if err != nil {
+			t.Fatalf("ListSessionsPage(%+v) failed: %v", tt.params, err)
+		}
+		if total != 3 || !reflect.DeepEqual(names(infos), tt.want) {
+			t.Errorf("ListSessionsPage(%+v) = %v (total %d), want %v (total 3)", tt.params, names(infos), total, tt.want)
+		}
+	}
+
+	if _, _, err := store.ListSessionsPage(ctx, SessionListParams{Sort: "size"}); err == nil {
+		t.Error("expected error for invalid sort order")
+	}
+}
@@ -0,0 +1,244 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/iteratr/internal/logger"
+	"github.com/mark3labs/iteratr/internal/nats"
+)
+
+// SessionExists reports whether a session has any events.
+func (s *Store) SessionExists(ctx context.Context, session string) (bool, error) {
+	count, err := s.log.Count(ctx, nats.SubjectForSession(session))
+	if err != nil {
+		return false, fmt.Errorf("failed to count session events: %w", err)
+	}
+	return count > 0, nil
+}
+
+// ForkSession copies the events of source up to the given point into a new
+// session named target, so work can be retried from a known-good point (for
+// example with a different model or template). A zero point copies the whole
+// session. Transcripts of the copied iterations are copied as well. The source
+// is left untouched. Returns the number of events copied.
+func (s *Store) ForkSession(ctx context.Context, source, target string, at StatePoint) (int, error) {
+	if at != (StatePoint{}) {
+		if err := at.Validate(); err != nil {
+			return 0, err
+		}
+	}
+	if err := s.checkCopyTarget(ctx, source, target); err != nil {
+		return 0, err
+	}
+
+	count, _, err := s.copyEvents(ctx, source, target, func(seq uint64, event Event) bool {
+		return at == (StatePoint{}) || at.Includes(seq, event)
+	})
+	if err != nil {
+		return 0, err
+	}
+	logger.Info("Forked session '%s' into '%s' (%d events)", source, target, count)
+	return count, nil
+}
+
+// RenameSession moves all messages of a session, events and transcripts, to
+// the subjects of a new name (iteratr.{target}.>). Every message is copied as
+// it is, with only its subject and the events' session field changed; a
+// session holding an event that can't be rewritten is not renamed at all. The
+// source is purged only once the target holds as many messages as the source
+// did, so a failure never loses events. If the source receives new events
+// while it is being copied, the copy is discarded and an error is returned.
+// Callers should hold the leases of both sessions, so no build loop writes to
+// them meanwhile.
+func (s *Store) RenameSession(ctx context.Context, source, target string) error {
+	if err := s.checkCopyTarget(ctx, source, target); err != nil {
+		return err
+	}
+
+	before, err := s.log.Count(ctx, nats.SubjectForSession(source))
+	if err != nil {
+		return fmt.Errorf("failed to count session events: %w", err)
+	}
+
+	// Everything is rewritten before anything is written, so nothing that
+	// can't be copied is found halfway through
+	sourcePrefix := strings.TrimSuffix(nats.SubjectForSession(source), ">")
+	targetPrefix := strings.TrimSuffix(nats.SubjectForSession(target), ">")
+	var msgs []Message
+	var rewriteErr error
+	if err := s.log.Read(ctx, nats.SubjectForSession(source), 0, func(msg Message) bool {
+		rest := strings.TrimPrefix(msg.Subject, sourcePrefix)
+		if !strings.Contains(rest, ".") {
+			// iteratr.{session}.{type}: an event naming its session
+			if msg.Data, rewriteErr = renameEvent(msg.Data, target); rewriteErr != nil {
+				rewriteErr = fmt.Errorf("can't rename event (seq=%d): %w", msg.Sequence, rewriteErr)
+				return false
+			}
+		}
+		msg.Subject = targetPrefix + rest
+		msgs = append(msgs, msg)
+		return true
+	}); err != nil {
+		return fmt.Errorf("failed to read session: %w", err)
+	}
+	if rewriteErr != nil {
+		return rewriteErr
+	}
+
+	for i, msg := range msgs {
+		if _, err := s.log.Append(ctx, msg.Subject, msg.Data); err != nil {
+			s.discardCopy(ctx, target)
+			return fmt.Errorf("failed to copy message %d of %d: %w", i+1, len(msgs), err)
+		}
+	}
+	s.updateSummary(ctx, target)
+
+	// Make sure nothing was appended to the source while copying, and that
+	// all of it was copied
+	after, err := s.log.Count(ctx, nats.SubjectForSession(source))
+	if err != nil {
+		s.discardCopy(ctx, target)
+		return fmt.Errorf("failed to verify rename: %w", err)
+	}
+	if after != before {
+		s.discardCopy(ctx, target)
+		return fmt.Errorf("session %q changed during rename (is it running?), try again", source)
+	}
+	copied, err := s.log.Count(ctx, nats.SubjectForSession(target))
+	if err != nil {
+		s.discardCopy(ctx, target)
+		return fmt.Errorf("failed to verify rename: %w", err)
+	}
+	if copied != before {
+		s.discardCopy(ctx, target)
+		return fmt.Errorf("copied %d of %d messages of session %q, not renamed", copied, before, source)
+	}
+
+	if err := s.ResetSession(ctx, source); err != nil {
+		return fmt.Errorf("copied session to %q but failed to remove %q: %w", target, source, err)
+	}
+	logger.Info("Renamed session '%s' to '%s' (%d messages)", source, target, before)
+	return nil
+}
+
+// renameEvent sets the session field of an encoded event, leaving the rest of
+// it as it was.
+func renameEvent(data []byte, session string) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	name, err := json.Marshal(session)
+	if err != nil {
+		return nil, err
+	}
+	fields["session"] = name
+	return json.Marshal(fields)
+}
+
+// checkCopyTarget validates that source exists and target is a valid, unused name.
+func (s *Store) checkCopyTarget(ctx context.Context, source, target string) error {
+	if err := ValidateName(target); err != nil {
+		return err
+	}
+	if exists, err := s.SessionExists(ctx, source); err != nil {
+		return err
+	} else if !exists {
+		return fmt.Errorf("session %q not found", source)
+	}
+	if exists, err := s.SessionExists(ctx, target); err != nil {
+		return err
+	} else if exists {
+		return fmt.Errorf("session %q already exists", target)
+	}
+	return nil
+}
+
+// copyEvents republishes the events of source under target, in stream order,
+// up to the first event include rejects, followed by the transcripts of the
+// iterations started by the copied events. Events keep their IDs and timestamps.
+// A partial copy is discarded on failure. Returns the number of events and
+// transcript entries copied.
+func (s *Store) copyEvents(ctx context.Context, source, target string, include func(seq uint64, event Event) bool) (int, int, error) {
+	var events []Event
+	iterations := make(map[int]bool)
+	if _, err := s.forEachEvent(ctx, source, 0, func(seq uint64, event Event) bool {
+		if !include(seq, event) {
+			return false
+		}
+		events = append(events, event)
+		if event.Type == nats.EventTypeIteration && event.Action == "start" {
+			var meta IterationStartMeta
+			_ = DecodeMeta(event, &meta)
+			iterations[meta.Number] = true
+		}
+		return true
+	}); err != nil {
+		return 0, 0, err
+	}
+	if len(events) == 0 {
+		return 0, 0, fmt.Errorf("no events to copy from session %q", source)
+	}
+
+	if err := s.publishAll(ctx, target, events); err != nil {
+		s.discardCopy(ctx, target)
+		return 0, 0, err
+	}
+
+	entries, err := s.copyTranscripts(ctx, source, target, iterations)
+	if err != nil {
+		s.discardCopy(ctx, target)
+		return 0, 0, err
+	}
+	return len(events), entries, nil
+}
+
+// copyTranscripts republishes the transcript entries of the given iterations
+// of source under target. Returns the number of entries copied.
+func (s *Store) copyTranscripts(ctx context.Context, source, target string, iterations map[int]bool) (int, error) {
+	count := 0
+	var publishErr error
+	_, err := s.forEachMsg(ctx, nats.SubjectForTranscripts(source), 0, func(seq uint64, data []byte) bool {
+		var entry struct {
+			Iteration int `json:"iteration"`
+		}
+		if err := json.Unmarshal(data, &entry); err != nil || !iterations[entry.Iteration] {
+			return true
+		}
+		if _, publishErr = s.log.Append(ctx, nats.SubjectForTranscript(target, entry.Iteration), data); publishErr != nil {
+			return false
+		}
+		count++
+		return true
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to read transcripts: %w", err)
+	}
+	if publishErr != nil {
+		return 0, fmt.Errorf("failed to copy transcript: %w", publishErr)
+	}
+	return count, nil
+}
+
+// publishAll publishes events in order under the given session name.
+func (s *Store) publishAll(ctx context.Context, session string, events []Event) error {
+	// The summary is brought up to date once, after the last event
+	defer s.updateSummary(ctx, session)
+	for i, event := range events {
+		event.Session = session
+		if _, err := s.appendEvent(ctx, event, nil); err != nil {
+			return fmt.Errorf("failed to publish event %d of %d: %w", i+1, len(events), err)
+		}
+	}
+	return nil
+}
+
+// discardCopy removes a partially written session after a failed copy.
+func (s *Store) discardCopy(ctx context.Context, session string) {
+	if err := s.ResetSession(ctx, session); err != nil {
+		logger.Warn("Failed to discard partial session '%s': %v", session, err)
+	}
+}
@@ -0,0 +1,134 @@
+package session
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/iteratr/internal/nats"
+)
+
+func TestRenameAndForkSession(t *testing.T) {
+	// Setup: Create embedded NATS and store
+	ctx := context.Background()
+	ns, _, err := nats.StartEmbeddedNATS(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to start NATS: %v", err)
+	}
+	defer ns.Shutdown()
+
+	nc, err := nats.ConnectInProcess(ns)
+	if err != nil {
+		t.Fatalf("failed to connect to NATS: %v", err)
+	}
+	defer nc.Close()
+
+	js, err := nats.CreateJetStream(nc)
+	if err != nil {
+		t.Fatalf("failed to create JetStream: %v", err)
+	}
+
+	stream, err := nats.SetupStream(ctx, js)
+	if err != nil {
+		t.Fatalf("failed to setup stream: %v", err)
+	}
+
+	store := NewStore(NewJetStreamLog(js, stream))
+
+	// Two iterations: TAS-1 added in the first, TAS-2 in the second
+	_ = store.IterationStart(ctx, "original", 1)
+	task1, _ := store.TaskAdd(ctx, "original", TaskAddParams{Content: "First", Iteration: 1})
+	_ = store.IterationComplete(ctx, "original", 1, IterationResult{})
+	_ = store.IterationStart(ctx, "original", 2)
+	task2, _ := store.TaskAdd(ctx, "original", TaskAddParams{Content: "Second", Iteration: 2})
+	_ = store.IterationComplete(ctx, "original", 2, IterationResult{})
+
+	t.Run("fork at iteration", func(t *testing.T) {
+		count, err := store.ForkSession(ctx, "original", "forked", StatePoint{Iteration: 1})
+		if err != nil {
+			t.Fatalf("ForkSession failed: %v", err)
+		}
+		if count != 3 {
+			t.Errorf("expected 3 events copied, got %d", count)
+		}
+
+		state, _ := store.LoadState(ctx, "forked")
+		if len(state.Tasks) != 1 || state.Tasks[task1.ID] == nil {
+			t.Errorf("expected only %s in fork, got %v", task1.ID, state.Tasks)
+		}
+		if len(state.Iterations) != 1 {
+			t.Errorf("expected 1 iteration in fork, got %d", len(state.Iterations))
+		}
+
+		// Source is untouched and the fork continues independently
+		original, _ := store.LoadState(ctx, "original")
+		if len(original.Tasks) != 2 {
+			t.Errorf("expected source to keep 2 tasks, got %d", len(original.Tasks))
+		}
+		next, err := store.TaskAdd(ctx, "forked", TaskAddParams{Content: "Retry", Iteration: 2})
+		if err != nil {
+			t.Fatalf("TaskAdd on fork failed: %v", err)
+		}
+		if next.ID != task2.ID {
+			t.Errorf("expected fork to allocate %s, got %s", task2.ID, next.ID)
+		}
+	})
+
+	t.Run("fork whole session", func(t *testing.T) {
+		count, err := store.ForkSession(ctx, "original", "copy", StatePoint{})
+		if err != nil {
+			t.Fatalf("ForkSession failed: %v", err)
+		}
+		if count != 6 {
+			t.Errorf("expected 6 events copied, got %d", count)
+		}
+	})
+
+	t.Run("rename", func(t *testing.T) {
+		if err := store.RenameSession(ctx, "copy", "renamed"); err != nil {
+			t.Fatalf("RenameSession failed: %v", err)
+		}
+		if exists, _ := store.SessionExists(ctx, "copy"); exists {
+			t.Error("expected old session to be removed")
+		}
+		state, _ := store.LoadState(ctx, "renamed")
+		if len(state.Tasks) != 2 || len(state.Iterations) != 2 {
+			t.Errorf("expected 2 tasks and 2 iterations, got %d and %d", len(state.Tasks), len(state.Iterations))
+		}
+		events, _ := store.History(ctx, "renamed")
+		for _, e := range events {
+			if e.Session != "renamed" {
+				t.Errorf("expected event session renamed, got %s", e.Session)
+			}
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		if err := store.RenameSession(ctx, "missing", "other"); err == nil {
+			t.Error("expected error renaming missing session")
+		}
+		if err := store.RenameSession(ctx, "original", "renamed"); err == nil {
+			t.Error("expected error renaming onto existing session")
+		}
+		if _, err := store.ForkSession(ctx, "original", "bad.name", StatePoint{}); err == nil {
+			t.Error("expected error for invalid name")
+		}
+		if exists, _ := store.SessionExists(ctx, "original"); !exists {
+			t.Error("expected source to survive failed operations")
+		}
+	})
+
+	t.Run("rename refuses unreadable events", func(t *testing.T) {
+		_, _ = store.TaskAdd(ctx, "damaged", TaskAddParams{Content: "Task"})
+		_, _ = store.log.Append(ctx, nats.SubjectForEvent("damaged", nats.EventTypeTask), []byte("not json"))
+
+		if err := store.RenameSession(ctx, "damaged", "repaired"); err == nil {
+			t.Fatal("expected error renaming a session with an unreadable event")
+		}
+		if count, _ := store.log.Count(ctx, nats.SubjectForSession("damaged")); count != 2 {
+			t.Errorf("expected source to keep both messages, got %d", count)
+		}
+		if exists, _ := store.SessionExists(ctx, "repaired"); exists {
+			t.Error("expected no target session")
+		}
+	})
+}
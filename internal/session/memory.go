@@ -0,0 +1,362 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// messageIndex is an in-memory copy of an event log, shared by MemoryLog and
+// FileLog. The owning log serializes access to it.
+type messageIndex struct {
+	msgs    []Message
+	lastSeq uint64        // Highest sequence ever assigned, kept across purges
+	changed chan struct{} // Closed and replaced whenever messages are added
+}
+
+func newMessageIndex() *messageIndex {
+	return &messageIndex{changed: make(chan struct{})}
+}
+
+// add stores a message and wakes up watchers.
+func (m *messageIndex) add(msg Message) {
+	m.msgs = append(m.msgs, msg)
+	if msg.Sequence > m.lastSeq {
+		m.lastSeq = msg.Sequence
+	}
+	close(m.changed)
+	m.changed = make(chan struct{})
+}
+
+// purge drops the messages matching filter with a sequence below before
+// (0 = all of them).
+func (m *messageIndex) purge(filter string, before uint64) {
+	kept := m.msgs[:0]
+	for _, msg := range m.msgs {
+		if (before > 0 && msg.Sequence >= before) || !subjectMatches(filter, msg.Subject) {
+			kept = append(kept, msg)
+		}
+	}
+	m.msgs = kept
+}
+
+// last returns the sequence of the last message matching subject (which may
+// be a filter), or of the log.
+func (m *messageIndex) last(subject string) uint64 {
+	if subject == "" {
+		return m.lastSeq
+	}
+	for i := len(m.msgs) - 1; i >= 0; i-- {
+		if subjectMatches(subject, m.msgs[i].Subject) {
+			return m.msgs[i].Sequence
+		}
+	}
+	return 0
+}
+
+// matching returns the messages matching filter after afterSeq.
+func (m *messageIndex) matching(filter string, afterSeq uint64) []Message {
+	var result []Message
+	for _, msg := range m.msgs {
+		if msg.Sequence > afterSeq && subjectMatches(filter, msg.Subject) {
+			result = append(result, msg)
+		}
+	}
+	return result
+}
+
+// sessions returns the sessions that have messages.
+func (m *messageIndex) sessions() []string {
+	subjects := make([]string, len(m.msgs))
+	for i, msg := range m.msgs {
+		subjects[i] = msg.Subject
+	}
+	return sessionsOf(subjects)
+}
+
+// watchLog delivers the messages of log matching filter after afterSeq. It
+// reads again whenever the channel returned by wake is closed and, if poll is
+// set, at that interval to pick up writes by other processes.
+func watchLog(ctx context.Context, log EventLog, filter string, afterSeq uint64, wake func() <-chan struct{}, poll time.Duration) <-chan Message {
+	ch := make(chan Message, 64)
+	go func() {
+		defer close(ch)
+
+		var tick <-chan time.Time
+		if poll > 0 {
+			ticker := time.NewTicker(poll)
+			defer ticker.Stop()
+			tick = ticker.C
+		}
+
+		for {
+			// Take the wake channel before reading so no append is missed
+			changed := wake()
+
+			var msgs []Message
+			_ = log.Read(ctx, filter, afterSeq, func(msg Message) bool {
+				msgs = append(msgs, msg)
+				return true
+			})
+			for _, msg := range msgs {
+				select {
+				case ch <- msg:
+					afterSeq = msg.Sequence
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-changed:
+			case <-tick:
+			}
+		}
+	}()
+	return ch
+}
+
+// MemoryLog is an EventLog kept entirely in memory, for tests and throwaway
+// sessions. It also stores snapshots and leases.
+type MemoryLog struct {
+	mu        sync.Mutex
+	index     *messageIndex
+	snapshots map[string][]byte
+	leases    map[string]Message // Lease data by session, Sequence is the revision
+	leaseRev  uint64             // Last lease revision
+}
+
+// NewMemoryLog creates an empty in-memory event log.
+func NewMemoryLog() *MemoryLog {
+	return &MemoryLog{
+		index:     newMessageIndex(),
+		snapshots: make(map[string][]byte),
+		leases:    make(map[string]Message),
+	}
+}
+
+// Append stores a copy of data on subject.
+func (l *MemoryLog) Append(ctx context.Context, subject string, data []byte) (uint64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.append(subject, data), nil
+}
+
+// AppendIfLast stores a copy of data on subject if its last sequence is lastSeq.
+func (l *MemoryLog) AppendIfLast(ctx context.Context, subject string, data []byte, lastSeq uint64) (uint64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.index.last(subject) != lastSeq {
+		return 0, ErrSequenceConflict
+	}
+	return l.append(subject, data), nil
+}
+
+func (l *MemoryLog) append(subject string, data []byte) uint64 {
+	seq := l.index.lastSeq + 1
+	l.index.add(Message{Sequence: seq, Subject: subject, Data: append([]byte(nil), data...)})
+	return seq
+}
+
+// LastSequence returns the last sequence of subject, or of the log.
+func (l *MemoryLog) LastSequence(ctx context.Context, subject string) (uint64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.index.last(subject), nil
+}
+
+// Read calls fn for the matching messages. The log is not locked while fn runs.
+func (l *MemoryLog) Read(ctx context.Context, filter string, afterSeq uint64, fn func(Message) bool) error {
+	l.mu.Lock()
+	msgs := l.index.matching(filter, afterSeq)
+	l.mu.Unlock()
+
+	for _, msg := range msgs {
+		if !fn(msg) {
+			break
+		}
+	}
+	return nil
+}
+
+// Count returns the number of messages matching filter.
+func (l *MemoryLog) Count(ctx context.Context, filter string) (uint64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return uint64(len(l.index.matching(filter, 0))), nil
+}
+
+// Purge removes the messages matching filter.
+func (l *MemoryLog) Purge(ctx context.Context, filter string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.index.purge(filter, 0)
+	return nil
+}
+
+// PurgeBefore removes the messages matching filter with a lower sequence than
+// seq.
+func (l *MemoryLog) PurgeBefore(ctx context.Context, filter string, seq uint64) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.index.purge(filter, seq)
+	return nil
+}
+
+// Sessions returns the sessions that have messages.
+func (l *MemoryLog) Sessions(ctx context.Context) ([]string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.index.sessions(), nil
+}
+
+// Watch delivers stored and new messages after afterSeq.
+func (l *MemoryLog) Watch(ctx context.Context, filter string, afterSeq uint64) (<-chan Message, error) {
+	return watchLog(ctx, l, filter, afterSeq, l.wake, 0), nil
+}
+
+// wake returns a channel that is closed on the next append.
+func (l *MemoryLog) wake() <-chan struct{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.index.changed
+}
+
+// Close is a no-op.
+func (l *MemoryLog) Close() error {
+	return nil
+}
+
+// SetupSnapshots is a no-op; snapshots are always available.
+func (l *MemoryLog) SetupSnapshots(ctx context.Context) error {
+	return nil
+}
+
+// GetSnapshot returns a session's snapshot.
+func (l *MemoryLog) GetSnapshot(ctx context.Context, session string) ([]byte, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	data, ok := l.snapshots[session]
+	if !ok {
+		return nil, ErrNoSnapshot
+	}
+	return data, nil
+}
+
+// PutSnapshot stores a copy of a session's snapshot.
+func (l *MemoryLog) PutSnapshot(ctx context.Context, session string, data []byte) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.snapshots[session] = append([]byte(nil), data...)
+	return nil
+}
+
+// DeleteSnapshot removes a session's snapshot.
+func (l *MemoryLog) DeleteSnapshot(ctx context.Context, session string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.snapshots, session)
+	return nil
+}
+
+// SetupLeases is a no-op; leases are always available.
+func (l *MemoryLog) SetupLeases(ctx context.Context) error {
+	return nil
+}
+
+// GetLease returns a session's lease and its revision.
+func (l *MemoryLog) GetLease(ctx context.Context, session string) ([]byte, uint64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	lease, ok := l.leases[session]
+	if !ok {
+		return nil, 0, ErrNoLease
+	}
+	return lease.Data, lease.Sequence, nil
+}
+
+// PutLease stores a copy of a session's lease if its revision is still rev.
+func (l *MemoryLog) PutLease(ctx context.Context, session string, data []byte, rev uint64) (uint64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.leases[session].Sequence != rev {
+		return 0, ErrSequenceConflict
+	}
+	l.leaseRev++
+	l.leases[session] = Message{Sequence: l.leaseRev, Data: append([]byte(nil), data...)}
+	return l.leaseRev, nil
+}
+
+// DeleteLease removes a session's lease if its revision is still rev.
+func (l *MemoryLog) DeleteLease(ctx context.Context, session string, rev uint64) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	lease, ok := l.leases[session]
+	if !ok {
+		return nil
+	}
+	if rev != 0 && lease.Sequence != rev {
+		return ErrSequenceConflict
+	}
+	delete(l.leases, session)
+	return nil
+}
+
+// BackupKind returns "memory".
+func (l *MemoryLog) BackupKind() string {
+	return "memory"
+}
+
+// Backup writes the messages as event file records.
+func (l *MemoryLog) Backup(ctx context.Context, w io.Writer) (*BackupData, error) {
+	l.mu.Lock()
+	msgs := append([]Message(nil), l.index.msgs...)
+	lastSeq := l.index.lastSeq
+	l.mu.Unlock()
+
+	enc := json.NewEncoder(w)
+	for _, msg := range msgs {
+		if err := enc.Encode(fileRecord{Seq: msg.Sequence, Subject: msg.Subject, Data: msg.Data}); err != nil {
+			return nil, err
+		}
+	}
+	return &BackupData{Messages: uint64(len(msgs)), LastSequence: lastSeq}, nil
+}
+
+// Restore replaces the messages with those of a backup. Watchers are not
+// told about messages that went away.
+func (l *MemoryLog) Restore(ctx context.Context, data *BackupData, r io.Reader) error {
+	index := newMessageIndex()
+	dec := json.NewDecoder(r)
+	for {
+		var record fileRecord
+		err := dec.Decode(&record)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("invalid backup record: %w", err)
+		}
+		index.add(Message{Sequence: record.Seq, Subject: record.Subject, Data: record.Data})
+	}
+	if data.LastSequence > index.lastSeq {
+		index.lastSeq = data.LastSequence
+	}
+	if err := checkRestored(uint64(len(index.msgs)), index.lastSeq, data); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	index.changed = l.index.changed
+	l.index = index
+	close(l.index.changed)
+	l.index.changed = make(chan struct{})
+	return nil
+}
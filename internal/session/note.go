@@ -2,7 +2,6 @@ package session
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"time"
 
@@ -23,6 +22,7 @@ type NoteListParams struct {
 
 // NoteAdd creates a new note in the session.
 // Type must be one of: learning, stuck, tip, decision.
+// The NOT-N ID is allocated atomically: concurrent writers never receive the same ID.
 func (s *Store) NoteAdd(ctx context.Context, session string, params NoteAddParams) (*Note, error) {
 	// Validate required fields
 	if params.Content == "" {
@@ -37,34 +37,30 @@ func (s *Store) NoteAdd(ctx context.Context, session string, params NoteAddParam
 		return nil, fmt.Errorf("invalid type: %s (must be learning, stuck, tip, or decision)", params.Type)
 	}
 
-	// Load current state to get next note counter
-	state, err := s.LoadState(ctx, session)
-	if err != nil {
-		return nil, fmt.Errorf("failed to load state for ID generation: %w", err)
-	}
-
-	// Generate sequential ID and timestamp
-	id := fmt.Sprintf("NOT-%d", state.NoteCounter+1)
+	// Allocate the next NOT-N ID and publish atomically
+	var id string
 	now := time.Now()
+	err := s.withAllocation(ctx, session, nats.EventTypeNote, func(state *State, expectedSeq uint64) error {
+		// Generate sequential ID
+		id = fmt.Sprintf("NOT-%d", state.NoteCounter+1)
+
+		// Create event metadata
+		meta := encodeMeta(NoteAddMeta{Type: params.Type, Iteration: params.Iteration})
+
+		// Create and publish event
+		event := Event{
+			ID:        id,
+			Timestamp: now,
+			Session:   session,
+			Type:      nats.EventTypeNote,
+			Action:    "add",
+			Data:      params.Content,
+			Meta:      meta,
+		}
 
-	// Create event metadata
-	meta, _ := json.Marshal(map[string]any{
-		"type":      params.Type,
-		"iteration": params.Iteration,
+		_, err := s.publishIfLast(ctx, event, expectedSeq)
+		return err
 	})
-
-	// Create and publish event
-	event := Event{
-		ID:        id,
-		Timestamp: now,
-		Session:   session,
-		Type:      nats.EventTypeNote,
-		Action:    "add",
-		Data:      params.Content,
-		Meta:      meta,
-	}
-
-	_, err = s.PublishEvent(ctx, event)
 	if err != nil {
 		return nil, err
 	}
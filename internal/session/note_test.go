@@ -32,7 +32,7 @@ func TestNoteOperations(t *testing.T) {
 		t.Fatalf("failed to setup stream: %v", err)
 	}
 
-	store := NewStore(js, stream)
+	store := NewStore(NewJetStreamLog(js, stream))
 	session := "test-session"
 
 	t.Run("NoteAdd creates note with valid type", func(t *testing.T) {
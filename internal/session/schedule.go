@@ -0,0 +1,146 @@
+package session
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SchedulingPolicy decides which ready task TaskNext returns.
+// Implementations must be deterministic for a given state so runs can be reproduced.
+type SchedulingPolicy interface {
+	// Name returns the policy name used in iteratr.yml.
+	Name() string
+	// Next picks one task from ready, which is non-empty and in creation order.
+	// state is the full session state, for policies that look beyond the ready set.
+	Next(state *State, ready []*Task) *Task
+}
+
+// Built-in scheduling policy names.
+const (
+	PolicyPriority     = "priority"      // Priority, then creation order (default)
+	PolicyCriticalPath = "critical-path" // Tasks that unblock the longest chain first
+	PolicyAging        = "aging"         // Oldest first, with priority boosted as tasks wait
+)
+
+// DefaultAgingInterval is how long a task waits before PriorityAging treats it
+// as one priority level higher.
+const DefaultAgingInterval = time.Hour
+
+// SchedulingPolicyByName returns the built-in policy with the given name.
+// An empty name selects the default priority policy.
+func SchedulingPolicyByName(name string) (SchedulingPolicy, error) {
+	switch name {
+	case "", PolicyPriority:
+		return PriorityFIFO{}, nil
+	case PolicyCriticalPath:
+		return CriticalPathFirst{}, nil
+	case PolicyAging:
+		return PriorityAging{Interval: DefaultAgingInterval}, nil
+	default:
+		return nil, fmt.Errorf("unknown scheduling policy %q (must be %s)", name,
+			strings.Join([]string{PolicyPriority, PolicyCriticalPath, PolicyAging}, ", "))
+	}
+}
+
+// SetSchedulingPolicy sets the policy TaskNext uses to pick among ready tasks.
+// A nil policy restores the default PriorityFIFO.
+func (s *Store) SetSchedulingPolicy(policy SchedulingPolicy) {
+	s.policy = policy
+}
+
+// schedulingPolicy returns the configured policy, defaulting to PriorityFIFO.
+func (s *Store) schedulingPolicy() SchedulingPolicy {
+	if s.policy == nil {
+		return PriorityFIFO{}
+	}
+	return s.policy
+}
+
+// PriorityFIFO picks the highest priority (lowest number) task, breaking ties
+// by creation order.
+type PriorityFIFO struct{}
+
+// Name implements SchedulingPolicy.
+func (PriorityFIFO) Name() string { return PolicyPriority }
+
+// Next implements SchedulingPolicy.
+func (PriorityFIFO) Next(state *State, ready []*Task) *Task {
+	sortTasksByPriority(ready)
+	return ready[0]
+}
+
+// CriticalPathFirst picks the task with the longest chain of open tasks waiting
+// on it, so the work that unblocks the most sequential work starts first. Ties
+// fall back to PriorityFIFO.
+type CriticalPathFirst struct{}
+
+// Name implements SchedulingPolicy.
+func (CriticalPathFirst) Name() string { return PolicyCriticalPath }
+
+// Next implements SchedulingPolicy.
+func (CriticalPathFirst) Next(state *State, ready []*Task) *Task {
+	chain := state.downstreamChainLengths()
+	sortTasksByPriority(ready)
+	sort.SliceStable(ready, func(i, j int) bool {
+		return chain[ready[i].ID] > chain[ready[j].ID]
+	})
+	return ready[0]
+}
+
+// PriorityAging picks the oldest task, after raising each task's priority by one
+// level for every Interval it has existed. A backlog task therefore eventually
+// overtakes newer high-priority work instead of starving.
+type PriorityAging struct {
+	Interval time.Duration    // Age per priority level (default DefaultAgingInterval)
+	Now      func() time.Time // Clock (default time.Now); set in tests
+}
+
+// Name implements SchedulingPolicy.
+func (PriorityAging) Name() string { return PolicyAging }
+
+// Next implements SchedulingPolicy.
+func (p PriorityAging) Next(state *State, ready []*Task) *Task {
+	interval := p.Interval
+	if interval <= 0 {
+		interval = DefaultAgingInterval
+	}
+	now := time.Now()
+	if p.Now != nil {
+		now = p.Now()
+	}
+
+	effective := func(task *Task) int {
+		return task.Priority - int(now.Sub(task.CreatedAt)/interval)
+	}
+	// ready is in creation order, so a stable sort keeps oldest first on ties
+	sort.SliceStable(ready, func(i, j int) bool {
+		return effective(ready[i]) < effective(ready[j])
+	})
+	return ready[0]
+}
+
+// downstreamChainLengths returns, for each open task, the number of open tasks
+// in the longest chain that (transitively) depends on it, counting itself.
+func (st *State) downstreamChainLengths() map[string]int {
+	order := st.TopologicalOrder()
+	open := make(map[string]bool, len(order))
+	for _, task := range order {
+		open[task.ID] = true
+	}
+
+	chain := make(map[string]int, len(order))
+	for i := len(order) - 1; i >= 0; i-- {
+		task := order[i]
+		if chain[task.ID] == 0 {
+			chain[task.ID] = 1
+		}
+		for _, depID := range task.DependsOn {
+			if open[depID] && chain[task.ID]+1 > chain[depID] {
+				chain[depID] = chain[task.ID] + 1
+			}
+		}
+	}
+	return chain
+}
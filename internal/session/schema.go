@@ -0,0 +1,290 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/mark3labs/iteratr/internal/nats"
+)
+
+// EventVersion is the schema version of the event payloads this build writes.
+// Events are upcast to it when they are read, so reducers only ever see the
+// current shapes. Bump it (and add an upcaster) whenever a payload changes in
+// a way old events can't be read as.
+//
+// Versions:
+//   - 1: events without a version field. Task adds omit priority when it is
+//     the default, and iteration completes may lack an outcome.
+//   - 2: task adds always carry priority, completes always carry an outcome.
+const EventVersion = 2
+
+// ErrStateCorruption is returned for events that cannot be applied: malformed
+// or incomplete metadata, unknown types or actions, or a schema version newer
+// than this build understands.
+var ErrStateCorruption = errors.New("state corruption")
+
+// upcasters[v] rewrites an event of schema version v into version v+1.
+var upcasters = map[int]func(event *Event) error{
+	1: upcastV1,
+}
+
+// Upcast returns the event rewritten to the current schema version. Events
+// without a version are version 1.
+func Upcast(event Event) (Event, error) {
+	version := event.Version
+	if version == 0 {
+		version = 1
+	}
+	if version > EventVersion {
+		return event, corruptEvent(event, "schema version %d is newer than supported version %d (upgrade iteratr)", version, EventVersion)
+	}
+	for ; version < EventVersion; version++ {
+		if err := upcasters[version](&event); err != nil {
+			return event, corruptEvent(event, "cannot upgrade from schema version %d: %v", version, err)
+		}
+	}
+	event.Version = EventVersion
+	return event, nil
+}
+
+// upcastV1 fills in the fields version 1 left implicit.
+func upcastV1(event *Event) error {
+	switch {
+	case event.Type == nats.EventTypeTask && event.Action == "add":
+		// A missing priority meant medium; an explicit 0 was critical
+		return setMetaDefault(event, "priority", 2)
+	case event.Type == nats.EventTypeIteration && event.Action == "complete":
+		// Completes written before outcomes were recorded only marked success
+		return setMetaDefault(event, "outcome", IterationSucceeded)
+	}
+	return nil
+}
+
+// setMetaDefault sets key in the event metadata if it is missing or empty.
+func setMetaDefault(event *Event, key string, value any) error {
+	fields := make(map[string]json.RawMessage)
+	if len(event.Meta) > 0 {
+		if err := json.Unmarshal(event.Meta, &fields); err != nil {
+			return err
+		}
+		if fields == nil {
+			fields = make(map[string]json.RawMessage) // Meta was null
+		}
+	}
+	if current, ok := fields[key]; ok && string(current) != `""` && string(current) != "null" {
+		return nil
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	fields[key] = encoded
+	meta, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	event.Meta = meta
+	return nil
+}
+
+// corruptEvent returns an ErrStateCorruption error describing event.
+func corruptEvent(event Event, format string, args ...any) error {
+	return fmt.Errorf("%w: %s %s event %s: %s", ErrStateCorruption, event.Type, event.Action, event.ID, fmt.Sprintf(format, args...))
+}
+
+// DecodeMeta upcasts event and decodes its metadata into meta, one of the
+// payload types below, validating its required fields.
+func DecodeMeta(event Event, meta any) error {
+	event, err := Upcast(event)
+	if err != nil {
+		return err
+	}
+	return decodeMeta(event, meta)
+}
+
+// decodeMeta is DecodeMeta for an event that is already upcast.
+func decodeMeta(event Event, meta any) error {
+	if len(event.Meta) > 0 {
+		if err := json.Unmarshal(event.Meta, meta); err != nil {
+			return corruptEvent(event, "invalid metadata: %v", err)
+		}
+	}
+	if v, ok := meta.(interface{ validate() error }); ok {
+		if err := v.validate(); err != nil {
+			return corruptEvent(event, "%v", err)
+		}
+	}
+	return nil
+}
+
+// encodeMeta marshals a payload for Event.Meta.
+func encodeMeta(meta any) json.RawMessage {
+	data, _ := json.Marshal(meta) // Payload types always marshal
+	return data
+}
+
+// TaskAddMeta is the payload of task "add" events. The event ID is the task
+// ID and Data its content.
+type TaskAddMeta struct {
+	Status     string   `json:"status"`
+	Priority   int      `json:"priority"`
+	ParentID   string   `json:"parent_id,omitempty"`
+	Acceptance string   `json:"acceptance,omitempty"`
+	Verify     string   `json:"verify,omitempty"`
+	Labels     []string `json:"labels,omitempty"`
+	Iteration  int      `json:"iteration"`
+}
+
+// TaskStatusMeta is the payload of task "status" events.
+type TaskStatusMeta struct {
+	TaskID    string `json:"task_id"`
+	Status    string `json:"status"`
+	Iteration int    `json:"iteration"`
+}
+
+func (m TaskStatusMeta) validate() error {
+	if m.TaskID == "" || m.Status == "" {
+		return errors.New("task_id and status are required")
+	}
+	return nil
+}
+
+// TaskPriorityMeta is the payload of task "priority" events.
+type TaskPriorityMeta struct {
+	TaskID    string `json:"task_id"`
+	Priority  int    `json:"priority"`
+	Iteration int    `json:"iteration"`
+}
+
+func (m TaskPriorityMeta) validate() error {
+	return requireTaskID(m.TaskID)
+}
+
+// TaskDependsMeta is the payload of task "depends" and "undepend" events.
+type TaskDependsMeta struct {
+	TaskID    string `json:"task_id"`
+	DependsOn string `json:"depends_on"`
+	Iteration int    `json:"iteration"`
+}
+
+func (m TaskDependsMeta) validate() error {
+	if m.TaskID == "" || m.DependsOn == "" {
+		return errors.New("task_id and depends_on are required")
+	}
+	return nil
+}
+
+// TaskLabelMeta is the payload of task "label" events.
+type TaskLabelMeta struct {
+	TaskID    string   `json:"task_id"`
+	Add       []string `json:"add,omitempty"`
+	Remove    []string `json:"remove,omitempty"`
+	Iteration int      `json:"iteration"`
+}
+
+func (m TaskLabelMeta) validate() error {
+	return requireTaskID(m.TaskID)
+}
+
+// TaskEditMeta is the payload of task "edit" events.
+type TaskEditMeta struct {
+	TaskID    string `json:"task_id"`
+	Content   string `json:"content"`
+	Iteration int    `json:"iteration"`
+}
+
+func (m TaskEditMeta) validate() error {
+	return requireTaskID(m.TaskID)
+}
+
+// TaskRemoveMeta is the payload of task "remove" events.
+type TaskRemoveMeta struct {
+	TaskID    string `json:"task_id"`
+	Iteration int    `json:"iteration"`
+}
+
+func (m TaskRemoveMeta) validate() error {
+	return requireTaskID(m.TaskID)
+}
+
+// requireTaskID rejects task events that don't name their task.
+func requireTaskID(id string) error {
+	if id == "" {
+		return errors.New("task_id is required")
+	}
+	return nil
+}
+
+// NoteAddMeta is the payload of note "add" events. The event ID is the note
+// ID and Data its content.
+type NoteAddMeta struct {
+	Type      string `json:"type"`
+	Iteration int    `json:"iteration"`
+}
+
+// IterationStartMeta is the payload of iteration "start" events.
+type IterationStartMeta struct {
+	Number int `json:"number"`
+}
+
+// IterationCompleteMeta is the payload of iteration "complete" events.
+type IterationCompleteMeta struct {
+	Number int `json:"number"`
+	IterationResult
+}
+
+func (m IterationCompleteMeta) validate() error {
+	if m.Outcome == "" {
+		return errors.New("outcome is required")
+	}
+	return nil
+}
+
+// IterationAbandonedMeta is the payload of iteration "abandoned" events.
+type IterationAbandonedMeta struct {
+	Number           int      `json:"number"`
+	InterruptedTasks []string `json:"interrupted_tasks,omitempty"`
+}
+
+// IterationSummaryMeta is the payload of iteration "summary" events.
+type IterationSummaryMeta struct {
+	Number      int      `json:"number"`
+	Summary     string   `json:"summary"`
+	TasksWorked []string `json:"tasks_worked"`
+}
+
+// IterationUsageMeta is the payload of iteration "usage" events: the usage of
+// one agent turn.
+type IterationUsageMeta struct {
+	Number int `json:"number"`
+	Usage
+}
+
+// CompactedMeta is the payload of control "compacted" events: the ID counters
+// of the tasks and notes that no longer appear in the compacted history.
+type CompactedMeta struct {
+	TaskCounter int `json:"task_counter"`
+	NoteCounter int `json:"note_counter"`
+}
+
+// KnowledgeAddMeta is the payload of knowledge "add" events. The event ID is
+// the entry ID and Data its content.
+type KnowledgeAddMeta struct {
+	Type   string `json:"type"`
+	Source string `json:"source,omitempty"`
+}
+
+// KnowledgeEntryMeta is the payload of knowledge "edit", "retire" and
+// "restore" events. Data holds the new content or the retire reason.
+type KnowledgeEntryMeta struct {
+	EntryID string `json:"entry_id"`
+	Type    string `json:"type,omitempty"`
+}
+
+func (m KnowledgeEntryMeta) validate() error {
+	if m.EntryID == "" {
+		return errors.New("entry_id is required")
+	}
+	return nil
+}
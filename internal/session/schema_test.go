@@ -0,0 +1,143 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/iteratr/internal/nats"
+)
+
+func TestUpcast(t *testing.T) {
+	t.Run("version 1 task add without priority is medium", func(t *testing.T) {
+		event, err := Upcast(Event{Type: nats.EventTypeTask, Action: "add", Meta: json.RawMessage(`{"status":"remaining"}`)})
+		if err != nil {
+			t.Fatalf("Upcast failed: %v", err)
+		}
+		var meta TaskAddMeta
+		if err := DecodeMeta(event, &meta); err != nil {
+			t.Fatalf("DecodeMeta failed: %v", err)
+		}
+		if meta.Priority != 2 || event.Version != EventVersion {
+			t.Errorf("expected priority 2 at version %d, got %d at version %d", EventVersion, meta.Priority, event.Version)
+		}
+	})
+
+	t.Run("version 1 explicit critical priority is kept", func(t *testing.T) {
+		var meta TaskAddMeta
+		err := DecodeMeta(Event{Type: nats.EventTypeTask, Action: "add", Meta: json.RawMessage(`{"priority":0}`)}, &meta)
+		if err != nil || meta.Priority != 0 {
+			t.Errorf("expected priority 0, got %d (%v)", meta.Priority, err)
+		}
+	})
+
+	t.Run("version 1 task add without metadata", func(t *testing.T) {
+		var meta TaskAddMeta
+		if err := DecodeMeta(Event{Type: nats.EventTypeTask, Action: "add"}, &meta); err != nil || meta.Priority != 2 {
+			t.Errorf("expected priority 2, got %d (%v)", meta.Priority, err)
+		}
+	})
+
+	t.Run("version 1 complete without outcome succeeded", func(t *testing.T) {
+		var meta IterationCompleteMeta
+		err := DecodeMeta(Event{Type: nats.EventTypeIteration, Action: "complete", Meta: json.RawMessage(`{"number":3}`)}, &meta)
+		if err != nil || meta.Outcome != IterationSucceeded {
+			t.Errorf("expected succeeded outcome, got %q (%v)", meta.Outcome, err)
+		}
+	})
+
+	t.Run("current version is not upcast", func(t *testing.T) {
+		var meta TaskAddMeta
+		event := Event{Type: nats.EventTypeTask, Action: "add", Meta: json.RawMessage(`{}`), Version: EventVersion}
+		if err := DecodeMeta(event, &meta); err != nil || meta.Priority != 0 {
+			t.Errorf("expected priority left at 0, got %d (%v)", meta.Priority, err)
+		}
+	})
+
+	t.Run("newer version is corruption", func(t *testing.T) {
+		_, err := Upcast(Event{Type: nats.EventTypeTask, Action: "add", Version: EventVersion + 1})
+		if !errors.Is(err, ErrStateCorruption) {
+			t.Errorf("expected ErrStateCorruption, got %v", err)
+		}
+	})
+}
+
+func TestState_ApplyCorruption(t *testing.T) {
+	state := &State{Session: "s", Tasks: make(map[string]*Task)}
+	if err := state.Apply(Event{ID: "TAS-1", Type: nats.EventTypeTask, Action: "add", Data: "Task", Version: EventVersion, Meta: json.RawMessage(`{"priority":1}`)}); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	bad := []Event{
+		{Type: nats.EventTypeTask, Action: "status", Meta: json.RawMessage(`{"task_id":"TAS-1","status":7}`)},
+		{Type: nats.EventTypeTask, Action: "status", Meta: json.RawMessage(`{"status":"completed"}`)},
+		{Type: nats.EventTypeTask, Action: "explode", Meta: json.RawMessage(`{}`)},
+		{Type: "inbox", Action: "add"},
+		{Type: nats.EventTypeIteration, Action: "usage", Meta: json.RawMessage(`not json`)},
+	}
+	for _, event := range bad {
+		if err := state.Apply(event); !errors.Is(err, ErrStateCorruption) {
+			t.Errorf("expected ErrStateCorruption for %s %s, got %v", event.Type, event.Action, err)
+		}
+	}
+	if task := state.Tasks["TAS-1"]; task.Status != "remaining" || task.Priority != 1 {
+		t.Errorf("expected task untouched by bad events, got %+v", task)
+	}
+
+	// Events for tasks that are gone are not corruption
+	if err := state.Apply(Event{Type: nats.EventTypeTask, Action: "status", Meta: json.RawMessage(`{"task_id":"TAS-9","status":"completed"}`)}); err != nil {
+		t.Errorf("expected no error for unknown task, got %v", err)
+	}
+}
+
+func TestStore_CheckEvents(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore(NewMemoryLog())
+	_, _ = store.TaskAdd(ctx, "good", TaskAddParams{Content: "Fine"})
+	_, _ = store.TaskAdd(ctx, "bad", TaskAddParams{Content: "Fine"})
+	_, _ = store.KnowledgeAdd(ctx, KnowledgeAddParams{Content: "Kept", Type: "tip"})
+
+	// A legacy event, a malformed one and one with bad metadata
+	_, _ = store.log.Append(ctx, "iteratr.bad.task", []byte(`{"id":"TAS-2","session":"bad","type":"task","action":"add","meta":{"status":"remaining"}}`))
+	_, _ = store.log.Append(ctx, "iteratr.bad.note", []byte(`{"id":`))
+	_, _ = store.log.Append(ctx, "iteratr.bad.task", []byte(`{"session":"bad","type":"task","action":"priority","meta":{"task_id":"TAS-1","priority":"high"},"version":2}`))
+
+	checks, err := store.CheckEvents(ctx)
+	if err != nil {
+		t.Fatalf("CheckEvents failed: %v", err)
+	}
+	byName := make(map[string]EventCheck)
+	for _, check := range checks {
+		byName[check.Name] = check
+	}
+
+	if good := byName["good"]; good.Events != 1 || len(good.Problems) != 0 || good.Outdated != 0 {
+		t.Errorf("unexpected check for good session: %+v", good)
+	}
+	bad := byName["bad"]
+	if bad.Events != 4 || bad.Outdated != 1 || len(bad.Problems) != 2 {
+		t.Fatalf("unexpected check for bad session: %+v", bad)
+	}
+	for _, problem := range bad.Problems {
+		if !errors.Is(problem.Err, ErrStateCorruption) || problem.Sequence == 0 {
+			t.Errorf("expected ErrStateCorruption with a sequence, got %+v", problem)
+		}
+	}
+	if !strings.Contains(bad.Problems[1].Err.Error(), "invalid metadata") {
+		t.Errorf("expected invalid metadata problem, got %v", bad.Problems[1].Err)
+	}
+	if kb := byName[KnowledgeCheckName]; kb.Events != 1 || len(kb.Problems) != 0 {
+		t.Errorf("unexpected knowledge check: %+v", kb)
+	}
+
+	// The corrupt events are skipped when loading, the rest still applies
+	state, err := store.LoadState(ctx, "bad")
+	if err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+	if len(state.Tasks) != 2 || state.Tasks["TAS-2"].Priority != 2 {
+		t.Errorf("expected both tasks with legacy priority upcast, got %+v", state.Tasks)
+	}
+}
@@ -0,0 +1,164 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/iteratr/internal/logger"
+)
+
+// Kinds of search results.
+const (
+	SearchKindTask    = "task"
+	SearchKindNote    = "note"
+	SearchKindSummary = "summary"
+)
+
+// SearchParams represents the parameters for searching across sessions.
+// NoteType and Status each restrict results to their own kind: with NoteType
+// set only notes of that type are returned, with Status set only tasks with
+// that status, and with both set matching notes and tasks.
+type SearchParams struct {
+	Query    string `json:"query"`               // Words that must all appear, case-insensitive
+	NoteType string `json:"note_type,omitempty"` // learning, stuck, tip, decision
+	Status   string `json:"status,omitempty"`    // Task status
+	Session  string `json:"session,omitempty"`   // Only search this session
+	Limit    int    `json:"limit,omitempty"`     // Maximum number of results (0 = all)
+}
+
+// SearchResult is a task, note or iteration summary matching a search.
+type SearchResult struct {
+	Session   string    `json:"session"`
+	Kind      string    `json:"kind"`                // task, note or summary
+	ID        string    `json:"id,omitempty"`        // Task or note ID; empty for summaries
+	Iteration int       `json:"iteration"`           // Iteration that created or last modified it
+	Status    string    `json:"status,omitempty"`    // Task status
+	NoteType  string    `json:"note_type,omitempty"` // Note type
+	Content   string    `json:"content"`
+	Time      time.Time `json:"time"`
+}
+
+// Search finds tasks, notes and iteration summaries containing every word of
+// the query, across all sessions in the event log. Results are ordered most
+// recent first.
+func (s *Store) Search(ctx context.Context, params SearchParams) ([]SearchResult, error) {
+	terms := strings.Fields(strings.ToLower(params.Query))
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("search query is required")
+	}
+	if params.NoteType != "" && !isValidNoteType(params.NoteType) {
+		return nil, fmt.Errorf("invalid note type filter: %s (must be learning, stuck, tip, or decision)", params.NoteType)
+	}
+	if params.Status != "" && !isValidTaskStatus(params.Status) {
+		return nil, fmt.Errorf("invalid status filter: %s (must be remaining, in_progress, completed, blocked, or cancelled)", params.Status)
+	}
+
+	sessions := []string{params.Session}
+	if params.Session == "" {
+		var err error
+		sessions, err = s.log.Sessions(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list sessions: %w", err)
+		}
+	}
+
+	var results []SearchResult
+	for _, name := range sessions {
+		state, err := s.LoadState(ctx, name)
+		if err != nil {
+			logger.Warn("Failed to load state for session '%s': %v", name, err)
+			continue // Skip sessions we can't load
+		}
+		results = append(results, searchState(state, terms, params)...)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Time.After(results[j].Time)
+	})
+	if params.Limit > 0 && len(results) > params.Limit {
+		results = results[:params.Limit]
+	}
+	return results, nil
+}
+
+// searchState returns the tasks, notes and summaries of one session that
+// contain all terms and pass the filters.
+func searchState(state *State, terms []string, params SearchParams) []SearchResult {
+	filtered := params.NoteType != "" || params.Status != ""
+	var results []SearchResult
+
+	if !filtered || params.Status != "" {
+		for _, task := range state.Tasks {
+			if params.Status != "" && task.Status != params.Status {
+				continue
+			}
+			if !containsTerms(task.Content, terms) {
+				continue
+			}
+			results = append(results, SearchResult{
+				Session:   state.Session,
+				Kind:      SearchKindTask,
+				ID:        task.ID,
+				Iteration: task.Iteration,
+				Status:    task.Status,
+				Content:   task.Content,
+				Time:      task.UpdatedAt,
+			})
+		}
+	}
+
+	if !filtered || params.NoteType != "" {
+		for _, note := range state.Notes {
+			if params.NoteType != "" && note.Type != params.NoteType {
+				continue
+			}
+			if !containsTerms(note.Content, terms) {
+				continue
+			}
+			results = append(results, SearchResult{
+				Session:   state.Session,
+				Kind:      SearchKindNote,
+				ID:        note.ID,
+				Iteration: note.Iteration,
+				NoteType:  note.Type,
+				Content:   note.Content,
+				Time:      note.CreatedAt,
+			})
+		}
+	}
+
+	if !filtered {
+		for _, iter := range state.Iterations {
+			if iter.Summary == "" || !containsTerms(iter.Summary, terms) {
+				continue
+			}
+			at := iter.EndedAt
+			if at.IsZero() {
+				at = iter.StartedAt
+			}
+			results = append(results, SearchResult{
+				Session:   state.Session,
+				Kind:      SearchKindSummary,
+				Iteration: iter.Number,
+				Content:   iter.Summary,
+				Time:      at,
+			})
+		}
+	}
+
+	return results
+}
+
+// containsTerms reports whether text contains every (lowercase) term.
+func containsTerms(text string, terms []string) bool {
+	text = strings.ToLower(text)
+	for _, term := range terms {
+		if !strings.Contains(text, term) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,78 @@
+package session
+
+import (
+	"context"
+	"testing"
+)
+
+func TestStore_Search(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore(NewMemoryLog())
+
+	_ = store.IterationStart(ctx, "alpha", 1)
+	task, _ := store.TaskAdd(ctx, "alpha", TaskAddParams{Content: "Fix the flaky NATS test", Iteration: 1})
+	_ = store.TaskStatus(ctx, "alpha", TaskStatusParams{ID: task.ID, Status: "completed", Iteration: 1})
+	_ = store.IterationSummary(ctx, "alpha", 1, "Stabilized NATS test by waiting for the stream", nil)
+	_ = store.IterationComplete(ctx, "alpha", 1, IterationResult{})
+
+	_, _ = store.NoteAdd(ctx, "beta", NoteAddParams{Content: "nats tests need a fresh data dir", Type: "learning"})
+	_, _ = store.NoteAdd(ctx, "beta", NoteAddParams{Content: "Stuck on NATS reconnects", Type: "stuck"})
+	_, _ = store.TaskAdd(ctx, "beta", TaskAddParams{Content: "Write docs"})
+
+	kinds := func(results []SearchResult) map[string]int {
+		counts := make(map[string]int)
+		for _, r := range results {
+			counts[r.Session+"/"+r.Kind]++
+		}
+		return counts
+	}
+
+	t.Run("all kinds across sessions", func(t *testing.T) {
+		results, err := store.Search(ctx, SearchParams{Query: "NATS test"})
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		got := kinds(results)
+		if len(results) != 3 || got["alpha/task"] != 1 || got["alpha/summary"] != 1 || got["beta/note"] != 1 {
+			t.Errorf("unexpected results: %+v", results)
+		}
+		for i := 1; i < len(results); i++ {
+			if results[i].Time.After(results[i-1].Time) {
+				t.Errorf("expected most recent first, got %v after %v", results[i].Time, results[i-1].Time)
+			}
+		}
+	})
+
+	t.Run("note type filter", func(t *testing.T) {
+		results, _ := store.Search(ctx, SearchParams{Query: "nats", NoteType: "stuck"})
+		if len(results) != 1 || results[0].Content != "Stuck on NATS reconnects" || results[0].ID != "NOT-2" {
+			t.Errorf("unexpected results: %+v", results)
+		}
+	})
+
+	t.Run("status filter", func(t *testing.T) {
+		results, _ := store.Search(ctx, SearchParams{Query: "nats", Status: "completed"})
+		if len(results) != 1 || results[0].ID != task.ID || results[0].Iteration != 1 {
+			t.Errorf("unexpected results: %+v", results)
+		}
+	})
+
+	t.Run("session and limit", func(t *testing.T) {
+		results, _ := store.Search(ctx, SearchParams{Query: "nats", Session: "beta", Limit: 1})
+		if len(results) != 1 || results[0].Session != "beta" {
+			t.Errorf("unexpected results: %+v", results)
+		}
+	})
+
+	t.Run("invalid params", func(t *testing.T) {
+		if _, err := store.Search(ctx, SearchParams{Query: "  "}); err == nil {
+			t.Error("expected error for empty query")
+		}
+		if _, err := store.Search(ctx, SearchParams{Query: "nats", NoteType: "bogus"}); err == nil {
+			t.Error("expected error for invalid note type")
+		}
+		if _, err := store.Search(ctx, SearchParams{Query: "nats", Status: "bogus"}); err == nil {
+			t.Error("expected error for invalid status")
+		}
+	})
+}
@@ -3,77 +3,206 @@ package session
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math/rand"
+	"sort"
 	"time"
 
 	"github.com/mark3labs/iteratr/internal/logger"
 	"github.com/mark3labs/iteratr/internal/nats"
-	"github.com/nats-io/nats.go/jetstream"
 )
 
-// Event represents a generic event stored in the JetStream event log.
+// Event represents a generic event stored in the event log.
 // All session operations (tasks, notes, inbox, iterations) are stored as events
 // following an append-only event sourcing pattern.
 type Event struct {
-	ID        string          `json:"id"`        // NATS message sequence ID
-	Timestamp time.Time       `json:"timestamp"` // When the event occurred
-	Session   string          `json:"session"`   // Session name
-	Type      string          `json:"type"`      // Event type: task, note, inbox, iteration, control
-	Action    string          `json:"action"`    // Action type: add, status, mark_read, start, complete, etc.
-	Meta      json.RawMessage `json:"meta"`      // Action-specific metadata
-	Data      string          `json:"data"`      // Primary content (task text, note text, etc.)
+	ID        string          `json:"id"`                // NATS message sequence ID
+	Timestamp time.Time       `json:"timestamp"`         // When the event occurred
+	Session   string          `json:"session"`           // Session name
+	Type      string          `json:"type"`              // Event type: task, note, inbox, iteration, control
+	Action    string          `json:"action"`            // Action type: add, status, mark_read, start, complete, etc.
+	Meta      json.RawMessage `json:"meta"`              // Action-specific metadata
+	Data      string          `json:"data"`              // Primary content (task text, note text, etc.)
+	Version   int             `json:"version,omitempty"` // Payload schema version (see EventVersion), 0 for version 1
 }
 
-// Store manages session state through JetStream event sourcing.
-// It provides methods for publishing events and loading state from the event stream.
+// Store manages session state through event sourcing.
+// It provides methods for publishing events and loading state from the event log.
 type Store struct {
-	js     jetstream.JetStream // JetStream context for operations
-	stream jetstream.Stream    // The iteratr_events stream
+	log       EventLog         // Where events are stored (JetStream by default)
+	snapshots SnapshotStore    // Snapshot storage (nil until EnableSnapshots is called)
+	policy    SchedulingPolicy // TaskNext policy (nil uses PriorityFIFO)
 }
 
-// NewStore creates a new Store instance with the given JetStream context and stream.
-func NewStore(js jetstream.JetStream, stream jetstream.Stream) *Store {
+// NewStore creates a new Store instance on the given event log.
+func NewStore(log EventLog) *Store {
 	return &Store{
-		js:     js,
-		stream: stream,
+		log: log,
 	}
 }
 
+// Close closes the underlying event log.
+func (s *Store) Close() error {
+	return s.log.Close()
+}
+
+// ValidateName checks that a session name is usable as a NATS subject token:
+// 1-64 characters of letters, digits, hyphens and underscores.
+func ValidateName(name string) error {
+	if name == "" {
+		return fmt.Errorf("session name cannot be empty")
+	}
+	if len(name) > 64 {
+		return fmt.Errorf("session name too long (max 64 characters): %s", name)
+	}
+	for _, r := range name {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') && r != '-' && r != '_' {
+			return fmt.Errorf("invalid session name: %s (use only alphanumeric, hyphens, underscores)", name)
+		}
+	}
+	return nil
+}
+
 // ResetSession removes all events for a session, resetting it to a fresh state.
+// Any snapshot for the session is deleted as well.
 func (s *Store) ResetSession(ctx context.Context, session string) error {
-	return nats.PurgeSession(ctx, s.stream, session)
+	if err := s.deleteSnapshot(ctx, session); err != nil {
+		return err
+	}
+	subject := nats.SubjectForSession(session)
+	logger.Info("Purging session data for '%s' (subject: %s)", session, subject)
+	return s.log.Purge(ctx, subject)
 }
 
-// PublishEvent appends an event to the JetStream event log.
+// PublishEvent appends an event to the event log.
 // Events are published to subjects following the pattern: iteratr.{session}.{type}
-// Returns the published ACK or an error if publishing fails.
-func (s *Store) PublishEvent(ctx context.Context, event Event) (*jetstream.PubAck, error) {
+// Returns the sequence of the stored event or an error if publishing fails.
+func (s *Store) PublishEvent(ctx context.Context, event Event) (uint64, error) {
+	return s.publish(ctx, event, nil)
+}
+
+// publishIfLast publishes an event only if the last event of its type in the
+// session still has sequence expectedSeq; otherwise it fails with
+// ErrSequenceConflict.
+func (s *Store) publishIfLast(ctx context.Context, event Event, expectedSeq uint64) (uint64, error) {
+	return s.publish(ctx, event, &expectedSeq)
+}
+
+// publish appends an event (see appendEvent) and brings the session's
+// summary up to date.
+func (s *Store) publish(ctx context.Context, event Event, expectedSeq *uint64) (uint64, error) {
+	seq, err := s.appendEvent(ctx, event, expectedSeq)
+	if err == nil && event.Type != EventTypeKnowledge {
+		s.updateSummary(ctx, event.Session)
+	}
+	return seq, err
+}
+
+// appendEvent marshals and appends an event, optionally guarded by the expected
+// last sequence of its subject. Events without a schema version get the
+// current one: their payload must have the current shape. Events read back
+// from the log are upcast, so republishing them is safe.
+func (s *Store) appendEvent(ctx context.Context, event Event, expectedSeq *uint64) (uint64, error) {
 	// Set timestamp if not already set
 	if event.Timestamp.IsZero() {
 		event.Timestamp = time.Now()
 	}
+	if event.Version == 0 {
+		event.Version = EventVersion
+	}
 
 	// Marshal event to JSON
 	data, err := json.Marshal(event)
 	if err != nil {
 		logger.Error("Failed to marshal event: %v", err)
-		return nil, fmt.Errorf("failed to marshal event: %w", err)
+		return 0, fmt.Errorf("failed to marshal event: %w", err)
 	}
 
-	// Build subject: iteratr.{session}.{type}
+	// Build subject: iteratr.{session}.{type}, or the project-level knowledge subject
 	subject := nats.SubjectForEvent(event.Session, event.Type)
+	if event.Type == EventTypeKnowledge {
+		subject = nats.SubjectKnowledge
+	}
 
 	logger.Debug("Publishing event: session=%s type=%s action=%s", event.Session, event.Type, event.Action)
 
-	// Publish to JetStream
-	ack, err := s.js.Publish(ctx, subject, data)
+	// Append to the event log
+	var seq uint64
+	if expectedSeq != nil {
+		seq, err = s.log.AppendIfLast(ctx, subject, data, *expectedSeq)
+	} else {
+		seq, err = s.log.Append(ctx, subject, data)
+	}
 	if err != nil {
+		if errors.Is(err, ErrSequenceConflict) {
+			logger.Debug("Publish to %s rejected: subject sequence changed", subject)
+			return 0, ErrSequenceConflict
+		}
 		logger.Error("Failed to publish event to subject %s: %v", subject, err)
-		return nil, fmt.Errorf("failed to publish event: %w", err)
+		return 0, fmt.Errorf("failed to publish event: %w", err)
 	}
 
-	logger.Debug("Event published successfully: seq=%d", ack.Sequence)
-	return ack, nil
+	logger.Debug("Event published successfully: seq=%d", seq)
+	return seq, nil
+}
+
+// maxAllocAttempts bounds how often ID allocation is retried when concurrent
+// writers keep winning the race for the same subject.
+const maxAllocAttempts = 50
+
+// lastSubjectSequence returns the log sequence of the latest event of the
+// given type in a session, or 0 if the session has no such events.
+func (s *Store) lastSubjectSequence(ctx context.Context, session, eventType string) (uint64, error) {
+	return s.log.LastSequence(ctx, nats.SubjectForEvent(session, eventType))
+}
+
+// withAllocation runs fn against freshly loaded state for allocating sequential
+// IDs (TAS-N, NOT-N) from the state counters, or for any other write that must
+// be validated against the latest state (e.g. dependency cycle checks). fn must publish with
+// publishIfLast(ctx, event, expectedSeq) so that a concurrent
+// writer on the same subject makes the publish fail with ErrSequenceConflict;
+// fn is then retried against reloaded state with a short randomized backoff.
+//
+// expectedSeq is read before state is loaded, so any event published in between
+// is either reflected in state or causes a conflict - never a duplicate ID.
+func (s *Store) withAllocation(ctx context.Context, session, eventType string, fn func(state *State, expectedSeq uint64) error) error {
+	return retryOnConflict(ctx, fmt.Sprintf("%s ID in session %s", eventType, session), func() error {
+		expectedSeq, err := s.lastSubjectSequence(ctx, session, eventType)
+		if err != nil {
+			return err
+		}
+
+		state, err := s.LoadState(ctx, session)
+		if err != nil {
+			return fmt.Errorf("failed to load state for ID generation: %w", err)
+		}
+
+		return fn(state, expectedSeq)
+	})
+}
+
+// retryOnConflict runs fn until it returns something other than
+// ErrSequenceConflict, with a short randomized backoff between attempts.
+// what describes the write for errors and logs.
+func retryOnConflict(ctx context.Context, what string, fn func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if !errors.Is(err, ErrSequenceConflict) {
+			return err
+		}
+		if attempt >= maxAllocAttempts {
+			return fmt.Errorf("failed to allocate %s after %d attempts: %w", what, attempt, err)
+		}
+
+		logger.Debug("Allocation conflict for %s (attempt %d), retrying", what, attempt)
+		backoff := time.Duration(rand.Int63n(int64(attempt)*int64(5*time.Millisecond))) + time.Millisecond
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+	}
 }
 
 // State represents the current state of a session, reconstructed from events.
@@ -90,14 +219,18 @@ type State struct {
 
 // Task represents a task in the task system.
 type Task struct {
-	ID        string    `json:"id"`
-	Content   string    `json:"content"`
-	Status    string    `json:"status"`     // remaining, in_progress, completed, blocked, cancelled
-	Priority  int       `json:"priority"`   // 0-4, default 2 (0=critical, 1=high, 2=medium, 3=low, 4=backlog)
-	DependsOn []string  `json:"depends_on"` // Task IDs this task is blocked by
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Iteration int       `json:"iteration"` // Iteration that last modified this task
+	ID         string    `json:"id"`
+	Content    string    `json:"content"`
+	Status     string    `json:"status"`               // remaining, in_progress, completed, blocked, cancelled
+	Priority   int       `json:"priority"`             // 0-4, default 2 (0=critical, 1=high, 2=medium, 3=low, 4=backlog)
+	DependsOn  []string  `json:"depends_on"`           // Task IDs this task is blocked by
+	ParentID   string    `json:"parent_id,omitempty"`  // Parent task ID for subtasks, empty for top-level tasks
+	Acceptance string    `json:"acceptance,omitempty"` // Acceptance criteria describing when the task is done
+	Verify     string    `json:"verify,omitempty"`     // Shell command that must succeed before the task can be completed
+	Labels     []string  `json:"labels,omitempty"`     // Free-form tags (e.g. backend, tests, docs, user-added)
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	Iteration  int       `json:"iteration"` // Iteration that last modified this task
 }
 
 // Note represents a note recorded during a session.
@@ -111,12 +244,14 @@ type Note struct {
 
 // Iteration represents a single iteration execution.
 type Iteration struct {
-	Number      int       `json:"number"`
-	StartedAt   time.Time `json:"started_at"`
-	EndedAt     time.Time `json:"ended_at,omitempty"`
-	Complete    bool      `json:"complete"`
-	Summary     string    `json:"summary,omitempty"`      // What was accomplished
-	TasksWorked []string  `json:"tasks_worked,omitempty"` // Task IDs touched
+	Number          int       `json:"number"`
+	StartedAt       time.Time `json:"started_at"`
+	EndedAt         time.Time `json:"ended_at,omitempty"`
+	Complete        bool      `json:"complete"`
+	Summary         string    `json:"summary,omitempty"`      // What was accomplished
+	TasksWorked     []string  `json:"tasks_worked,omitempty"` // Task IDs touched
+	Usage           Usage     `json:"usage"`                  // Tokens and cost of all agent turns
+	IterationResult           // How the iteration ended, set with EndedAt
 }
 
 // SessionInfo provides summary information about a session for UI display.
@@ -125,78 +260,73 @@ type SessionInfo struct {
 	Complete       bool      `json:"complete"`
 	TasksTotal     int       `json:"tasks_total"`
 	TasksCompleted int       `json:"tasks_completed"`
+	CreatedAt      time.Time `json:"created_at"` // Time of the first event
 	LastActivity   time.Time `json:"last_activity"`
 }
 
 // Apply applies an event to the state, implementing the reduce pattern.
-// This method mutates the state based on the event type and action.
-func (st *State) Apply(event Event) {
+// This method mutates the state based on the event type and action. Events of
+// older schema versions are upcast first. An event that cannot be applied
+// leaves the state untouched and returns an ErrStateCorruption error; events
+// referring to tasks that don't exist (anymore) are ignored.
+func (st *State) Apply(event Event) error {
+	event, err := Upcast(event)
+	if err != nil {
+		return err
+	}
 	switch event.Type {
 	case nats.EventTypeTask:
-		st.applyTaskEvent(event)
+		return st.applyTaskEvent(event)
 	case nats.EventTypeNote:
-		st.applyNoteEvent(event)
+		return st.applyNoteEvent(event)
 	case nats.EventTypeIteration:
-		st.applyIterationEvent(event)
+		return st.applyIterationEvent(event)
 	case nats.EventTypeControl:
-		st.applyControlEvent(event)
+		return st.applyControlEvent(event)
 	}
+	return corruptEvent(event, "unknown event type")
 }
 
 // applyTaskEvent handles task-related events.
-func (st *State) applyTaskEvent(event Event) {
+func (st *State) applyTaskEvent(event Event) error {
 	switch event.Action {
 	case "add":
-		// Parse metadata for status, priority, and iteration
-		var meta struct {
-			Status    string `json:"status"`
-			Priority  int    `json:"priority"`
-			Iteration int    `json:"iteration"`
+		var meta TaskAddMeta
+		if err := decodeMeta(event, &meta); err != nil {
+			return err
+		}
+		if event.ID == "" {
+			return corruptEvent(event, "task ID is required")
 		}
-		_ = json.Unmarshal(event.Meta, &meta)
 
 		// Default status to "remaining"
 		if meta.Status == "" {
 			meta.Status = "remaining"
 		}
 
-		// Default priority to 2 (medium) if not set
-		// Note: If priority is explicitly 0 (critical), it will be preserved
-		// We check if meta has priority set by seeing if it's non-zero or checking meta itself
-		priority := meta.Priority
-		if priority == 0 && event.Meta != nil {
-			// Check if priority was explicitly set to 0 in metadata
-			var checkMeta map[string]interface{}
-			_ = json.Unmarshal(event.Meta, &checkMeta)
-			if _, hasPriority := checkMeta["priority"]; !hasPriority {
-				priority = 2 // Default to medium
-			}
-		} else if priority == 0 && event.Meta == nil {
-			priority = 2 // Default to medium
-		}
-
 		// Create new task
 		task := &Task{
-			ID:        event.ID,
-			Content:   event.Data,
-			Status:    meta.Status,
-			Priority:  priority,
-			DependsOn: []string{}, // Initialize empty dependencies
-			CreatedAt: event.Timestamp,
-			UpdatedAt: event.Timestamp,
-			Iteration: meta.Iteration,
+			ID:         event.ID,
+			Content:    event.Data,
+			Status:     meta.Status,
+			Priority:   meta.Priority,
+			DependsOn:  []string{}, // Initialize empty dependencies
+			ParentID:   meta.ParentID,
+			Acceptance: meta.Acceptance,
+			Verify:     meta.Verify,
+			Labels:     meta.Labels,
+			CreatedAt:  event.Timestamp,
+			UpdatedAt:  event.Timestamp,
+			Iteration:  meta.Iteration,
 		}
 		st.Tasks[event.ID] = task
 		st.TaskCounter++
 
 	case "status":
-		// Parse metadata for task ID and new status
-		var meta struct {
-			TaskID    string `json:"task_id"`
-			Status    string `json:"status"`
-			Iteration int    `json:"iteration"`
+		var meta TaskStatusMeta
+		if err := decodeMeta(event, &meta); err != nil {
+			return err
 		}
-		_ = json.Unmarshal(event.Meta, &meta)
 
 		// Update task status if it exists
 		if task, exists := st.Tasks[meta.TaskID]; exists {
@@ -206,13 +336,10 @@ func (st *State) applyTaskEvent(event Event) {
 		}
 
 	case "priority":
-		// Parse metadata for task ID and new priority
-		var meta struct {
-			TaskID    string `json:"task_id"`
-			Priority  int    `json:"priority"`
-			Iteration int    `json:"iteration"`
+		var meta TaskPriorityMeta
+		if err := decodeMeta(event, &meta); err != nil {
+			return err
 		}
-		_ = json.Unmarshal(event.Meta, &meta)
 
 		// Update task priority if it exists
 		if task, exists := st.Tasks[meta.TaskID]; exists {
@@ -222,13 +349,10 @@ func (st *State) applyTaskEvent(event Event) {
 		}
 
 	case "depends":
-		// Parse metadata for task ID and dependency
-		var meta struct {
-			TaskID    string `json:"task_id"`
-			DependsOn string `json:"depends_on"`
-			Iteration int    `json:"iteration"`
+		var meta TaskDependsMeta
+		if err := decodeMeta(event, &meta); err != nil {
+			return err
 		}
-		_ = json.Unmarshal(event.Meta, &meta)
 
 		// Add dependency if task exists and dependency not already present
 		if task, exists := st.Tasks[meta.TaskID]; exists {
@@ -247,19 +371,96 @@ func (st *State) applyTaskEvent(event Event) {
 			task.UpdatedAt = event.Timestamp
 			task.Iteration = meta.Iteration
 		}
+
+	case "undepend":
+		var meta TaskDependsMeta
+		if err := decodeMeta(event, &meta); err != nil {
+			return err
+		}
+
+		// Remove dependency if task exists
+		if task, exists := st.Tasks[meta.TaskID]; exists {
+			task.DependsOn = removeString(task.DependsOn, meta.DependsOn)
+			task.UpdatedAt = event.Timestamp
+			task.Iteration = meta.Iteration
+		}
+
+	case "label":
+		var meta TaskLabelMeta
+		if err := decodeMeta(event, &meta); err != nil {
+			return err
+		}
+
+		// Apply label changes if task exists
+		if task, exists := st.Tasks[meta.TaskID]; exists {
+			task.Labels = addLabels(task.Labels, meta.Add)
+			for _, label := range meta.Remove {
+				task.Labels = removeString(task.Labels, label)
+			}
+			task.UpdatedAt = event.Timestamp
+			task.Iteration = meta.Iteration
+		}
+
+	case "edit":
+		var meta TaskEditMeta
+		if err := decodeMeta(event, &meta); err != nil {
+			return err
+		}
+
+		// Update task content if it exists
+		if task, exists := st.Tasks[meta.TaskID]; exists && meta.Content != "" {
+			task.Content = meta.Content
+			task.UpdatedAt = event.Timestamp
+			task.Iteration = meta.Iteration
+		}
+
+	case "remove":
+		var meta TaskRemoveMeta
+		if err := decodeMeta(event, &meta); err != nil {
+			return err
+		}
+
+		// Tombstone: drop the task and any dependencies on it, and move its
+		// subtasks up to its parent. TaskCounter is not decremented so IDs are
+		// never reused.
+		if removed, exists := st.Tasks[meta.TaskID]; exists {
+			delete(st.Tasks, meta.TaskID)
+			for _, task := range st.Tasks {
+				task.DependsOn = removeString(task.DependsOn, meta.TaskID)
+				if task.ParentID == meta.TaskID {
+					task.ParentID = removed.ParentID
+				}
+			}
+		}
+
+	default:
+		return corruptEvent(event, "unknown action")
+	}
+	return nil
+}
+
+// removeString returns list without any occurrence of value.
+func removeString(list []string, value string) []string {
+	result := list[:0]
+	for _, item := range list {
+		if item != value {
+			result = append(result, item)
+		}
 	}
+	return result
 }
 
 // applyNoteEvent handles note-related events.
-func (st *State) applyNoteEvent(event Event) {
+func (st *State) applyNoteEvent(event Event) error {
 	switch event.Action {
 	case "add":
-		// Parse metadata for note type and iteration
-		var meta struct {
-			Type      string `json:"type"`
-			Iteration int    `json:"iteration"`
+		var meta NoteAddMeta
+		if err := decodeMeta(event, &meta); err != nil {
+			return err
+		}
+		if event.ID == "" {
+			return corruptEvent(event, "note ID is required")
 		}
-		_ = json.Unmarshal(event.Meta, &meta)
 
 		// Create new note
 		note := &Note{
@@ -271,18 +472,21 @@ func (st *State) applyNoteEvent(event Event) {
 		}
 		st.Notes = append(st.Notes, note)
 		st.NoteCounter++
+
+	default:
+		return corruptEvent(event, "unknown action")
 	}
+	return nil
 }
 
 // applyIterationEvent handles iteration-related events.
-func (st *State) applyIterationEvent(event Event) {
+func (st *State) applyIterationEvent(event Event) error {
 	switch event.Action {
 	case "start":
-		// Parse metadata for iteration number
-		var meta struct {
-			Number int `json:"number"`
+		var meta IterationStartMeta
+		if err := decodeMeta(event, &meta); err != nil {
+			return err
 		}
-		_ = json.Unmarshal(event.Meta, &meta)
 
 		// Create new iteration
 		iter := &Iteration{
@@ -293,199 +497,278 @@ func (st *State) applyIterationEvent(event Event) {
 		st.Iterations = append(st.Iterations, iter)
 
 	case "complete":
-		// Parse metadata for iteration number
-		var meta struct {
-			Number int `json:"number"`
-		}
-		_ = json.Unmarshal(event.Meta, &meta)
-
-		// Mark iteration as complete
-		for _, iter := range st.Iterations {
-			if iter.Number == meta.Number {
-				iter.Complete = true
-				iter.EndedAt = event.Timestamp
-				break
-			}
+		var meta IterationCompleteMeta
+		if err := decodeMeta(event, &meta); err != nil {
+			return err
+		}
+
+		// Record how the iteration ended; only successful iterations are complete
+		if iter := st.iteration(meta.Number); iter != nil {
+			iter.Complete = meta.Outcome == IterationSucceeded
+			iter.EndedAt = event.Timestamp
+			iter.IterationResult = meta.IterationResult
+		}
+
+	case "abandoned":
+		var meta IterationAbandonedMeta
+		if err := decodeMeta(event, &meta); err != nil {
+			return err
+		}
+
+		// End the iteration without completing it
+		if iter := st.iteration(meta.Number); iter != nil {
+			iter.EndedAt = event.Timestamp
+			iter.Outcome = IterationAbandoned
+			iter.InterruptedTasks = meta.InterruptedTasks
 		}
 
 	case "summary":
-		// Parse metadata for iteration number, summary, and tasks worked
-		var meta struct {
-			Number      int      `json:"number"`
-			Summary     string   `json:"summary"`
-			TasksWorked []string `json:"tasks_worked"`
+		var meta IterationSummaryMeta
+		if err := decodeMeta(event, &meta); err != nil {
+			return err
 		}
-		_ = json.Unmarshal(event.Meta, &meta)
 
 		// Update iteration with summary and tasks worked
-		for _, iter := range st.Iterations {
-			if iter.Number == meta.Number {
-				iter.Summary = meta.Summary
-				iter.TasksWorked = meta.TasksWorked
-				break
-			}
+		if iter := st.iteration(meta.Number); iter != nil {
+			iter.Summary = meta.Summary
+			iter.TasksWorked = meta.TasksWorked
+		}
+
+	case "usage":
+		var meta IterationUsageMeta
+		if err := decodeMeta(event, &meta); err != nil {
+			return err
+		}
+
+		// Add to the iteration's running total
+		if iter := st.iteration(meta.Number); iter != nil {
+			iter.Usage = iter.Usage.Add(meta.Usage)
+		}
+
+	default:
+		return corruptEvent(event, "unknown action")
+	}
+	return nil
+}
+
+// iteration returns the first iteration with the given number, or nil.
+func (st *State) iteration(number int) *Iteration {
+	for _, iter := range st.Iterations {
+		if iter.Number == number {
+			return iter
 		}
 	}
+	return nil
 }
 
 // applyControlEvent handles control-related events.
-func (st *State) applyControlEvent(event Event) {
+func (st *State) applyControlEvent(event Event) error {
 	switch event.Action {
 	case "session_complete":
 		st.Complete = true
 	case "session_restart":
 		st.Complete = false
+	case "compacted":
+		// History before this event was rewritten into the events that follow
+		// it; start over from the counters of the tasks and notes that are gone
+		var meta CompactedMeta
+		if err := decodeMeta(event, &meta); err != nil {
+			return err
+		}
+		*st = State{
+			Session:     st.Session,
+			Tasks:       make(map[string]*Task),
+			TaskCounter: meta.TaskCounter,
+			NoteCounter: meta.NoteCounter,
+		}
+	default:
+		return corruptEvent(event, "unknown action")
 	}
+	return nil
+}
+
+// Orders accepted by SessionListParams.Sort.
+const (
+	SortByActivity = "activity" // Most recently active first (default)
+	SortByName     = "name"     // Alphabetical
+	SortByCreated  = "created"  // Newest first
+)
+
+// SessionListParams selects a page of sessions for ListSessionsPage.
+type SessionListParams struct {
+	Sort   string // SortByActivity (default), SortByName or SortByCreated
+	Offset int    // Sessions to skip
+	Limit  int    // Maximum sessions to return (0 = all)
 }
 
 // ListSessions returns summary information for all sessions, sorted by last activity.
-// It queries the stream for session names, loads each session's state, and builds
-// SessionInfo structs with task counts and activity timestamps.
 func (s *Store) ListSessions(ctx context.Context) ([]SessionInfo, error) {
-	logger.Debug("Listing all sessions")
+	infos, _, err := s.ListSessionsPage(ctx, SessionListParams{})
+	return infos, err
+}
+
+// ListSessionsPage returns one page of session summaries in the requested
+// order, along with the total number of sessions. Each session is served from
+// its stored summary, which is kept up to date on publish, so listing doesn't
+// replay events unless a summary is missing or behind.
+func (s *Store) ListSessionsPage(ctx context.Context, params SessionListParams) ([]SessionInfo, int, error) {
+	logger.Debug("Listing sessions (sort=%s offset=%d limit=%d)", params.Sort, params.Offset, params.Limit)
+
+	less, err := sessionOrder(params.Sort)
+	if err != nil {
+		return nil, 0, err
+	}
 
-	// Get unique session names from stream subjects
-	sessionNames, err := nats.ListSessions(ctx, s.stream)
+	// Get unique session names from the event log
+	sessionNames, err := s.log.Sessions(ctx)
 	if err != nil {
 		logger.Error("Failed to list sessions: %v", err)
-		return nil, fmt.Errorf("failed to list sessions: %w", err)
+		return nil, 0, fmt.Errorf("failed to list sessions: %w", err)
 	}
 
-	// Build SessionInfo for each session
 	infos := make([]SessionInfo, 0, len(sessionNames))
 	for _, name := range sessionNames {
-		// Load session state to extract details
-		state, err := s.LoadState(ctx, name)
+		sum, err := s.sessionSummary(ctx, name)
 		if err != nil {
-			logger.Warn("Failed to load state for session '%s': %v", name, err)
+			logger.Warn("Failed to load summary for session '%s': %v", name, err)
 			continue // Skip sessions we can't load
 		}
+		infos = append(infos, sum.info(name))
+	}
 
-		// Count completed tasks
-		completed := 0
-		for _, task := range state.Tasks {
-			if task.Status == "completed" {
-				completed++
-			}
-		}
-
-		// Find last activity timestamp (most recent event)
-		lastActivity := time.Time{} // Zero time
-		for _, task := range state.Tasks {
-			if task.UpdatedAt.After(lastActivity) {
-				lastActivity = task.UpdatedAt
-			}
-		}
-		if len(state.Notes) > 0 {
-			lastNote := state.Notes[len(state.Notes)-1]
-			if lastNote.CreatedAt.After(lastActivity) {
-				lastActivity = lastNote.CreatedAt
-			}
-		}
-		if len(state.Iterations) > 0 {
-			lastIter := state.Iterations[len(state.Iterations)-1]
-			if lastIter.EndedAt.After(lastActivity) {
-				lastActivity = lastIter.EndedAt
-			} else if lastIter.StartedAt.After(lastActivity) {
-				lastActivity = lastIter.StartedAt
-			}
-		}
+	sort.SliceStable(infos, func(i, j int) bool {
+		return less(infos[i], infos[j])
+	})
 
-		// Build SessionInfo
-		info := SessionInfo{
-			Name:           name,
-			Complete:       state.Complete,
-			TasksTotal:     len(state.Tasks),
-			TasksCompleted: completed,
-			LastActivity:   lastActivity,
-		}
-		infos = append(infos, info)
+	total := len(infos)
+	if params.Offset > 0 {
+		infos = infos[min(params.Offset, total):]
 	}
+	if params.Limit > 0 && params.Limit < len(infos) {
+		infos = infos[:params.Limit]
+	}
+
+	logger.Debug("Found %d sessions", total)
+	return infos, total, nil
+}
 
-	// Sort by LastActivity descending (most recent first)
-	// Using simple bubble sort for small lists
-	for i := 0; i < len(infos); i++ {
-		for j := i + 1; j < len(infos); j++ {
-			if infos[j].LastActivity.After(infos[i].LastActivity) {
-				infos[i], infos[j] = infos[j], infos[i]
+// sessionOrder returns the less function of a SessionListParams.Sort order.
+// Ties are broken by name.
+func sessionOrder(order string) (func(a, b SessionInfo) bool, error) {
+	switch order {
+	case "", SortByActivity:
+		return func(a, b SessionInfo) bool {
+			if !a.LastActivity.Equal(b.LastActivity) {
+				return a.LastActivity.After(b.LastActivity)
 			}
-		}
+			return a.Name < b.Name
+		}, nil
+	case SortByName:
+		return func(a, b SessionInfo) bool { return a.Name < b.Name }, nil
+	case SortByCreated:
+		return func(a, b SessionInfo) bool {
+			if !a.CreatedAt.Equal(b.CreatedAt) {
+				return a.CreatedAt.After(b.CreatedAt)
+			}
+			return a.Name < b.Name
+		}, nil
+	default:
+		return nil, fmt.Errorf("invalid sort order: %s (use %s, %s or %s)", order, SortByActivity, SortByName, SortByCreated)
 	}
-
-	logger.Debug("Found %d sessions", len(infos))
-	return infos, nil
 }
 
 // LoadState reconstructs the current state of a session by reading and reducing
-// all events from the JetStream event log. This implements the event sourcing pattern.
+// events from the event log. This implements the event sourcing pattern.
+// If a snapshot exists for the session, only events after the snapshot sequence
+// are replayed; otherwise (or if the snapshot is stale) all events are replayed.
 func (s *Store) LoadState(ctx context.Context, session string) (*State, error) {
+	state, _, err := s.loadState(ctx, session)
+	return state, err
+}
+
+// loadState is LoadState that also returns the stream sequence of the last
+// event reflected in the returned state (0 if the session has no events).
+func (s *Store) loadState(ctx context.Context, session string) (*State, uint64, error) {
 	logger.Debug("Loading state for session: %s", session)
 
-	// Create a consumer filtered to this session's events
-	consumer, err := s.stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
-		FilterSubject: nats.SubjectForSession(session),
-		DeliverPolicy: jetstream.DeliverAllPolicy, // Start from beginning
-		AckPolicy:     jetstream.AckExplicitPolicy,
-	})
-	if err != nil {
-		logger.Error("Failed to create consumer for session %s: %v", session, err)
-		return nil, fmt.Errorf("failed to create consumer: %w", err)
+	// Try snapshot first
+	if snapshot := s.usableSnapshot(ctx, session); snapshot != nil {
+		logger.Debug("Using snapshot for session %s (after seq=%d)", session, snapshot.AfterSequence)
+		lastSeq, err := s.replayEvents(ctx, session, snapshot.State, snapshot.AfterSequence)
+		if err != nil {
+			return nil, 0, err
+		}
+		return snapshot.State, lastSeq, nil
 	}
 
-	// Initialize empty state
+	// Fall back to full replay from an empty state
 	state := &State{
 		Session: session,
 		Tasks:   make(map[string]*Task),
 	}
+	lastSeq, err := s.replayEvents(ctx, session, state, 0)
+	if err != nil {
+		return nil, 0, err
+	}
+	return state, lastSeq, nil
+}
 
-	// Fetch events in batches and reduce into state
-	// Using a large batch size to minimize round trips
-	const batchSize = 1000
-	malformedCount := 0
-	totalEvents := 0
-	for {
-		// Fetch with short timeout to avoid blocking forever
-		msgs, err := consumer.FetchNoWait(batchSize)
-		if err != nil {
-			// No more messages or error - we've read everything
-			logger.Debug("Finished reading events (batch fetch complete)")
-			break
+// replayEvents applies all events for the session with a stream sequence greater
+// than afterSeq to the given state. Returns the sequence of the last applied event,
+// or afterSeq if there were no newer events.
+func (s *Store) replayEvents(ctx context.Context, session string, state *State, afterSeq uint64) (uint64, error) {
+	corrupt := 0
+	lastSeq, err := s.forEachEvent(ctx, session, afterSeq, func(seq uint64, event Event) bool {
+		// Apply event to state (reduce); one bad event must not make the
+		// session unloadable, so report it and carry on
+		if err := state.Apply(event); err != nil {
+			corrupt++
+			logger.Warn("Skipping event seq=%d: %v", seq, err)
 		}
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+	if corrupt > 0 {
+		logger.Warn("Skipped %d corrupt events in session %s (run 'iteratr doctor --events')", corrupt, session)
+	}
 
-		msgCount := 0
-		for msg := range msgs.Messages() {
-			msgCount++
-			totalEvents++
-			// Unmarshal event
-			var event Event
-			if err := json.Unmarshal(msg.Data(), &event); err != nil {
-				// Log malformed event and skip (but acknowledge to prevent redelivery)
-				malformedCount++
-				meta, _ := msg.Metadata()
-				logger.Warn("Skipping malformed event (seq=%d): %v", meta.Sequence.Stream, err)
-				_ = msg.Ack()
-				continue
-			}
-
-			// Store the message sequence as ID if not set
-			if event.ID == "" {
-				meta, _ := msg.Metadata()
-				event.ID = fmt.Sprintf("%d", meta.Sequence.Stream)
-			}
+	logger.Debug("State loaded after seq %d: %d tasks, %d notes, %d iterations",
+		afterSeq, len(state.Tasks), len(state.Notes), len(state.Iterations))
 
-			// Apply event to state (reduce)
-			state.Apply(event)
+	return lastSeq, nil
+}
 
-			// Acknowledge message
-			_ = msg.Ack()
+// forEachEvent calls fn for each event of the session with a stream sequence
+// greater than afterSeq, in stream order, upcast to the current schema version
+// where possible. Malformed events are skipped. Iteration
+// stops early when fn returns false. Returns the sequence of the last event
+// passed to fn, or afterSeq if there was none.
+func (s *Store) forEachEvent(ctx context.Context, session string, afterSeq uint64, fn func(seq uint64, event Event) bool) (uint64, error) {
+	malformedCount := 0
+	totalEvents := 0
+	lastSeq, err := s.forEachMsg(ctx, nats.SubjectForSessionEvents(session), afterSeq, func(seq uint64, data []byte) bool {
+		totalEvents++
+
+		// Unmarshal event
+		var event Event
+		if err := json.Unmarshal(data, &event); err != nil {
+			// Log malformed event and skip
+			malformedCount++
+			logger.Warn("Skipping malformed event (seq=%d): %v", seq, err)
+			return true
 		}
 
-		logger.Debug("Processed batch: %d events", msgCount)
-
-		// If we got fewer messages than batch size, we've reached the end
-		if msgCount < batchSize {
-			break
+		// Store the message sequence as ID if not set
+		if event.ID == "" {
+			event.ID = fmt.Sprintf("%d", seq)
 		}
+		return fn(seq, upcastRead(event))
+	})
+	if err != nil {
+		logger.Error("Failed to read events for session %s: %v", session, err)
+		return 0, err
 	}
 
 	// Warn if we encountered malformed events
@@ -493,8 +776,93 @@ func (s *Store) LoadState(ctx context.Context, session string) (*State, error) {
 		logger.Warn("Skipped %d malformed events while loading state", malformedCount)
 	}
 
-	logger.Debug("State loaded: %d total events, %d tasks, %d notes, %d iterations",
-		totalEvents, len(state.Tasks), len(state.Notes), len(state.Iterations))
+	logger.Debug("Read %d events after seq %d", totalEvents, afterSeq)
+
+	return lastSeq, nil
+}
+
+// Watch delivers the state events (task, note, iteration, control) appended to
+// a session after the call, by this or any other process sharing the event
+// log. Malformed events are skipped. The channel is closed when ctx is done.
+func (s *Store) Watch(ctx context.Context, session string) (<-chan Event, error) {
+	lastSeq, err := s.log.LastSequence(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to watch session: %w", err)
+	}
+	return s.watchEvents(ctx, session, lastSeq)
+}
+
+// WatchState loads the current state of a session and watches the events
+// that follow it. Applying each event to the returned state keeps it in sync
+// with all writers: no event is missed or delivered twice between the load
+// and the start of the watch.
+func (s *Store) WatchState(ctx context.Context, session string) (*State, <-chan Event, error) {
+	state, lastSeq, err := s.loadState(ctx, session)
+	if err != nil {
+		return nil, nil, err
+	}
+	events, err := s.watchEvents(ctx, session, lastSeq)
+	if err != nil {
+		return nil, nil, err
+	}
+	return state, events, nil
+}
+
+// watchEvents delivers the state events of a session after afterSeq.
+func (s *Store) watchEvents(ctx context.Context, session string, afterSeq uint64) (<-chan Event, error) {
+	msgs, err := s.log.Watch(ctx, nats.SubjectForSessionEvents(session), afterSeq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to watch session: %w", err)
+	}
+
+	events := make(chan Event, 64)
+	go func() {
+		defer close(events)
+		for msg := range msgs {
+			var event Event
+			if err := json.Unmarshal(msg.Data, &event); err != nil {
+				logger.Warn("Skipping malformed event (seq=%d): %v", msg.Sequence, err)
+				continue
+			}
+			if event.ID == "" {
+				event.ID = fmt.Sprintf("%d", msg.Sequence)
+			}
+			select {
+			case events <- upcastRead(event):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, nil
+}
+
+// upcastRead upcasts an event read from the log. An event that can't be upcast
+// is returned as is, for Apply to report.
+func upcastRead(event Event) Event {
+	if upcast, err := Upcast(event); err == nil {
+		return upcast
+	}
+	return event
+}
 
-	return state, nil
+// forEachMsg calls fn with the data of each log message matching filter with
+// a sequence greater than afterSeq, in log order. Iteration stops early when
+// fn returns false. Returns the sequence of the last message passed to fn, or
+// afterSeq if there was none.
+func (s *Store) forEachMsg(ctx context.Context, filter string, afterSeq uint64, fn func(seq uint64, data []byte) bool) (uint64, error) {
+	lastSeq := afterSeq
+	err := s.log.Read(ctx, filter, afterSeq, func(msg Message) bool {
+		if !fn(msg.Sequence, msg.Data) {
+			return false
+		}
+		if msg.Sequence > lastSeq {
+			lastSeq = msg.Sequence
+		}
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+	return lastSeq, nil
 }
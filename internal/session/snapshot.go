@@ -0,0 +1,155 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/iteratr/internal/logger"
+)
+
+// snapshotVersion is bumped whenever the State shape or reducer semantics change
+// in a way that makes previously written snapshots unsafe to reuse.
+const snapshotVersion = 3
+
+// Snapshot is a serialized session state together with the stream sequence of
+// the last event it reflects. Events remain authoritative: a snapshot is only a
+// cache that lets LoadState skip replaying events up to AfterSequence.
+type Snapshot struct {
+	Version       int       `json:"version"`
+	State         *State    `json:"state"`
+	AfterSequence uint64    `json:"after_sequence"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// ErrNoSnapshot is returned by ReadSnapshot when no snapshot exists for a session.
+var ErrNoSnapshot = errors.New("no snapshot")
+
+// EnableSnapshots prepares the event log's snapshot storage (e.g. creates the
+// snapshot KV bucket) and enables snapshot reads and writes on this store.
+// Safe to call repeatedly.
+func (s *Store) EnableSnapshots(ctx context.Context) error {
+	snapshots, ok := s.log.(SnapshotStore)
+	if !ok {
+		return fmt.Errorf("storage backend does not support snapshots")
+	}
+	if err := snapshots.SetupSnapshots(ctx); err != nil {
+		return err
+	}
+	s.snapshots = snapshots
+	return nil
+}
+
+// WriteSnapshot stores the given state as the latest snapshot for a session.
+// afterSeq must be the stream sequence of the last event applied to state.
+func (s *Store) WriteSnapshot(ctx context.Context, session string, state *State, afterSeq uint64) error {
+	if s.snapshots == nil {
+		return fmt.Errorf("snapshots not enabled")
+	}
+
+	data, err := json.Marshal(Snapshot{
+		Version:       snapshotVersion,
+		State:         state,
+		AfterSequence: afterSeq,
+		CreatedAt:     time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal snapshot: %w", err)
+	}
+
+	if err := s.snapshots.PutSnapshot(ctx, session, data); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+
+	logger.Debug("Snapshot written for session %s (after seq=%d, %d bytes)", session, afterSeq, len(data))
+	return nil
+}
+
+// ReadSnapshot returns the latest snapshot for a session.
+// Returns ErrNoSnapshot if the session has no snapshot or snapshots are disabled.
+func (s *Store) ReadSnapshot(ctx context.Context, session string) (*Snapshot, error) {
+	if s.snapshots == nil {
+		return nil, ErrNoSnapshot
+	}
+
+	data, err := s.snapshots.GetSnapshot(ctx, session)
+	if err != nil {
+		if errors.Is(err, ErrNoSnapshot) {
+			return nil, ErrNoSnapshot
+		}
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+	return &snapshot, nil
+}
+
+// SaveSnapshot loads the current state of a session and writes it as the
+// latest snapshot. Intended to be called at iteration end.
+func (s *Store) SaveSnapshot(ctx context.Context, session string) error {
+	state, lastSeq, err := s.loadState(ctx, session)
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+	if lastSeq == 0 {
+		// Nothing to snapshot yet
+		return nil
+	}
+	return s.WriteSnapshot(ctx, session, state, lastSeq)
+}
+
+// deleteSnapshot removes the snapshot and summary for a session, if any.
+func (s *Store) deleteSnapshot(ctx context.Context, session string) error {
+	if s.snapshots == nil {
+		return nil
+	}
+	if err := s.snapshots.DeleteSnapshot(ctx, session); err != nil {
+		return fmt.Errorf("failed to delete snapshot: %w", err)
+	}
+	if err := s.snapshots.DeleteSnapshot(ctx, summaryKey(session)); err != nil {
+		return fmt.Errorf("failed to delete summary: %w", err)
+	}
+	return nil
+}
+
+// usableSnapshot returns the session's snapshot if it can safely seed LoadState,
+// or nil if a full replay is required. A snapshot is stale if it was written by a
+// different snapshot version or references a sequence beyond the end of the stream
+// (e.g. the stream was recreated after the snapshot was taken).
+func (s *Store) usableSnapshot(ctx context.Context, session string) *Snapshot {
+	snapshot, err := s.ReadSnapshot(ctx, session)
+	if err != nil {
+		if !errors.Is(err, ErrNoSnapshot) {
+			logger.Warn("Ignoring snapshot for session %s: %v", session, err)
+		}
+		return nil
+	}
+
+	if snapshot.Version != snapshotVersion || snapshot.State == nil {
+		logger.Debug("Ignoring stale snapshot for session %s (version=%d)", session, snapshot.Version)
+		return nil
+	}
+
+	lastSeq, err := s.log.LastSequence(ctx, "")
+	if err != nil {
+		logger.Warn("Failed to get last sequence for snapshot validation: %v", err)
+		return nil
+	}
+	if snapshot.AfterSequence > lastSeq {
+		logger.Warn("Ignoring snapshot for session %s: sequence %d is past stream end %d",
+			session, snapshot.AfterSequence, lastSeq)
+		return nil
+	}
+
+	// Maps decode as nil when empty; the reducers expect them initialized
+	if snapshot.State.Tasks == nil {
+		snapshot.State.Tasks = make(map[string]*Task)
+	}
+	snapshot.State.Session = session
+	return snapshot
+}
@@ -0,0 +1,255 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/mark3labs/iteratr/internal/nats"
+)
+
+func TestSnapshots(t *testing.T) {
+	// Setup: Create embedded NATS and store
+	ctx := context.Background()
+	ns, _, err := nats.StartEmbeddedNATS(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to start NATS: %v", err)
+	}
+	defer ns.Shutdown()
+
+	nc, err := nats.ConnectInProcess(ns)
+	if err != nil {
+		t.Fatalf("failed to connect to NATS: %v", err)
+	}
+	defer nc.Close()
+
+	js, err := nats.CreateJetStream(nc)
+	if err != nil {
+		t.Fatalf("failed to create JetStream: %v", err)
+	}
+
+	stream, err := nats.SetupStream(ctx, js)
+	if err != nil {
+		t.Fatalf("failed to setup stream: %v", err)
+	}
+
+	store := NewStore(NewJetStreamLog(js, stream))
+	if err := store.EnableSnapshots(ctx); err != nil {
+		t.Fatalf("failed to create snapshot bucket: %v", err)
+	}
+
+	t.Run("ReadSnapshot returns ErrNoSnapshot when missing", func(t *testing.T) {
+		_, err := store.ReadSnapshot(ctx, "no-snapshot")
+		if !errors.Is(err, ErrNoSnapshot) {
+			t.Errorf("expected ErrNoSnapshot, got %v", err)
+		}
+	})
+
+	t.Run("WriteSnapshot and ReadSnapshot round-trip", func(t *testing.T) {
+		session := "roundtrip"
+		_, _ = store.TaskAdd(ctx, session, TaskAddParams{Content: "Task A", Priority: 1})
+		_, _ = store.NoteAdd(ctx, session, NoteAddParams{Content: "Note A", Type: "tip"})
+
+		state, lastSeq, err := store.loadState(ctx, session)
+		if err != nil {
+			t.Fatalf("loadState failed: %v", err)
+		}
+		if err := store.WriteSnapshot(ctx, session, state, lastSeq); err != nil {
+			t.Fatalf("WriteSnapshot failed: %v", err)
+		}
+
+		snapshot, err := store.ReadSnapshot(ctx, session)
+		if err != nil {
+			t.Fatalf("ReadSnapshot failed: %v", err)
+		}
+		if snapshot.AfterSequence != lastSeq {
+			t.Errorf("expected after_sequence %d, got %d", lastSeq, snapshot.AfterSequence)
+		}
+		if len(snapshot.State.Tasks) != 1 || snapshot.State.Tasks["TAS-1"].Priority != 1 {
+			t.Errorf("unexpected snapshot tasks: %+v", snapshot.State.Tasks)
+		}
+		if len(snapshot.State.Notes) != 1 || snapshot.State.NoteCounter != 1 {
+			t.Errorf("unexpected snapshot notes: %+v", snapshot.State.Notes)
+		}
+	})
+
+	t.Run("LoadState replays only events after snapshot", func(t *testing.T) {
+		session := "partial-replay"
+		_, _ = store.TaskAdd(ctx, session, TaskAddParams{Content: "Before snapshot"})
+		if err := store.SaveSnapshot(ctx, session); err != nil {
+			t.Fatalf("SaveSnapshot failed: %v", err)
+		}
+
+		// Tamper with the snapshot so we can tell it was used: events before
+		// the snapshot sequence must not be replayed on top of it.
+		snapshot, err := store.ReadSnapshot(ctx, session)
+		if err != nil {
+			t.Fatalf("ReadSnapshot failed: %v", err)
+		}
+		snapshot.State.Tasks["TAS-1"].Content = "From snapshot"
+		if err := store.WriteSnapshot(ctx, session, snapshot.State, snapshot.AfterSequence); err != nil {
+			t.Fatalf("WriteSnapshot failed: %v", err)
+		}
+
+		// New events after the snapshot
+		_, _ = store.TaskAdd(ctx, session, TaskAddParams{Content: "After snapshot"})
+		_ = store.TaskStatus(ctx, session, TaskStatusParams{ID: "TAS-1", Status: "completed"})
+
+		state, err := store.LoadState(ctx, session)
+		if err != nil {
+			t.Fatalf("LoadState failed: %v", err)
+		}
+		if len(state.Tasks) != 2 {
+			t.Fatalf("expected 2 tasks, got %d", len(state.Tasks))
+		}
+		if state.Tasks["TAS-1"].Content != "From snapshot" {
+			t.Errorf("expected snapshot to seed state, got content %q", state.Tasks["TAS-1"].Content)
+		}
+		if state.Tasks["TAS-1"].Status != "completed" {
+			t.Errorf("expected post-snapshot status event applied, got %q", state.Tasks["TAS-1"].Status)
+		}
+		if state.Tasks["TAS-2"].Content != "After snapshot" {
+			t.Errorf("expected TAS-2 from post-snapshot event, got %+v", state.Tasks["TAS-2"])
+		}
+		if state.TaskCounter != 2 {
+			t.Errorf("expected task counter 2, got %d", state.TaskCounter)
+		}
+	})
+
+	t.Run("LoadState matches full replay", func(t *testing.T) {
+		session := "equivalence"
+		_, _ = store.TaskAdd(ctx, session, TaskAddParams{Content: "One"})
+		_ = store.IterationStart(ctx, session, 1)
+		if err := store.SaveSnapshot(ctx, session); err != nil {
+			t.Fatalf("SaveSnapshot failed: %v", err)
+		}
+		_, _ = store.TaskAdd(ctx, session, TaskAddParams{Content: "Two"})
+		_ = store.IterationComplete(ctx, session, 1, IterationResult{})
+
+		withSnapshot, err := store.LoadState(ctx, session)
+		if err != nil {
+			t.Fatalf("LoadState failed: %v", err)
+		}
+		full, err := NewStore(NewJetStreamLog(js, stream)).LoadState(ctx, session)
+		if err != nil {
+			t.Fatalf("full LoadState failed: %v", err)
+		}
+
+		if len(withSnapshot.Tasks) != len(full.Tasks) || withSnapshot.TaskCounter != full.TaskCounter {
+			t.Errorf("task mismatch: snapshot=%d/%d full=%d/%d",
+				len(withSnapshot.Tasks), withSnapshot.TaskCounter, len(full.Tasks), full.TaskCounter)
+		}
+		if len(withSnapshot.Iterations) != 1 || !withSnapshot.Iterations[0].Complete {
+			t.Errorf("expected completed iteration 1, got %+v", withSnapshot.Iterations)
+		}
+	})
+
+	t.Run("LoadState falls back when no snapshot", func(t *testing.T) {
+		session := "no-snapshot-fallback"
+		_, _ = store.TaskAdd(ctx, session, TaskAddParams{Content: "Only task"})
+
+		state, err := store.LoadState(ctx, session)
+		if err != nil {
+			t.Fatalf("LoadState failed: %v", err)
+		}
+		if len(state.Tasks) != 1 {
+			t.Errorf("expected 1 task, got %d", len(state.Tasks))
+		}
+	})
+
+	t.Run("LoadState ignores snapshot past stream end", func(t *testing.T) {
+		session := "stale-snapshot"
+		_, _ = store.TaskAdd(ctx, session, TaskAddParams{Content: "Real task"})
+
+		stale := &State{Session: session, Tasks: map[string]*Task{
+			"TAS-99": {ID: "TAS-99", Content: "Ghost"},
+		}}
+		if err := store.WriteSnapshot(ctx, session, stale, 1<<40); err != nil {
+			t.Fatalf("WriteSnapshot failed: %v", err)
+		}
+
+		state, err := store.LoadState(ctx, session)
+		if err != nil {
+			t.Fatalf("LoadState failed: %v", err)
+		}
+		if _, ok := state.Tasks["TAS-99"]; ok {
+			t.Error("expected stale snapshot to be ignored")
+		}
+		if len(state.Tasks) != 1 {
+			t.Errorf("expected 1 task from full replay, got %d", len(state.Tasks))
+		}
+	})
+
+	t.Run("LoadState ignores snapshot of an older reducer", func(t *testing.T) {
+		session := "old-snapshot"
+		_, _ = store.TaskAdd(ctx, session, TaskAddParams{Content: "Real task"})
+		lastSeq, _ := store.log.LastSequence(ctx, "")
+
+		// Written before typed payloads were upcast on replay
+		data, _ := json.Marshal(Snapshot{
+			Version: 2,
+			State: &State{Session: session, Tasks: map[string]*Task{
+				"TAS-99": {ID: "TAS-99", Content: "Ghost"},
+			}},
+			AfterSequence: lastSeq,
+		})
+		if err := store.snapshots.PutSnapshot(ctx, session, data); err != nil {
+			t.Fatalf("PutSnapshot failed: %v", err)
+		}
+
+		state, err := store.LoadState(ctx, session)
+		if err != nil {
+			t.Fatalf("LoadState failed: %v", err)
+		}
+		if _, ok := state.Tasks["TAS-99"]; ok || len(state.Tasks) != 1 {
+			t.Errorf("expected old snapshot to be ignored, got %d tasks", len(state.Tasks))
+		}
+	})
+
+	t.Run("ResetSession deletes snapshot", func(t *testing.T) {
+		session := "reset-snapshot"
+		_, _ = store.TaskAdd(ctx, session, TaskAddParams{Content: "Task"})
+		if err := store.SaveSnapshot(ctx, session); err != nil {
+			t.Fatalf("SaveSnapshot failed: %v", err)
+		}
+
+		if err := store.ResetSession(ctx, session); err != nil {
+			t.Fatalf("ResetSession failed: %v", err)
+		}
+
+		if _, err := store.ReadSnapshot(ctx, session); !errors.Is(err, ErrNoSnapshot) {
+			t.Errorf("expected snapshot to be deleted, got %v", err)
+		}
+		state, err := store.LoadState(ctx, session)
+		if err != nil {
+			t.Fatalf("LoadState failed: %v", err)
+		}
+		if len(state.Tasks) != 0 {
+			t.Errorf("expected empty state after reset, got %d tasks", len(state.Tasks))
+		}
+	})
+
+	t.Run("snapshot survives store restart", func(t *testing.T) {
+		session := "restart"
+		_, _ = store.TaskAdd(ctx, session, TaskAddParams{Content: "Persisted"})
+		if err := store.SaveSnapshot(ctx, session); err != nil {
+			t.Fatalf("SaveSnapshot failed: %v", err)
+		}
+
+		restarted := NewStore(NewJetStreamLog(js, stream))
+		if err := restarted.EnableSnapshots(ctx); err != nil {
+			t.Fatalf("EnableSnapshots failed: %v", err)
+		}
+		if _, err := restarted.ReadSnapshot(ctx, session); err != nil {
+			t.Fatalf("expected snapshot after restart, got %v", err)
+		}
+		state, err := restarted.LoadState(ctx, session)
+		if err != nil {
+			t.Fatalf("LoadState failed: %v", err)
+		}
+		if len(state.Tasks) != 1 {
+			t.Errorf("expected 1 task, got %d", len(state.Tasks))
+		}
+	})
+}
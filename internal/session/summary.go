@@ -0,0 +1,203 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/iteratr/internal/logger"
+	"github.com/mark3labs/iteratr/internal/nats"
+)
+
+// summaryVersion is bumped whenever sessionSummary or its reducer changes in a
+// way that makes stored summaries unsafe to reuse.
+const summaryVersion = 1
+
+// sessionSummary is the small per-session record ListSessions is served from.
+// Like a snapshot it is only a cache: it reflects the session's state events
+// up to AfterSequence and is caught up from the event log when it falls
+// behind. Summaries are kept in the snapshot store under summaryKey.
+type sessionSummary struct {
+	Version       int               `json:"version"`
+	AfterSequence uint64            `json:"after_sequence"`
+	Complete      bool              `json:"complete"`
+	Tasks         map[string]string `json:"tasks"` // Status by task ID
+	CreatedAt     time.Time         `json:"created_at"`
+	LastActivity  time.Time         `json:"last_activity"`
+}
+
+// summaryKey returns the snapshot store key of a session's summary. Session
+// names can't contain dots, so it never collides with a snapshot key.
+func summaryKey(session string) string {
+	return session + ".summary"
+}
+
+// newSessionSummary returns the summary of a session without events.
+func newSessionSummary() *sessionSummary {
+	return &sessionSummary{Version: summaryVersion, Tasks: make(map[string]string)}
+}
+
+// apply folds one state event into the summary, following State.Apply for
+// the fields it keeps. Last activity is the time of the latest task, note or
+// iteration event.
+func (sum *sessionSummary) apply(event Event) {
+	if sum.CreatedAt.IsZero() || event.Timestamp.Before(sum.CreatedAt) {
+		sum.CreatedAt = event.Timestamp
+	}
+	switch event.Type {
+	case nats.EventTypeTask:
+		sum.applyTaskEvent(event)
+	case nats.EventTypeControl:
+		switch event.Action {
+		case "session_complete":
+			sum.Complete = true
+		case "session_restart":
+			sum.Complete = false
+		case "compacted":
+			// The tasks are republished after the marker
+			sum.Complete = false
+			sum.Tasks = make(map[string]string)
+		}
+		return
+	case nats.EventTypeNote, nats.EventTypeIteration:
+	default:
+		return
+	}
+	if event.Timestamp.After(sum.LastActivity) {
+		sum.LastActivity = event.Timestamp
+	}
+}
+
+// applyTaskEvent tracks the status of each task. Corrupt events are skipped,
+// as State.Apply leaves the state untouched for them.
+func (sum *sessionSummary) applyTaskEvent(event Event) {
+	switch event.Action {
+	case "add":
+		var meta TaskAddMeta
+		if decodeMeta(event, &meta) != nil || event.ID == "" {
+			return
+		}
+		if meta.Status == "" {
+			meta.Status = "remaining"
+		}
+		sum.Tasks[event.ID] = meta.Status
+	case "status":
+		var meta TaskStatusMeta
+		if decodeMeta(event, &meta) != nil {
+			return
+		}
+		if _, exists := sum.Tasks[meta.TaskID]; exists {
+			sum.Tasks[meta.TaskID] = meta.Status
+		}
+	case "remove":
+		var meta TaskRemoveMeta
+		if decodeMeta(event, &meta) != nil {
+			return
+		}
+		delete(sum.Tasks, meta.TaskID)
+	}
+}
+
+// info returns the SessionInfo of the summarized session.
+func (sum *sessionSummary) info(name string) SessionInfo {
+	completed := 0
+	for _, status := range sum.Tasks {
+		if status == "completed" {
+			completed++
+		}
+	}
+	return SessionInfo{
+		Name:           name,
+		Complete:       sum.Complete,
+		TasksTotal:     len(sum.Tasks),
+		TasksCompleted: completed,
+		CreatedAt:      sum.CreatedAt,
+		LastActivity:   sum.LastActivity,
+	}
+}
+
+// sessionSummary returns the up-to-date summary of a session: the stored one
+// caught up with the events appended since it was written, or one built from
+// all events if there is none (or it is unusable). A summary that had to be
+// caught up is stored again, so the next call only costs a read and a
+// sequence lookup.
+func (s *Store) sessionSummary(ctx context.Context, session string) (*sessionSummary, error) {
+	sum := s.readSummary(ctx, session)
+	lastSeq, err := s.log.LastSequence(ctx, nats.SubjectForSessionEvents(session))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read last sequence: %w", err)
+	}
+	if sum.AfterSequence > lastSeq {
+		// Written before the session was reset and recreated
+		sum = newSessionSummary()
+	}
+	if sum.AfterSequence == lastSeq {
+		return sum, nil
+	}
+
+	afterSeq, err := s.forEachEvent(ctx, session, sum.AfterSequence, func(seq uint64, event Event) bool {
+		if event.Version == EventVersion {
+			sum.apply(event) // Not upcast means corrupt
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	sum.AfterSequence = afterSeq
+	s.writeSummary(ctx, session, sum)
+	return sum, nil
+}
+
+// readSummary returns the stored summary of a session, or an empty one if
+// there is none, summaries are disabled, or it was written by another version.
+func (s *Store) readSummary(ctx context.Context, session string) *sessionSummary {
+	if s.snapshots == nil {
+		return newSessionSummary()
+	}
+	data, err := s.snapshots.GetSnapshot(ctx, summaryKey(session))
+	if err != nil {
+		if !errors.Is(err, ErrNoSnapshot) {
+			logger.Warn("Ignoring summary for session %s: %v", session, err)
+		}
+		return newSessionSummary()
+	}
+	var sum sessionSummary
+	if err := json.Unmarshal(data, &sum); err != nil || sum.Version != summaryVersion {
+		logger.Debug("Ignoring stale summary for session %s", session)
+		return newSessionSummary()
+	}
+	if sum.Tasks == nil {
+		sum.Tasks = make(map[string]string)
+	}
+	return &sum
+}
+
+// writeSummary stores a session's summary. Failures are only logged: the
+// summary is rebuilt from the events when it is next needed.
+func (s *Store) writeSummary(ctx context.Context, session string, sum *sessionSummary) {
+	if s.snapshots == nil {
+		return
+	}
+	data, err := json.Marshal(sum)
+	if err == nil {
+		err = s.snapshots.PutSnapshot(ctx, summaryKey(session), data)
+	}
+	if err != nil {
+		logger.Warn("Failed to write summary for session %s: %v", session, err)
+	}
+}
+
+// updateSummary brings a session's stored summary up to date after a
+// publish. Concurrent writers may store their summaries out of order; an
+// older one is caught up by the next read.
+func (s *Store) updateSummary(ctx context.Context, session string) {
+	if s.snapshots == nil {
+		return
+	}
+	if _, err := s.sessionSummary(ctx, session); err != nil {
+		logger.Warn("Failed to update summary for session %s: %v", session, err)
+	}
+}
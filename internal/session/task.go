@@ -2,7 +2,7 @@ package session
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -12,10 +12,14 @@ import (
 
 // TaskAddParams represents the parameters for adding a task.
 type TaskAddParams struct {
-	Content   string `json:"content"`
-	Status    string `json:"status,omitempty"`   // Optional: remaining, in_progress, completed, blocked, cancelled
-	Priority  int    `json:"priority,omitempty"` // Optional: 0=critical, 1=high, 2=medium, 3=low, 4=backlog
-	Iteration int    `json:"iteration"`
+	Content    string   `json:"content"`
+	Status     string   `json:"status,omitempty"`     // Optional: remaining, in_progress, completed, blocked, cancelled
+	Priority   int      `json:"priority,omitempty"`   // Optional: 0=critical, 1=high, 2=medium, 3=low, 4=backlog
+	ParentID   string   `json:"parent_id,omitempty"`  // Optional: ID or prefix of an existing parent task
+	Acceptance string   `json:"acceptance,omitempty"` // Optional: acceptance criteria
+	Verify     string   `json:"verify,omitempty"`     // Optional: shell command that must pass to complete the task
+	Labels     []string `json:"labels,omitempty"`     // Optional: task labels
+	Iteration  int      `json:"iteration"`
 }
 
 // TaskStatusParams represents the parameters for updating task status.
@@ -25,6 +29,16 @@ type TaskStatusParams struct {
 	Iteration int    `json:"iteration"`
 }
 
+// TaskListParams represents the parameters for listing tasks.
+type TaskListParams struct {
+	Labels []string `json:"labels,omitempty"` // Optional: only tasks carrying all of these labels
+}
+
+// TaskNextParams represents the parameters for picking the next task.
+type TaskNextParams struct {
+	Labels []string `json:"labels,omitempty"` // Optional: only consider tasks carrying all of these labels
+}
+
 // TaskListResult represents the result of listing tasks.
 type TaskListResult struct {
 	Remaining  []*Task `json:"remaining"`
@@ -37,162 +51,147 @@ type TaskListResult struct {
 // TaskAdd creates a new task in the session.
 // Status defaults to "remaining" if not specified.
 // Returns an error if a task with the same content already exists.
+// The TAS-N ID is allocated atomically: concurrent writers never receive the same ID.
 func (s *Store) TaskAdd(ctx context.Context, session string, params TaskAddParams) (*Task, error) {
-	// Validate required fields
-	if params.Content == "" {
-		return nil, fmt.Errorf("content is required")
-	}
-
-	// Default status to "remaining"
-	status := params.Status
-	if status == "" {
-		status = "remaining"
-	}
-
-	// Validate status
-	if !isValidTaskStatus(status) {
-		return nil, fmt.Errorf("invalid status: %s (must be remaining, in_progress, completed, blocked, or cancelled)", status)
-	}
-
-	// Load current state to get next task counter
-	state, err := s.LoadState(ctx, session)
-	if err != nil {
-		return nil, fmt.Errorf("failed to load state for ID generation: %w", err)
-	}
-
-	// Check for duplicate content
-	if existingID := findTaskByContent(state, params.Content); existingID != "" {
-		return nil, fmt.Errorf("task already exists with ID %s: %q", existingID, params.Content)
-	}
-
-	// Generate sequential ID and timestamp
-	id := fmt.Sprintf("TAS-%d", state.TaskCounter+1)
-	now := time.Now()
-
-	// Create event metadata
-	metaMap := map[string]any{
-		"status":    status,
-		"iteration": params.Iteration,
-	}
-	// Only include priority if explicitly set (non-zero)
-	if params.Priority != 0 {
-		metaMap["priority"] = params.Priority
-	}
-	meta, _ := json.Marshal(metaMap)
-
-	// Create and publish event
-	event := Event{
-		ID:        id,
-		Timestamp: now,
-		Session:   session,
-		Type:      nats.EventTypeTask,
-		Action:    "add",
-		Data:      params.Content,
-		Meta:      meta,
-	}
-
-	_, err = s.PublishEvent(ctx, event)
+	tasks, err := s.taskAdd(ctx, session, []TaskAddParams{params})
 	if err != nil {
 		return nil, err
 	}
-
-	// Build task object to return
-	task := &Task{
-		ID:        id,
-		Content:   params.Content,
-		Status:    status,
-		CreatedAt: now,
-		UpdatedAt: now,
-		Iteration: params.Iteration,
-	}
-
-	return task, nil
+	return tasks[0], nil
 }
 
 // TaskBatchAdd creates multiple tasks in a single operation.
 // Loads state once and generates sequential IDs efficiently.
 // Returns an error if any task content already exists or if duplicates are in the batch.
+// If another writer adds a task mid-batch, the remaining tasks are allocated
+// fresh IDs after the conflicting task, so IDs may not be contiguous.
 func (s *Store) TaskBatchAdd(ctx context.Context, session string, tasks []TaskAddParams) ([]*Task, error) {
 	if len(tasks) == 0 {
 		return nil, fmt.Errorf("at least one task is required")
 	}
+	return s.taskAdd(ctx, session, tasks)
+}
 
-	// Load state once to get the current counter
-	state, err := s.LoadState(ctx, session)
-	if err != nil {
-		return nil, fmt.Errorf("failed to load state for ID generation: %w", err)
-	}
-
-	// Check for duplicates against existing tasks and within the batch
+// taskAdd validates and publishes add events for the given tasks, allocating
+// sequential IDs under optimistic concurrency control.
+func (s *Store) taskAdd(ctx context.Context, session string, tasks []TaskAddParams) ([]*Task, error) {
+	// Validate all tasks before publishing anything (on a copy, since defaults are filled in)
+	tasks = append([]TaskAddParams(nil), tasks...)
+	single := len(tasks) == 1
 	seenInBatch := make(map[string]bool)
-	for _, params := range tasks {
-		normalizedContent := strings.ToLower(strings.TrimSpace(params.Content))
+	for i, params := range tasks {
+		if params.Content == "" {
+			if single {
+				return nil, fmt.Errorf("content is required")
+			}
+			return nil, fmt.Errorf("content is required for all tasks")
+		}
 
-		// Check against existing tasks
-		if existingID := findTaskByContent(state, params.Content); existingID != "" {
-			return nil, fmt.Errorf("task already exists with ID %s: %q", existingID, params.Content)
+		// Default status to "remaining"
+		if params.Status == "" {
+			tasks[i].Status = "remaining"
+		}
+		if !isValidTaskStatus(tasks[i].Status) {
+			return nil, fmt.Errorf("invalid status: %s (must be remaining, in_progress, completed, blocked, or cancelled)", tasks[i].Status)
 		}
 
+		labels, err := normalizeLabels(params.Labels)
+		if err != nil {
+			return nil, err
+		}
+		tasks[i].Labels = labels
+
 		// Check for duplicates within the batch
+		normalizedContent := strings.ToLower(strings.TrimSpace(params.Content))
 		if seenInBatch[normalizedContent] {
 			return nil, fmt.Errorf("duplicate task in batch: %q", params.Content)
 		}
 		seenInBatch[normalizedContent] = true
 	}
 
-	counter := state.TaskCounter
-	now := time.Now()
 	result := make([]*Task, 0, len(tasks))
-
-	for _, params := range tasks {
-		if params.Content == "" {
-			return nil, fmt.Errorf("content is required for all tasks")
-		}
-
-		status := params.Status
-		if status == "" {
-			status = "remaining"
-		}
-		if !isValidTaskStatus(status) {
-			return nil, fmt.Errorf("invalid status: %s (must be remaining, in_progress, completed, blocked, or cancelled)", status)
-		}
-
-		counter++
-		id := fmt.Sprintf("TAS-%d", counter)
-
-		metaMap := map[string]any{
-			"status":    status,
-			"iteration": params.Iteration,
-		}
-		// Only include priority if explicitly set (non-zero)
-		if params.Priority != 0 {
-			metaMap["priority"] = params.Priority
+	pending := tasks
+	err := s.withAllocation(ctx, session, nats.EventTypeTask, func(state *State, expectedSeq uint64) error {
+		// Check for duplicates against existing tasks and resolve parents
+		parentIDs := make([]string, len(pending))
+		for i, params := range pending {
+			if existingID := findTaskByContent(state, params.Content); existingID != "" {
+				return fmt.Errorf("task already exists with ID %s: %q", existingID, params.Content)
+			}
+			if params.ParentID == "" {
+				continue
+			}
+			parentID, err := resolveTaskID(state, params.ParentID)
+			if err != nil {
+				return fmt.Errorf("failed to resolve parent task: %w", err)
+			}
+			if !isOpenStatus(state.Tasks[parentID].Status) {
+				return fmt.Errorf("cannot add subtask to %s task %s", state.Tasks[parentID].Status, parentID)
+			}
+			parentIDs[i] = parentID
 		}
-		meta, _ := json.Marshal(metaMap)
 
-		event := Event{
-			ID:        id,
-			Timestamp: now,
-			Session:   session,
-			Type:      nats.EventTypeTask,
-			Action:    "add",
-			Data:      params.Content,
-			Meta:      meta,
-		}
+		counter := state.TaskCounter
+		now := time.Now()
+		for len(pending) > 0 {
+			params := pending[0]
+			parentID := parentIDs[0]
+			counter++
+			id := fmt.Sprintf("TAS-%d", counter)
+
+			// Priority 0 in params means not set, i.e. medium
+			priority := params.Priority
+			if priority == 0 {
+				priority = 2
+			}
+			meta := encodeMeta(TaskAddMeta{
+				Status:     params.Status,
+				Priority:   priority,
+				ParentID:   parentID,
+				Acceptance: params.Acceptance,
+				Verify:     params.Verify,
+				Labels:     params.Labels,
+				Iteration:  params.Iteration,
+			})
+
+			event := Event{
+				ID:        id,
+				Timestamp: now,
+				Session:   session,
+				Type:      nats.EventTypeTask,
+				Action:    "add",
+				Data:      params.Content,
+				Meta:      meta,
+			}
 
-		_, err := s.PublishEvent(ctx, event)
-		if err != nil {
-			return nil, fmt.Errorf("failed to publish task %q: %w", params.Content, err)
+			seq, err := s.publishIfLast(ctx, event, expectedSeq)
+			if err != nil {
+				if errors.Is(err, ErrSequenceConflict) || single {
+					return err
+				}
+				return fmt.Errorf("failed to publish task %q: %w", params.Content, err)
+			}
+			expectedSeq = seq
+
+			result = append(result, &Task{
+				ID:         id,
+				Content:    params.Content,
+				Status:     params.Status,
+				ParentID:   parentID,
+				Acceptance: params.Acceptance,
+				Verify:     params.Verify,
+				Labels:     params.Labels,
+				CreatedAt:  now,
+				UpdatedAt:  now,
+				Iteration:  params.Iteration,
+			})
+			pending = pending[1:]
+			parentIDs = parentIDs[1:]
 		}
-
-		result = append(result, &Task{
-			ID:        id,
-			Content:   params.Content,
-			Status:    status,
-			CreatedAt: now,
-			UpdatedAt: now,
-			Iteration: params.Iteration,
-		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return result, nil
@@ -214,36 +213,41 @@ func (s *Store) TaskStatus(ctx context.Context, session string, params TaskStatu
 		return fmt.Errorf("invalid status: %s (must be remaining, in_progress, completed, blocked, or cancelled)", params.Status)
 	}
 
-	// Load current state to resolve task ID prefix
-	state, err := s.LoadState(ctx, session)
-	if err != nil {
-		return fmt.Errorf("failed to load state: %w", err)
-	}
+	// Check and publish against the same state, so a subtask added
+	// concurrently can't slip in under a completed parent
+	return s.withAllocation(ctx, session, nats.EventTypeTask, func(state *State, expectedSeq uint64) error {
+		// Resolve task ID (supports prefix matching)
+		taskID, err := resolveTaskID(state, params.ID)
+		if err != nil {
+			return err
+		}
 
-	// Resolve task ID (supports prefix matching)
-	taskID, err := resolveTaskID(state, params.ID)
-	if err != nil {
-		return err
-	}
+		// A parent can't complete while any of its subtasks are still open
+		if params.Status == "completed" {
+			if open := state.OpenChildren(taskID); len(open) > 0 {
+				ids := make([]string, len(open))
+				for i, child := range open {
+					ids[i] = child.ID
+				}
+				return fmt.Errorf("cannot complete %s: subtasks still open: %s", taskID, strings.Join(ids, ", "))
+			}
+		}
 
-	// Create event metadata
-	meta, _ := json.Marshal(map[string]any{
-		"task_id":   taskID,
-		"status":    params.Status,
-		"iteration": params.Iteration,
-	})
+		// Create event metadata
+		meta := encodeMeta(TaskStatusMeta{TaskID: taskID, Status: params.Status, Iteration: params.Iteration})
 
-	// Create and publish event
-	event := Event{
-		Session: session,
-		Type:    nats.EventTypeTask,
-		Action:  "status",
-		Data:    params.Status, // Store new status in data field for convenience
-		Meta:    meta,
-	}
+		// Create and publish event
+		event := Event{
+			Session: session,
+			Type:    nats.EventTypeTask,
+			Action:  "status",
+			Data:    params.Status, // Store new status in data field for convenience
+			Meta:    meta,
+		}
 
-	_, err = s.PublishEvent(ctx, event)
-	return err
+		_, err = s.publishIfLast(ctx, event, expectedSeq)
+		return err
+	})
 }
 
 // TaskPriorityParams represents the parameters for updating task priority.
@@ -280,11 +284,7 @@ func (s *Store) TaskPriority(ctx context.Context, session string, params TaskPri
 	}
 
 	// Create event metadata
-	meta, _ := json.Marshal(map[string]any{
-		"task_id":   taskID,
-		"priority":  params.Priority,
-		"iteration": params.Iteration,
-	})
+	meta := encodeMeta(TaskPriorityMeta{TaskID: taskID, Priority: params.Priority, Iteration: params.Iteration})
 
 	// Create and publish event
 	event := Event{
@@ -317,51 +317,198 @@ func (s *Store) TaskDepends(ctx context.Context, session string, params TaskDepe
 		return fmt.Errorf("depends_on is required")
 	}
 
-	// Load current state to resolve task ID prefixes
-	state, err := s.LoadState(ctx, session)
-	if err != nil {
-		return fmt.Errorf("failed to load state: %w", err)
-	}
+	// Validate against the latest state and publish atomically, so two concurrent
+	// depends calls cannot close a cycle between them
+	return s.withAllocation(ctx, session, nats.EventTypeTask, func(state *State, expectedSeq uint64) error {
+		// Resolve task ID (supports prefix matching)
+		taskID, err := resolveTaskID(state, params.ID)
+		if err != nil {
+			return err
+		}
 
-	// Resolve task ID (supports prefix matching)
-	taskID, err := resolveTaskID(state, params.ID)
-	if err != nil {
+		// Resolve dependency task ID (supports prefix matching)
+		dependsOnID, err := resolveTaskID(state, params.DependsOn)
+		if err != nil {
+			return fmt.Errorf("failed to resolve depends_on task: %w", err)
+		}
+
+		// Validate that task isn't depending on itself
+		if taskID == dependsOnID {
+			return fmt.Errorf("task cannot depend on itself")
+		}
+
+		// Reject dependencies that would close a cycle: if the new dependency
+		// already (transitively) depends on this task, no task in the loop could
+		// ever become ready
+		if path := state.DependencyPath(dependsOnID, taskID); path != nil {
+			return fmt.Errorf("dependency would create a cycle: %s", FormatChain(append([]string{taskID}, path...)))
+		}
+
+		// Create event metadata
+		meta := encodeMeta(TaskDependsMeta{TaskID: taskID, DependsOn: dependsOnID, Iteration: params.Iteration})
+
+		// Create and publish event
+		event := Event{
+			Session: session,
+			Type:    nats.EventTypeTask,
+			Action:  "depends",
+			Data:    dependsOnID, // Store dependency ID in data field for convenience
+			Meta:    meta,
+		}
+
+		_, err = s.publishIfLast(ctx, event, expectedSeq)
 		return err
-	}
+	})
+}
 
-	// Resolve dependency task ID (supports prefix matching)
-	dependsOnID, err := resolveTaskID(state, params.DependsOn)
-	if err != nil {
-		return fmt.Errorf("failed to resolve depends_on task: %w", err)
+// TaskUndepend removes a dependency from an existing task.
+// The ID and DependsOn parameters support prefix matching (minimum 3 characters).
+func (s *Store) TaskUndepend(ctx context.Context, session string, params TaskDependsParams) error {
+	// Validate required fields
+	if params.ID == "" {
+		return fmt.Errorf("task ID is required")
+	}
+	if params.DependsOn == "" {
+		return fmt.Errorf("depends_on is required")
 	}
 
-	// Validate that task isn't depending on itself
-	if taskID == dependsOnID {
-		return fmt.Errorf("task cannot depend on itself")
+	// Check and publish against the same state, so the dependency can't be
+	// dropped or its task removed meanwhile
+	return s.withAllocation(ctx, session, nats.EventTypeTask, func(state *State, expectedSeq uint64) error {
+		// Resolve task ID (supports prefix matching)
+		taskID, err := resolveTaskID(state, params.ID)
+		if err != nil {
+			return err
+		}
+
+		// Resolve dependency task ID (supports prefix matching)
+		dependsOnID, err := resolveTaskID(state, params.DependsOn)
+		if err != nil {
+			return fmt.Errorf("failed to resolve depends_on task: %w", err)
+		}
+
+		// Validate that the dependency exists
+		found := false
+		for _, dep := range state.Tasks[taskID].DependsOn {
+			if dep == dependsOnID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("task %s does not depend on %s", taskID, dependsOnID)
+		}
+
+		// Create event metadata
+		meta := encodeMeta(TaskDependsMeta{TaskID: taskID, DependsOn: dependsOnID, Iteration: params.Iteration})
+
+		// Create and publish event
+		event := Event{
+			Session: session,
+			Type:    nats.EventTypeTask,
+			Action:  "undepend",
+			Data:    dependsOnID, // Store removed dependency ID in data field for convenience
+			Meta:    meta,
+		}
+
+		_, err = s.publishIfLast(ctx, event, expectedSeq)
+		return err
+	})
+}
+
+// TaskEditParams represents the parameters for editing task content.
+type TaskEditParams struct {
+	ID        string `json:"id"`      // Task ID or prefix
+	Content   string `json:"content"` // New task description
+	Iteration int    `json:"iteration"`
+}
+
+// TaskEdit replaces the content of an existing task.
+// The ID parameter supports prefix matching (minimum 3 characters).
+func (s *Store) TaskEdit(ctx context.Context, session string, params TaskEditParams) error {
+	// Validate required fields
+	if params.ID == "" {
+		return fmt.Errorf("task ID is required")
+	}
+	if params.Content == "" {
+		return fmt.Errorf("content is required")
 	}
 
-	// Create event metadata
-	meta, _ := json.Marshal(map[string]any{
-		"task_id":    taskID,
-		"depends_on": dependsOnID,
-		"iteration":  params.Iteration,
+	// Check and publish against the same state, so the edit can't land on a
+	// task removed meanwhile or duplicate one added meanwhile
+	return s.withAllocation(ctx, session, nats.EventTypeTask, func(state *State, expectedSeq uint64) error {
+		// Resolve task ID (supports prefix matching)
+		taskID, err := resolveTaskID(state, params.ID)
+		if err != nil {
+			return err
+		}
+
+		// Reject content that would duplicate another task
+		if existingID := findTaskByContent(state, params.Content); existingID != "" && existingID != taskID {
+			return fmt.Errorf("task already exists with ID %s: %q", existingID, params.Content)
+		}
+
+		// Create event metadata
+		meta := encodeMeta(TaskEditMeta{TaskID: taskID, Content: params.Content, Iteration: params.Iteration})
+
+		// Create and publish event
+		event := Event{
+			Session: session,
+			Type:    nats.EventTypeTask,
+			Action:  "edit",
+			Data:    params.Content,
+			Meta:    meta,
+		}
+
+		_, err = s.publishIfLast(ctx, event, expectedSeq)
+		return err
 	})
+}
 
-	// Create and publish event
-	event := Event{
-		Session: session,
-		Type:    nats.EventTypeTask,
-		Action:  "depends",
-		Data:    dependsOnID, // Store dependency ID in data field for convenience
-		Meta:    meta,
+// TaskRemoveParams represents the parameters for removing a task.
+type TaskRemoveParams struct {
+	ID        string `json:"id"` // Task ID or prefix
+	Iteration int    `json:"iteration"`
+}
+
+// TaskRemove deletes a task from the session. The task's ID is never reused and
+// any dependencies on it are dropped from other tasks.
+// The ID parameter supports prefix matching (minimum 3 characters).
+func (s *Store) TaskRemove(ctx context.Context, session string, params TaskRemoveParams) error {
+	// Validate required fields
+	if params.ID == "" {
+		return fmt.Errorf("task ID is required")
 	}
 
-	_, err = s.PublishEvent(ctx, event)
-	return err
+	// Check and publish against the same state, so a task already removed by
+	// another writer isn't removed twice
+	return s.withAllocation(ctx, session, nats.EventTypeTask, func(state *State, expectedSeq uint64) error {
+		// Resolve task ID (supports prefix matching)
+		taskID, err := resolveTaskID(state, params.ID)
+		if err != nil {
+			return err
+		}
+
+		// Create event metadata
+		meta := encodeMeta(TaskRemoveMeta{TaskID: taskID, Iteration: params.Iteration})
+
+		// Create and publish event
+		event := Event{
+			Session: session,
+			Type:    nats.EventTypeTask,
+			Action:  "remove",
+			Data:    taskID,
+			Meta:    meta,
+		}
+
+		_, err = s.publishIfLast(ctx, event, expectedSeq)
+		return err
+	})
 }
 
 // TaskList returns all tasks grouped by status.
-func (s *Store) TaskList(ctx context.Context, session string) (*TaskListResult, error) {
+// If params.Labels is set, only tasks carrying all of those labels are returned.
+func (s *Store) TaskList(ctx context.Context, session string, params TaskListParams) (*TaskListResult, error) {
 	// Load current state
 	state, err := s.LoadState(ctx, session)
 	if err != nil {
@@ -377,7 +524,15 @@ func (s *Store) TaskList(ctx context.Context, session string) (*TaskListResult,
 		Cancelled:  make([]*Task, 0),
 	}
 
+	labels, err := normalizeLabels(params.Labels)
+	if err != nil {
+		return nil, err
+	}
+
 	for _, task := range state.Tasks {
+		if !task.HasLabels(labels) {
+			continue
+		}
 		switch task.Status {
 		case "remaining":
 			result.Remaining = append(result.Remaining, task)
@@ -418,27 +573,56 @@ func findTaskByContent(state *State, content string) string {
 	return ""
 }
 
-// TaskNext returns the highest priority unblocked task.
-// A task is "ready" if it has status "remaining" and all its dependencies are completed.
+// TaskNext returns the next task to work on among the ready tasks, as chosen by
+// the store's scheduling policy (PriorityFIFO unless set via SetSchedulingPolicy).
+// A task is "ready" if it has status "remaining", no open subtasks, and all its dependencies are completed.
+// If params.Labels is set, only tasks carrying all of those labels are considered.
 // Returns nil if no ready tasks exist.
-func (s *Store) TaskNext(ctx context.Context, session string) (*Task, error) {
+func (s *Store) TaskNext(ctx context.Context, session string, params TaskNextParams) (*Task, error) {
 	// Load current state
 	state, err := s.LoadState(ctx, session)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load state: %w", err)
 	}
 
-	var bestTask *Task
-	for _, task := range state.Tasks {
+	labels, err := normalizeLabels(params.Labels)
+	if err != nil {
+		return nil, err
+	}
+
+	ready := state.ReadyTasks()
+	filtered := ready[:0]
+	for _, task := range ready {
+		if task.HasLabels(labels) {
+			filtered = append(filtered, task)
+		}
+	}
+	if len(filtered) == 0 {
+		return nil, nil
+	}
+
+	return s.schedulingPolicy().Next(state, filtered), nil
+}
+
+// ReadyTasks returns the tasks that can be worked on now, in creation order:
+// status "remaining", no open subtasks, and all dependencies completed.
+func (st *State) ReadyTasks() []*Task {
+	var ready []*Task
+	for _, task := range st.sortedTasks() {
 		// Skip non-remaining tasks
 		if task.Status != "remaining" {
 			continue
 		}
 
+		// Skip parents with open subtasks; work happens on the leaves
+		if !st.IsLeaf(task.ID) {
+			continue
+		}
+
 		// Check if all dependencies are completed
 		allDepsCompleted := true
 		for _, depID := range task.DependsOn {
-			if depTask, exists := state.Tasks[depID]; exists {
+			if depTask, exists := st.Tasks[depID]; exists {
 				if depTask.Status != "completed" {
 					allDepsCompleted = false
 					break
@@ -450,17 +634,17 @@ func (s *Store) TaskNext(ctx context.Context, session string) (*Task, error) {
 			}
 		}
 
-		if !allDepsCompleted {
-			continue
-		}
-
-		// This task is ready - compare priority (lower is higher priority)
-		if bestTask == nil || task.Priority < bestTask.Priority {
-			bestTask = task
+		if allDepsCompleted {
+			ready = append(ready, task)
 		}
 	}
+	return ready
+}
 
-	return bestTask, nil
+// ResolveTaskID resolves a full task ID or unique prefix (minimum 3 characters)
+// to the ID of a task in this state.
+func (st *State) ResolveTaskID(idOrPrefix string) (string, error) {
+	return resolveTaskID(st, idOrPrefix)
 }
 
 // resolveTaskID resolves a task ID or prefix to a full task ID.
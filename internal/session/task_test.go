@@ -2,6 +2,7 @@ package session
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"testing"
 
@@ -33,7 +34,7 @@ func TestTaskOperations(t *testing.T) {
 		t.Fatalf("failed to setup stream: %v", err)
 	}
 
-	store := NewStore(js, stream)
+	store := NewStore(NewJetStreamLog(js, stream))
 	session := "test-session"
 
 	t.Run("TaskAdd creates task with default status", func(t *testing.T) {
@@ -85,6 +86,31 @@ func TestTaskOperations(t *testing.T) {
 		}
 	})
 
+	t.Run("TaskAdd stores acceptance criteria and verify command", func(t *testing.T) {
+		criteriaSession := "test-session-criteria"
+
+		task, err := store.TaskAdd(ctx, criteriaSession, TaskAddParams{
+			Content:    "Add snapshot support",
+			Acceptance: "LoadState replays only events after the snapshot",
+			Verify:     "go test ./internal/session/...",
+		})
+		if err != nil {
+			t.Fatalf("TaskAdd failed: %v", err)
+		}
+
+		state, err := store.LoadState(ctx, criteriaSession)
+		if err != nil {
+			t.Fatalf("LoadState failed: %v", err)
+		}
+		stored := state.Tasks[task.ID]
+		if stored.Acceptance != "LoadState replays only events after the snapshot" {
+			t.Errorf("unexpected acceptance: %q", stored.Acceptance)
+		}
+		if stored.Verify != "go test ./internal/session/..." {
+			t.Errorf("unexpected verify command: %q", stored.Verify)
+		}
+	})
+
 	t.Run("TaskList groups tasks by status", func(t *testing.T) {
 		// Add a few more tasks
 		_, _ = store.TaskAdd(ctx, session, TaskAddParams{
@@ -103,7 +129,7 @@ func TestTaskOperations(t *testing.T) {
 			Iteration: 1,
 		})
 
-		result, err := store.TaskList(ctx, session)
+		result, err := store.TaskList(ctx, session, TaskListParams{})
 		if err != nil {
 			t.Fatalf("TaskList failed: %v", err)
 		}
@@ -369,6 +395,38 @@ func TestTaskOperations(t *testing.T) {
 		}
 	})
 
+	t.Run("TaskDepends prevents dependency cycles", func(t *testing.T) {
+		// Use a dedicated session
+		cycleSession := "test-session-dep-cycle"
+
+		a, _ := store.TaskAdd(ctx, cycleSession, TaskAddParams{Content: "Cycle A"})
+		b, _ := store.TaskAdd(ctx, cycleSession, TaskAddParams{Content: "Cycle B"})
+		c, _ := store.TaskAdd(ctx, cycleSession, TaskAddParams{Content: "Cycle C"})
+
+		// A -> B -> C is fine
+		if err := store.TaskDepends(ctx, cycleSession, TaskDependsParams{ID: a.ID, DependsOn: b.ID}); err != nil {
+			t.Fatalf("TaskDepends failed: %v", err)
+		}
+		if err := store.TaskDepends(ctx, cycleSession, TaskDependsParams{ID: b.ID, DependsOn: c.ID}); err != nil {
+			t.Fatalf("TaskDepends failed: %v", err)
+		}
+
+		// C -> A would close the loop
+		err := store.TaskDepends(ctx, cycleSession, TaskDependsParams{ID: c.ID, DependsOn: a.ID})
+		if err == nil {
+			t.Fatal("expected error for dependency cycle")
+		}
+		want := fmt.Sprintf("%s -> %s -> %s -> %s", c.ID, a.ID, b.ID, c.ID)
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected cycle %q in error, got %v", want, err)
+		}
+
+		state, _ := store.LoadState(ctx, cycleSession)
+		if len(state.Tasks[c.ID].DependsOn) != 0 {
+			t.Errorf("expected no dependency recorded, got %v", state.Tasks[c.ID].DependsOn)
+		}
+	})
+
 	t.Run("TaskDepends prevents duplicate dependencies", func(t *testing.T) {
 		// Use a dedicated session
 		dupDepSession := "test-session-dup-dep"
@@ -469,7 +527,7 @@ func TestTaskOperations(t *testing.T) {
 		})
 
 		// TaskNext should return the critical priority task
-		nextTask, err := store.TaskNext(ctx, nextSession)
+		nextTask, err := store.TaskNext(ctx, nextSession, TaskNextParams{})
 		if err != nil {
 			t.Fatalf("TaskNext failed: %v", err)
 		}
@@ -517,7 +575,7 @@ func TestTaskOperations(t *testing.T) {
 		})
 
 		// TaskNext should return task1 (task2 is blocked by dependency)
-		nextTask, err := store.TaskNext(ctx, blockedSession)
+		nextTask, err := store.TaskNext(ctx, blockedSession, TaskNextParams{})
 		if err != nil {
 			t.Fatalf("TaskNext failed: %v", err)
 		}
@@ -560,7 +618,7 @@ func TestTaskOperations(t *testing.T) {
 		})
 
 		// Now task2 depends on task1 which is completed, so task2 should be ready
-		nextTask, err := store.TaskNext(ctx, noReadySession)
+		nextTask, err := store.TaskNext(ctx, noReadySession, TaskNextParams{})
 		if err != nil {
 			t.Fatalf("TaskNext failed: %v", err)
 		}
@@ -575,7 +633,7 @@ func TestTaskOperations(t *testing.T) {
 			Iteration: 1,
 		})
 
-		nextTask, err = store.TaskNext(ctx, noReadySession)
+		nextTask, err = store.TaskNext(ctx, noReadySession, TaskNextParams{})
 		if err != nil {
 			t.Fatalf("TaskNext failed: %v", err)
 		}
@@ -721,4 +779,140 @@ func TestTaskOperations(t *testing.T) {
 			t.Errorf("expected 3 tasks, got %d", len(tasks))
 		}
 	})
+
+	t.Run("TaskEdit updates task content", func(t *testing.T) {
+		// Use a dedicated session
+		editSession := "test-session-edit"
+
+		task, err := store.TaskAdd(ctx, editSession, TaskAddParams{
+			Content:   "Original content",
+			Iteration: 1,
+		})
+		if err != nil {
+			t.Fatalf("TaskAdd failed: %v", err)
+		}
+
+		err = store.TaskEdit(ctx, editSession, TaskEditParams{
+			ID:        task.ID,
+			Content:   "Edited content",
+			Iteration: 2,
+		})
+		if err != nil {
+			t.Fatalf("TaskEdit failed: %v", err)
+		}
+
+		state, err := store.LoadState(ctx, editSession)
+		if err != nil {
+			t.Fatalf("LoadState failed: %v", err)
+		}
+
+		edited := state.Tasks[task.ID]
+		if edited.Content != "Edited content" {
+			t.Errorf("expected content 'Edited content', got '%s'", edited.Content)
+		}
+		if edited.Iteration != 2 {
+			t.Errorf("expected iteration 2, got %d", edited.Iteration)
+		}
+		if edited.Status != "remaining" {
+			t.Errorf("expected status to be preserved, got '%s'", edited.Status)
+		}
+	})
+
+	t.Run("TaskEdit validates content", func(t *testing.T) {
+		// Use a dedicated session
+		editSession := "test-session-edit-validate"
+
+		task1, _ := store.TaskAdd(ctx, editSession, TaskAddParams{Content: "First task"})
+		task2, _ := store.TaskAdd(ctx, editSession, TaskAddParams{Content: "Second task"})
+
+		// Empty content is rejected
+		err := store.TaskEdit(ctx, editSession, TaskEditParams{ID: task1.ID})
+		if err == nil {
+			t.Error("expected error for empty content")
+		}
+
+		// Content duplicating another task is rejected
+		err = store.TaskEdit(ctx, editSession, TaskEditParams{ID: task2.ID, Content: "first task"})
+		if err == nil || !strings.Contains(err.Error(), "already exists") {
+			t.Errorf("expected 'already exists' error, got: %v", err)
+		}
+
+		// Re-saving a task's own content is allowed
+		err = store.TaskEdit(ctx, editSession, TaskEditParams{ID: task1.ID, Content: "First task"})
+		if err != nil {
+			t.Errorf("expected editing to own content to succeed, got: %v", err)
+		}
+	})
+
+	t.Run("TaskRemove deletes task and dependencies on it", func(t *testing.T) {
+		// Use a dedicated session
+		removeSession := "test-session-remove"
+
+		task1, _ := store.TaskAdd(ctx, removeSession, TaskAddParams{Content: "Removed task"})
+		task2, _ := store.TaskAdd(ctx, removeSession, TaskAddParams{Content: "Dependent task"})
+		if err := store.TaskDepends(ctx, removeSession, TaskDependsParams{ID: task2.ID, DependsOn: task1.ID}); err != nil {
+			t.Fatalf("TaskDepends failed: %v", err)
+		}
+
+		if err := store.TaskRemove(ctx, removeSession, TaskRemoveParams{ID: task1.ID}); err != nil {
+			t.Fatalf("TaskRemove failed: %v", err)
+		}
+
+		state, err := store.LoadState(ctx, removeSession)
+		if err != nil {
+			t.Fatalf("LoadState failed: %v", err)
+		}
+
+		if _, exists := state.Tasks[task1.ID]; exists {
+			t.Error("expected removed task to be gone")
+		}
+		if len(state.Tasks[task2.ID].DependsOn) != 0 {
+			t.Errorf("expected dependency on removed task to be dropped, got %v", state.Tasks[task2.ID].DependsOn)
+		}
+
+		// IDs of removed tasks are not reused
+		task3, err := store.TaskAdd(ctx, removeSession, TaskAddParams{Content: "New task"})
+		if err != nil {
+			t.Fatalf("TaskAdd failed: %v", err)
+		}
+		if task3.ID != "TAS-3" {
+			t.Errorf("expected new task ID TAS-3, got %s", task3.ID)
+		}
+
+		// Removing again fails since the task no longer exists
+		if err := store.TaskRemove(ctx, removeSession, TaskRemoveParams{ID: task1.ID}); err == nil {
+			t.Error("expected error removing non-existent task")
+		}
+	})
+
+	t.Run("TaskUndepend removes dependency", func(t *testing.T) {
+		// Use a dedicated session
+		undependSession := "test-session-undepend"
+
+		task1, _ := store.TaskAdd(ctx, undependSession, TaskAddParams{Content: "Base task"})
+		task2, _ := store.TaskAdd(ctx, undependSession, TaskAddParams{Content: "Other base task"})
+		task3, _ := store.TaskAdd(ctx, undependSession, TaskAddParams{Content: "Dependent task"})
+		_ = store.TaskDepends(ctx, undependSession, TaskDependsParams{ID: task3.ID, DependsOn: task1.ID})
+		_ = store.TaskDepends(ctx, undependSession, TaskDependsParams{ID: task3.ID, DependsOn: task2.ID})
+
+		err := store.TaskUndepend(ctx, undependSession, TaskDependsParams{ID: task3.ID, DependsOn: task1.ID})
+		if err != nil {
+			t.Fatalf("TaskUndepend failed: %v", err)
+		}
+
+		state, err := store.LoadState(ctx, undependSession)
+		if err != nil {
+			t.Fatalf("LoadState failed: %v", err)
+		}
+		deps := state.Tasks[task3.ID].DependsOn
+		if len(deps) != 1 || deps[0] != task2.ID {
+			t.Errorf("expected only dependency %s, got %v", task2.ID, deps)
+		}
+
+		// Removing a dependency that doesn't exist is an error
+		err = store.TaskUndepend(ctx, undependSession, TaskDependsParams{ID: task3.ID, DependsOn: task1.ID})
+		if err == nil {
+			t.Error("expected error for missing dependency")
+		}
+	})
 }
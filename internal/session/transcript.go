@@ -0,0 +1,272 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/iteratr/internal/logger"
+	"github.com/mark3labs/iteratr/internal/nats"
+)
+
+// Transcript entry kinds.
+const (
+	TranscriptKindPrompt   = "prompt"    // Content block sent to the agent (prompt, hook output, user message)
+	TranscriptKindText     = "text"      // Agent response text
+	TranscriptKindThinking = "thinking"  // Agent reasoning
+	TranscriptKindToolCall = "tool_call" // Tool call lifecycle update
+	TranscriptKindFinish   = "finish"    // Agent turn finished
+)
+
+// maxTranscriptEntry bounds the encoded size of one transcript entry so it
+// stays well below the NATS max payload. Larger entries are trimmed.
+const maxTranscriptEntry = 256 * 1024
+
+// maxPendingText is how much streamed text is buffered before it is flushed
+// as an entry even if the agent keeps producing the same kind of output.
+const maxPendingText = 64 * 1024
+
+// TranscriptEntry is one piece of an agent conversation. Transcripts are
+// stored on their own subjects (iteratr.{session}.transcript.{iteration}) so
+// that LoadState never has to read them.
+type TranscriptEntry struct {
+	Timestamp time.Time         `json:"timestamp"`
+	Iteration int               `json:"iteration"`
+	Kind      string            `json:"kind"`
+	Content   string            `json:"content,omitempty"` // prompt, text and thinking entries
+	Tool      *TranscriptTool   `json:"tool,omitempty"`    // tool_call entries
+	Finish    *TranscriptFinish `json:"finish,omitempty"`  // finish entries
+}
+
+// TranscriptTool is the state of a tool call at one lifecycle update.
+// Updates share the same ID; the last one holds the final state.
+type TranscriptTool struct {
+	ID        string          `json:"id"`
+	Title     string          `json:"title"`
+	Status    string          `json:"status"`
+	Kind      string          `json:"kind,omitempty"`
+	Input     map[string]any  `json:"input,omitempty"`
+	Output    string          `json:"output,omitempty"`
+	Diff      *TranscriptDiff `json:"diff,omitempty"`
+	SessionID string          `json:"session_id,omitempty"` // Subagent session
+}
+
+// TranscriptDiff is a file edit made by a tool call.
+type TranscriptDiff struct {
+	File      string `json:"file"`
+	Before    string `json:"before,omitempty"`
+	After     string `json:"after,omitempty"`
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+}
+
+// TranscriptFinish records how an agent turn ended.
+type TranscriptFinish struct {
+	Reason   string        `json:"reason"`
+	Error    string        `json:"error,omitempty"`
+	Model    string        `json:"model,omitempty"`
+	Provider string        `json:"provider,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// AppendTranscript publishes one transcript entry for the entry's iteration.
+// Entries that are too large are trimmed: file contents of diffs are dropped
+// first, then long text is truncated.
+func (s *Store) AppendTranscript(ctx context.Context, session string, entry TranscriptEntry) error {
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+	data, err := encodeTranscriptEntry(entry)
+	if err != nil {
+		return err
+	}
+	if _, err := s.log.Append(ctx, nats.SubjectForTranscript(session, entry.Iteration), data); err != nil {
+		return fmt.Errorf("failed to publish transcript entry: %w", err)
+	}
+	return nil
+}
+
+// Transcript returns the recorded conversation of one iteration in order.
+// Returns an empty slice if nothing was recorded.
+func (s *Store) Transcript(ctx context.Context, session string, iteration int) ([]TranscriptEntry, error) {
+	var entries []TranscriptEntry
+	_, err := s.forEachMsg(ctx, nats.SubjectForTranscript(session, iteration), 0, func(seq uint64, data []byte) bool {
+		var entry TranscriptEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			logger.Warn("Skipping malformed transcript entry (seq=%d): %v", seq, err)
+			return true
+		}
+		entries = append(entries, entry)
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transcript: %w", err)
+	}
+	return entries, nil
+}
+
+// encodeTranscriptEntry marshals an entry, trimming it to maxTranscriptEntry.
+func encodeTranscriptEntry(entry TranscriptEntry) ([]byte, error) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal transcript entry: %w", err)
+	}
+	if len(data) <= maxTranscriptEntry {
+		return data, nil
+	}
+
+	// Drop full file contents; the diff stats are kept
+	if entry.Tool != nil && entry.Tool.Diff != nil {
+		tool := *entry.Tool
+		diff := *tool.Diff
+		diff.Before, diff.After = "", ""
+		tool.Diff = &diff
+		entry.Tool = &tool
+	}
+	const marker = "\n[... truncated]"
+	limit := maxTranscriptEntry / 2
+	if len(entry.Content) > limit {
+		entry.Content = entry.Content[:limit] + marker
+	}
+	if entry.Tool != nil && len(entry.Tool.Output) > limit {
+		tool := *entry.Tool
+		tool.Output = tool.Output[:limit] + marker
+		entry.Tool = &tool
+	}
+
+	data, err = json.Marshal(entry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal transcript entry: %w", err)
+	}
+	if len(data) > maxTranscriptEntry && entry.Tool != nil {
+		// Oversized tool input is the only thing left; keep its shape only
+		tool := *entry.Tool
+		tool.Input = map[string]any{"truncated": true}
+		entry.Tool = &tool
+		data, err = json.Marshal(entry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal transcript entry: %w", err)
+		}
+	}
+	return data, nil
+}
+
+// TranscriptRecorder records an agent conversation as it streams. Consecutive
+// text or thinking chunks are merged into one entry, which is written when the
+// output changes kind, grows large, or Flush is called. Publish errors are
+// logged rather than returned so recording never interrupts an iteration.
+// It is safe for concurrent use.
+type TranscriptRecorder struct {
+	ctx     context.Context
+	store   *Store
+	session string
+
+	mu        sync.Mutex
+	iteration int
+	pending   *TranscriptEntry // Text or thinking being merged
+}
+
+// NewTranscriptRecorder creates a recorder for a session. Call SetIteration
+// before recording.
+func (s *Store) NewTranscriptRecorder(ctx context.Context, session string) *TranscriptRecorder {
+	return &TranscriptRecorder{
+		ctx:     ctx,
+		store:   s,
+		session: session,
+	}
+}
+
+// SetIteration flushes buffered output and attributes subsequent entries to
+// the given iteration.
+func (r *TranscriptRecorder) SetIteration(iteration int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.flushLocked()
+	r.iteration = iteration
+}
+
+// Prompt records the content blocks sent to the agent.
+func (r *TranscriptRecorder) Prompt(texts []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.flushLocked()
+	for _, text := range texts {
+		r.appendLocked(TranscriptEntry{Kind: TranscriptKindPrompt, Content: text})
+	}
+}
+
+// Text records a chunk of agent response text.
+func (r *TranscriptRecorder) Text(content string) {
+	r.stream(TranscriptKindText, content)
+}
+
+// Thinking records a chunk of agent reasoning.
+func (r *TranscriptRecorder) Thinking(content string) {
+	r.stream(TranscriptKindThinking, content)
+}
+
+// ToolCall records a tool call lifecycle update.
+func (r *TranscriptRecorder) ToolCall(tool TranscriptTool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.flushLocked()
+	r.appendLocked(TranscriptEntry{Kind: TranscriptKindToolCall, Tool: &tool})
+}
+
+// Finish records the end of an agent turn and flushes buffered output.
+func (r *TranscriptRecorder) Finish(finish TranscriptFinish) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.flushLocked()
+	r.appendLocked(TranscriptEntry{Kind: TranscriptKindFinish, Finish: &finish})
+}
+
+// Flush writes any buffered text or thinking output.
+func (r *TranscriptRecorder) Flush() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.flushLocked()
+}
+
+// stream merges a text or thinking chunk into the pending entry.
+func (r *TranscriptRecorder) stream(kind, content string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.pending != nil && r.pending.Kind != kind {
+		r.flushLocked()
+	}
+	if r.pending == nil {
+		r.pending = &TranscriptEntry{Timestamp: time.Now(), Iteration: r.iteration, Kind: kind}
+	}
+	r.pending.Content += content
+	if len(r.pending.Content) >= maxPendingText {
+		r.flushLocked()
+	}
+}
+
+// flushLocked writes the pending entry. r.mu must be held.
+func (r *TranscriptRecorder) flushLocked() {
+	if r.pending == nil {
+		return
+	}
+	entry := *r.pending
+	r.pending = nil
+	r.publishLocked(entry)
+}
+
+// appendLocked writes an entry for the current iteration. r.mu must be held.
+func (r *TranscriptRecorder) appendLocked(entry TranscriptEntry) {
+	entry.Timestamp = time.Now()
+	entry.Iteration = r.iteration
+	r.publishLocked(entry)
+}
+
+// publishLocked publishes an entry, logging failures. r.mu must be held so
+// entries are published in the order they were recorded.
+func (r *TranscriptRecorder) publishLocked(entry TranscriptEntry) {
+	if err := r.store.AppendTranscript(r.ctx, r.session, entry); err != nil {
+		logger.Warn("Failed to record transcript entry: %v", err)
+	}
+}
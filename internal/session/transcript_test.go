@@ -0,0 +1,175 @@
+package session
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/iteratr/internal/nats"
+)
+
+func TestTranscriptRecorder(t *testing.T) {
+	// Setup: Create embedded NATS and store
+	ctx := context.Background()
+	ns, _, err := nats.StartEmbeddedNATS(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to start NATS: %v", err)
+	}
+	defer ns.Shutdown()
+
+	nc, err := nats.ConnectInProcess(ns)
+	if err != nil {
+		t.Fatalf("failed to connect to NATS: %v", err)
+	}
+	defer nc.Close()
+
+	js, err := nats.CreateJetStream(nc)
+	if err != nil {
+		t.Fatalf("failed to create JetStream: %v", err)
+	}
+
+	stream, err := nats.SetupStream(ctx, js)
+	if err != nil {
+		t.Fatalf("failed to setup stream: %v", err)
+	}
+
+	store := NewStore(NewJetStreamLog(js, stream))
+	session := "test-transcript"
+
+	// Iteration 1 conversation
+	_ = store.IterationStart(ctx, session, 1)
+	rec := store.NewTranscriptRecorder(ctx, session)
+	rec.SetIteration(1)
+	rec.Prompt([]string{"hook output", "Do the next task"})
+	rec.Thinking("Let me ")
+	rec.Thinking("think.")
+	rec.Text("Hello, ")
+	rec.Text("world.")
+	rec.ToolCall(TranscriptTool{ID: "call-1", Title: "bash", Status: "pending"})
+	rec.ToolCall(TranscriptTool{ID: "call-1", Title: "bash", Status: "completed", Input: map[string]any{"command": "ls"}, Output: "a\nb"})
+	rec.Text("Done.")
+	rec.Finish(TranscriptFinish{Reason: "end_turn", Duration: time.Second})
+	_ = store.IterationComplete(ctx, session, 1, IterationResult{})
+
+	// Iteration 2 has only buffered text until flushed
+	_ = store.IterationStart(ctx, session, 2)
+	rec.SetIteration(2)
+	rec.Text("Second iteration")
+	rec.Flush()
+
+	t.Run("merges streamed chunks", func(t *testing.T) {
+		entries, err := store.Transcript(ctx, session, 1)
+		if err != nil {
+			t.Fatalf("Transcript failed: %v", err)
+		}
+
+		var kinds []string
+		for _, e := range entries {
+			kinds = append(kinds, e.Kind)
+			if e.Iteration != 1 {
+				t.Errorf("expected iteration 1, got %d", e.Iteration)
+			}
+		}
+		want := "prompt,prompt,thinking,text,tool_call,tool_call,text,finish"
+		if got := strings.Join(kinds, ","); got != want {
+			t.Fatalf("expected kinds %s, got %s", want, got)
+		}
+		if entries[2].Content != "Let me think." || entries[3].Content != "Hello, world." {
+			t.Errorf("expected merged chunks, got %q and %q", entries[2].Content, entries[3].Content)
+		}
+		if entries[5].Tool.Output != "a\nb" || entries[5].Tool.Input["command"] != "ls" {
+			t.Errorf("unexpected tool entry: %+v", entries[5].Tool)
+		}
+		if entries[7].Finish.Reason != "end_turn" || entries[7].Finish.Duration != time.Second {
+			t.Errorf("unexpected finish entry: %+v", entries[7].Finish)
+		}
+	})
+
+	t.Run("iterations are separate", func(t *testing.T) {
+		entries, _ := store.Transcript(ctx, session, 2)
+		if len(entries) != 1 || entries[0].Content != "Second iteration" {
+			t.Errorf("expected one entry in iteration 2, got %+v", entries)
+		}
+		if entries, _ := store.Transcript(ctx, session, 3); len(entries) != 0 {
+			t.Errorf("expected no entries for iteration 3, got %d", len(entries))
+		}
+	})
+
+	t.Run("state ignores transcripts", func(t *testing.T) {
+		events, _ := store.History(ctx, session)
+		if len(events) != 3 {
+			t.Errorf("expected 3 state events, got %d", len(events))
+		}
+		state, err := store.LoadState(ctx, session)
+		if err != nil {
+			t.Fatalf("LoadState failed: %v", err)
+		}
+		if len(state.Iterations) != 2 {
+			t.Errorf("expected 2 iterations, got %d", len(state.Iterations))
+		}
+	})
+
+	t.Run("fork and rename carry transcripts", func(t *testing.T) {
+		if _, err := store.ForkSession(ctx, session, "forked", StatePoint{Iteration: 1}); err != nil {
+			t.Fatalf("ForkSession failed: %v", err)
+		}
+		if entries, _ := store.Transcript(ctx, "forked", 1); len(entries) != 8 {
+			t.Errorf("expected 8 entries in forked iteration 1, got %d", len(entries))
+		}
+		if entries, _ := store.Transcript(ctx, "forked", 2); len(entries) != 0 {
+			t.Errorf("expected no forked transcript past iteration 1, got %d", len(entries))
+		}
+
+		if err := store.RenameSession(ctx, session, "renamed"); err != nil {
+			t.Fatalf("RenameSession failed: %v", err)
+		}
+		if entries, _ := store.Transcript(ctx, "renamed", 2); len(entries) != 1 {
+			t.Errorf("expected renamed transcript for iteration 2, got %d entries", len(entries))
+		}
+		if entries, _ := store.Transcript(ctx, session, 1); len(entries) != 0 {
+			t.Errorf("expected old transcript removed, got %d entries", len(entries))
+		}
+	})
+
+	t.Run("rename keeps transcripts of unknown iterations", func(t *testing.T) {
+		// A transcript left without its iteration's start event is moved too
+		_ = store.AppendTranscript(ctx, "renamed", TranscriptEntry{Iteration: 9, Kind: TranscriptKindText, Content: "stray"})
+
+		if err := store.RenameSession(ctx, "renamed", "renamed-again"); err != nil {
+			t.Fatalf("RenameSession failed: %v", err)
+		}
+		if entries, _ := store.Transcript(ctx, "renamed-again", 2); len(entries) != 1 {
+			t.Errorf("expected renamed transcript for iteration 2, got %d entries", len(entries))
+		}
+		if entries, _ := store.Transcript(ctx, "renamed-again", 9); len(entries) != 1 || entries[0].Content != "stray" {
+			t.Errorf("expected stray transcript to be moved, got %+v", entries)
+		}
+	})
+}
+
+func TestEncodeTranscriptEntry_Trims(t *testing.T) {
+	big := strings.Repeat("x", maxTranscriptEntry)
+	entry := TranscriptEntry{
+		Kind: TranscriptKindToolCall,
+		Tool: &TranscriptTool{
+			ID:     "call-1",
+			Output: big,
+			Diff:   &TranscriptDiff{File: "main.go", Before: big, After: big, Additions: 3},
+		},
+	}
+
+	data, err := encodeTranscriptEntry(entry)
+	if err != nil {
+		t.Fatalf("encodeTranscriptEntry failed: %v", err)
+	}
+	if len(data) > maxTranscriptEntry {
+		t.Errorf("expected at most %d bytes, got %d", maxTranscriptEntry, len(data))
+	}
+	if !strings.Contains(string(data), `"additions":3`) {
+		t.Error("expected diff stats to be kept")
+	}
+	if entry.Tool.Diff.Before != big {
+		t.Error("expected original entry to be left unchanged")
+	}
+}
@@ -0,0 +1,118 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/iteratr/internal/nats"
+)
+
+// Usage is the token usage and cost of one or more agent turns.
+type Usage struct {
+	InputTokens      int     `json:"input_tokens"`
+	OutputTokens     int     `json:"output_tokens"`
+	ReasoningTokens  int     `json:"reasoning_tokens,omitempty"`
+	CacheReadTokens  int     `json:"cache_read_tokens,omitempty"`
+	CacheWriteTokens int     `json:"cache_write_tokens,omitempty"`
+	Cost             float64 `json:"cost,omitempty"` // USD, 0 if unknown
+}
+
+// Add returns the sum of two usages.
+func (u Usage) Add(other Usage) Usage {
+	return Usage{
+		InputTokens:      u.InputTokens + other.InputTokens,
+		OutputTokens:     u.OutputTokens + other.OutputTokens,
+		ReasoningTokens:  u.ReasoningTokens + other.ReasoningTokens,
+		CacheReadTokens:  u.CacheReadTokens + other.CacheReadTokens,
+		CacheWriteTokens: u.CacheWriteTokens + other.CacheWriteTokens,
+		Cost:             u.Cost + other.Cost,
+	}
+}
+
+// TotalTokens returns all tokens counted in the usage.
+func (u Usage) TotalTokens() int {
+	return u.InputTokens + u.OutputTokens + u.ReasoningTokens + u.CacheReadTokens + u.CacheWriteTokens
+}
+
+// IsZero reports whether no usage was recorded.
+func (u Usage) IsZero() bool {
+	return u == Usage{}
+}
+
+// String formats the usage compactly, e.g. "12.3k tok $0.42".
+// The cost is omitted when unknown.
+func (u Usage) String() string {
+	s := FormatTokens(u.TotalTokens()) + " tok"
+	if u.Cost > 0 {
+		s += fmt.Sprintf(" $%.2f", u.Cost)
+	}
+	return s
+}
+
+// FormatTokens formats a token count with a k/M suffix (e.g. 950, 12.3k, 1.2M).
+func FormatTokens(n int) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1_000_000)
+	case n >= 1_000:
+		return fmt.Sprintf("%.1fk", float64(n)/1_000)
+	default:
+		return fmt.Sprintf("%d", n)
+	}
+}
+
+// Pricing is the price of a model in USD per million tokens. Reasoning tokens
+// are billed at the output rate.
+type Pricing struct {
+	Input      float64
+	Output     float64
+	CacheRead  float64
+	CacheWrite float64
+}
+
+// Cost returns the price of the given usage.
+func (p Pricing) Cost(u Usage) float64 {
+	return (float64(u.InputTokens)*p.Input +
+		float64(u.OutputTokens+u.ReasoningTokens)*p.Output +
+		float64(u.CacheReadTokens)*p.CacheRead +
+		float64(u.CacheWriteTokens)*p.CacheWrite) / 1_000_000
+}
+
+// IterationUsage records the usage of an agent turn in an iteration.
+// An iteration can have several turns (the iteration prompt, hook output,
+// user messages); their usage adds up on Iteration.Usage.
+// Creates an event of type "iteration" with action "usage".
+func (s *Store) IterationUsage(ctx context.Context, session string, number int, usage Usage) error {
+	// Build metadata
+	meta, err := json.Marshal(IterationUsageMeta{Number: number, Usage: usage})
+	if err != nil {
+		return fmt.Errorf("failed to marshal iteration usage metadata: %w", err)
+	}
+
+	// Create event
+	event := Event{
+		Session: session,
+		Type:    nats.EventTypeIteration,
+		Action:  "usage",
+		Meta:    meta,
+		Data:    fmt.Sprintf("Iteration %d used %s", number, usage),
+	}
+
+	// Publish event
+	_, err = s.PublishEvent(ctx, event)
+	if err != nil {
+		return fmt.Errorf("failed to publish iteration usage event: %w", err)
+	}
+
+	return nil
+}
+
+// TotalUsage returns the usage summed over all iterations of the session.
+func (st *State) TotalUsage() Usage {
+	var total Usage
+	for _, iter := range st.Iterations {
+		total = total.Add(iter.Usage)
+	}
+	return total
+}
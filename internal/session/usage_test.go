@@ -0,0 +1,105 @@
+package session
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/mark3labs/iteratr/internal/nats"
+)
+
+func TestIterationUsage(t *testing.T) {
+	// Setup: Create embedded NATS and store
+	ctx := context.Background()
+	ns, _, err := nats.StartEmbeddedNATS(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to start NATS: %v", err)
+	}
+	defer ns.Shutdown()
+
+	nc, err := nats.ConnectInProcess(ns)
+	if err != nil {
+		t.Fatalf("failed to connect to NATS: %v", err)
+	}
+	defer nc.Close()
+
+	js, err := nats.CreateJetStream(nc)
+	if err != nil {
+		t.Fatalf("failed to create JetStream: %v", err)
+	}
+
+	stream, err := nats.SetupStream(ctx, js)
+	if err != nil {
+		t.Fatalf("failed to setup stream: %v", err)
+	}
+
+	store := NewStore(NewJetStreamLog(js, stream))
+	session := "test-usage"
+
+	_ = store.IterationStart(ctx, session, 1)
+	_ = store.IterationUsage(ctx, session, 1, Usage{InputTokens: 1000, OutputTokens: 200, Cost: 0.01})
+	_ = store.IterationUsage(ctx, session, 1, Usage{InputTokens: 500, CacheReadTokens: 4000, Cost: 0.02})
+	_ = store.IterationComplete(ctx, session, 1, IterationResult{})
+	_ = store.IterationStart(ctx, session, 2)
+	_ = store.IterationUsage(ctx, session, 2, Usage{InputTokens: 100, OutputTokens: 50, ReasoningTokens: 25})
+
+	state, err := store.LoadState(ctx, session)
+	if err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+
+	t.Run("turns add up per iteration", func(t *testing.T) {
+		want := Usage{InputTokens: 1500, OutputTokens: 200, CacheReadTokens: 4000, Cost: 0.03}
+		got := state.Iterations[0].Usage
+		if math.Abs(got.Cost-want.Cost) > 1e-9 {
+			t.Errorf("expected cost %v, got %v", want.Cost, got.Cost)
+		}
+		got.Cost = want.Cost
+		if got != want {
+			t.Errorf("expected %+v, got %+v", want, got)
+		}
+		if !state.Iterations[0].Complete {
+			t.Error("expected usage to leave iteration complete")
+		}
+	})
+
+	t.Run("session total", func(t *testing.T) {
+		total := state.TotalUsage()
+		if total.TotalTokens() != 5875 {
+			t.Errorf("expected 5875 tokens, got %d", total.TotalTokens())
+		}
+		if total.String() != "5.9k tok $0.03" {
+			t.Errorf("unexpected usage string %q", total.String())
+		}
+	})
+}
+
+func TestPricingCost(t *testing.T) {
+	p := Pricing{Input: 3, Output: 15, CacheRead: 0.3, CacheWrite: 3.75}
+	u := Usage{
+		InputTokens:      1_000_000,
+		OutputTokens:     100_000,
+		ReasoningTokens:  100_000,
+		CacheReadTokens:  2_000_000,
+		CacheWriteTokens: 400_000,
+	}
+
+	// 3 + 0.2*15 + 2*0.3 + 0.4*3.75
+	if got := p.Cost(u); math.Abs(got-8.1) > 1e-9 {
+		t.Errorf("expected cost 8.1, got %v", got)
+	}
+}
+
+func TestFormatTokens(t *testing.T) {
+	tests := map[int]string{
+		0:         "0",
+		950:       "950",
+		12_345:    "12.3k",
+		1_200_000: "1.2M",
+	}
+	for n, want := range tests {
+		if got := FormatTokens(n); got != want {
+			t.Errorf("FormatTokens(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
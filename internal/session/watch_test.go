@@ -0,0 +1,97 @@
+package session
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/iteratr/internal/nats"
+)
+
+func TestStore_WatchState(t *testing.T) {
+	// Setup: Create embedded NATS and store
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ns, _, err := nats.StartEmbeddedNATS(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to start NATS: %v", err)
+	}
+	defer ns.Shutdown()
+
+	nc, err := nats.ConnectInProcess(ns)
+	if err != nil {
+		t.Fatalf("failed to connect to NATS: %v", err)
+	}
+	defer nc.Close()
+
+	js, err := nats.CreateJetStream(nc)
+	if err != nil {
+		t.Fatalf("failed to create JetStream: %v", err)
+	}
+
+	stream, err := nats.SetupStream(ctx, js)
+	if err != nil {
+		t.Fatalf("failed to setup stream: %v", err)
+	}
+
+	store := NewStore(NewJetStreamLog(js, stream))
+	session := "test-watch"
+
+	task, _ := store.TaskAdd(ctx, session, TaskAddParams{Content: "First"})
+	_, _ = store.TaskAdd(ctx, session, TaskAddParams{Content: "Second"})
+
+	state, events, err := store.WatchState(ctx, session)
+	if err != nil {
+		t.Fatalf("WatchState failed: %v", err)
+	}
+	if len(state.Tasks) != 2 {
+		t.Fatalf("expected 2 tasks in initial state, got %d", len(state.Tasks))
+	}
+
+	plain, err := store.Watch(ctx, session)
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+
+	// Another writer, like the tool CLI in a separate process
+	other := NewStore(NewJetStreamLog(js, stream))
+	_ = other.TaskStatus(ctx, session, TaskStatusParams{ID: task.ID, Status: "completed"})
+	_, _ = other.TaskAdd(ctx, session, TaskAddParams{Content: "Third"})
+	_, _ = other.NoteAdd(ctx, session, NoteAddParams{Content: "Learned something", Type: "learning"})
+
+	receive := func(ch <-chan Event) Event {
+		t.Helper()
+		select {
+		case event := <-ch:
+			return event
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for event")
+			return Event{}
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		state.Apply(receive(events))
+	}
+
+	want, err := store.LoadState(ctx, session)
+	if err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+	if len(state.Tasks) != len(want.Tasks) || len(state.Notes) != len(want.Notes) {
+		t.Fatalf("expected %d tasks and %d notes, got %d and %d", len(want.Tasks), len(want.Notes), len(state.Tasks), len(state.Notes))
+	}
+	if state.Tasks[task.ID].Status != "completed" {
+		t.Errorf("expected %s completed, got %s", task.ID, state.Tasks[task.ID].Status)
+	}
+	if state.Tasks["TAS-3"] == nil || state.Tasks["TAS-3"].Content != "Third" {
+		t.Errorf("expected TAS-3 from the other writer, got %+v", state.Tasks["TAS-3"])
+	}
+
+	t.Run("watch delivers only new events", func(t *testing.T) {
+		first := receive(plain)
+		if first.Type != "task" || first.Action != "status" {
+			t.Errorf("expected the status change first, got %s %s", first.Type, first.Action)
+		}
+	})
+}
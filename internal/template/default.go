@@ -14,6 +14,8 @@ Session: {{session}} | Iteration: #{{iteration}}
 
 {{notes}}
 
+{{knowledge}}
+
 ## Rules
 - ONE task per iteration - complete fully, then STOP
 - Test changes before marking complete
@@ -22,7 +24,7 @@ Session: {{session}} | Iteration: #{{iteration}}
 - Respect user-added tasks even if not in spec
 
 ## Workflow
-1. If no tasks: sync from spec using task-add tool
+1. If no tasks: sync from spec using task-add tool (split large items into subtasks via parent)
 2. Pick ONE ready task (highest priority, no blockers) using task-next tool
 3. Mark task as in_progress using task-update tool
 4. Implement + test
@@ -34,6 +36,11 @@ Session: {{session}} | Iteration: #{{iteration}}
 - Add a note using note-add tool with type "stuck" describing the issue
 - Mark task blocked or fix before completing
 - If blocked by another task: use task-update tool to set depends_on
+- To see ordering, the critical path, or what blocks a task: use task-graph tool
+- To check whether an earlier session already solved a problem: use search tool
+
+## Knowledge
+- When a learning, tip or decision will matter beyond this session (project quirks, conventions, pitfalls), promote its note using knowledge-promote tool
 
 ## Subagents
 Spin up subagents (via Task tool) to parallelize work. Each subagent has fresh context, so "one task per agent" is preserved.
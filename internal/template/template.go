@@ -18,6 +18,7 @@ type Variables struct {
 	Iteration string // Current iteration number
 	Spec      string // Spec file content
 	Notes     string // Formatted notes from previous iterations
+	Knowledge string // Formatted project knowledge base entries
 	Tasks     string // Formatted task list
 	History   string // Formatted iteration history
 	Extra     string // Extra instructions
@@ -31,6 +32,7 @@ type Variables struct {
 // - {{iteration}} - Current iteration number
 // - {{spec}} - Spec file content
 // - {{notes}} - Formatted notes (empty if none)
+// - {{knowledge}} - Formatted project knowledge (empty if none)
 // - {{tasks}} - Formatted task list
 // - {{history}} - Formatted iteration history
 // - {{extra}} - Extra instructions (empty if none)
@@ -44,6 +46,7 @@ func Render(template string, vars Variables) string {
 		"{{iteration}}": vars.Iteration,
 		"{{spec}}":      vars.Spec,
 		"{{notes}}":     vars.Notes,
+		"{{knowledge}}": vars.Knowledge,
 		"{{tasks}}":     vars.Tasks,
 		"{{history}}":   vars.History,
 		"{{extra}}":     vars.Extra,
@@ -101,6 +104,13 @@ func BuildPrompt(ctx context.Context, cfg BuildConfig) (string, error) {
 		return "", fmt.Errorf("failed to load session state: %w", err)
 	}
 
+	// Load the project knowledge base
+	knowledge, err := cfg.Store.LoadKnowledge(ctx)
+	if err != nil {
+		logger.Error("Failed to load knowledge base: %v", err)
+		return "", err
+	}
+
 	// Load spec file content
 	specContent := ""
 	if cfg.SpecPath != "" {
@@ -140,6 +150,7 @@ func BuildPrompt(ctx context.Context, cfg BuildConfig) (string, error) {
 		Iteration: strconv.Itoa(cfg.IterationNumber),
 		Spec:      specContent,
 		Notes:     formatNotes(state),
+		Knowledge: formatKnowledge(knowledge, specContent),
 		Tasks:     formatTasks(state),
 		History:   formatIterationHistory(state),
 		Extra:     cfg.ExtraInstructions,
@@ -188,6 +199,26 @@ func formatNotes(state *session.State) string {
 	return sb.String()
 }
 
+// maxPromptKnowledge caps the knowledge entries injected into a prompt.
+const maxPromptKnowledge = 30
+
+// formatKnowledge formats the knowledge entries most relevant to the spec for
+// template injection. Returns empty string if there are none.
+func formatKnowledge(kb *session.Knowledge, spec string) string {
+	entries := kb.Relevant(spec, maxPromptKnowledge)
+	if len(entries) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("## Project Knowledge\n")
+	sb.WriteString("Learnings, tips and decisions from earlier sessions of this project:\n")
+	for _, entry := range entries {
+		sb.WriteString(fmt.Sprintf("  - [%s] %s\n", entry.Type, entry.Content))
+	}
+	return sb.String()
+}
+
 // formatTasks formats tasks grouped by status for template injection.
 // Always includes section header since workflow requires checking tasks.
 func formatTasks(state *session.State) string {
@@ -213,7 +244,11 @@ func formatTasks(state *session.State) string {
 		// Uppercase first letter for display
 		displayStatus := strings.ToUpper(status[:1]) + strings.ReplaceAll(status[1:], "_", " ")
 		sb.WriteString(fmt.Sprintf("%s:\n", displayStatus))
-		for _, task := range tasks {
+		// Subtasks are nested under their parent when both share a status
+		for _, node := range session.TaskTree(tasks) {
+			task := node.Task
+			indent := strings.Repeat("  ", node.Depth)
+
 			// Format priority prefix [P0]-[P4]
 			priorityPrefix := fmt.Sprintf("[P%d] ", task.Priority)
 
@@ -231,7 +266,38 @@ func formatTasks(state *session.State) string {
 				depInfo = fmt.Sprintf(" (depends on: %s)", strings.Join(depIDs, ", "))
 			}
 
-			sb.WriteString(fmt.Sprintf("  - %s[%s] %s%s%s\n", priorityPrefix, task.ID, task.Content, iterInfo, depInfo))
+			// Format parent info for subtasks not nested under their parent
+			parentInfo := ""
+			if task.ParentID != "" && node.Depth == 0 {
+				parentInfo = fmt.Sprintf(" (subtask of: %s)", task.ParentID)
+			}
+
+			// Format label info
+			labelInfo := ""
+			if len(task.Labels) > 0 {
+				labelInfo = fmt.Sprintf(" [labels: %s]", strings.Join(task.Labels, ", "))
+			}
+
+			sb.WriteString(fmt.Sprintf("  %s- %s[%s] %s%s%s%s%s\n", indent, priorityPrefix, task.ID, task.Content, iterInfo, depInfo, parentInfo, labelInfo))
+
+			// Acceptance criteria and verify command only matter for open tasks
+			if status != "completed" && status != "cancelled" {
+				if task.Acceptance != "" {
+					sb.WriteString(fmt.Sprintf("  %s    Acceptance: %s\n", indent, task.Acceptance))
+				}
+				if task.Verify != "" {
+					sb.WriteString(fmt.Sprintf("  %s    Verify: %s\n", indent, task.Verify))
+				}
+			}
+		}
+	}
+
+	// Tasks in a dependency cycle can never become ready; call them out so the
+	// agent breaks the cycle instead of spinning on "no ready tasks"
+	if cycles := state.DependencyCycles(); len(cycles) > 0 {
+		sb.WriteString("Dependency cycles (remove one dependency to break each):\n")
+		for _, cycle := range cycles {
+			sb.WriteString(fmt.Sprintf("  - %s\n", session.FormatChain(cycle)))
 		}
 	}
 
@@ -239,17 +305,19 @@ func formatTasks(state *session.State) string {
 }
 
 // formatIterationHistory formats recent iteration summaries for template injection.
-// Shows the last 5 completed iterations with their summaries and tasks worked.
+// Shows the last 5 iterations that have a summary or did not succeed, with how
+// they ended, the tasks an interrupted iteration left behind and the files
+// they modified.
 // Returns empty string if no history (section header will be omitted).
 func formatIterationHistory(state *session.State) string {
 	if len(state.Iterations) == 0 {
 		return ""
 	}
 
-	// Filter to iterations with summaries
+	// Filter to iterations with summaries or failures worth knowing about
 	withSummaries := []*session.Iteration{}
 	for _, iter := range state.Iterations {
-		if iter.Summary != "" {
+		if iter.Summary != "" || (iter.Outcome != "" && iter.Outcome != session.IterationSucceeded) {
 			withSummaries = append(withSummaries, iter)
 		}
 	}
@@ -272,13 +340,51 @@ func formatIterationHistory(state *session.State) string {
 		elapsed := time.Since(iter.EndedAt)
 		timeAgo := formatTimeAgo(elapsed)
 
-		// Format: "- #N (time ago): Summary"
-		sb.WriteString(fmt.Sprintf("- #%d (%s): %s\n", iter.Number, timeAgo, iter.Summary))
+		// Format: "- #N (time ago[, outcome: error]): Summary [files: ...]"
+		line := fmt.Sprintf("- #%d (%s", iter.Number, timeAgo)
+		if iter.Outcome != "" && iter.Outcome != session.IterationSucceeded {
+			line += ", " + iter.Outcome
+			if iter.Error != "" {
+				line += ": " + firstLine(iter.Error)
+			}
+			if len(iter.InterruptedTasks) > 0 {
+				line += "; interrupted: " + strings.Join(iter.InterruptedTasks, ", ")
+			}
+		}
+		line += ")"
+		if iter.Summary != "" {
+			line += ": " + iter.Summary
+		}
+		if len(iter.Files) > 0 {
+			line += " [files: " + formatFileChanges(iter.Files) + "]"
+		}
+		sb.WriteString(line + "\n")
 	}
 
 	return sb.String()
 }
 
+// formatFileChanges formats modified files compactly, e.g. "main.go +3 -1, new.go (new) +20 -0".
+func formatFileChanges(files []session.FileChange) string {
+	parts := make([]string, 0, len(files))
+	for _, f := range files {
+		part := f.Path
+		if f.IsNew {
+			part += " (new)"
+		}
+		parts = append(parts, fmt.Sprintf("%s +%d -%d", part, f.Additions, f.Deletions))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// firstLine returns the first line of s.
+func firstLine(s string) string {
+	if idx := strings.IndexByte(s, '\n'); idx >= 0 {
+		return s[:idx]
+	}
+	return s
+}
+
 // formatTimeAgo formats a duration into a human-readable "time ago" string.
 func formatTimeAgo(d time.Duration) string {
 	if d < time.Minute {
@@ -286,6 +286,27 @@ func TestFormatNotes(t *testing.T) {
 	}
 }
 
+func TestFormatKnowledge(t *testing.T) {
+	if got := formatKnowledge(&session.Knowledge{}, "spec"); got != "" {
+		t.Errorf("formatKnowledge() with no entries = %q, want empty", got)
+	}
+
+	kb := &session.Knowledge{Entries: []*session.KnowledgeEntry{
+		{ID: "KNO-1", Content: "Run go generate after editing schemas", Type: "tip"},
+		{ID: "KNO-2", Content: "Old migration tool is gone", Type: "learning", Retired: true},
+		{ID: "KNO-3", Content: "Errors are wrapped with %w", Type: "decision"},
+	}}
+	got := formatKnowledge(kb, "spec")
+	for _, expected := range []string{"## Project Knowledge", "[tip] Run go generate after editing schemas", "[decision] Errors are wrapped with %w"} {
+		if !strings.Contains(got, expected) {
+			t.Errorf("formatKnowledge() = %q, want to contain %q", got, expected)
+		}
+	}
+	if strings.Contains(got, "Old migration tool") {
+		t.Errorf("formatKnowledge() = %q, should not contain retired entries", got)
+	}
+}
+
 func TestFormatTasks(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -356,6 +377,44 @@ func TestFormatTasks(t *testing.T) {
 				"[P2] [task003def] Multi-dependent (depends on: task001abc, task002xyz)",
 			},
 		},
+		{
+			name: "subtasks nested under parent",
+			state: &session.State{
+				Tasks: map[string]*session.Task{
+					"TAS-1": {ID: "TAS-1", Content: "Epic", Status: "remaining", Priority: 2},
+					"TAS-2": {ID: "TAS-2", Content: "Step one", Status: "completed", Priority: 2, ParentID: "TAS-1"},
+					"TAS-3": {ID: "TAS-3", Content: "Step two", Status: "remaining", Priority: 2, ParentID: "TAS-1"},
+					"TAS-4": {ID: "TAS-4", Content: "Step two detail", Status: "remaining", Priority: 2, ParentID: "TAS-3"},
+				},
+			},
+			want: []string{
+				"  - [P2] [TAS-1] Epic\n    - [P2] [TAS-3] Step two\n      - [P2] [TAS-4] Step two detail\n",
+				"  - [P2] [TAS-2] Step one (subtask of: TAS-1)",
+			},
+		},
+		{
+			name: "dependency cycle warning",
+			state: &session.State{
+				Tasks: map[string]*session.Task{
+					"TAS-1": {ID: "TAS-1", Content: "A", Status: "remaining", Priority: 2, DependsOn: []string{"TAS-2"}},
+					"TAS-2": {ID: "TAS-2", Content: "B", Status: "remaining", Priority: 2, DependsOn: []string{"TAS-1"}},
+				},
+			},
+			want: []string{
+				"Dependency cycles (remove one dependency to break each):\n  - TAS-1 -> TAS-2 -> TAS-1",
+			},
+		},
+		{
+			name: "tasks with labels",
+			state: &session.State{
+				Tasks: map[string]*session.Task{
+					"TAS-1": {ID: "TAS-1", Content: "Fix login", Status: "remaining", Priority: 1, Labels: []string{"backend", "bug"}},
+				},
+			},
+			want: []string{
+				"[P1] [TAS-1] Fix login [labels: backend, bug]",
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -436,6 +495,34 @@ func TestFormatIterationHistory(t *testing.T) {
 				"- #3 (10min ago): Fixed bug Y",
 			},
 		},
+		{
+			name: "failed iteration and files touched",
+			state: &session.State{
+				Iterations: []*session.Iteration{
+					{Number: 1, StartedAt: now.Add(-1 * time.Hour), EndedAt: now.Add(-30 * time.Minute), Complete: true, Summary: "Added auth",
+						IterationResult: session.IterationResult{Outcome: session.IterationSucceeded, Files: []session.FileChange{
+							{Path: "auth.go", IsNew: true, Additions: 40},
+							{Path: "main.go", Additions: 2, Deletions: 1},
+						}}},
+					{Number: 2, StartedAt: now.Add(-20 * time.Minute), EndedAt: now.Add(-10 * time.Minute),
+						IterationResult: session.IterationResult{Outcome: session.IterationFailed, Error: "agent exited\nstack..."}},
+				},
+			},
+			want: []string{
+				"- #1 (30min ago): Added auth [files: auth.go (new) +40 -0, main.go +2 -1]",
+				"- #2 (10min ago, failed: agent exited)\n",
+			},
+		},
+		{
+			name: "abandoned iteration lists interrupted tasks",
+			state: &session.State{
+				Iterations: []*session.Iteration{
+					{Number: 3, StartedAt: now.Add(-2 * time.Hour), EndedAt: now.Add(-1 * time.Hour),
+						IterationResult: session.IterationResult{Outcome: session.IterationAbandoned, InterruptedTasks: []string{"TAS-1", "TAS-4"}}},
+				},
+			},
+			want: []string{"- #3 (1hr ago, abandoned; interrupted: TAS-1, TAS-4)\n"},
+		},
 	}
 
 	for _, tt := range tests {
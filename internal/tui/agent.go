@@ -9,6 +9,7 @@ import (
 	tea "charm.land/bubbletea/v2"
 	uv "github.com/charmbracelet/ultraviolet"
 
+	"github.com/mark3labs/iteratr/internal/session"
 	"github.com/mark3labs/iteratr/internal/tui/theme"
 )
 
@@ -510,6 +511,18 @@ func (a *AgentOutput) AddIterationDivider(iteration int) tea.Cmd {
 	return nil
 }
 
+// AppendIterationResult appends a line showing how an iteration ended.
+func (a *AgentOutput) AppendIterationResult(iteration int, result session.IterationResult) tea.Cmd {
+	newMsg := &IterationResultMessageItem{
+		id:        fmt.Sprintf("result-%d", iteration),
+		iteration: iteration,
+		result:    result,
+	}
+	a.messages = append(a.messages, newMsg)
+	a.refreshContent()
+	return nil
+}
+
 // HandleClick processes a mouse click at screen coordinates (x, y).
 // Returns a command if the click should open a modal (e.g., subagent viewer).
 // Returns nil if the click toggled an expandable message or had no effect.
@@ -2,7 +2,6 @@ package tui
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"time"
 
@@ -62,6 +61,7 @@ type App struct {
 	awaitingPrefixKey bool      // True when waiting for second key after ctrl+x
 	lastGitCheck      time.Time // Last time git info was fetched (for throttling)
 	store             *session.Store
+	state             *session.State // Session state, kept current by applying watched events
 	sessionName       string
 	workDir           string // Working directory for agent (needed for subagent modal)
 	dataDir           string // Data directory for persistent storage
@@ -73,6 +73,7 @@ type App struct {
 	eventChan         chan session.Event // Channel for receiving NATS events
 	sendChan          chan string        // Channel for sending user messages to orchestrator
 	orchestrator      Orchestrator       // Interface to orchestrator for pause/resume control
+	observer          bool               // Read-only: the session is run by another process
 }
 
 // NewApp creates a new TUI application with the given session store and NATS connection.
@@ -118,8 +119,6 @@ func NewApp(ctx context.Context, store *session.Store, sessionName, workDir, dat
 func (a *App) Init() tea.Cmd {
 	return tea.Batch(
 		a.subscribeToEvents(),
-		a.waitForEvents(),
-		a.loadInitialState(),
 		a.agent.Init(),
 		a.checkConnectionHealth(), // Start periodic connection health checks
 		a.status.StartDurationTick(),
@@ -127,8 +126,36 @@ func (a *App) Init() tea.Cmd {
 	)
 }
 
+// SetObserver puts the app in observer mode before it starts: the session is
+// shown as owner, another process, runs it, and changes made in the TUI are
+// dropped.
+func (a *App) SetObserver(owner string) {
+	a.observer = true
+	a.status.SetObserver(owner)
+}
+
+// changesSession reports whether msg would write to the session.
+func changesSession(msg tea.Msg) bool {
+	switch msg.(type) {
+	case UserInputMsg, CreateNoteMsg, CreateTaskMsg, OpenTaskEditMsg, UpdateTaskMsg,
+		PromoteNoteMsg, RemoveTaskMsg, UndependTaskMsg:
+		return true
+	}
+	return false
+}
+
 // Update handles incoming messages and updates the model state.
 func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if a.observer && changesSession(msg) {
+		// Read-only: close whatever modal the change came from
+		logger.Debug("Observer mode, dropping %T", msg)
+		a.taskModal.Close()
+		a.noteModal.Close()
+		a.noteInputModal.Close()
+		a.taskInputModal.Close()
+		return a, nil
+	}
+
 	switch msg := msg.(type) {
 	case tea.KeyPressMsg:
 		return a.handleKeyPress(msg)
@@ -204,19 +231,35 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		)
 
 	case StateUpdateMsg:
-		// Propagate state updates to all components
-		a.status.SetState(msg.State)
-		a.sidebar.SetState(msg.State)
-		a.dashboard.SetState(msg.State)
-		a.logs.SetState(msg.State)
-		return a, a.status.Tick()
+		return a, a.setState(msg.State)
+
+	case stateWatchMsg:
+		// Initial state loaded; start applying the events that follow it
+		return a, tea.Batch(a.setState(msg.State), a.waitForEvents())
 
 	case EventMsg:
-		// Forward event to log viewer, reload state, and wait for next event
+		// Show how an iteration ended in the agent output
+		var resultCmd tea.Cmd
+		if msg.Event.Type == "iteration" && msg.Event.Action == "complete" {
+			var meta session.IterationCompleteMeta
+			if err := session.DecodeMeta(msg.Event, &meta); err == nil {
+				resultCmd = a.agent.AppendIterationResult(meta.Number, meta.IterationResult)
+			}
+		}
+		// Apply the event to the session state instead of reloading it
+		var stateCmd tea.Cmd
+		if a.state != nil {
+			if err := a.state.Apply(msg.Event); err != nil {
+				logger.Warn("Failed to apply event: %v", err)
+			}
+			stateCmd = a.setState(a.state)
+		}
+		// Forward event to log viewer and wait for next event
 		return a, tea.Batch(
 			a.logs.AddEvent(msg.Event),
-			a.loadInitialState(), // Reload state to reflect changes
-			a.waitForEvents(),    // Recursively wait for next event
+			resultCmd,
+			stateCmd,
+			a.waitForEvents(), // Recursively wait for next event
 		)
 
 	case ConnectionStatusMsg:
@@ -234,9 +277,8 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			"All tasks have been completed successfully!",
 			nil, // Just close the modal, don't quit
 		)
-		// Reload state to ensure UI shows latest task counts
-		// (SessionCompleteMsg is sent directly by orchestrator, bypassing NATS event flow)
-		return a, a.loadInitialState()
+		// The session_complete event itself arrives through the event watch
+		return a, nil
 
 	case UserInputMsg:
 		// Handle user input from the text field - send to orchestrator queue
@@ -321,6 +363,7 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			_, err := a.store.TaskAdd(a.ctx, a.sessionName, session.TaskAddParams{
 				Content:   msg.Content,
 				Priority:  msg.Priority,
+				Labels:    []string{session.LabelUserAdded},
 				Iteration: iteration,
 			})
 			if err != nil {
@@ -332,6 +375,98 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		a.taskInputModal.Close()
 		return a, nil
 
+	case OpenTaskEditMsg:
+		// Swap the read-only task modal for the input modal in edit mode
+		a.taskModal.Close()
+		if a.sidebar != nil {
+			a.sidebar.ClearActiveTask()
+		}
+		return a, a.taskInputModal.ShowEdit(msg.Task)
+
+	case UpdateTaskMsg:
+		// Publish only the fields that changed relative to the task being edited
+		iteration := a.iteration
+		if msg.Iteration != 0 {
+			iteration = msg.Iteration // Allow override if explicitly set
+		}
+		var original session.Task
+		if a.taskInputModal.editTask != nil {
+			original = *a.taskInputModal.editTask
+		}
+		go func() {
+			if msg.Content != original.Content {
+				err := a.store.TaskEdit(a.ctx, a.sessionName, session.TaskEditParams{
+					ID:        msg.ID,
+					Content:   msg.Content,
+					Iteration: iteration,
+				})
+				if err != nil {
+					// TODO: Add visual feedback for user
+					logger.Warn("failed to edit task: %v", err)
+				}
+			}
+			if msg.Priority != original.Priority {
+				err := a.store.TaskPriority(a.ctx, a.sessionName, session.TaskPriorityParams{
+					ID:        msg.ID,
+					Priority:  msg.Priority,
+					Iteration: iteration,
+				})
+				if err != nil {
+					logger.Warn("failed to update task priority: %v", err)
+				}
+			}
+		}()
+		// Close the modal after submitting
+		a.taskInputModal.Close()
+		return a, nil
+
+	case PromoteNoteMsg:
+		go func() {
+			if _, err := a.store.KnowledgePromote(a.ctx, a.sessionName, msg.ID); err != nil {
+				// TODO: Add visual feedback for user
+				logger.Warn("failed to promote note: %v", err)
+			}
+		}()
+		a.noteModal.Close()
+		if a.sidebar != nil {
+			a.sidebar.ClearActiveNote()
+		}
+		return a, nil
+
+	case RemoveTaskMsg:
+		iteration := a.iteration
+		go func() {
+			err := a.store.TaskRemove(a.ctx, a.sessionName, session.TaskRemoveParams{
+				ID:        msg.ID,
+				Iteration: iteration,
+			})
+			if err != nil {
+				// TODO: Add visual feedback for user
+				logger.Warn("failed to remove task: %v", err)
+			}
+		}()
+		a.taskModal.Close()
+		if a.sidebar != nil {
+			a.sidebar.ClearActiveTask()
+		}
+		return a, nil
+
+	case UndependTaskMsg:
+		iteration := a.iteration
+		go func() {
+			for _, dep := range msg.DependsOn {
+				err := a.store.TaskUndepend(a.ctx, a.sessionName, session.TaskDependsParams{
+					ID:        msg.ID,
+					DependsOn: dep,
+					Iteration: iteration,
+				})
+				if err != nil {
+					logger.Warn("failed to remove task dependency: %v", err)
+				}
+			}
+		}()
+		return a, nil
+
 	case FileChangeMsg:
 		// Increment modified file count when a file is modified
 		a.modifiedFileCount++
@@ -465,8 +600,8 @@ func (a *App) handleKeyPress(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 			}
 			return a, nil
 		}
-		// Block all other keys when modal is visible
-		return a, nil
+		// Forward other keys for task actions (edit, remove, clear deps)
+		return a, a.taskModal.Update(msg)
 	}
 
 	if a.noteModal != nil && a.noteModal.IsVisible() {
@@ -477,7 +612,8 @@ func (a *App) handleKeyPress(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 			}
 			return a, nil
 		}
-		return a, nil
+		// Forward other keys for note actions (promote)
+		return a, a.noteModal.Update(msg)
 	}
 
 	// Note input modal gets priority when visible
@@ -889,62 +1025,67 @@ func (a *App) waitForEvents() tea.Cmd {
 	}
 }
 
-// subscribeToEvents subscribes to NATS events for this session.
-// This runs in a managed goroutine and sends messages to the Update loop.
+// subscribeToEvents loads the session state and watches the event log for the
+// events that follow it, from this process or any other writer (e.g. the
+// iteratr tool CLI in another shell). Events are forwarded to the event
+// channel in order; waitForEvents starts reading it once the state is set.
 func (a *App) subscribeToEvents() tea.Cmd {
 	return func() tea.Msg {
-		// Subscribe to all events for this session using wildcard pattern
-		subject := fmt.Sprintf("iteratr.%s.>", a.sessionName)
-
-		// Create subscription that forwards events to the event channel
-		sub, err := a.nc.Subscribe(subject, func(msg *nats.Msg) {
-			// Parse event from message data
-			var event session.Event
-			if err := json.Unmarshal(msg.Data, &event); err != nil {
-				// Skip malformed events
-				return
-			}
-
-			// Send to channel (non-blocking)
-			select {
-			case a.eventChan <- event:
-			default:
-				// Channel full, drop event
-			}
-		})
+		if a.store == nil {
+			return nil
+		}
 
+		// Load state and watch the session's state events; transcripts are not included
+		state, events, err := a.store.WatchState(a.ctx, a.sessionName)
 		if err != nil {
 			// Return error message
 			return fmt.Errorf("failed to subscribe to events: %w", err)
 		}
 
-		// Clean up when context is cancelled
-		<-a.ctx.Done()
-		_ = sub.Unsubscribe()
-		close(a.eventChan)
+		// Forward events until the context is cancelled and the watch ends.
+		// Events are never dropped: each one is applied to the state.
+		go func() {
+			defer close(a.eventChan)
+			for event := range events {
+				select {
+				case a.eventChan <- event:
+				case <-a.ctx.Done():
+					return
+				}
+			}
+		}()
 
-		return nil
+		return stateWatchMsg{State: state}
 	}
 }
 
-// loadInitialState loads the current session state from the event log.
-func (a *App) loadInitialState() tea.Cmd {
-	return func() tea.Msg {
-		state, err := a.store.LoadState(a.ctx, a.sessionName)
-		if err != nil {
-			// TODO: Handle error properly
-			return nil
+// setState makes state the current session state and propagates it to all
+// components.
+func (a *App) setState(state *session.State) tea.Cmd {
+	a.state = state
+	a.status.SetState(state)
+	a.sidebar.SetState(state)
+	a.dashboard.SetState(state)
+	a.logs.SetState(state)
+	// Keep an open task modal in sync; close it if the task was removed
+	if a.taskModal.IsVisible() && state != nil {
+		if task, ok := state.Tasks[a.taskModal.TaskID()]; ok {
+			a.taskModal.Refresh(task)
+		} else {
+			a.taskModal.Close()
+			a.sidebar.ClearActiveTask()
 		}
-		return StateUpdateMsg{State: state}
 	}
+	return a.status.Tick()
 }
 
 // checkConnectionHealth monitors NATS connection status and sends updates.
 // It checks the connection every 2 seconds and sends a ConnectionStatusMsg
-// when the status changes.
+// when the status changes. Without a connection (file storage) the event log
+// is always reachable.
 func (a *App) checkConnectionHealth() tea.Cmd {
 	return tea.Tick(2*time.Second, func(t time.Time) tea.Msg {
-		connected := a.nc != nil && a.nc.IsConnected()
+		connected := a.nc == nil || a.nc.IsConnected()
 		return ConnectionStatusMsg{Connected: connected}
 	})
 }
@@ -990,6 +1131,11 @@ type IterationStartMsg struct {
 	Number int
 }
 
+// stateWatchMsg carries the session state loaded when the event watch starts.
+type stateWatchMsg struct {
+	State *session.State
+}
+
 type StateUpdateMsg struct {
 	State *session.State
 }
@@ -1032,6 +1178,35 @@ type CreateTaskMsg struct {
 	Iteration int
 }
 
+// OpenTaskEditMsg is sent when the user chooses to edit the task shown in the task modal.
+type OpenTaskEditMsg struct {
+	Task *session.Task
+}
+
+// UpdateTaskMsg is sent when the user saves an edited task from the task input modal.
+type UpdateTaskMsg struct {
+	ID        string
+	Content   string
+	Priority  int
+	Iteration int
+}
+
+// RemoveTaskMsg is sent when the user confirms removal of a task from the task modal.
+type RemoveTaskMsg struct {
+	ID string
+}
+
+// PromoteNoteMsg is sent when the user promotes a note to the knowledge base from the note modal.
+type PromoteNoteMsg struct {
+	ID string
+}
+
+// UndependTaskMsg is sent when the user clears dependencies from the task modal.
+type UndependTaskMsg struct {
+	ID        string
+	DependsOn []string
+}
+
 // FileChangeMsg is sent when a file is modified during an iteration.
 type FileChangeMsg struct {
 	Path      string
@@ -221,3 +221,54 @@ func TestApp_TaskModal_QuitAfterClose(t *testing.T) {
 		t.Error("App should quit when ctrl+c pressed and modal closed")
 	}
 }
+
+// TestApp_TaskModal_EditFlow tests editing a task from the task modal
+func TestApp_TaskModal_EditFlow(t *testing.T) {
+	app := &App{
+		taskModal:      NewTaskModal(),
+		taskInputModal: NewTaskInputModal(),
+		dialog:         NewDialog(),
+	}
+
+	task := &session.Task{
+		ID:       "TAS-1",
+		Content:  "Original content",
+		Priority: 1,
+	}
+	app.taskModal.SetTask(task)
+
+	// Edit swaps the task modal for the input modal in edit mode
+	_, _ = app.Update(OpenTaskEditMsg{Task: task})
+
+	if app.taskModal.IsVisible() {
+		t.Error("Task modal should close when editing")
+	}
+	if !app.taskInputModal.IsVisible() || !app.taskInputModal.IsEditing() {
+		t.Fatal("Task input modal should be visible in edit mode")
+	}
+	if app.taskInputModal.textarea.Value() != "Original content" {
+		t.Errorf("expected prefilled content, got %q", app.taskInputModal.textarea.Value())
+	}
+	if !strings.Contains(app.taskInputModal.View(), "Edit TAS-1") {
+		t.Error("expected edit title in input modal")
+	}
+
+	// Submitting emits UpdateTaskMsg with the original priority preserved
+	cmd := app.taskInputModal.Update(tea.KeyPressMsg{Text: "ctrl+enter"})
+	if cmd == nil {
+		t.Fatal("expected submit command")
+	}
+	msg, ok := cmd().(UpdateTaskMsg)
+	if !ok {
+		t.Fatalf("expected UpdateTaskMsg, got %T", cmd())
+	}
+	if msg.ID != "TAS-1" || msg.Content != "Original content" || msg.Priority != 1 {
+		t.Errorf("unexpected UpdateTaskMsg: %#v", msg)
+	}
+
+	// Closing leaves edit mode
+	app.taskInputModal.Close()
+	if app.taskInputModal.IsEditing() {
+		t.Error("Task input modal should leave edit mode on close")
+	}
+}
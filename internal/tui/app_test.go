@@ -2,7 +2,10 @@ package tui
 
 import (
 	"context"
+	"encoding/json"
+	"strings"
 	"testing"
+	"time"
 
 	tea "charm.land/bubbletea/v2"
 	"github.com/mark3labs/iteratr/internal/session"
@@ -148,6 +151,50 @@ func TestApp_Update_StateUpdate(t *testing.T) {
 	_ = cmd
 }
 
+func TestApp_Update_EventAppliedToState(t *testing.T) {
+	ctx := context.Background()
+	app := NewApp(ctx, nil, "test-session", "/tmp", t.TempDir(), nil, nil, nil)
+
+	state := &session.State{
+		Session: "test-session",
+		Tasks: map[string]*session.Task{
+			"TAS-1": {ID: "TAS-1", Content: "Task 1", Status: "remaining"},
+		},
+		TaskCounter: 1,
+	}
+	_, _ = app.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	_, _ = app.Update(stateWatchMsg{State: state})
+
+	// A task added by another writer (e.g. the tool CLI)
+	_, _ = app.Update(EventMsg{Event: session.Event{
+		ID:      "TAS-2",
+		Session: "test-session",
+		Type:    "task",
+		Action:  "add",
+		Data:    "Task 2",
+		Meta:    json.RawMessage(`{"status":"in_progress"}`),
+	}})
+	_, _ = app.Update(EventMsg{Event: session.Event{
+		Session: "test-session",
+		Type:    "task",
+		Action:  "status",
+		Meta:    json.RawMessage(`{"task_id":"TAS-1","status":"completed"}`),
+	}})
+
+	if len(app.sidebar.state.Tasks) != 2 || app.sidebar.state.Tasks["TAS-2"].Content != "Task 2" {
+		t.Errorf("expected sidebar to show the added task, got %+v", app.sidebar.state.Tasks)
+	}
+	if app.status.state.Tasks["TAS-1"].Status != "completed" {
+		t.Errorf("expected status bar state to have TAS-1 completed, got %s", app.status.state.Tasks["TAS-1"].Status)
+	}
+	if !app.status.working {
+		t.Error("expected status bar to show work in progress")
+	}
+	if len(app.logs.events) != 2 {
+		t.Errorf("expected 2 events in log viewer, got %d", len(app.logs.events))
+	}
+}
+
 func TestApp_View(t *testing.T) {
 	ctx := context.Background()
 	app := NewApp(ctx, nil, "test-session", "/tmp", t.TempDir(), nil, nil, nil)
@@ -477,3 +524,30 @@ func TestApp_ManualTogglePreservedAcrossResizes(t *testing.T) {
 		t.Error("sidebarUserHidden should be false after manual show")
 	}
 }
+
+func TestApp_Observer(t *testing.T) {
+	ctx := context.Background()
+	store := session.NewStore(session.NewMemoryLog())
+	sendChan := make(chan string, 1)
+	app := NewApp(ctx, store, "test-session", "/tmp", t.TempDir(), nil, sendChan, nil)
+	app.SetObserver("by pid 42 on build-box since 10:00:00")
+
+	app.Update(UserInputMsg{Text: "hello"})
+	app.Update(CreateTaskMsg{Content: "Task", Priority: 2})
+	app.Update(CreateNoteMsg{Content: "Note", NoteType: "learning"})
+	time.Sleep(20 * time.Millisecond)
+
+	if len(sendChan) != 0 || app.queueDepth != 0 {
+		t.Error("expected user input to be dropped")
+	}
+	state, err := store.LoadState(ctx, "test-session")
+	if err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+	if len(state.Tasks) != 0 || len(state.Notes) != 0 {
+		t.Errorf("expected no changes, got %d tasks and %d notes", len(state.Tasks), len(state.Notes))
+	}
+	if left := app.status.buildLeft(); !strings.Contains(left, "OBSERVING") || !strings.Contains(left, "pid 42") {
+		t.Errorf("expected observer indicator in status bar, got %q", left)
+	}
+}
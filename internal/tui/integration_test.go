@@ -321,7 +321,7 @@ func TestIntegration_EventHandling(t *testing.T) {
 	msg := EventMsg{Event: event}
 	_, cmd := app.Update(msg)
 
-	// Should return a batch command (logs.AddEvent + loadInitialState + waitForEvents)
+	// Should return a batch command (logs.AddEvent + waitForEvents)
 	if cmd == nil {
 		t.Error("Expected command from event handling")
 	}
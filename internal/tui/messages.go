@@ -12,6 +12,7 @@ import (
 	"github.com/alecthomas/chroma/v2/lexers"
 	"github.com/alecthomas/chroma/v2/styles"
 	udiff "github.com/aymanbagabas/go-udiff"
+	"github.com/mark3labs/iteratr/internal/session"
 	"github.com/mark3labs/iteratr/internal/tui/theme"
 )
 
@@ -796,6 +797,70 @@ func (d *DividerMessageItem) Height() int {
 	return lines
 }
 
+// IterationResultMessageItem represents how an iteration ended.
+type IterationResultMessageItem struct {
+	id           string
+	iteration    int
+	result       session.IterationResult
+	cachedRender string
+	cachedWidth  int
+}
+
+// ID returns the unique identifier for this result message.
+func (r *IterationResultMessageItem) ID() string {
+	return r.id
+}
+
+// Render renders the result at the given width.
+// Formats as "✓ Iteration #N succeeded in 2m13s · 3 files +40 -12", with the
+// error on a second line if the iteration failed.
+func (r *IterationResultMessageItem) Render(width int) string {
+	// Return cached render if width matches
+	if r.cachedWidth == width && r.cachedRender != "" {
+		return r.cachedRender
+	}
+
+	s := theme.Current().S()
+	icon, style := "✓", s.InfoDuration
+	switch r.result.Outcome {
+	case session.IterationFailed:
+		icon, style = "✗", s.FinishError
+	case session.IterationCancelled:
+		icon, style = "⊘", s.FinishCanceled
+	}
+
+	text := fmt.Sprintf("%s Iteration #%d %s in %s", icon, r.iteration, r.result.Outcome, formatDuration(r.result.Duration))
+	if n := len(r.result.Files); n > 0 {
+		additions, deletions := 0, 0
+		for _, f := range r.result.Files {
+			additions += f.Additions
+			deletions += f.Deletions
+		}
+		text += fmt.Sprintf(" · %d file", n)
+		if n > 1 {
+			text += "s"
+		}
+		text += fmt.Sprintf(" +%d -%d", additions, deletions)
+	}
+	result := style.Render(text)
+	if r.result.Outcome == session.IterationFailed && r.result.Error != "" {
+		result += "\n" + s.FinishError.Render(fmt.Sprintf("Error: %s", r.result.Error))
+	}
+
+	// Cache and return
+	r.cachedRender = result
+	r.cachedWidth = width
+	return result
+}
+
+// Height returns the number of lines this result message occupies.
+func (r *IterationResultMessageItem) Height() int {
+	if r.cachedRender == "" {
+		return 0
+	}
+	return strings.Count(r.cachedRender, "\n") + 1
+}
+
 // PauseStateMsg signals pause state change to TUI.
 type PauseStateMsg struct{ Paused bool }
 
@@ -5,6 +5,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/mark3labs/iteratr/internal/session"
 )
 
 func TestInfoMessageItemRender(t *testing.T) {
@@ -575,6 +577,56 @@ func TestDividerMessageItemRenderMinLineWidth(t *testing.T) {
 	}
 }
 
+func TestIterationResultMessageItemRender(t *testing.T) {
+	tests := []struct {
+		name     string
+		result   session.IterationResult
+		contains []string
+		lines    int
+	}{
+		{
+			name: "succeeded with files",
+			result: session.IterationResult{
+				Outcome:  session.IterationSucceeded,
+				Duration: 90 * time.Second,
+				Files: []session.FileChange{
+					{Path: "a.go", Additions: 10, Deletions: 2},
+					{Path: "b.go", IsNew: true, Additions: 30},
+				},
+			},
+			contains: []string{"✓", "Iteration #3 succeeded in 1m30s", "2 files +40 -2"},
+			lines:    1,
+		},
+		{
+			name:     "failed shows error",
+			result:   session.IterationResult{Outcome: session.IterationFailed, Duration: time.Second, Error: "agent exited"},
+			contains: []string{"✗", "Iteration #3 failed", "Error: agent exited"},
+			lines:    2,
+		},
+		{
+			name:     "cancelled",
+			result:   session.IterationResult{Outcome: session.IterationCancelled, Duration: time.Second},
+			contains: []string{"⊘", "Iteration #3 cancelled"},
+			lines:    1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := &IterationResultMessageItem{id: "result-3", iteration: 3, result: tt.result}
+			result := item.Render(80)
+			for _, want := range tt.contains {
+				if !strings.Contains(result, want) {
+					t.Errorf("Render() = %q, want to contain %q", result, want)
+				}
+			}
+			if item.Height() != tt.lines {
+				t.Errorf("Height() = %d, want %d", item.Height(), tt.lines)
+			}
+		})
+	}
+}
+
 func TestFormatToolParams(t *testing.T) {
 	tests := []struct {
 		name     string
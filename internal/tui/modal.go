@@ -14,10 +14,11 @@ import (
 
 // TaskModal displays detailed information about a single task in a centered overlay.
 type TaskModal struct {
-	task    *session.Task
-	visible bool
-	width   int // Modal width
-	height  int // Modal height
+	task          *session.Task
+	visible       bool
+	confirmRemove bool // True after the first "d" press; a second press removes the task
+	width         int  // Modal width
+	height        int  // Modal height
 
 	// Render cache
 	cachedContent      string
@@ -38,15 +39,82 @@ func NewTaskModal() *TaskModal {
 func (m *TaskModal) SetTask(task *session.Task) {
 	m.task = task
 	m.visible = true
+	m.confirmRemove = false
 	m.cachedContent = "" // Invalidate cache
 }
 
+// Refresh replaces the displayed task with a newer version of the same task
+// (e.g. after a state reload) without resetting a pending remove confirmation.
+func (m *TaskModal) Refresh(task *session.Task) {
+	m.task = task
+	m.cachedContent = "" // Invalidate cache
+}
+
+// TaskID returns the ID of the displayed task, or "" if none.
+func (m *TaskModal) TaskID() string {
+	if m.task == nil {
+		return ""
+	}
+	return m.task.ID
+}
+
 // Close hides the modal.
 func (m *TaskModal) Close() {
 	m.visible = false
+	m.confirmRemove = false
 	m.task = nil
 }
 
+// Update handles keyboard input for the task actions.
+// "e" opens the task for editing, "d" pressed twice removes the task,
+// and "u" clears the task's dependencies. ESC is handled by the App.
+func (m *TaskModal) Update(msg tea.Msg) tea.Cmd {
+	if !m.visible || m.task == nil {
+		return nil
+	}
+
+	keyMsg, ok := msg.(tea.KeyPressMsg)
+	if !ok {
+		return nil
+	}
+
+	task := m.task
+	key := keyMsg.String()
+
+	// Any key other than "d" cancels a pending remove confirmation
+	if key != "d" && m.confirmRemove {
+		m.confirmRemove = false
+		m.cachedContent = ""
+	}
+
+	switch key {
+	case "e":
+		return func() tea.Msg {
+			return OpenTaskEditMsg{Task: task}
+		}
+	case "d":
+		if !m.confirmRemove {
+			m.confirmRemove = true
+			m.cachedContent = ""
+			return nil
+		}
+		m.confirmRemove = false
+		return func() tea.Msg {
+			return RemoveTaskMsg{ID: task.ID}
+		}
+	case "u":
+		if len(task.DependsOn) == 0 {
+			return nil
+		}
+		dependsOn := append([]string(nil), task.DependsOn...)
+		return func() tea.Msg {
+			return UndependTaskMsg{ID: task.ID, DependsOn: dependsOn}
+		}
+	}
+
+	return nil
+}
+
 // IsVisible returns whether the modal is currently visible.
 func (m *TaskModal) IsVisible() bool {
 	return m.visible
@@ -159,6 +227,25 @@ func (m *TaskModal) buildContent(width int) string {
 	sections = append(sections, separator)
 	sections = append(sections, "") // Blank line
 
+	// === Acceptance Section ===
+	if m.task.Acceptance != "" {
+		sections = append(sections, s.ModalLabel.Render("Acceptance:"))
+		sections = append(sections, s.ModalSection.Render(m.wordWrap(m.task.Acceptance, width-2)))
+		sections = append(sections, "") // Blank line
+	}
+	if m.task.Verify != "" {
+		verifyLine := s.ModalLabel.Render("Verify: ") + s.ModalValue.Render(m.task.Verify)
+		sections = append(sections, verifyLine)
+		sections = append(sections, "") // Blank line
+	}
+
+	// === Labels Section ===
+	if len(m.task.Labels) > 0 {
+		labelsLine := s.ModalLabel.Render("Labels: ") + s.ModalValue.Render(strings.Join(m.task.Labels, ", "))
+		sections = append(sections, labelsLine)
+		sections = append(sections, "") // Blank line
+	}
+
 	// === Dependencies Section ===
 	if len(m.task.DependsOn) > 0 {
 		depsLabel := s.ModalLabel.Render("Depends on: ")
@@ -174,12 +261,26 @@ func (m *TaskModal) buildContent(width int) string {
 	sections = append(sections, updatedLine)
 	sections = append(sections, "") // Blank line
 
-	// === Close Instructions (key/description differentiation) ===
-	closeHint := s.HintKey.Render("esc") + " " +
-		s.HintDesc.Render("close") + " " +
-		s.HintSeparator.Render("•") + " " +
-		s.HintKey.Render("click outside") + " " +
-		s.HintDesc.Render("dismiss")
+	// === Action Instructions (key/description differentiation) ===
+	sep := " " + s.HintSeparator.Render("•") + " "
+	var closeHint string
+	if m.confirmRemove {
+		closeHint = s.HintKey.Render("d") + " " +
+			s.HintDesc.Render("confirm remove") + sep +
+			s.HintKey.Render("any key") + " " +
+			s.HintDesc.Render("cancel")
+	} else {
+		closeHint = s.HintKey.Render("e") + " " +
+			s.HintDesc.Render("edit") + sep +
+			s.HintKey.Render("d") + " " +
+			s.HintDesc.Render("remove") + sep
+		if len(m.task.DependsOn) > 0 {
+			closeHint += s.HintKey.Render("u") + " " +
+				s.HintDesc.Render("clear deps") + sep
+		}
+		closeHint += s.HintKey.Render("esc") + " " +
+			s.HintDesc.Render("close")
+	}
 	closeText := lipgloss.NewStyle().Width(width - 2).Align(lipgloss.Center).Render(closeHint)
 	sections = append(sections, closeText)
 
@@ -295,8 +396,3 @@ func (m *TaskModal) wordWrap(text string, width int) string {
 
 	return strings.Join(lines, "\n")
 }
-
-// Update handles messages for the modal.
-func (m *TaskModal) Update(msg tea.Msg) tea.Cmd {
-	return nil
-}
@@ -238,6 +238,31 @@ func TestTaskModal_Update(t *testing.T) {
 	}
 }
 
+func TestNoteModal_Promote(t *testing.T) {
+	modal := NewNoteModal()
+	modal.SetNote(&session.Note{ID: "NOT-3", Content: "Use make test", Type: "tip"})
+
+	cmd := modal.Update(tea.KeyPressMsg{Code: 'p', Text: "p"})
+	if cmd == nil {
+		t.Fatal("expected a command for p")
+	}
+	if msg, ok := cmd().(PromoteNoteMsg); !ok || msg.ID != "NOT-3" {
+		t.Errorf("expected PromoteNoteMsg for NOT-3, got %#v", cmd())
+	}
+	if !strings.Contains(modal.buildContent(60), "promote to knowledge") {
+		t.Error("expected promote hint for tip note")
+	}
+
+	// Stuck notes stay in their session
+	modal.SetNote(&session.Note{ID: "NOT-4", Content: "Blocked on API", Type: "stuck"})
+	if cmd := modal.Update(tea.KeyPressMsg{Code: 'p', Text: "p"}); cmd != nil {
+		t.Error("expected no command for stuck note")
+	}
+	if strings.Contains(modal.buildContent(60), "promote to knowledge") {
+		t.Error("expected no promote hint for stuck note")
+	}
+}
+
 func TestTaskModal_FormatTime(t *testing.T) {
 	modal := NewTaskModal()
 	testTime := time.Date(2024, 1, 15, 14, 30, 45, 0, time.UTC)
@@ -317,3 +342,73 @@ func TestTaskModal_NoDependencies(t *testing.T) {
 		t.Error("Content should not include 'Depends on:' when task has no dependencies")
 	}
 }
+
+func TestTaskModal_EditKey(t *testing.T) {
+	modal := NewTaskModal()
+	task := &session.Task{ID: "TAS-1", Content: "Test task"}
+	modal.SetTask(task)
+
+	cmd := modal.Update(tea.KeyPressMsg{Text: "e"})
+	if cmd == nil {
+		t.Fatal("expected command from 'e' key")
+	}
+	msg, ok := cmd().(OpenTaskEditMsg)
+	if !ok {
+		t.Fatalf("expected OpenTaskEditMsg, got %T", cmd())
+	}
+	if msg.Task != task {
+		t.Error("expected OpenTaskEditMsg to carry the displayed task")
+	}
+}
+
+func TestTaskModal_RemoveRequiresConfirmation(t *testing.T) {
+	modal := NewTaskModal()
+	modal.SetTask(&session.Task{ID: "TAS-1", Content: "Test task"})
+
+	// First press only asks for confirmation
+	if cmd := modal.Update(tea.KeyPressMsg{Text: "d"}); cmd != nil {
+		t.Fatal("expected no command on first 'd' press")
+	}
+	if !strings.Contains(modal.buildContent(56), "confirm remove") {
+		t.Error("expected confirmation hint after first 'd' press")
+	}
+
+	// Any other key cancels the confirmation
+	_ = modal.Update(tea.KeyPressMsg{Text: "x"})
+	if cmd := modal.Update(tea.KeyPressMsg{Text: "d"}); cmd != nil {
+		t.Fatal("expected confirmation to be reset by other key")
+	}
+
+	// Second consecutive press removes
+	cmd := modal.Update(tea.KeyPressMsg{Text: "d"})
+	if cmd == nil {
+		t.Fatal("expected command on second 'd' press")
+	}
+	msg, ok := cmd().(RemoveTaskMsg)
+	if !ok || msg.ID != "TAS-1" {
+		t.Errorf("expected RemoveTaskMsg for TAS-1, got %#v", cmd())
+	}
+}
+
+func TestTaskModal_ClearDependencies(t *testing.T) {
+	modal := NewTaskModal()
+
+	// No dependencies: nothing to clear
+	modal.SetTask(&session.Task{ID: "TAS-1", Content: "Test task"})
+	if cmd := modal.Update(tea.KeyPressMsg{Text: "u"}); cmd != nil {
+		t.Error("expected no command when task has no dependencies")
+	}
+
+	modal.SetTask(&session.Task{ID: "TAS-3", Content: "Dependent task", DependsOn: []string{"TAS-1", "TAS-2"}})
+	cmd := modal.Update(tea.KeyPressMsg{Text: "u"})
+	if cmd == nil {
+		t.Fatal("expected command from 'u' key")
+	}
+	msg, ok := cmd().(UndependTaskMsg)
+	if !ok {
+		t.Fatalf("expected UndependTaskMsg, got %T", cmd())
+	}
+	if msg.ID != "TAS-3" || len(msg.DependsOn) != 2 {
+		t.Errorf("unexpected UndependTaskMsg: %#v", msg)
+	}
+}
@@ -137,7 +137,13 @@ func (m *NoteModal) buildContent(width int) string {
 	sections = append(sections, "")
 
 	// Close instructions (key/description differentiation)
-	closeHint := s.HintKey.Render("esc") + " " +
+	closeHint := ""
+	if isPromotableNote(m.note) {
+		closeHint = s.HintKey.Render("p") + " " +
+			s.HintDesc.Render("promote to knowledge") + " " +
+			s.HintSeparator.Render("•") + " "
+	}
+	closeHint += s.HintKey.Render("esc") + " " +
 		s.HintDesc.Render("close") + " " +
 		s.HintSeparator.Render("•") + " " +
 		s.HintKey.Render("click outside") + " " +
@@ -213,7 +219,26 @@ func (m *NoteModal) wordWrap(text string, width int) string {
 	return strings.Join(lines, "\n")
 }
 
-// Update handles messages for the modal.
+// Update handles keyboard input for the note actions.
+// "p" promotes a learning, tip or decision note to the project knowledge base.
+// ESC is handled by the App.
 func (m *NoteModal) Update(msg tea.Msg) tea.Cmd {
-	return nil
+	if !m.visible || m.note == nil {
+		return nil
+	}
+
+	keyMsg, ok := msg.(tea.KeyPressMsg)
+	if !ok || keyMsg.String() != "p" || !isPromotableNote(m.note) {
+		return nil
+	}
+	id := m.note.ID
+	return func() tea.Msg {
+		return PromoteNoteMsg{ID: id}
+	}
+}
+
+// isPromotableNote reports whether a note can be promoted to the knowledge
+// base; stuck notes only make sense within their session.
+func isPromotableNote(note *session.Note) bool {
+	return note.Type != "stuck"
 }
@@ -368,6 +368,11 @@ func (m *SetupModel) WriteConfig() error {
 		Iterations: 0,
 		Headless:   false,
 		Template:   "",
+		ResetTasks: true,
+		Storage:    "nats",
+		Retention: config.Retention{
+			MaxAge: "0",
+		},
 	}
 
 	if m.isProject {
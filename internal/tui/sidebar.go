@@ -2,7 +2,7 @@ package tui
 
 import (
 	"fmt"
-	"sort"
+	"slices"
 	"strings"
 
 	tea "charm.land/bubbletea/v2"
@@ -20,6 +20,7 @@ type OpenTaskModalMsg struct {
 // taskScrollItem wraps a task for use in ScrollList.
 type taskScrollItem struct {
 	task       *session.Task
+	depth      int // Nesting depth for subtasks (0 = top-level)
 	isSelected bool
 	width      int
 	rendered   string
@@ -74,8 +75,11 @@ func (t *taskScrollItem) renderTask() string {
 		indicatorStyle = s.StatusRemaining
 	}
 
-	// Truncate content to fit width (leave room for indicator and padding)
-	maxContentWidth := t.width - 6 // 2 for indicator+space, 2 padding, 2 for selection arrow
+	// Indent subtasks under their parent
+	indent := strings.Repeat("  ", t.depth)
+
+	// Truncate content to fit width (leave room for indent, indicator and padding)
+	maxContentWidth := t.width - 6 - len(indent) // 2 for indicator+space, 2 padding, 2 for selection arrow
 	if maxContentWidth < 10 {
 		maxContentWidth = 10
 	}
@@ -87,7 +91,7 @@ func (t *taskScrollItem) renderTask() string {
 
 	// Build line (selection arrow handled by ScrollList)
 	styledIndicator := indicatorStyle.Render(indicator)
-	line := fmt.Sprintf(" %s %s", styledIndicator, content)
+	line := fmt.Sprintf(" %s%s %s", indent, styledIndicator, content)
 
 	return line
 }
@@ -191,6 +195,7 @@ type Sidebar struct {
 	tasksContentArea uv.Rectangle      // Screen area where task lines are drawn (for mouse hit detection)
 	notesContentArea uv.Rectangle      // Screen area where note lines are drawn (for mouse hit detection)
 	activeNoteID     string            // Currently active note (shown in modal)
+	labelFilter      string            // Only show tasks with this label ("" shows all)
 }
 
 // NewSidebar creates a new Sidebar component.
@@ -249,6 +254,10 @@ func (s *Sidebar) handleKeyPress(msg tea.KeyPressMsg) tea.Cmd {
 			s.tasksScrollList.ScrollToItem(s.cursor)
 		}
 		return nil
+	case "f":
+		// Cycle the task label filter: all -> each label -> all
+		s.cycleLabelFilter()
+		return nil
 	case "enter":
 		// Return OpenTaskModalMsg for the selected task
 		if len(tasks) > 0 && s.cursor < len(tasks) {
@@ -276,11 +285,14 @@ func (s *Sidebar) handleKeyPress(msg tea.KeyPressMsg) tea.Cmd {
 func (s *Sidebar) drawTasksSection(scr uv.Screen, area uv.Rectangle) {
 	// Apply pulse effect to title if task status changed
 	title := "Tasks"
+	if s.labelFilter != "" {
+		title = fmt.Sprintf("Tasks [%s]", s.labelFilter)
+	}
 	if s.pulse.IsActive() {
 		// Add visual indicator when pulse is active
 		intensity := s.pulse.Intensity()
 		if intensity > 0.5 {
-			title = "Tasks ●" // Add dot indicator during pulse
+			title += " ●" // Add dot indicator during pulse
 		}
 	}
 
@@ -414,22 +426,35 @@ func (s *Sidebar) drawLogo(scr uv.Screen, area uv.Rectangle) {
 	}
 }
 
-// getTasks returns all tasks ordered by ID.
+// getTasks returns all tasks in tree order (subtasks follow their parent).
 func (s *Sidebar) getTasks() []*session.Task {
+	nodes := s.getTaskNodes()
+	tasks := make([]*session.Task, len(nodes))
+	for i, node := range nodes {
+		tasks[i] = node.Task
+	}
+	return tasks
+}
+
+// getTaskNodes returns all tasks matching the label filter in tree order with their nesting depth.
+func (s *Sidebar) getTaskNodes() []session.TaskTreeNode {
 	if s.state == nil {
 		return nil
 	}
 
+	var filter []string
+	if s.labelFilter != "" {
+		filter = []string{s.labelFilter}
+	}
+
 	tasks := make([]*session.Task, 0, len(s.state.Tasks))
 	for _, task := range s.state.Tasks {
-		tasks = append(tasks, task)
+		if task.HasLabels(filter) {
+			tasks = append(tasks, task)
+		}
 	}
 
-	sort.Slice(tasks, func(i, j int) bool {
-		return tasks[i].ID < tasks[j].ID
-	})
-
-	return tasks
+	return session.TaskTree(tasks)
 }
 
 // Draw renders the sidebar to the screen buffer with logo, tasks, and notes sections.
@@ -606,6 +631,11 @@ func (s *Sidebar) SetState(state *session.State) {
 		}
 	}
 
+	// Drop the label filter if no task carries that label anymore
+	if s.labelFilter != "" && state != nil && !slices.Contains(state.Labels(), s.labelFilter) {
+		s.labelFilter = ""
+	}
+
 	s.updateContent()
 
 	// Clamp cursor to valid range after state update
@@ -617,6 +647,34 @@ func (s *Sidebar) SetState(state *session.State) {
 	}
 }
 
+// LabelFilter returns the label tasks are currently filtered by, or "" if unfiltered.
+func (s *Sidebar) LabelFilter() string {
+	return s.labelFilter
+}
+
+// cycleLabelFilter advances the task label filter to the next label in the
+// session (alphabetically), wrapping back to showing all tasks after the last.
+func (s *Sidebar) cycleLabelFilter() {
+	if s.state == nil {
+		return
+	}
+
+	labels := s.state.Labels()
+	next := ""
+	if s.labelFilter == "" {
+		if len(labels) > 0 {
+			next = labels[0]
+		}
+	} else if idx := slices.Index(labels, s.labelFilter); idx >= 0 && idx+1 < len(labels) {
+		next = labels[idx+1]
+	}
+
+	s.labelFilter = next
+	s.cursor = 0
+	s.updateContent()
+	s.tasksScrollList.ScrollToItem(0)
+}
+
 // rebuildIndex rebuilds the ID-based lookup indices for tasks and notes.
 // This provides O(1) lookups by ID.
 func (s *Sidebar) rebuildIndex() {
@@ -651,19 +709,21 @@ func (s *Sidebar) updateContent() {
 	s.rebuildIndex()
 
 	// Update tasks ScrollList
-	tasks := s.getTasks()
-	taskItems := make([]ScrollItem, 0, len(tasks))
-	for idx, task := range tasks {
+	nodes := s.getTaskNodes()
+	taskItems := make([]ScrollItem, 0, len(nodes))
+	for idx, node := range nodes {
+		task := node.Task
 		isSelected := (s.focused && idx == s.cursor) || task.ID == s.activeTaskID
 		taskItems = append(taskItems, &taskScrollItem{
 			task:       task,
+			depth:      node.Depth,
 			isSelected: isSelected,
 			width:      s.tasksScrollList.width,
 		})
 	}
 	s.tasksScrollList.SetItems(taskItems)
 	// Set selected index for cursor highlighting
-	if s.focused && s.cursor >= 0 && s.cursor < len(tasks) {
+	if s.focused && s.cursor >= 0 && s.cursor < len(nodes) {
 		s.tasksScrollList.SetSelected(s.cursor)
 	} else {
 		s.tasksScrollList.SetSelected(-1)
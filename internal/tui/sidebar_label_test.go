@@ -0,0 +1,64 @@
+package tui
+
+import (
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/mark3labs/iteratr/internal/session"
+)
+
+func TestSidebar_LabelFilterCycle(t *testing.T) {
+	sidebar := NewSidebar()
+	sidebar.SetSize(40, 30)
+	sidebar.SetTasksScrollFocused(true)
+	sidebar.SetState(&session.State{
+		Tasks: map[string]*session.Task{
+			"TAS-1": {ID: "TAS-1", Content: "API", Status: "remaining", Labels: []string{"backend"}},
+			"TAS-2": {ID: "TAS-2", Content: "Button", Status: "remaining", Labels: []string{"frontend"}},
+			"TAS-3": {ID: "TAS-3", Content: "Docs", Status: "remaining"},
+		},
+	})
+
+	fKey := tea.KeyPressMsg{Code: 'f', Text: "f"}
+	steps := []struct {
+		filter string
+		tasks  int
+	}{
+		{"backend", 1},
+		{"frontend", 1},
+		{"", 3},
+	}
+	for _, step := range steps {
+		sidebar.Update(fKey)
+		if sidebar.LabelFilter() != step.filter {
+			t.Fatalf("expected filter %q, got %q", step.filter, sidebar.LabelFilter())
+		}
+		if got := len(sidebar.getTasks()); got != step.tasks {
+			t.Errorf("filter %q: expected %d tasks, got %d", step.filter, step.tasks, got)
+		}
+	}
+}
+
+func TestSidebar_LabelFilterClearedWhenLabelDisappears(t *testing.T) {
+	sidebar := NewSidebar()
+	sidebar.SetSize(40, 30)
+	sidebar.SetTasksScrollFocused(true)
+	sidebar.SetState(&session.State{
+		Tasks: map[string]*session.Task{
+			"TAS-1": {ID: "TAS-1", Content: "API", Status: "remaining", Labels: []string{"backend"}},
+		},
+	})
+	sidebar.Update(tea.KeyPressMsg{Code: 'f', Text: "f"})
+	if sidebar.LabelFilter() != "backend" {
+		t.Fatalf("expected filter %q, got %q", "backend", sidebar.LabelFilter())
+	}
+
+	sidebar.SetState(&session.State{
+		Tasks: map[string]*session.Task{
+			"TAS-1": {ID: "TAS-1", Content: "API", Status: "remaining"},
+		},
+	})
+	if sidebar.LabelFilter() != "" {
+		t.Errorf("expected filter to be cleared, got %q", sidebar.LabelFilter())
+	}
+}
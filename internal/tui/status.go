@@ -31,9 +31,10 @@ type StatusBar struct {
 	needsTick         bool // Whether a tick needs to be started on next Tick() call
 	layoutMode        LayoutMode
 	spinner           Spinner
-	modifiedFileCount int  // Number of files modified in current iteration
-	prefixMode        bool // Whether waiting for second key after ctrl+x
-	sidebarHidden     bool // Whether sidebar is currently hidden
+	modifiedFileCount int    // Number of files modified in current iteration
+	prefixMode        bool   // Whether waiting for second key after ctrl+x
+	sidebarHidden     bool   // Whether sidebar is currently hidden
+	observing         string // Owner of the observed session ("" unless in observer mode)
 
 	// Git status fields
 	gitBranch string // Branch name or "HEAD" if detached
@@ -86,6 +87,21 @@ func (s *StatusBar) Draw(scr uv.Screen, area uv.Rectangle) *tea.Cursor {
 	return nil
 }
 
+// buildUsageInfo formats the usage of the current iteration and the session
+// total, e.g. "12.3k tok $0.42 (Σ 40.1k tok $1.30)". Returns "" before any
+// usage has been recorded.
+func (s *StatusBar) buildUsageInfo() string {
+	if s.state == nil || len(s.state.Iterations) == 0 {
+		return ""
+	}
+	total := s.state.TotalUsage()
+	if total.IsZero() {
+		return ""
+	}
+	current := s.state.Iterations[len(s.state.Iterations)-1].Usage
+	return fmt.Sprintf("%s (Σ %s)", current, total)
+}
+
 // buildLeft builds the left side of the status bar with session info.
 func (s *StatusBar) buildLeft() string {
 	title := theme.Current().S().HeaderTitle.Render("iteratr")
@@ -116,6 +132,11 @@ func (s *StatusBar) buildLeft() string {
 		left += sep + theme.Current().S().HeaderInfo.Render(iterInfo)
 	}
 
+	// Add token usage of the current iteration and the session if any
+	if usage := s.buildUsageInfo(); usage != "" {
+		left += sep + theme.Current().S().HeaderInfo.Render(usage)
+	}
+
 	// Add task stats if tasks exist
 	if stats := s.buildTaskStats(); stats != "" {
 		left += sep + stats
@@ -136,6 +157,12 @@ func (s *StatusBar) buildLeft() string {
 		left += " " + s.spinner.View()
 	}
 
+	// Add observer indicator with the process running the session
+	if s.observing != "" {
+		left += " " + theme.Current().S().StatusPaused.Render("◉ OBSERVING") +
+			theme.Current().S().HeaderInfo.Render(" "+s.observing)
+	}
+
 	// Add pause indicator (uses agentBusy, not working, to determine PAUSING vs PAUSED)
 	if s.paused {
 		if s.agentBusy {
@@ -276,6 +303,11 @@ func (s *StatusBar) SetSidebarHidden(hidden bool) {
 	s.sidebarHidden = hidden
 }
 
+// SetObserver shows the observer indicator with the owner of the session.
+func (s *StatusBar) SetObserver(owner string) {
+	s.observing = owner
+}
+
 // SetGitInfo updates the git repository status fields.
 func (s *StatusBar) SetGitInfo(msg GitInfoMsg) {
 	s.gitBranch = msg.Branch
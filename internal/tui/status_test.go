@@ -216,3 +216,20 @@ func TestStatusBar_SidebarHint(t *testing.T) {
 		})
 	}
 }
+
+func TestStatusBar_UsageInfo(t *testing.T) {
+	sb := NewStatusBar("test-session")
+
+	sb.SetState(&session.State{Iterations: []*session.Iteration{{Number: 1}}})
+	if got := sb.buildUsageInfo(); got != "" {
+		t.Errorf("expected no usage info without usage, got %q", got)
+	}
+
+	sb.SetState(&session.State{Iterations: []*session.Iteration{
+		{Number: 1, Usage: session.Usage{InputTokens: 30000, OutputTokens: 1000, Cost: 0.5}},
+		{Number: 2, Usage: session.Usage{InputTokens: 12000, OutputTokens: 300, Cost: 0.25}},
+	}})
+	if got, want := sb.buildUsageInfo(), "12.3k tok $0.25 (Σ 43.3k tok $0.75)"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	lipgloss "charm.land/lipgloss/v2"
 	uv "github.com/charmbracelet/ultraviolet"
 
+	"github.com/mark3labs/iteratr/internal/session"
 	"github.com/mark3labs/iteratr/internal/tui/theme"
 )
 
@@ -30,10 +31,11 @@ var priorities = []struct {
 	{4, "backlog", "○"},
 }
 
-// TaskInputModal is an interactive modal for creating new tasks.
+// TaskInputModal is an interactive modal for creating new tasks and editing existing ones.
 // It displays a textarea for content input, a priority selector, and allows the user to submit tasks.
 type TaskInputModal struct {
 	visible       bool
+	editTask      *session.Task  // Task being edited; nil when creating a new task
 	textarea      textarea.Model // Bubbles v2 textarea
 	priorityIndex int            // Current selected priority (0-4)
 	focus         focusZone      // Which UI element currently has keyboard focus
@@ -88,6 +90,25 @@ func (m *TaskInputModal) Show() tea.Cmd {
 	return m.textarea.Focus()
 }
 
+// ShowEdit makes the modal visible in edit mode, prefilled with the task's
+// content and priority. Submitting emits UpdateTaskMsg instead of CreateTaskMsg.
+func (m *TaskInputModal) ShowEdit(task *session.Task) tea.Cmd {
+	m.editTask = task
+	m.textarea.SetValue(task.Content)
+	for i, p := range priorities {
+		if p.value == task.Priority {
+			m.priorityIndex = i
+			break
+		}
+	}
+	return m.Show()
+}
+
+// IsEditing returns whether the modal is editing an existing task.
+func (m *TaskInputModal) IsEditing() bool {
+	return m.editTask != nil
+}
+
 // Close hides the modal and resets its state.
 func (m *TaskInputModal) Close() {
 	m.visible = false
@@ -97,8 +118,9 @@ func (m *TaskInputModal) Close() {
 // reset clears the textarea and resets the modal to initial state.
 // Called on both cancel (ESC) and submit to ensure clean state on next open.
 func (m *TaskInputModal) reset() {
-	// Clear textarea content
+	// Clear textarea content and leave edit mode
 	m.textarea.SetValue("")
+	m.editTask = nil
 
 	// Reset priority to default (medium)
 	m.priorityIndex = 2
@@ -245,10 +267,20 @@ func (m *TaskInputModal) Update(msg tea.Msg) tea.Cmd {
 	return nil
 }
 
-// submit returns a command that creates a CreateTaskMsg.
+// submit returns a command that creates a CreateTaskMsg, or an UpdateTaskMsg in edit mode.
 // The App will receive this message and fill in the iteration number.
 func (m *TaskInputModal) submit(content string) tea.Cmd {
 	priority := priorities[m.priorityIndex].value
+	if task := m.editTask; task != nil {
+		return func() tea.Msg {
+			return UpdateTaskMsg{
+				ID:        task.ID,
+				Content:   content,
+				Priority:  priority,
+				Iteration: 0, // Will be filled in by App
+			}
+		}
+	}
 	return func() tea.Msg {
 		return CreateTaskMsg{
 			Content:   content,
@@ -345,7 +377,11 @@ func (m *TaskInputModal) renderButton() string {
 		buttonStyle = s.BadgeMuted
 	}
 
-	return buttonStyle.Render("  Add Task  ")
+	label := "  Add Task  "
+	if m.editTask != nil {
+		label = "  Save Task  "
+	}
+	return buttonStyle.Render(label)
 }
 
 // View renders the modal content (for testing and integration).
@@ -358,7 +394,11 @@ func (m *TaskInputModal) View() string {
 	var sections []string
 
 	// Title - width accounts for border (2) + padding (4)
-	title := renderModalTitle("New Task", m.width-6)
+	titleText := "New Task"
+	if m.editTask != nil {
+		titleText = "Edit " + m.editTask.ID
+	}
+	title := renderModalTitle(titleText, m.width-6)
 	sections = append(sections, title)
 	sections = append(sections, "")
 
@@ -106,10 +106,20 @@ func formatRelativeTime(t time.Time) string {
 	}
 }
 
+// sessionPageSize is how many sessions the selector loads at a time. The next
+// page is loaded when the selection reaches the end of the list.
+const sessionPageSize = 50
+
+// sessionSortOrders are the orders the selector cycles through with "s".
+var sessionSortOrders = []string{session.SortByActivity, session.SortByName, session.SortByCreated}
+
 // SessionSelectorStep manages the session selector UI step.
 type SessionSelectorStep struct {
 	sessionStore    *session.Store  // Session store for loading sessions
-	sessions        []SessionItem   // All session items ("New Session" + existing sessions)
+	sessions        []SessionItem   // Loaded session items ("New Session" + existing sessions)
+	total           int             // Number of existing sessions, loaded or not
+	sortIdx         int             // Index into sessionSortOrders
+	loadingMore     bool            // Whether the next page is being fetched
 	scrollList      *tui.ScrollList // Lazy-rendering scroll list
 	selectedIdx     int             // Index of selected item
 	loading         bool            // Whether sessions are being fetched
@@ -148,24 +158,38 @@ func NewSessionSelectorStep(sessionStore *session.Store) *SessionSelectorStep {
 // Init initializes the session selector and starts fetching sessions.
 func (s *SessionSelectorStep) Init() tea.Cmd {
 	return tea.Batch(
-		s.fetchSessions(),
+		s.fetchSessions(0),
 		s.spinner.Tick,
 	)
 }
 
-// fetchSessions loads all sessions from the session store.
-func (s *SessionSelectorStep) fetchSessions() tea.Cmd {
+// fetchSessions loads the page of sessions starting at offset, in the
+// current sort order.
+func (s *SessionSelectorStep) fetchSessions(offset int) tea.Cmd {
+	sort := sessionSortOrders[s.sortIdx]
 	return func() tea.Msg {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 
-		sessions, err := s.sessionStore.ListSessions(ctx)
+		sessions, total, err := s.sessionStore.ListSessionsPage(ctx, session.SessionListParams{
+			Sort:   sort,
+			Offset: offset,
+			Limit:  sessionPageSize,
+		})
 		if err != nil {
 			return SessionsErrorMsg{err: err}
 		}
 
-		return SessionsLoadedMsg{sessions: sessions}
+		return SessionsLoadedMsg{sessions: sessions, total: total, offset: offset, sort: sort}
+	}
+}
+
+// loadedCount returns the number of existing sessions loaded so far.
+func (s *SessionSelectorStep) loadedCount() int {
+	if len(s.sessions) == 0 {
+		return 0
 	}
+	return len(s.sessions) - 1 // Without "New Session"
 }
 
 // SetSize updates the dimensions for the session selector.
@@ -182,12 +206,21 @@ func (s *SessionSelectorStep) Update(msg tea.Msg) tea.Cmd {
 
 	switch msg := msg.(type) {
 	case SessionsLoadedMsg:
+		if msg.sort != sessionSortOrders[s.sortIdx] {
+			return nil // Page of an order the user already switched away from
+		}
 		// Sessions fetched successfully
 		s.loading = false
-		s.sessions = make([]SessionItem, 0, len(msg.sessions)+1)
-
-		// Add "New Session" at the top
-		s.sessions = append(s.sessions, SessionItem{isNew: true})
+		s.loadingMore = false
+		s.total = msg.total
+		if msg.offset == 0 {
+			s.sessions = make([]SessionItem, 0, len(msg.sessions)+1)
+
+			// Add "New Session" at the top
+			s.sessions = append(s.sessions, SessionItem{isNew: true})
+		} else if msg.offset != s.loadedCount() {
+			return nil // Duplicate page
+		}
 
 		// Add existing sessions
 		for _, info := range msg.sessions {
@@ -262,8 +295,21 @@ func (s *SessionSelectorStep) handleListingInput(keyMsg tea.KeyPressMsg) tea.Cmd
 			s.scrollList.SetSelected(s.selectedIdx)
 			s.scrollList.ScrollToItem(s.selectedIdx)
 		}
+		// Load the next page when reaching the end of the loaded sessions
+		if s.selectedIdx == len(s.sessions)-1 && s.loadedCount() < s.total && !s.loadingMore {
+			s.loadingMore = true
+			return s.fetchSessions(s.loadedCount())
+		}
 		return nil
 
+	case "s":
+		// Cycle the sort order and reload from the first page
+		s.sortIdx = (s.sortIdx + 1) % len(sessionSortOrders)
+		s.selectedIdx = 0
+		s.loading = true
+		s.loadingMore = false
+		return tea.Batch(s.fetchSessions(0), s.spinner.Tick)
+
 	case "enter":
 		// Session selected
 		if s.selectedIdx >= 0 && s.selectedIdx < len(s.sessions) {
@@ -405,10 +451,18 @@ func (s *SessionSelectorStep) renderListing() string {
 	// Add spacing before hint bar
 	b.WriteString("\n")
 
+	// Page position, when not everything is loaded
+	if s.loadedCount() < s.total {
+		countStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(t.FgMuted))
+		b.WriteString(countStyle.Render(fmt.Sprintf("Showing %d of %d sessions", s.loadedCount(), s.total)))
+		b.WriteString("\n")
+	}
+
 	// Hint bar
 	hintBar := renderHintBar(
 		"↑↓/j/k", "navigate",
 		"enter", "select",
+		"s", "sort: "+sessionSortOrders[s.sortIdx],
 		"tab", "buttons",
 		"esc", "cancel",
 	)
@@ -536,9 +590,12 @@ func (s *SessionSelectorStep) ReturnToListing() {
 	s.selectedSession = nil
 }
 
-// SessionsLoadedMsg is sent when sessions are successfully fetched.
+// SessionsLoadedMsg is sent when a page of sessions is successfully fetched.
 type SessionsLoadedMsg struct {
 	sessions []session.SessionInfo
+	total    int    // Number of sessions across all pages
+	offset   int    // Position of the page's first session
+	sort     string // Order the page was fetched in
 }
 
 // SessionsErrorMsg is sent when session fetching fails.
@@ -559,7 +616,12 @@ func (s *SessionSelectorStep) resetAndResume() tea.Cmd {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 
-		// Reset the session (purge all events)
+		// Reset the session (purge all events), unless another process runs it
+		lease, err := s.sessionStore.AcquireLease(ctx, s.selectedSession.info.Name)
+		if err != nil {
+			return SessionsErrorMsg{err: fmt.Errorf("can't reset a running session: %w", err)}
+		}
+		defer func() { _ = lease.Release(ctx) }()
 		if err := s.sessionStore.ResetSession(ctx, s.selectedSession.info.Name); err != nil {
 			return SessionsErrorMsg{err: err}
 		}
@@ -622,6 +684,9 @@ func (s *SessionSelectorStep) PreferredHeight() int {
 	if listItems > 20 {
 		listItems = 20 // Cap at 20 for scrollable list
 	}
+	if s.loadedCount() < s.total {
+		listItems++ // "Showing N of M sessions"
+	}
 
 	return listItems + 2
 }
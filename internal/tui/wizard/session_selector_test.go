@@ -0,0 +1,56 @@
+package wizard
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/mark3labs/iteratr/internal/session"
+)
+
+func TestSessionSelectorPaging(t *testing.T) {
+	ctx := context.Background()
+	store := session.NewStore(session.NewMemoryLog())
+	for i := 0; i < sessionPageSize+5; i++ {
+		_, _ = store.TaskAdd(ctx, fmt.Sprintf("session-%02d", i), session.TaskAddParams{Content: "Task"})
+	}
+
+	step := NewSessionSelectorStep(store)
+	step.Update(step.fetchSessions(0)())
+	if step.loadedCount() != sessionPageSize || step.total != sessionPageSize+5 {
+		t.Fatalf("expected first page of %d of %d sessions, got %d of %d", sessionPageSize, sessionPageSize+5, step.loadedCount(), step.total)
+	}
+
+	// Moving to the last loaded session fetches the next page
+	var cmd tea.Cmd
+	for i := 0; i < sessionPageSize; i++ {
+		cmd = step.Update(tea.KeyPressMsg{Code: tea.KeyDown})
+	}
+	if cmd == nil {
+		t.Fatal("expected next page to be fetched at the end of the list")
+	}
+	step.Update(cmd())
+	if step.loadedCount() != sessionPageSize+5 {
+		t.Fatalf("expected all %d sessions loaded, got %d", sessionPageSize+5, step.loadedCount())
+	}
+	if step.SelectedSession() == "" {
+		t.Error("expected selection to stay on a session")
+	}
+
+	// Sorting by name reloads from the first page
+	step.Update(tea.KeyPressMsg{Code: 's', Text: "s"})
+	if sessionSortOrders[step.sortIdx] != session.SortByName || step.selectedIdx != 0 {
+		t.Fatalf("expected name order with selection reset, got %s at %d", sessionSortOrders[step.sortIdx], step.selectedIdx)
+	}
+	step.Update(step.fetchSessions(0)())
+	if step.loadedCount() != sessionPageSize || step.sessions[1].info.Name != "session-00" {
+		t.Errorf("expected first page in name order, got %d sessions starting with %s", step.loadedCount(), step.sessions[1].info.Name)
+	}
+
+	// A page of the previous order arriving late is ignored
+	step.Update(SessionsLoadedMsg{sessions: nil, total: 0, sort: session.SortByActivity})
+	if step.total != sessionPageSize+5 {
+		t.Error("expected stale page to be ignored")
+	}
+}